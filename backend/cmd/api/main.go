@@ -12,6 +12,9 @@ import (
 	"warehouse-backend/internal/db"
 	"warehouse-backend/internal/httpapi"
 	"warehouse-backend/internal/logger"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/scheduler"
+	"warehouse-backend/internal/service"
 
 	"github.com/rs/zerolog/log"
 )
@@ -51,6 +54,27 @@ func main() {
 
 	router := httpapi.NewRouter(pg, cfg)
 
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if cfg.SnapshotCron != "off" {
+		schedule, err := scheduler.ParseCron(cfg.SnapshotCron)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid SNAPSHOT_CRON")
+		}
+		snapshotJobService := service.NewStockSnapshotJobService(
+			repository.NewStockSnapshotRepository(pg.Pool),
+			repository.NewStockSnapshotRunRepository(pg.Pool),
+		)
+		go scheduler.Run(jobCtx, "stock_snapshots", schedule, func(ctx context.Context) error {
+			_, err := snapshotJobService.Generate(ctx, repository.SnapshotRunTriggerSchedule, nil)
+			if err == repository.ErrSnapshotGenerationLocked {
+				return nil
+			}
+			return err
+		})
+	}
+
 	addr := ":" + cfg.Port
 	srv := &http.Server{
 		Addr:    addr,
@@ -69,6 +93,7 @@ func main() {
 	<-quit
 
 	log.Info().Msg("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
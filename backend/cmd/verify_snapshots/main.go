@@ -0,0 +1,87 @@
+// Команда проверяет, что снапшоты остатков сходятся с движениями, и по флагу -repair исправляет расхождения.
+//
+//	go run ./cmd/verify_snapshots [-warehouse <id>] [-product <id>] [-repair]
+//
+// Код выхода 1, если остались неисправленные расхождения.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/config"
+	"warehouse-backend/internal/db"
+	"warehouse-backend/internal/logger"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+)
+
+func parseOptionalUUID(value, name string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %s: %v\n", name, err)
+		os.Exit(2)
+	}
+	return &id
+}
+
+func main() {
+	warehouse := flag.String("warehouse", "", "check only this warehouse")
+	product := flag.String("product", "", "check only this product")
+	repair := flag.Bool("repair", false, "set mismatched snapshots to the expected quantity")
+	flag.Parse()
+
+	warehouseID := parseOptionalUUID(*warehouse, "warehouse id")
+	productID := parseOptionalUUID(*product, "product id")
+
+	cfg := config.Load()
+	logger.Init(cfg.Env)
+
+	pg, err := db.New(db.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "DB connection failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer pg.Pool.Close()
+
+	auditService := service.NewAuditService(repository.NewAuditRepository(pg.Pool))
+	snapshotService := service.NewStockSnapshotService(
+		repository.NewStockSnapshotRepository(pg.Pool),
+		repository.NewWarehouseRepository(pg.Pool),
+		repository.NewProductRepository(pg.Pool),
+		auditService,
+	)
+
+	result, err := snapshotService.Verify(context.Background(), warehouseID, productID, *repair)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verification failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, m := range result.Mismatches {
+		status := ""
+		if m.Repaired {
+			status = " (repaired)"
+		}
+		fmt.Printf("%s product=%s warehouse=%s: quantity %d, expected %d = %d on %s + %d movements%s\n",
+			m.SnapshotDate.Format("2006-01-02"), m.ProductID, m.WarehouseID, m.Quantity, m.ExpectedQuantity,
+			m.PreviousQuantity, m.PreviousDate.Format("2006-01-02"), m.MovementsQuantity, status)
+	}
+	fmt.Printf("Mismatches: %d, repaired: %d\n", len(result.Mismatches), result.Repaired)
+
+	if len(result.Mismatches) > result.Repaired {
+		os.Exit(1)
+	}
+}
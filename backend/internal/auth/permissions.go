@@ -0,0 +1,54 @@
+package auth
+
+import "net/http"
+
+// Действия, из которых вместе с ресурсом складывается разрешение (например, "products:update")
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Ресурсы API, на которые выдаются разрешения (совпадают с каталогом permissions в schema.sql)
+const (
+	ResourceStock             = "stock"
+	ResourceProducts          = "products"
+	ResourceWarehouses        = "warehouses"
+	ResourceWarehouseTypes    = "warehouse_types"
+	ResourceStores            = "stores"
+	ResourceSupplierOrders    = "supplier_orders"
+	ResourceMpShipments       = "mp_shipments"
+	ResourceOrderStatuses     = "order_statuses"
+	ResourceShipmentStatuses  = "shipment_statuses"
+	ResourceInventoryStatuses = "inventory_statuses"
+	ResourceInventories       = "inventories"
+	ResourceTransfers         = "transfers"
+	ResourceMpReturns         = "mp_returns"
+	ResourceStorageLocations  = "storage_locations"
+	ResourceProductCosts      = "product_costs"
+	ResourceStockSnapshots    = "stock_snapshots"
+	ResourceUsers             = "users"
+	ResourceRoles             = "roles"
+	ResourceFiles             = "files"
+	ResourceAudit             = "audit"
+)
+
+// PermissionCode returns the "resource:action" code used in API responses and requests.
+func PermissionCode(resource, action string) string {
+	return resource + ":" + action
+}
+
+// ActionForMethod maps an HTTP method to the action it requires on a resource.
+func ActionForMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return ActionUpdate
+	case http.MethodDelete:
+		return ActionDelete
+	default:
+		return ActionRead
+	}
+}
@@ -19,6 +19,10 @@ type Config struct {
 
 	JWTSecret string // Секретный ключ для JWT токенов
 	BaseURL   string // Base URL for serving files (e.g., "http://localhost:8080")
+
+	SnapshotCron string // Расписание формирования снапшотов на конец месяца (cron, 5 полей); "off" - отключено
+
+	NegativeStockPolicy string // Отрицательные остатки: block, warn или allow
 }
 
 func Load() Config {
@@ -42,6 +46,10 @@ func Load() Config {
 
 		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 		BaseURL:   getEnv("BASE_URL", "http://localhost:"+port),
+
+		SnapshotCron: getEnv("SNAPSHOT_CRON", "5 0 1 * *"),
+
+		NegativeStockPolicy: getEnv("NEGATIVE_STOCK_POLICY", "warn"),
 	}
 
 	return cfg
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntryResponse struct {
+	AuditID    string          `json:"auditId"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Action     string          `json:"action"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Changes    json.RawMessage `json:"changes,omitempty"`
+	UserID     *string         `json:"userId,omitempty"`
+	UserEmail  *string         `json:"userEmail,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// AuditFieldChange is the old and new value of one field that changed, as stored in the audit entry.
+type AuditFieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
@@ -18,3 +18,8 @@ type RegisterRequest struct {
 	Surname    *string `json:"surname,omitempty"`
 	Patronymic *string `json:"patronymic,omitempty"`
 }
+
+type MeResponse struct {
+	UserResponse
+	Permissions []string `json:"permissions"`
+}
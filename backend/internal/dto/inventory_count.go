@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type InventoryCountResponse struct {
+	InventoryID  string                       `json:"inventoryId"`
+	WarehouseID  string                       `json:"warehouseId"`
+	StartedBy    *string                      `json:"startedBy,omitempty"`
+	StartedAt    time.Time                    `json:"startedAt"`
+	AppliedAt    *time.Time                   `json:"appliedAt,omitempty"`
+	TotalLines   int                          `json:"totalLines"`
+	CountedLines int                          `json:"countedLines"`
+	Lines        []InventoryCountLineResponse `json:"lines,omitempty"`
+}
+
+type InventoryCountLineResponse struct {
+	CountLineID string     `json:"countLineId"`
+	ProductID   string     `json:"productId"`
+	Article     string     `json:"article"`
+	Barcode     string     `json:"barcode"`
+	ExpectedQty int        `json:"expectedQty"`
+	CountedQty  *int       `json:"countedQty,omitempty"`
+	Difference  *int       `json:"difference,omitempty"` // countedQty - expectedQty, пока товар не пересчитан - нет
+	CountedBy   *string    `json:"countedBy,omitempty"`
+	CountedAt   *time.Time `json:"countedAt,omitempty"`
+}
+
+type InventoryCountStartRequest struct {
+	WarehouseID string `json:"warehouseId"`
+}
+
+// InventoryCountRecordRequest - результат пересчета товара, указанного по productId или штрихкоду.
+// По умолчанию quantity прибавляется к уже посчитанному, replace заменяет его
+type InventoryCountRecordRequest struct {
+	ProductID *string `json:"productId,omitempty"`
+	Barcode   *string `json:"barcode,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Replace   bool    `json:"replace,omitempty"`
+}
@@ -1,8 +1,12 @@
 package dto
 
+import "time"
+
 type InventoryStatusResponse struct {
-	InventoryStatusID string `json:"inventoryStatusId"`
-	Name              string `json:"name"`
+	InventoryStatusID string     `json:"inventoryStatusId"`
+	Name              string     `json:"name"`
+	IsArchived        bool       `json:"isArchived"`
+	ArchivedAt        *time.Time `json:"archivedAt,omitempty"`
 }
 
 type InventoryStatusCreateRequest struct {
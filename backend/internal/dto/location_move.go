@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+type LocationMoveResponse struct {
+	MoveID      string                     `json:"moveId"`
+	MoveNumber  string                     `json:"moveNumber"`
+	WarehouseID string                     `json:"warehouseId"`
+	Status      string                     `json:"status"` // Черновик, Проведен, Отменен
+	Notes       *string                    `json:"notes,omitempty"`
+	Items       []LocationMoveItemResponse `json:"items,omitempty"`
+	CreatedBy   *string                    `json:"createdBy,omitempty"`
+	CreatedAt   time.Time                  `json:"createdAt"`
+	UpdatedBy   *string                    `json:"updatedBy,omitempty"`
+	UpdatedAt   time.Time                  `json:"updatedAt"`
+}
+
+type LocationMoveItemResponse struct {
+	MoveItemID     string  `json:"moveItemId"`
+	ProductID      string  `json:"productId"`
+	Article        string  `json:"article"`
+	FromLocationID *string `json:"fromLocationId,omitempty"`
+	FromAddress    *string `json:"fromAddress,omitempty"`
+	ToLocationID   *string `json:"toLocationId,omitempty"`
+	ToAddress      *string `json:"toAddress,omitempty"`
+	Quantity       int     `json:"quantity"`
+}
+
+// LocationMoveItemRequest - позиция перемещения. Без fromLocationId товар берется из неразмещенного
+// остатка склада, без toLocationId - снимается из ячейки
+type LocationMoveItemRequest struct {
+	ProductID      string  `json:"productId"`
+	FromLocationID *string `json:"fromLocationId,omitempty"`
+	ToLocationID   *string `json:"toLocationId,omitempty"`
+	Quantity       int     `json:"quantity"`
+}
+
+type LocationMoveCreateRequest struct {
+	MoveNumber string                    `json:"moveNumber"`
+	Notes      *string                   `json:"notes,omitempty"`
+	Items      []LocationMoveItemRequest `json:"items"`
+}
+
+// LocationMoveUpdateRequest заменяет номер, примечание и позиции перемещения; допускается только для черновика
+type LocationMoveUpdateRequest struct {
+	MoveNumber string                    `json:"moveNumber"`
+	Notes      *string                   `json:"notes,omitempty"`
+	Items      []LocationMoveItemRequest `json:"items"`
+}
+
+type LocationMoveActionRequest struct {
+	Comment *string `json:"comment,omitempty"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+type MpReturnResponse struct {
+	ReturnID     string                 `json:"returnId"`
+	ReturnNumber string                 `json:"returnNumber"`
+	StoreID      string                 `json:"storeId"`
+	ShipmentID   *string                `json:"shipmentId,omitempty"`
+	WarehouseID  string                 `json:"warehouseId"`
+	ReturnType   string                 `json:"returnType"` // rejection, customer
+	Status       string                 `json:"status"`     // Черновик, Проведен, Отменен
+	ReturnDate   time.Time              `json:"returnDate"`
+	Notes        *string                `json:"notes,omitempty"`
+	Items        []MpReturnItemResponse `json:"items,omitempty"`
+	CreatedBy    *string                `json:"createdBy,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	UpdatedBy    *string                `json:"updatedBy,omitempty"`
+	UpdatedAt    time.Time              `json:"updatedAt"`
+}
+
+type MpReturnItemResponse struct {
+	ReturnItemID string `json:"returnItemId"`
+	ProductID    string `json:"productId"`
+	Article      string `json:"article"`
+	Quantity     int    `json:"quantity"`
+	Condition    string `json:"condition"` // resellable, defect, write_off
+}
+
+type MpReturnItemRequest struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	Condition string `json:"condition"`
+}
+
+type MpReturnCreateRequest struct {
+	ReturnNumber string                `json:"returnNumber"`
+	StoreID      string                `json:"storeId"`
+	ShipmentID   *string               `json:"shipmentId,omitempty"`
+	WarehouseID  string                `json:"warehouseId"`          // склад возвратов
+	ReturnType   *string               `json:"returnType,omitempty"` // по умолчанию customer
+	ReturnDate   *time.Time            `json:"returnDate,omitempty"` // по умолчанию текущая дата
+	Notes        *string               `json:"notes,omitempty"`
+	Items        []MpReturnItemRequest `json:"items"`
+}
+
+// MpReturnUpdateRequest заменяет шапку и позиции возврата; допускается только для черновика
+type MpReturnUpdateRequest struct {
+	ReturnNumber string                `json:"returnNumber"`
+	StoreID      string                `json:"storeId"`
+	ShipmentID   *string               `json:"shipmentId,omitempty"`
+	WarehouseID  string                `json:"warehouseId"`
+	ReturnType   *string               `json:"returnType,omitempty"`
+	ReturnDate   *time.Time            `json:"returnDate,omitempty"`
+	Notes        *string               `json:"notes,omitempty"`
+	Items        []MpReturnItemRequest `json:"items"`
+}
+
+type MpReturnActionRequest struct {
+	Comment *string `json:"comment,omitempty"`
+}
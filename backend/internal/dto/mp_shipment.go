@@ -3,51 +3,60 @@ package dto
 import "time"
 
 type MpShipmentResponse struct {
-	ShipmentID     string     `json:"shipmentId"`
-	ShipmentDate   *time.Time `json:"shipmentDate,omitempty"`
-	ShipmentNumber string     `json:"shipmentNumber"`
-	StoreID        *string    `json:"storeId,omitempty"`
-	WarehouseID    *string    `json:"warehouseId,omitempty"`
-	StatusID       *string    `json:"statusId,omitempty"`
-	LogisticsCost  *float64   `json:"logisticsCost,omitempty"`
-	UnitLogistics  *float64   `json:"unitLogistics,omitempty"`
-	AcceptanceCost *float64   `json:"acceptanceCost,omitempty"`
-	AcceptanceDate *time.Time `json:"acceptanceDate,omitempty"`
-	PositionsQty   int        `json:"positionsQty"`
-	SentQty        int        `json:"sentQty"`
-	AcceptedQty    int        `json:"acceptedQty"`
-	CreatedBy      *string    `json:"createdBy,omitempty"`
-	CreatedAt      time.Time  `json:"createdAt"`
-	UpdatedBy      *string    `json:"updatedBy,omitempty"`
-	UpdatedAt      time.Time  `json:"updatedAt"`
+	ShipmentID                string     `json:"shipmentId"`
+	ShipmentDate              *time.Time `json:"shipmentDate,omitempty"`
+	ShipmentNumber            string     `json:"shipmentNumber"`
+	StoreID                   *string    `json:"storeId,omitempty"`
+	WarehouseID               *string    `json:"warehouseId,omitempty"`
+	StatusID                  *string    `json:"statusId,omitempty"`
+	LogisticsCost             *float64   `json:"logisticsCost,omitempty"`
+	UnitLogistics             *float64   `json:"unitLogistics,omitempty"`
+	AcceptanceCost            *float64   `json:"acceptanceCost,omitempty"`
+	AcceptanceDate            *time.Time `json:"acceptanceDate,omitempty"`
+	PositionsQty              int        `json:"positionsQty"`
+	SentQty                   int        `json:"sentQty"`
+	AcceptedQty               int        `json:"acceptedQty"`
+	LogisticsAllocationMethod string     `json:"logisticsAllocationMethod"`
+	ResolutionDate            *time.Time `json:"resolutionDate,omitempty"`
+	CreatedBy                 *string    `json:"createdBy,omitempty"`
+	CreatedAt                 time.Time  `json:"createdAt"`
+	UpdatedBy                 *string    `json:"updatedBy,omitempty"`
+	UpdatedAt                 time.Time  `json:"updatedAt"`
 }
 
 type MpShipmentCreateRequest struct {
-	ShipmentDate   *time.Time `json:"shipmentDate,omitempty"`
-	ShipmentNumber string     `json:"shipmentNumber"`
-	StoreID        *string    `json:"storeId,omitempty"`
-	WarehouseID    *string    `json:"warehouseId,omitempty"`
-	StatusID       *string    `json:"statusId,omitempty"`
-	LogisticsCost  *float64   `json:"logisticsCost,omitempty"`
-	UnitLogistics  *float64   `json:"unitLogistics,omitempty"`
-	AcceptanceCost *float64   `json:"acceptanceCost,omitempty"`
-	AcceptanceDate *time.Time `json:"acceptanceDate,omitempty"`
-	PositionsQty   int        `json:"positionsQty"`
-	SentQty        int        `json:"sentQty"`
-	AcceptedQty    int        `json:"acceptedQty"`
+	ShipmentDate              *time.Time `json:"shipmentDate,omitempty"`
+	ShipmentNumber            string     `json:"shipmentNumber"`
+	StoreID                   *string    `json:"storeId,omitempty"`
+	WarehouseID               *string    `json:"warehouseId,omitempty"`
+	StatusID                  *string    `json:"statusId,omitempty"`
+	LogisticsCost             *float64   `json:"logisticsCost,omitempty"`
+	AcceptanceCost            *float64   `json:"acceptanceCost,omitempty"`
+	AcceptanceDate            *time.Time `json:"acceptanceDate,omitempty"`
+	LogisticsAllocationMethod string     `json:"logisticsAllocationMethod,omitempty"`
 }
 
 type MpShipmentUpdateRequest struct {
-	ShipmentDate   *time.Time `json:"shipmentDate,omitempty"`
-	ShipmentNumber string     `json:"shipmentNumber"`
-	StoreID        *string    `json:"storeId,omitempty"`
-	WarehouseID    *string    `json:"warehouseId,omitempty"`
-	StatusID       *string    `json:"statusId,omitempty"`
-	LogisticsCost  *float64   `json:"logisticsCost,omitempty"`
-	UnitLogistics  *float64   `json:"unitLogistics,omitempty"`
-	AcceptanceCost *float64   `json:"acceptanceCost,omitempty"`
-	AcceptanceDate *time.Time `json:"acceptanceDate,omitempty"`
-	PositionsQty   int        `json:"positionsQty"`
-	SentQty        int        `json:"sentQty"`
-	AcceptedQty    int        `json:"acceptedQty"`
+	ShipmentDate              *time.Time `json:"shipmentDate,omitempty"`
+	ShipmentNumber            string     `json:"shipmentNumber"`
+	StoreID                   *string    `json:"storeId,omitempty"`
+	WarehouseID               *string    `json:"warehouseId,omitempty"`
+	StatusID                  *string    `json:"statusId,omitempty"`
+	LogisticsCost             *float64   `json:"logisticsCost,omitempty"`
+	AcceptanceCost            *float64   `json:"acceptanceCost,omitempty"`
+	AcceptanceDate            *time.Time `json:"acceptanceDate,omitempty"`
+	LogisticsAllocationMethod string     `json:"logisticsAllocationMethod,omitempty"`
+}
+
+type MpShipmentItemResolutionRequest struct {
+	ShipmentItemID string `json:"shipmentItemId"`
+	ReturnedQty    int    `json:"returnedQty"`
+	LostQty        int    `json:"lostQty"`
+}
+
+// MpShipmentResolutionRequest - разбор расхождения принятой или отклоненной отгрузки. Для каждой позиции
+// с расхождением returnedQty + lostQty должно равняться sentQty - acceptedQty
+type MpShipmentResolutionRequest struct {
+	ResolutionDate *time.Time                        `json:"resolutionDate,omitempty"` // по умолчанию текущая дата
+	Items          []MpShipmentItemResolutionRequest `json:"items"`
 }
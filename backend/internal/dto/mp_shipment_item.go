@@ -8,22 +8,22 @@ type MpShipmentItemResponse struct {
 	SentQty          int      `json:"sentQty"`
 	AcceptedQty      int      `json:"acceptedQty"`
 	LogisticsForItem *float64 `json:"logisticsForItem,omitempty"`
+	ReturnedQty      int      `json:"returnedQty"`
+	LostQty          int      `json:"lostQty"`
 }
 
 type MpShipmentItemCreateRequest struct {
-	ShipmentID       string   `json:"shipmentId"`
-	ProductID        string   `json:"productId"`
-	WarehouseID      string   `json:"warehouseId"`
-	SentQty          int      `json:"sentQty"`
-	AcceptedQty      int      `json:"acceptedQty"`
-	LogisticsForItem *float64 `json:"logisticsForItem,omitempty"`
+	ShipmentID  string `json:"shipmentId"`
+	ProductID   string `json:"productId"`
+	WarehouseID string `json:"warehouseId"`
+	SentQty     int    `json:"sentQty"`
+	AcceptedQty int    `json:"acceptedQty"`
 }
 
 type MpShipmentItemUpdateRequest struct {
-	ShipmentID       string   `json:"shipmentId"`
-	ProductID        string   `json:"productId"`
-	WarehouseID      string   `json:"warehouseId"`
-	SentQty          int      `json:"sentQty"`
-	AcceptedQty      int      `json:"acceptedQty"`
-	LogisticsForItem *float64 `json:"logisticsForItem,omitempty"`
+	ShipmentID  string `json:"shipmentId"`
+	ProductID   string `json:"productId"`
+	WarehouseID string `json:"warehouseId"`
+	SentQty     int    `json:"sentQty"`
+	AcceptedQty int    `json:"acceptedQty"`
 }
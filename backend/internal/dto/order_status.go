@@ -1,8 +1,12 @@
 package dto
 
+import "time"
+
 type OrderStatusResponse struct {
-	OrderStatusID string `json:"orderStatusId"`
-	Name          string `json:"name"`
+	OrderStatusID string     `json:"orderStatusId"`
+	Name          string     `json:"name"`
+	IsArchived    bool       `json:"isArchived"`
+	ArchivedAt    *time.Time `json:"archivedAt,omitempty"`
 }
 
 type OrderStatusCreateRequest struct {
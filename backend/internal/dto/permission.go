@@ -0,0 +1,12 @@
+package dto
+
+type PermissionResponse struct {
+	PermissionID string `json:"permissionId"`
+	Resource     string `json:"resource"`
+	Action       string `json:"action"`
+	Code         string `json:"code"`
+}
+
+type RolePermissionsUpdateRequest struct {
+	Permissions []string `json:"permissions"`
+}
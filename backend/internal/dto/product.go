@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type ProductResponse struct {
 	ProductID       string                `json:"productId"`
 	Article         string                `json:"article"`
@@ -9,6 +11,8 @@ type ProductResponse struct {
 	PurchasePrice   *float64              `json:"purchasePrice,omitempty"`
 	ProcessingPrice *float64              `json:"processingPrice,omitempty"`
 	Images          []ProductImageResponse `json:"images,omitempty"`
+	IsArchived      bool                  `json:"isArchived"`
+	ArchivedAt      *time.Time            `json:"archivedAt,omitempty"`
 }
 
 type ProductImageResponse struct {
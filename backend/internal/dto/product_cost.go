@@ -9,6 +9,7 @@ type ProductCostResponse struct {
 	PeriodEnd           time.Time `json:"periodEnd"`
 	UnitCostToWarehouse float64   `json:"unitCostToWarehouse"`
 	Notes               *string   `json:"notes,omitempty"`
+	SourceOrderID       *string   `json:"sourceOrderId,omitempty"`
 	CreatedBy           *string  `json:"createdBy,omitempty"`
 	CreatedAt           time.Time `json:"createdAt"`
 	UpdatedBy           *string  `json:"updatedBy,omitempty"`
@@ -21,6 +22,7 @@ type ProductCostCreateRequest struct {
 	PeriodEnd           time.Time `json:"periodEnd"`
 	UnitCostToWarehouse float64   `json:"unitCostToWarehouse"`
 	Notes               *string   `json:"notes,omitempty"`
+	AutoTrim            bool      `json:"autoTrim,omitempty"` // укоротить предыдущий пересекающийся период вместо ошибки
 }
 
 type ProductCostUpdateRequest struct {
@@ -29,4 +31,20 @@ type ProductCostUpdateRequest struct {
 	PeriodEnd           time.Time `json:"periodEnd"`
 	UnitCostToWarehouse float64   `json:"unitCostToWarehouse"`
 	Notes               *string   `json:"notes,omitempty"`
+	AutoTrim            bool      `json:"autoTrim,omitempty"` // укоротить предыдущий пересекающийся период вместо ошибки
+}
+
+type DateRangeResponse struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// ProductCostCoverageResponse - товар, у которого в запрошенном диапазоне нет себестоимости на часть дней или есть пересечения периодов
+type ProductCostCoverageResponse struct {
+	ProductID     string              `json:"productId"`
+	Article       string              `json:"article"`
+	NoCost        bool                `json:"noCost"`
+	UncoveredDays int                 `json:"uncoveredDays"`
+	Gaps          []DateRangeResponse `json:"gaps"`
+	Overlaps      []DateRangeResponse `json:"overlaps,omitempty"`
 }
@@ -7,8 +7,9 @@ type APIResponse[T any] struct {
 }
 
 type Meta struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
 type Error struct {
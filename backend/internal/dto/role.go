@@ -1,8 +1,9 @@
 package dto
 
 type RoleResponse struct {
-	RoleID string `json:"roleId"`
-	Name   string `json:"name"`
+	RoleID      string   `json:"roleId"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 type RoleCreateRequest struct {
@@ -1,8 +1,12 @@
 package dto
 
+import "time"
+
 type ShipmentStatusResponse struct {
-	ShipmentStatusID string `json:"shipmentStatusId"`
-	Name             string `json:"name"`
+	ShipmentStatusID string     `json:"shipmentStatusId"`
+	Name             string     `json:"name"`
+	IsArchived       bool       `json:"isArchived"`
+	ArchivedAt       *time.Time `json:"archivedAt,omitempty"`
 }
 
 type ShipmentStatusCreateRequest struct {
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type StatusTransitionRequest struct {
+	StatusID string  `json:"statusId"`
+	Comment  *string `json:"comment,omitempty"`
+}
+
+type StatusHistoryResponse struct {
+	HistoryID      string    `json:"historyId"`
+	FromStatus     *string   `json:"fromStatus,omitempty"`
+	ToStatus       string    `json:"toStatus"`
+	Comment        *string   `json:"comment,omitempty"`
+	ChangedBy      *string   `json:"changedBy,omitempty"`
+	ChangedByEmail *string   `json:"changedByEmail,omitempty"`
+	ChangedAt      time.Time `json:"changedAt"`
+}
@@ -1,7 +1,45 @@
 package dto
 
+import "time"
+
 type StockItemResponse struct {
 	ProductID       string `json:"productId"`
 	WarehouseID     string `json:"warehouseId"`
 	CurrentQuantity int    `json:"currentQuantity"`
 }
+
+type StockMovementResponse struct {
+	MovementID     string    `json:"movementId"`
+	ProductID      *string   `json:"productId,omitempty"`
+	WarehouseID    string    `json:"warehouseId"`
+	MovementDate   time.Time `json:"movementDate"`
+	Quantity       int       `json:"quantity"`
+	MovementType   string    `json:"movementType"`
+	DocumentID     string    `json:"documentId"`
+	RunningBalance int       `json:"runningBalance"`
+}
+
+type StockAsOfItemResponse struct {
+	ProductID         string     `json:"productId"`
+	WarehouseID       string     `json:"warehouseId"`
+	AsOfDate          time.Time  `json:"asOfDate"`
+	SnapshotDate      *time.Time `json:"snapshotDate,omitempty"`
+	SnapshotQuantity  int        `json:"snapshotQuantity"`
+	MovementsQuantity int        `json:"movementsQuantity"`
+	Quantity          int        `json:"quantity"`
+}
+
+type StockAvailabilityResponse struct {
+	ProductID   string `json:"productId"`
+	WarehouseID string `json:"warehouseId"`
+	OnHand      int    `json:"onHand"`
+	Reserved    int    `json:"reserved"`
+	Available   int    `json:"available"`
+}
+
+// NegativeStockReportResponse - товары с отрицательным остатком и документы, которые увели остаток ниже нуля
+type NegativeStockReportResponse struct {
+	Policy    string                  `json:"policy"` // block, warn, allow
+	Items     []StockItemResponse     `json:"items"`
+	Documents []StockMovementResponse `json:"documents"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+type StockLevelResponse struct {
+	LevelID     string    `json:"levelId"`
+	ProductID   string    `json:"productId"`
+	WarehouseID string    `json:"warehouseId"`
+	MinQty      int       `json:"minQty"`
+	MaxQty      int       `json:"maxQty"`
+	CreatedBy   *string   `json:"createdBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedBy   *string   `json:"updatedBy,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type StockLevelCreateRequest struct {
+	ProductID   string `json:"productId"`
+	WarehouseID string `json:"warehouseId"`
+	MinQty      int    `json:"minQty"`
+	MaxQty      int    `json:"maxQty"`
+}
+
+type StockLevelUpdateRequest struct {
+	ProductID   string `json:"productId"`
+	WarehouseID string `json:"warehouseId"`
+	MinQty      int    `json:"minQty"`
+	MaxQty      int    `json:"maxQty"`
+}
+
+// ReorderSuggestionResponse - товар, остаток которого вместе с ожидаемыми поставками (projectedQty) ниже
+// минимального; suggestedQty доводит его до максимального
+type ReorderSuggestionResponse struct {
+	LevelID       string   `json:"levelId"`
+	ProductID     string   `json:"productId"`
+	Article       string   `json:"article"`
+	WarehouseID   string   `json:"warehouseId"`
+	MinQty        int      `json:"minQty"`
+	MaxQty        int      `json:"maxQty"`
+	CurrentQty    int      `json:"currentQty"`
+	IncomingQty   int      `json:"incomingQty"` // не поступило по открытым заказам поставщиков
+	ProjectedQty  int      `json:"projectedQty"`
+	SuggestedQty  int      `json:"suggestedQty"`
+	PurchasePrice *float64 `json:"purchasePrice,omitempty"`
+}
+
+// ReorderOrderCreateRequest - создание черновика заказа поставщику из предложений дозаказа.
+// Без warehouseId/productIds в заказ попадают все предложения
+type ReorderOrderCreateRequest struct {
+	OrderNumber *string  `json:"orderNumber,omitempty"` // по умолчанию REORDER-<дата>-<время>
+	Buyer       *string  `json:"buyer,omitempty"`
+	WarehouseID *string  `json:"warehouseId,omitempty"`
+	ProductIDs  []string `json:"productIds,omitempty"`
+}
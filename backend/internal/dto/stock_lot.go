@@ -0,0 +1,66 @@
+package dto
+
+import "time"
+
+// StockLotResponse - остаток партии на складе. Партия может быть разнесена перемещениями по нескольким складам.
+// У начальной партии (opening) нет заказа и себестоимости, receiptDate - дата самого раннего снапшота
+type StockLotResponse struct {
+	LotID          string    `json:"lotId"` // позиция заказа поставщику или снапшот начальной партии
+	Opening        bool      `json:"opening"`
+	OrderID        *string   `json:"orderId,omitempty"`
+	OrderNumber    string    `json:"orderNumber,omitempty"`
+	ProductID      string    `json:"productId"`
+	WarehouseID    string    `json:"warehouseId"`
+	ReceiptDate    time.Time `json:"receiptDate"`
+	UnitCost       *float64  `json:"unitCost,omitempty"`
+	ReceivedQty    int       `json:"receivedQty"`
+	RemainingQty   int       `json:"remainingQty"`
+	RemainingValue *float64  `json:"remainingValue,omitempty"`
+}
+
+type StockLotReportResponse struct {
+	Items      []StockLotResponse `json:"items"`
+	TotalQty   int                `json:"totalQty"`
+	TotalValue float64            `json:"totalValue"`
+	// UncostedQty - остаток партий без себестоимости, не вошедший в totalValue
+	UncostedQty int `json:"uncostedQty"`
+}
+
+type MpShipmentLotCostResponse struct {
+	LotID       string    `json:"lotId"`
+	Opening     bool      `json:"opening"`
+	OrderNumber string    `json:"orderNumber,omitempty"`
+	ReceiptDate time.Time `json:"receiptDate"`
+	Quantity    int       `json:"quantity"`
+	UnitCost    *float64  `json:"unitCost,omitempty"`
+}
+
+type MpShipmentItemCostResponse struct {
+	ShipmentItemID string                      `json:"shipmentItemId"`
+	ProductID      string                      `json:"productId"`
+	WarehouseID    string                      `json:"warehouseId"`
+	SentQty        int                         `json:"sentQty"`
+	ReturnedQty    int                         `json:"returnedQty"`
+	UncostedQty    int                         `json:"uncostedQty"`
+	Cost           float64                     `json:"cost"`
+	ReturnedCost   float64                     `json:"returnedCost"`
+	NetCost        float64                     `json:"netCost"`
+	Lots           []MpShipmentLotCostResponse `json:"lots"`
+}
+
+// MpShipmentCostResponse - себестоимость отгруженного товара по партиям (FIFO). uncostedQty - отправленное
+// количество, не покрытое партиями с известной себестоимостью
+type MpShipmentCostResponse struct {
+	ShipmentID     string                       `json:"shipmentId"`
+	ShipmentNumber string                       `json:"shipmentNumber"`
+	ShipmentDate   time.Time                    `json:"shipmentDate"`
+	StoreID        *string                      `json:"storeId,omitempty"`
+	WarehouseID    *string                      `json:"warehouseId,omitempty"`
+	SentQty        int                          `json:"sentQty"`
+	ReturnedQty    int                          `json:"returnedQty"`
+	UncostedQty    int                          `json:"uncostedQty"`
+	Cost           float64                      `json:"cost"`
+	ReturnedCost   float64                      `json:"returnedCost"`
+	NetCost        float64                      `json:"netCost"`
+	Items          []MpShipmentItemCostResponse `json:"items,omitempty"`
+}
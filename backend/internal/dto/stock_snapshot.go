@@ -25,3 +25,34 @@ type StockSnapshotUpdateRequest struct {
 	SnapshotDate time.Time `json:"snapshotDate"`
 	Quantity     int       `json:"quantity"`
 }
+
+type StockSnapshotRunResponse struct {
+	RunID            string     `json:"runId"`
+	Trigger          string     `json:"trigger"` // schedule, manual
+	SnapshotDate     time.Time  `json:"snapshotDate"`
+	Status           string     `json:"status"` // running, succeeded, failed, skipped
+	SnapshotsCreated int        `json:"snapshotsCreated"`
+	Error            *string    `json:"error,omitempty"`
+	StartedBy        *string    `json:"startedBy,omitempty"`
+	StartedAt        time.Time  `json:"startedAt"`
+	FinishedAt       *time.Time `json:"finishedAt,omitempty"`
+}
+
+type StockSnapshotMismatchResponse struct {
+	SnapshotID        string    `json:"snapshotId"`
+	ProductID         string    `json:"productId"`
+	WarehouseID       string    `json:"warehouseId"`
+	SnapshotDate      time.Time `json:"snapshotDate"`
+	PreviousDate      time.Time `json:"previousDate"`
+	PreviousQuantity  int       `json:"previousQuantity"`
+	MovementsQuantity int       `json:"movementsQuantity"`
+	Quantity          int       `json:"quantity"`
+	ExpectedQuantity  int       `json:"expectedQuantity"` // previousQuantity + movementsQuantity
+	Difference        int       `json:"difference"`       // quantity - expectedQuantity
+	Repaired          bool      `json:"repaired"`
+}
+
+type StockSnapshotVerifyResponse struct {
+	Mismatches []StockSnapshotMismatchResponse `json:"mismatches"`
+	Repaired   int                             `json:"repaired"`
+}
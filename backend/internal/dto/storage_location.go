@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+type StorageLocationResponse struct {
+	LocationID   string     `json:"locationId"`
+	WarehouseID  string     `json:"warehouseId"`
+	ParentID     *string    `json:"parentId,omitempty"`
+	LocationType string     `json:"locationType"` // zone, rack, shelf, bin
+	Code         string     `json:"code"`
+	Address      string     `json:"address"` // коды по цепочке родителей, например A-01-3-2
+	Name         *string    `json:"name,omitempty"`
+	IsArchived   bool       `json:"isArchived"`
+	ArchivedAt   *time.Time `json:"archivedAt,omitempty"`
+	CreatedBy    *string    `json:"createdBy,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedBy    *string    `json:"updatedBy,omitempty"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// StorageLocationCreateRequest - новое место хранения. Зона создается без родителя, остальные уровни -
+// под местом на уровень выше (стеллаж в зоне, полка на стеллаже, ячейка на полке)
+type StorageLocationCreateRequest struct {
+	ParentID     *string `json:"parentId,omitempty"`
+	LocationType string  `json:"locationType"`
+	Code         string  `json:"code"`
+	Name         *string `json:"name,omitempty"`
+}
+
+type StorageLocationUpdateRequest struct {
+	Code string  `json:"code"`
+	Name *string `json:"name,omitempty"`
+}
+
+// LocationStockResponse - остаток товара в ячейке. Строка без locationId - неразмещенный остаток склада
+type LocationStockResponse struct {
+	LocationID *string `json:"locationId,omitempty"`
+	Address    *string `json:"address,omitempty"`
+	ProductID  string  `json:"productId"`
+	Article    string  `json:"article"`
+	Quantity   int     `json:"quantity"`
+}
+
+// PutAwaySuggestionResponse - куда разместить неразмещенный товар: ячейка, где товар уже лежит, или
+// свободная ячейка. Без locationId - свободных ячеек не осталось
+type PutAwaySuggestionResponse struct {
+	ProductID   string  `json:"productId"`
+	Article     string  `json:"article"`
+	Quantity    int     `json:"quantity"`
+	LocationID  *string `json:"locationId,omitempty"`
+	Address     *string `json:"address,omitempty"`
+	LocationQty int     `json:"locationQty"` // сколько товара уже лежит в предложенной ячейке
+}
+
+// PutAwayCreateRequest создает черновик перемещения по предложениям размещения. С orderId размещается
+// только товар, полученный на склад по этому заказу поставщику
+type PutAwayCreateRequest struct {
+	MoveNumber *string `json:"moveNumber,omitempty"` // по умолчанию PUTAWAY-<дата-время>
+	OrderID    *string `json:"orderId,omitempty"`
+	Notes      *string `json:"notes,omitempty"`
+}
@@ -1,8 +1,12 @@
 package dto
 
+import "time"
+
 type StoreResponse struct {
-	StoreID string `json:"storeId"`
-	Name    string `json:"name"`
+	StoreID    string     `json:"storeId"`
+	Name       string     `json:"name"`
+	IsArchived bool       `json:"isArchived"`
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
 }
 
 type StoreCreateRequest struct {
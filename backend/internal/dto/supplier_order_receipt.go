@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+type SupplierOrderReceiveItemRequest struct {
+	OrderItemID string `json:"orderItemId"`
+	ReceivedQty int    `json:"receivedQty"`
+}
+
+type SupplierOrderReceiveRequest struct {
+	ReceiptDate *time.Time                        `json:"receiptDate,omitempty"`
+	Notes       *string                           `json:"notes,omitempty"`
+	Items       []SupplierOrderReceiveItemRequest `json:"items"`
+}
+
+type SupplierOrderReceiptItemResponse struct {
+	ReceiptItemID  string `json:"receiptItemId"`
+	OrderItemID    string `json:"orderItemId"`
+	ProductID      string `json:"productId"`
+	WarehouseID    string `json:"warehouseId"`
+	OrderedQty     int    `json:"orderedQty"`
+	ReceivedQty    int    `json:"receivedQty"`
+	DiscrepancyQty int    `json:"discrepancyQty"`
+	Discrepancy    string `json:"discrepancy"` // MATCH, SHORTAGE, OVERAGE
+}
+
+type SupplierOrderReceiptResponse struct {
+	ReceiptID        string                             `json:"receiptId"`
+	OrderID          string                             `json:"orderId"`
+	ReceiptDate      time.Time                          `json:"receiptDate"`
+	Notes            *string                            `json:"notes,omitempty"`
+	ReceivedBy       *string                            `json:"receivedBy,omitempty"`
+	CreatedAt        time.Time                          `json:"createdAt"`
+	TotalOrderedQty  int                                `json:"totalOrderedQty"`
+	TotalReceivedQty int                                `json:"totalReceivedQty"`
+	HasDiscrepancies bool                               `json:"hasDiscrepancies"`
+	Items            []SupplierOrderReceiptItemResponse `json:"items"`
+	Costs            []SupplierOrderReceiptCostResponse `json:"costs,omitempty"`
+}
+
+// SupplierOrderReceiptCostResponse - период себестоимости, открытый приемкой
+type SupplierOrderReceiptCostResponse struct {
+	CostID           string    `json:"costId"`
+	ProductID        string    `json:"productId"`
+	PreviousUnitCost *float64  `json:"previousUnitCost,omitempty"`
+	ExistingQty      int       `json:"existingQty"`
+	ReceivedQty      int       `json:"receivedQty"`
+	ReceivedUnitCost float64   `json:"receivedUnitCost"`
+	UnitCost         float64   `json:"unitCost"`
+	PeriodStart      time.Time `json:"periodStart"`
+	PeriodEnd        time.Time `json:"periodEnd"`
+}
@@ -0,0 +1,72 @@
+package dto
+
+import "time"
+
+type TransferResponse struct {
+	TransferID             string                 `json:"transferId"`
+	TransferNumber         string                 `json:"transferNumber"`
+	SourceWarehouseID      string                 `json:"sourceWarehouseId"`
+	DestinationWarehouseID string                 `json:"destinationWarehouseId"`
+	Status                 string                 `json:"status"` // Черновик, В пути, Получено, Отменено
+	ShipmentDate           *time.Time             `json:"shipmentDate,omitempty"`
+	ReceiptDate            *time.Time             `json:"receiptDate,omitempty"`
+	Notes                  *string                `json:"notes,omitempty"`
+	Items                  []TransferItemResponse `json:"items,omitempty"`
+	CreatedBy              *string                `json:"createdBy,omitempty"`
+	CreatedAt              time.Time              `json:"createdAt"`
+	UpdatedBy              *string                `json:"updatedBy,omitempty"`
+	UpdatedAt              time.Time              `json:"updatedAt"`
+}
+
+type TransferItemResponse struct {
+	TransferItemID string `json:"transferItemId"`
+	ProductID      string `json:"productId"`
+	Article        string `json:"article"`
+	ShippedQty     int    `json:"shippedQty"`
+	ReceivedQty    int    `json:"receivedQty"`
+	DiscrepancyQty *int   `json:"discrepancyQty,omitempty"` // receivedQty - shippedQty, только для полученных
+}
+
+type TransferItemRequest struct {
+	ProductID  string `json:"productId"`
+	ShippedQty int    `json:"shippedQty"`
+}
+
+type TransferCreateRequest struct {
+	TransferNumber         string                `json:"transferNumber"`
+	SourceWarehouseID      string                `json:"sourceWarehouseId"`
+	DestinationWarehouseID string                `json:"destinationWarehouseId"`
+	Notes                  *string               `json:"notes,omitempty"`
+	Items                  []TransferItemRequest `json:"items"`
+}
+
+// TransferUpdateRequest заменяет шапку и позиции перемещения; допускается только для черновика
+type TransferUpdateRequest struct {
+	TransferNumber         string                `json:"transferNumber"`
+	SourceWarehouseID      string                `json:"sourceWarehouseId"`
+	DestinationWarehouseID string                `json:"destinationWarehouseId"`
+	Notes                  *string               `json:"notes,omitempty"`
+	Items                  []TransferItemRequest `json:"items"`
+}
+
+type TransferShipRequest struct {
+	ShipmentDate *time.Time `json:"shipmentDate,omitempty"` // по умолчанию текущая дата
+	Comment      *string    `json:"comment,omitempty"`
+}
+
+type TransferReceiveItemRequest struct {
+	ProductID   string `json:"productId"`
+	ReceivedQty int    `json:"receivedQty"`
+}
+
+// TransferReceiveRequest - приемка перемещения. Позиции, которых нет в items, считаются полученными
+// в отправленном количестве
+type TransferReceiveRequest struct {
+	ReceiptDate *time.Time                   `json:"receiptDate,omitempty"` // по умолчанию текущая дата
+	Items       []TransferReceiveItemRequest `json:"items,omitempty"`
+	Comment     *string                      `json:"comment,omitempty"`
+}
+
+type TransferCancelRequest struct {
+	Comment *string `json:"comment,omitempty"`
+}
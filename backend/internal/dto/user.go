@@ -1,12 +1,16 @@
 package dto
 
+import "time"
+
 type UserResponse struct {
-	UserID     string  `json:"userId"`
-	Email      string  `json:"email"`
-	Name       *string `json:"name,omitempty"`
-	Surname    *string `json:"surname,omitempty"`
-	Patronymic *string `json:"patronymic,omitempty"`
-	RoleID     string  `json:"roleId"`
+	UserID     string     `json:"userId"`
+	Email      string     `json:"email"`
+	Name       *string    `json:"name,omitempty"`
+	Surname    *string    `json:"surname,omitempty"`
+	Patronymic *string    `json:"patronymic,omitempty"`
+	RoleID     string     `json:"roleId"`
+	IsArchived bool       `json:"isArchived"`
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
 }
 
 type UserCreateRequest struct {
@@ -1,10 +1,14 @@
 package dto
 
+import "time"
+
 type WarehouseResponse struct {
-	WarehouseID     string  `json:"warehouseId"`
-	Name            string  `json:"name"`
-	WarehouseTypeID *string `json:"warehouseTypeId,omitempty"`
-	Location        *string `json:"location,omitempty"`
+	WarehouseID     string     `json:"warehouseId"`
+	Name            string     `json:"name"`
+	WarehouseTypeID *string    `json:"warehouseTypeId,omitempty"`
+	Location        *string    `json:"location,omitempty"`
+	IsArchived      bool       `json:"isArchived"`
+	ArchivedAt      *time.Time `json:"archivedAt,omitempty"`
 }
 
 type WarehouseCreateRequest struct {
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	var filter repository.AuditFilter
+	q := r.URL.Query()
+
+	if v := q.Get("entityType"); v != "" {
+		filter.EntityType = &v
+	}
+	if v := q.Get("entityId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ENTITY_ID", "invalid entityId")
+			return
+		}
+		filter.EntityID = &id
+	}
+	if v := q.Get("userId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_USER_ID", "invalid userId")
+			return
+		}
+		filter.UserID = &id
+	}
+	if v := q.Get("action"); v != "" {
+		if v != repository.AuditActionCreate && v != repository.AuditActionUpdate && v != repository.AuditActionDelete {
+			writeError(w, http.StatusBadRequest, "INVALID_ACTION", "action must be one of create, update, delete")
+			return
+		}
+		filter.Action = &v
+	}
+	if v := q.Get("dateFrom"); v != "" {
+		date, err := parseDate(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE", "dateFrom must be in YYYY-MM-DD format")
+			return
+		}
+		filter.DateFrom = &date
+	}
+	if v := q.Get("dateTo"); v != "" {
+		date, err := parseDate(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE", "dateTo must be in YYYY-MM-DD format")
+			return
+		}
+		filter.DateTo = &date
+	}
+	if filter.DateFrom != nil && filter.DateTo != nil && filter.DateTo.Before(*filter.DateFrom) {
+		writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "dateTo must not be before dateFrom")
+		return
+	}
+
+	limit := parseInt(q.Get("limit"), 50)
+	offset := parseInt(q.Get("offset"), 0)
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be non-negative")
+		return
+	}
+
+	entries, err := h.service.List(r.Context(), filter, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Interface("filter", filter).Msg("Failed to load audit log")
+		writeError(w, http.StatusInternalServerError, "AUDIT_LOAD_FAILED", "failed to load audit log")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.AuditEntryResponse]{
+		Data: entries,
+		Meta: &dto.Meta{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
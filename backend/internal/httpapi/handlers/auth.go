@@ -154,14 +154,24 @@ func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := dto.APIResponse[dto.UserResponse]{
-		Data: dto.UserResponse{
-			UserID:     user.UserID.String(),
-			Email:      user.Email,
-			Name:       user.Name,
-			Surname:    user.Surname,
-			Patronymic: user.Patronymic,
-			RoleID:     user.RoleID.String(),
+	permissions, err := h.service.GetPermissions(r.Context(), user.RoleID)
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to load user permissions")
+		writeError(w, http.StatusInternalServerError, "PERMISSIONS_LOAD_FAILED", "failed to load user permissions")
+		return
+	}
+
+	response := dto.APIResponse[dto.MeResponse]{
+		Data: dto.MeResponse{
+			UserResponse: dto.UserResponse{
+				UserID:     user.UserID.String(),
+				Email:      user.Email,
+				Name:       user.Name,
+				Surname:    user.Surname,
+				Patronymic: user.Patronymic,
+				RoleID:     user.RoleID.String(),
+			},
+			Permissions: permissions,
 		},
 	}
 
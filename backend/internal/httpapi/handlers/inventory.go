@@ -125,6 +125,15 @@ func (h *InventoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "INVENTORY_STATUS_NOT_FOUND", "specified inventory status does not exist")
 			return
 		}
+		if err == repository.ErrInventoryStatusArchived {
+			log.Warn().Str("statusId", req.StatusID).Msg("Inventory status is archived")
+			writeError(w, http.StatusBadRequest, "INVENTORY_STATUS_ARCHIVED", "specified inventory status is archived")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "document cannot be created in this status")
+			return
+		}
 		log.Error().Err(err).Str("statusId", req.StatusID).Str("userId", userID.String()).Msg("Failed to create inventory")
 		writeError(w, http.StatusInternalServerError, "INVENTORY_CREATE_FAILED", "failed to create inventory")
 		return
@@ -181,6 +190,27 @@ func (h *InventoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "INVENTORY_STATUS_NOT_FOUND", "specified inventory status does not exist")
 			return
 		}
+		if err == repository.ErrInventoryStatusArchived {
+			log.Warn().Str("statusId", req.StatusID).Msg("Inventory status is archived")
+			writeError(w, http.StatusBadRequest, "INVENTORY_STATUS_ARCHIVED", "specified inventory status is archived")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+			return
+		}
+		if err == repository.ErrNegativeStock {
+			writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+			return
+		}
+		if err == repository.ErrStockNotPicked {
+			writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+			return
+		}
+		if err == repository.ErrInventoryClosed {
+			writeError(w, http.StatusConflict, "INVENTORY_CLOSED", "inventory is completed or cancelled")
+			return
+		}
 		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Failed to update inventory")
 		writeError(w, http.StatusInternalServerError, "INVENTORY_UPDATE_FAILED", "failed to update inventory")
 		return
@@ -217,3 +247,249 @@ func (h *InventoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *InventoryHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	inventoryID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INVENTORY_ID", "invalid inventory id")
+		return
+	}
+
+	var req dto.StatusTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+	if req.StatusID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "statusId is required")
+		return
+	}
+
+	inventory, err := h.service.Transition(r.Context(), inventoryID, userID, req)
+	if err != nil {
+		if err == repository.ErrInventoryNotFound {
+			writeError(w, http.StatusNotFound, "INVENTORY_NOT_FOUND", "inventory not found")
+			return
+		}
+		if err == repository.ErrInventoryStatusNotFound {
+			writeError(w, http.StatusBadRequest, "INVENTORY_STATUS_NOT_FOUND", "specified inventory status does not exist")
+			return
+		}
+		if err == repository.ErrInventoryStatusArchived {
+			writeError(w, http.StatusBadRequest, "INVENTORY_STATUS_ARCHIVED", "specified inventory status is archived")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+			return
+		}
+		if err == repository.ErrNegativeStock {
+			writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+			return
+		}
+		if err == repository.ErrStockNotPicked {
+			writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+			return
+		}
+		if err == repository.ErrStatusConflict {
+			writeError(w, http.StatusConflict, "STATUS_CONFLICT", "inventory status was changed by another request")
+			return
+		}
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Failed to change inventory status")
+		writeError(w, http.StatusInternalServerError, "INVENTORY_TRANSITION_FAILED", "failed to change inventory status")
+		return
+	}
+
+	response := dto.APIResponse[dto.InventoryResponse]{
+		Data: *inventory,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *InventoryHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	inventoryID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INVENTORY_ID", "invalid inventory id")
+		return
+	}
+
+	history, err := h.service.History(r.Context(), inventoryID)
+	if err != nil {
+		if err == repository.ErrInventoryNotFound {
+			writeError(w, http.StatusNotFound, "INVENTORY_NOT_FOUND", "inventory not found")
+			return
+		}
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to load inventory status history")
+		writeError(w, http.StatusInternalServerError, "HISTORY_LOAD_FAILED", "failed to load inventory status history")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StatusHistoryResponse]{
+		Data: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *InventoryHandler) GetCount(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	inventoryID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INVENTORY_ID", "invalid inventory id")
+		return
+	}
+
+	count, err := h.service.GetCount(r.Context(), inventoryID)
+	if err != nil {
+		if err == repository.ErrInventoryCountNotFound {
+			writeError(w, http.StatusNotFound, "INVENTORY_COUNT_NOT_FOUND", "inventory count is not started")
+			return
+		}
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to load inventory count")
+		writeError(w, http.StatusInternalServerError, "INVENTORY_COUNT_LOAD_FAILED", "failed to load inventory count")
+		return
+	}
+
+	response := dto.APIResponse[dto.InventoryCountResponse]{
+		Data: *count,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *InventoryHandler) StartCount(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	inventoryID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INVENTORY_ID", "invalid inventory id")
+		return
+	}
+
+	var req dto.InventoryCountStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+	if req.WarehouseID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "warehouseId is required")
+		return
+	}
+
+	count, err := h.service.StartCount(r.Context(), inventoryID, userID, req)
+	if err != nil {
+		if err == repository.ErrInventoryNotFound {
+			writeError(w, http.StatusNotFound, "INVENTORY_NOT_FOUND", "inventory not found")
+			return
+		}
+		if err == repository.ErrWarehouseNotFound {
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
+			return
+		}
+		if err == repository.ErrWarehouseArchived {
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
+		if err == repository.ErrInventoryCountExists {
+			writeError(w, http.StatusConflict, "INVENTORY_COUNT_EXISTS", "inventory count is already started")
+			return
+		}
+		if err == repository.ErrInventoryClosed {
+			writeError(w, http.StatusConflict, "INVENTORY_CLOSED", "inventory is completed or cancelled")
+			return
+		}
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Failed to start inventory count")
+		writeError(w, http.StatusInternalServerError, "INVENTORY_COUNT_START_FAILED", "failed to start inventory count")
+		return
+	}
+
+	response := dto.APIResponse[dto.InventoryCountResponse]{
+		Data: *count,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *InventoryHandler) RecordCount(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	inventoryID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_INVENTORY_ID", "invalid inventory id")
+		return
+	}
+
+	var req dto.InventoryCountRecordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+	hasProduct := req.ProductID != nil && *req.ProductID != ""
+	hasBarcode := req.Barcode != nil && *req.Barcode != ""
+	if hasProduct == hasBarcode {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "exactly one of productId or barcode is required")
+		return
+	}
+
+	line, err := h.service.RecordCount(r.Context(), inventoryID, userID, req)
+	if err != nil {
+		if err == repository.ErrInventoryNotFound {
+			writeError(w, http.StatusNotFound, "INVENTORY_NOT_FOUND", "inventory not found")
+			return
+		}
+		if err == repository.ErrInventoryCountNotFound {
+			writeError(w, http.StatusConflict, "INVENTORY_COUNT_NOT_STARTED", "inventory count is not started")
+			return
+		}
+		if err == repository.ErrInventoryClosed {
+			writeError(w, http.StatusConflict, "INVENTORY_CLOSED", "inventory is completed or cancelled")
+			return
+		}
+		if err == repository.ErrProductNotFound {
+			writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
+			return
+		}
+		if err == repository.ErrInvalidQuantity {
+			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "quantity must be non-zero, and the counted quantity must not become negative")
+			return
+		}
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Failed to record counted quantity")
+		writeError(w, http.StatusInternalServerError, "INVENTORY_COUNT_RECORD_FAILED", "failed to record counted quantity")
+		return
+	}
+
+	response := dto.APIResponse[dto.InventoryCountLineResponse]{
+		Data: *line,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -109,18 +109,32 @@ func (h *InventoryItemHandler) Create(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "INVENTORY_NOT_FOUND", "specified inventory does not exist")
 			return
 		}
+		if err == repository.ErrInventoryClosed {
+			writeError(w, http.StatusConflict, "INVENTORY_CLOSED", "inventory is completed or cancelled")
+			return
+		}
 		if req.ProductID != nil {
 			if err == repository.ErrProductNotFound {
 				log.Warn().Str("productId", *req.ProductID).Msg("Product not found")
 				writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 				return
 			}
+			if err == repository.ErrProductArchived {
+				log.Warn().Str("productId", *req.ProductID).Msg("Product is archived")
+				writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+				return
+			}
 		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
 		if err == repository.ErrInvalidQuantity {
 			log.Warn().Int("receiptQty", req.ReceiptQty).Int("writeOffQty", req.WriteOffQty).Msg("Invalid quantity")
 			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "quantities must be non-negative")
@@ -188,18 +202,32 @@ func (h *InventoryItemHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "INVENTORY_NOT_FOUND", "specified inventory does not exist")
 			return
 		}
+		if err == repository.ErrInventoryClosed {
+			writeError(w, http.StatusConflict, "INVENTORY_CLOSED", "inventory is completed or cancelled")
+			return
+		}
 		if req.ProductID != nil {
 			if err == repository.ErrProductNotFound {
 				log.Warn().Str("productId", *req.ProductID).Msg("Product not found")
 				writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 				return
 			}
+			if err == repository.ErrProductArchived {
+				log.Warn().Str("productId", *req.ProductID).Msg("Product is archived")
+				writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+				return
+			}
 		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
 		if err == repository.ErrInvalidQuantity {
 			log.Warn().Int("receiptQty", req.ReceiptQty).Int("writeOffQty", req.WriteOffQty).Msg("Invalid quantity")
 			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "quantities must be non-negative")
@@ -234,6 +262,10 @@ func (h *InventoryItemHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "ITEM_NOT_FOUND", "inventory item not found")
 			return
 		}
+		if err == repository.ErrInventoryClosed {
+			writeError(w, http.StatusConflict, "INVENTORY_CLOSED", "inventory is completed or cancelled")
+			return
+		}
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to delete inventory item")
 		writeError(w, http.StatusInternalServerError, "ITEM_DELETE_FAILED", "failed to delete inventory item")
 		return
@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type LocationMoveHandler struct {
+	service *service.LocationMoveService
+}
+
+func NewLocationMoveHandler(service *service.LocationMoveService) *LocationMoveHandler {
+	return &LocationMoveHandler{service: service}
+}
+
+// writeLocationMoveError maps errors shared by location move operations; returns false for unknown errors.
+func writeLocationMoveError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case repository.ErrWarehouseNotFound:
+		writeError(w, http.StatusNotFound, "WAREHOUSE_NOT_FOUND", "warehouse not found")
+	case repository.ErrWarehouseArchived:
+		writeError(w, http.StatusConflict, "WAREHOUSE_ARCHIVED", "warehouse is archived")
+	case repository.ErrLocationMoveNotFound:
+		writeError(w, http.StatusNotFound, "MOVE_NOT_FOUND", "location move not found")
+	case repository.ErrLocationMoveExists:
+		writeError(w, http.StatusConflict, "MOVE_EXISTS", "location move with this number already exists")
+	case repository.ErrLocationMoveNotEditable:
+		writeError(w, http.StatusConflict, "MOVE_NOT_EDITABLE", "location move is already posted")
+	case repository.ErrEmptyLocationMove:
+		writeError(w, http.StatusBadRequest, "EMPTY_MOVE", "location move must contain at least one item")
+	case repository.ErrInvalidMoveLocations:
+		writeError(w, http.StatusBadRequest, "INVALID_MOVE_LOCATIONS", "fromLocationId and toLocationId must differ and at least one of them is required")
+	case repository.ErrNotBinLocation:
+		writeError(w, http.StatusBadRequest, "NOT_BIN_LOCATION", "stock can only be moved from and to bins")
+	case repository.ErrStorageLocationNotFound:
+		writeError(w, http.StatusBadRequest, "LOCATION_NOT_FOUND", "specified location does not exist in this warehouse")
+	case repository.ErrStorageLocationArchived:
+		writeError(w, http.StatusBadRequest, "LOCATION_ARCHIVED", "specified location is archived")
+	case repository.ErrInvalidQuantity:
+		writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "quantity must be positive")
+	case repository.ErrProductNotFound:
+		writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
+	case repository.ErrSupplierOrderNotFound:
+		writeError(w, http.StatusBadRequest, "ORDER_NOT_FOUND", "specified supplier order does not exist")
+	case repository.ErrInsufficientLocationStock:
+		writeError(w, http.StatusConflict, "INSUFFICIENT_LOCATION_STOCK", "source location does not hold enough stock")
+	case repository.ErrNoPutAwaySuggestions:
+		writeError(w, http.StatusConflict, "NOTHING_TO_PUT_AWAY", "no unplaced stock fits into a bin")
+	case repository.ErrInvalidStatusTransition:
+		writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+	case repository.ErrStatusConflict:
+		writeError(w, http.StatusConflict, "STATUS_CONFLICT", "location move status was changed by another request")
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *LocationMoveHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	moveID, err := parseUUID(chi.URLParam(r, "moveId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MOVE_ID", "invalid location move id")
+		return
+	}
+
+	move, err := h.service.GetByID(r.Context(), warehouseID, moveID)
+	if err != nil {
+		if err == repository.ErrLocationMoveNotFound {
+			writeError(w, http.StatusNotFound, "MOVE_NOT_FOUND", "location move not found")
+			return
+		}
+		log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to load location move")
+		writeError(w, http.StatusInternalServerError, "MOVE_LOAD_FAILED", "failed to load location move")
+		return
+	}
+
+	response := dto.APIResponse[dto.LocationMoveResponse]{
+		Data: *move,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *LocationMoveHandler) List(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	limit := parseInt(r.URL.Query().Get("limit"), 50)
+	offset := parseInt(r.URL.Query().Get("offset"), 0)
+
+	var status *string
+	if v := r.URL.Query().Get("status"); v != "" {
+		switch v {
+		case repository.LocationMoveStatusDraft, repository.LocationMoveStatusPosted, repository.LocationMoveStatusCancelled:
+			status = &v
+		default:
+			writeError(w, http.StatusBadRequest, "INVALID_STATUS", "status must be one of: Черновик, Проведен, Отменен")
+			return
+		}
+	}
+
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be non-negative")
+		return
+	}
+
+	moves, err := h.service.List(r.Context(), warehouseID, limit, offset, status)
+	if err != nil {
+		if writeLocationMoveError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Int("limit", limit).Int("offset", offset).Msg("Failed to load location moves")
+		writeError(w, http.StatusInternalServerError, "MOVES_LOAD_FAILED", "failed to load location moves")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.LocationMoveResponse]{
+		Data: moves,
+		Meta: &dto.Meta{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *LocationMoveHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	var req dto.LocationMoveCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.MoveNumber == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "moveNumber is required")
+		return
+	}
+
+	move, err := h.service.Create(r.Context(), warehouseID, userID, req)
+	if err != nil {
+		if writeLocationMoveError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("moveNumber", req.MoveNumber).Msg("Failed to create location move")
+		writeError(w, http.StatusInternalServerError, "MOVE_CREATE_FAILED", "failed to create location move")
+		return
+	}
+
+	response := dto.APIResponse[dto.LocationMoveResponse]{
+		Data: *move,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreatePutAway creates a draft location move from the current put-away suggestions.
+func (h *LocationMoveHandler) CreatePutAway(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	var req dto.PutAwayCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	move, err := h.service.CreatePutAway(r.Context(), warehouseID, userID, req)
+	if err != nil {
+		if writeLocationMoveError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Str("userId", userID.String()).Msg("Failed to create put-away move")
+		writeError(w, http.StatusInternalServerError, "MOVE_CREATE_FAILED", "failed to create location move")
+		return
+	}
+
+	response := dto.APIResponse[dto.LocationMoveResponse]{
+		Data: *move,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *LocationMoveHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	moveID, err := parseUUID(chi.URLParam(r, "moveId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MOVE_ID", "invalid location move id")
+		return
+	}
+
+	var req dto.LocationMoveUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.MoveNumber == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "moveNumber is required")
+		return
+	}
+
+	move, err := h.service.Update(r.Context(), warehouseID, moveID, userID, req)
+	if err != nil {
+		if writeLocationMoveError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to update location move")
+		writeError(w, http.StatusInternalServerError, "MOVE_UPDATE_FAILED", "failed to update location move")
+		return
+	}
+
+	response := dto.APIResponse[dto.LocationMoveResponse]{
+		Data: *move,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *LocationMoveHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	moveID, err := parseUUID(chi.URLParam(r, "moveId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MOVE_ID", "invalid location move id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), warehouseID, moveID)
+	if err != nil {
+		if err == repository.ErrLocationMoveNotFound {
+			writeError(w, http.StatusNotFound, "MOVE_NOT_FOUND", "location move not found")
+			return
+		}
+		if err == repository.ErrLocationMoveNotEditable {
+			writeError(w, http.StatusConflict, "MOVE_NOT_EDITABLE", "only draft or cancelled location moves can be deleted")
+			return
+		}
+		log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to delete location move")
+		writeError(w, http.StatusInternalServerError, "MOVE_DELETE_FAILED", "failed to delete location move")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *LocationMoveHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	moveID, err := parseUUID(chi.URLParam(r, "moveId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MOVE_ID", "invalid location move id")
+		return
+	}
+
+	history, err := h.service.History(r.Context(), warehouseID, moveID)
+	if err != nil {
+		if err == repository.ErrLocationMoveNotFound {
+			writeError(w, http.StatusNotFound, "MOVE_NOT_FOUND", "location move not found")
+			return
+		}
+		log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to load location move status history")
+		writeError(w, http.StatusInternalServerError, "HISTORY_LOAD_FAILED", "failed to load location move status history")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StatusHistoryResponse]{
+		Data: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *LocationMoveHandler) Post(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	moveID, err := parseUUID(chi.URLParam(r, "moveId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MOVE_ID", "invalid location move id")
+		return
+	}
+
+	var req dto.LocationMoveActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	move, err := h.service.Post(r.Context(), warehouseID, moveID, userID, req)
+	if err != nil {
+		if writeLocationMoveError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("moveId", moveID.String()).Str("userId", userID.String()).Msg("Failed to post location move")
+		writeError(w, http.StatusInternalServerError, "MOVE_POST_FAILED", "failed to post location move")
+		return
+	}
+
+	response := dto.APIResponse[dto.LocationMoveResponse]{
+		Data: *move,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *LocationMoveHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	moveID, err := parseUUID(chi.URLParam(r, "moveId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_MOVE_ID", "invalid location move id")
+		return
+	}
+
+	var req dto.LocationMoveActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	move, err := h.service.Cancel(r.Context(), warehouseID, moveID, userID, req)
+	if err != nil {
+		if writeLocationMoveError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("moveId", moveID.String()).Str("userId", userID.String()).Msg("Failed to cancel location move")
+		writeError(w, http.StatusInternalServerError, "MOVE_CANCEL_FAILED", "failed to cancel location move")
+		return
+	}
+
+	response := dto.APIResponse[dto.LocationMoveResponse]{
+		Data: *move,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type MpReturnHandler struct {
+	service *service.MpReturnService
+}
+
+func NewMpReturnHandler(service *service.MpReturnService) *MpReturnHandler {
+	return &MpReturnHandler{service: service}
+}
+
+func (h *MpReturnHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	returnID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_RETURN_ID", "invalid return id")
+		return
+	}
+
+	mpReturn, err := h.service.GetByID(r.Context(), returnID)
+	if err != nil {
+		if err == repository.ErrMpReturnNotFound {
+			log.Warn().Str("returnId", returnID.String()).Msg("Mp return not found")
+			writeError(w, http.StatusNotFound, "RETURN_NOT_FOUND", "return not found")
+			return
+		}
+		log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to load mp return")
+		writeError(w, http.StatusInternalServerError, "RETURN_LOAD_FAILED", "failed to load return")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpReturnResponse]{
+		Data: *mpReturn,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MpReturnHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := parseInt(r.URL.Query().Get("limit"), 50)
+	offset := parseInt(r.URL.Query().Get("offset"), 0)
+
+	var storeID *uuid.UUID
+	if v := r.URL.Query().Get("storeId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_STORE_ID", "invalid storeId")
+			return
+		}
+		storeID = &id
+	}
+
+	var shipmentID *uuid.UUID
+	if v := r.URL.Query().Get("shipmentId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_SHIPMENT_ID", "invalid shipmentId")
+			return
+		}
+		shipmentID = &id
+	}
+
+	var status *string
+	if v := r.URL.Query().Get("status"); v != "" {
+		switch v {
+		case repository.MpReturnStatusDraft, repository.MpReturnStatusPosted, repository.MpReturnStatusCancelled:
+			status = &v
+		default:
+			writeError(w, http.StatusBadRequest, "INVALID_STATUS", "status must be one of: Черновик, Проведен, Отменен")
+			return
+		}
+	}
+
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be non-negative")
+		return
+	}
+
+	returns, err := h.service.List(r.Context(), limit, offset, storeID, shipmentID, status)
+	if err != nil {
+		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load mp returns")
+		writeError(w, http.StatusInternalServerError, "RETURNS_LOAD_FAILED", "failed to load returns")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.MpReturnResponse]{
+		Data: returns,
+		Meta: &dto.Meta{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeMpReturnError maps errors shared by return operations; returns false for unknown errors.
+func writeMpReturnError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case repository.ErrMpReturnNotFound:
+		writeError(w, http.StatusNotFound, "RETURN_NOT_FOUND", "return not found")
+	case repository.ErrMpReturnExists:
+		writeError(w, http.StatusConflict, "RETURN_EXISTS", "return with this number already exists")
+	case repository.ErrMpReturnNotEditable:
+		writeError(w, http.StatusConflict, "RETURN_NOT_EDITABLE", "return is already posted or cancelled")
+	case repository.ErrEmptyReturn:
+		writeError(w, http.StatusBadRequest, "EMPTY_RETURN", "return must contain at least one item")
+	case repository.ErrInvalidReturnType:
+		writeError(w, http.StatusBadRequest, "INVALID_RETURN_TYPE", "returnType must be one of: rejection, customer")
+	case repository.ErrInvalidReturnCondition:
+		writeError(w, http.StatusBadRequest, "INVALID_CONDITION", "condition must be one of: resellable, defect, write_off")
+	case repository.ErrDuplicateItem:
+		writeError(w, http.StatusBadRequest, "DUPLICATE_ITEM", "each product may be listed only once per condition")
+	case repository.ErrInvalidQuantity:
+		writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "quantity must be positive")
+	case repository.ErrReturnShipmentMismatch:
+		writeError(w, http.StatusBadRequest, "SHIPMENT_STORE_MISMATCH", "shipment belongs to another store")
+	case repository.ErrReturnExceedsShipped:
+		writeError(w, http.StatusBadRequest, "RETURN_EXCEEDS_SHIPPED", "returned quantity exceeds quantity sent in shipment")
+	case repository.ErrReturnShipmentNotSent:
+		writeError(w, http.StatusConflict, "SHIPMENT_NOT_SENT", "shipment has not been dispatched yet")
+	case repository.ErrReturnShipmentNotRejected:
+		writeError(w, http.StatusConflict, "SHIPMENT_NOT_REJECTED", "rejection return requires a shipment in status Отклонен")
+	case repository.ErrMpShipmentNotFound:
+		writeError(w, http.StatusBadRequest, "SHIPMENT_NOT_FOUND", "specified shipment does not exist")
+	case repository.ErrStoreNotFound:
+		writeError(w, http.StatusBadRequest, "STORE_NOT_FOUND", "specified store does not exist")
+	case repository.ErrStoreArchived:
+		writeError(w, http.StatusBadRequest, "STORE_ARCHIVED", "specified store is archived")
+	case repository.ErrProductNotFound:
+		writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
+	case repository.ErrProductArchived:
+		writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+	case repository.ErrWarehouseNotFound:
+		writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
+	case repository.ErrWarehouseArchived:
+		writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+	case repository.ErrNotReturnsWarehouse:
+		writeError(w, http.StatusBadRequest, "NOT_RETURNS_WAREHOUSE", "defective items can only be returned to a warehouse of type \"Склад возвратов\"")
+	case repository.ErrInvalidStatusTransition:
+		writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+	case repository.ErrNegativeStock:
+		writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+	case repository.ErrStockNotPicked:
+		writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+	case repository.ErrStatusConflict:
+		writeError(w, http.StatusConflict, "STATUS_CONFLICT", "return status was changed by another request")
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *MpReturnHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	var req dto.MpReturnCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.ReturnNumber == "" || req.StoreID == "" || req.WarehouseID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "returnNumber, storeId and warehouseId are required")
+		return
+	}
+
+	mpReturn, err := h.service.Create(r.Context(), userID, req)
+	if err != nil {
+		if writeMpReturnError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("returnNumber", req.ReturnNumber).Msg("Failed to create mp return")
+		writeError(w, http.StatusInternalServerError, "RETURN_CREATE_FAILED", "failed to create return")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpReturnResponse]{
+		Data: *mpReturn,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MpReturnHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	returnID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_RETURN_ID", "invalid return id")
+		return
+	}
+
+	var req dto.MpReturnUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.ReturnNumber == "" || req.StoreID == "" || req.WarehouseID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "returnNumber, storeId and warehouseId are required")
+		return
+	}
+
+	mpReturn, err := h.service.Update(r.Context(), returnID, userID, req)
+	if err != nil {
+		if writeMpReturnError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to update mp return")
+		writeError(w, http.StatusInternalServerError, "RETURN_UPDATE_FAILED", "failed to update return")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpReturnResponse]{
+		Data: *mpReturn,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MpReturnHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	returnID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_RETURN_ID", "invalid return id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), returnID)
+	if err != nil {
+		if err == repository.ErrMpReturnNotFound {
+			log.Warn().Str("returnId", returnID.String()).Msg("Mp return not found for deletion")
+			writeError(w, http.StatusNotFound, "RETURN_NOT_FOUND", "return not found")
+			return
+		}
+		if err == repository.ErrMpReturnNotEditable {
+			writeError(w, http.StatusConflict, "RETURN_NOT_EDITABLE", "only draft or cancelled returns can be deleted")
+			return
+		}
+		log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to delete mp return")
+		writeError(w, http.StatusInternalServerError, "RETURN_DELETE_FAILED", "failed to delete return")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MpReturnHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	returnID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_RETURN_ID", "invalid return id")
+		return
+	}
+
+	history, err := h.service.History(r.Context(), returnID)
+	if err != nil {
+		if err == repository.ErrMpReturnNotFound {
+			writeError(w, http.StatusNotFound, "RETURN_NOT_FOUND", "return not found")
+			return
+		}
+		log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to load mp return status history")
+		writeError(w, http.StatusInternalServerError, "HISTORY_LOAD_FAILED", "failed to load return status history")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StatusHistoryResponse]{
+		Data: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MpReturnHandler) Post(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	returnID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_RETURN_ID", "invalid return id")
+		return
+	}
+
+	var req dto.MpReturnActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	mpReturn, err := h.service.Post(r.Context(), returnID, userID, req)
+	if err != nil {
+		if writeMpReturnError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("returnId", returnID.String()).Str("userId", userID.String()).Msg("Failed to post mp return")
+		writeError(w, http.StatusInternalServerError, "RETURN_POST_FAILED", "failed to post return")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpReturnResponse]{
+		Data: *mpReturn,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MpReturnHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	returnID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_RETURN_ID", "invalid return id")
+		return
+	}
+
+	var req dto.MpReturnActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	mpReturn, err := h.service.Cancel(r.Context(), returnID, userID, req)
+	if err != nil {
+		if writeMpReturnError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("returnId", returnID.String()).Str("userId", userID.String()).Msg("Failed to cancel mp return")
+		writeError(w, http.StatusInternalServerError, "RETURN_CANCEL_FAILED", "failed to cancel return")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpReturnResponse]{
+		Data: *mpReturn,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -123,16 +123,12 @@ func (h *MpShipmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "shipmentNumber is required")
 		return
 	}
-	if req.PositionsQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "positionsQty must be non-negative")
+	if req.LogisticsCost != nil && *req.LogisticsCost < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "logisticsCost must be non-negative")
 		return
 	}
-	if req.SentQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "sentQty must be non-negative")
-		return
-	}
-	if req.AcceptedQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "acceptedQty must be non-negative")
+	if req.AcceptanceCost != nil && *req.AcceptanceCost < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "acceptanceCost must be non-negative")
 		return
 	}
 
@@ -148,19 +144,37 @@ func (h *MpShipmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "STORE_NOT_FOUND", "specified store does not exist")
 			return
 		}
+		if err == repository.ErrStoreArchived {
+			log.Warn().Interface("storeId", req.StoreID).Msg("Store is archived")
+			writeError(w, http.StatusBadRequest, "STORE_ARCHIVED", "specified store is archived")
+			return
+		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Interface("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Interface("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
 		if err == repository.ErrShipmentStatusNotFound {
 			log.Warn().Interface("statusId", req.StatusID).Msg("Shipment status not found")
 			writeError(w, http.StatusBadRequest, "SHIPMENT_STATUS_NOT_FOUND", "specified shipment status does not exist")
 			return
 		}
-		if err == repository.ErrInvalidQuantity {
-			log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Invalid quantity")
-			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "accepted quantity cannot exceed sent quantity")
+		if err == repository.ErrShipmentStatusArchived {
+			log.Warn().Interface("statusId", req.StatusID).Msg("Shipment status is archived")
+			writeError(w, http.StatusBadRequest, "SHIPMENT_STATUS_ARCHIVED", "specified shipment status is archived")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "document cannot be created in this status")
+			return
+		}
+		if err == repository.ErrInvalidAllocationMethod {
+			writeError(w, http.StatusBadRequest, "INVALID_ALLOCATION_METHOD", "logisticsAllocationMethod must be one of: weight, quantity")
 			return
 		}
 		log.Error().Err(err).Str("shipmentNumber", req.ShipmentNumber).Str("userId", userID.String()).Msg("Failed to create mp shipment")
@@ -201,16 +215,12 @@ func (h *MpShipmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "shipmentNumber is required")
 		return
 	}
-	if req.PositionsQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "positionsQty must be non-negative")
+	if req.LogisticsCost != nil && *req.LogisticsCost < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "logisticsCost must be non-negative")
 		return
 	}
-	if req.SentQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "sentQty must be non-negative")
-		return
-	}
-	if req.AcceptedQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "acceptedQty must be non-negative")
+	if req.AcceptanceCost != nil && *req.AcceptanceCost < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "acceptanceCost must be non-negative")
 		return
 	}
 
@@ -231,19 +241,49 @@ func (h *MpShipmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "STORE_NOT_FOUND", "specified store does not exist")
 			return
 		}
+		if err == repository.ErrStoreArchived {
+			log.Warn().Interface("storeId", req.StoreID).Msg("Store is archived")
+			writeError(w, http.StatusBadRequest, "STORE_ARCHIVED", "specified store is archived")
+			return
+		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Interface("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Interface("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
 		if err == repository.ErrShipmentStatusNotFound {
 			log.Warn().Interface("statusId", req.StatusID).Msg("Shipment status not found")
 			writeError(w, http.StatusBadRequest, "SHIPMENT_STATUS_NOT_FOUND", "specified shipment status does not exist")
 			return
 		}
-		if err == repository.ErrInvalidQuantity {
-			log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Invalid quantity")
-			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "accepted quantity cannot exceed sent quantity")
+		if err == repository.ErrShipmentStatusArchived {
+			log.Warn().Interface("statusId", req.StatusID).Msg("Shipment status is archived")
+			writeError(w, http.StatusBadRequest, "SHIPMENT_STATUS_ARCHIVED", "specified shipment status is archived")
+			return
+		}
+		if err == repository.ErrInvalidAllocationMethod {
+			writeError(w, http.StatusBadRequest, "INVALID_ALLOCATION_METHOD", "logisticsAllocationMethod must be one of: weight, quantity")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+			return
+		}
+		if err == repository.ErrNegativeStock {
+			writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+			return
+		}
+		if err == repository.ErrStockNotPicked {
+			writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+			return
+		}
+		if err == repository.ErrShipmentDispatched {
+			writeError(w, http.StatusConflict, "SHIPMENT_DISPATCHED", "date and warehouse of a dispatched shipment cannot be changed")
 			return
 		}
 		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Str("userId", userID.String()).Msg("Failed to update mp shipment")
@@ -282,3 +322,167 @@ func (h *MpShipmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *MpShipmentHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	shipmentID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_SHIPMENT_ID", "invalid shipment id")
+		return
+	}
+
+	var req dto.StatusTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+	if req.StatusID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "statusId is required")
+		return
+	}
+
+	shipment, err := h.service.Transition(r.Context(), shipmentID, userID, req)
+	if err != nil {
+		if err == repository.ErrMpShipmentNotFound {
+			writeError(w, http.StatusNotFound, "SHIPMENT_NOT_FOUND", "mp shipment not found")
+			return
+		}
+		if err == repository.ErrShipmentStatusNotFound {
+			writeError(w, http.StatusBadRequest, "SHIPMENT_STATUS_NOT_FOUND", "specified shipment status does not exist")
+			return
+		}
+		if err == repository.ErrShipmentStatusArchived {
+			writeError(w, http.StatusBadRequest, "SHIPMENT_STATUS_ARCHIVED", "specified shipment status is archived")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+			return
+		}
+		if err == repository.ErrStatusConflict {
+			writeError(w, http.StatusConflict, "STATUS_CONFLICT", "mp shipment status was changed by another request")
+			return
+		}
+		if err == repository.ErrNegativeStock {
+			writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+			return
+		}
+		if err == repository.ErrStockNotPicked {
+			writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+			return
+		}
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Str("userId", userID.String()).Msg("Failed to change mp shipment status")
+		writeError(w, http.StatusInternalServerError, "SHIPMENT_TRANSITION_FAILED", "failed to change mp shipment status")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpShipmentResponse]{
+		Data: *shipment,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MpShipmentHandler) ResolveDiscrepancy(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	shipmentID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_SHIPMENT_ID", "invalid shipment id")
+		return
+	}
+
+	var req dto.MpShipmentResolutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	shipment, err := h.service.ResolveDiscrepancy(r.Context(), shipmentID, userID, req)
+	if err != nil {
+		if err == repository.ErrMpShipmentNotFound {
+			writeError(w, http.StatusNotFound, "SHIPMENT_NOT_FOUND", "mp shipment not found")
+			return
+		}
+		if err == repository.ErrMpShipmentItemNotFound {
+			writeError(w, http.StatusBadRequest, "SHIPMENT_ITEM_NOT_FOUND", "specified item does not belong to the shipment")
+			return
+		}
+		if err == repository.ErrShipmentNotClosed {
+			writeError(w, http.StatusConflict, "SHIPMENT_NOT_CLOSED", "discrepancy can be resolved only for an accepted or rejected shipment")
+			return
+		}
+		if err == repository.ErrInvalidResolution {
+			writeError(w, http.StatusBadRequest, "INVALID_RESOLUTION", "returnedQty + lostQty must equal sentQty - acceptedQty for every item")
+			return
+		}
+		if err == repository.ErrInvalidDateRange {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "resolutionDate cannot be before shipmentDate")
+			return
+		}
+		if err == repository.ErrReturnExceedsShipped {
+			writeError(w, http.StatusBadRequest, "RETURN_EXCEEDS_SHIPPED", "returned quantity together with marketplace returns exceeds sent quantity")
+			return
+		}
+		if err == repository.ErrNegativeStock {
+			writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+			return
+		}
+		if err == repository.ErrStockNotPicked {
+			writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+			return
+		}
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Str("userId", userID.String()).Msg("Failed to resolve mp shipment discrepancy")
+		writeError(w, http.StatusInternalServerError, "SHIPMENT_RESOLVE_FAILED", "failed to resolve mp shipment discrepancy")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpShipmentResponse]{
+		Data: *shipment,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MpShipmentHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	shipmentID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_SHIPMENT_ID", "invalid shipment id")
+		return
+	}
+
+	history, err := h.service.History(r.Context(), shipmentID)
+	if err != nil {
+		if err == repository.ErrMpShipmentNotFound {
+			writeError(w, http.StatusNotFound, "SHIPMENT_NOT_FOUND", "mp shipment not found")
+			return
+		}
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to load mp shipment status history")
+		writeError(w, http.StatusInternalServerError, "HISTORY_LOAD_FAILED", "failed to load mp shipment status history")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StatusHistoryResponse]{
+		Data: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
 	"warehouse-backend/internal/dto"
 	"warehouse-backend/internal/repository"
 	"warehouse-backend/internal/service"
@@ -74,6 +76,12 @@ func (h *MpShipmentItemHandler) GetByShipmentID(w http.ResponseWriter, r *http.R
 }
 
 func (h *MpShipmentItemHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
 	var req dto.MpShipmentItemCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
@@ -101,7 +109,7 @@ func (h *MpShipmentItemHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := h.service.Create(r.Context(), req)
+	item, err := h.service.Create(r.Context(), userID, req)
 	if err != nil {
 		if err == repository.ErrMpShipmentNotFound {
 			log.Warn().Str("shipmentId", req.ShipmentID).Msg("Mp shipment not found")
@@ -113,16 +121,43 @@ func (h *MpShipmentItemHandler) Create(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 			return
 		}
+		if err == repository.ErrProductArchived {
+			log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+			writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+			return
+		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
 		if err == repository.ErrInvalidQuantity {
 			log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Invalid quantity")
 			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "accepted quantity cannot exceed sent quantity")
 			return
 		}
+		if err == repository.ErrInsufficientStock {
+			log.Warn().Str("productId", req.ProductID).Str("warehouseId", req.WarehouseID).Int("sentQty", req.SentQty).Msg("Insufficient stock")
+			writeError(w, http.StatusConflict, "INSUFFICIENT_STOCK", "not enough available stock on warehouse (current stock minus reservations)")
+			return
+		}
+		if err == repository.ErrNegativeStock {
+			writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+			return
+		}
+		if err == repository.ErrStockNotPicked {
+			writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+			return
+		}
+		if err == repository.ErrShipmentResolved {
+			writeError(w, http.StatusConflict, "SHIPMENT_RESOLVED", "items of a shipment with resolved discrepancy cannot be changed")
+			return
+		}
 		log.Error().Err(err).Str("shipmentId", req.ShipmentID).Str("productId", req.ProductID).Msg("Failed to create mp shipment item")
 		writeError(w, http.StatusInternalServerError, "ITEM_CREATE_FAILED", "failed to create mp shipment item")
 		return
@@ -138,6 +173,12 @@ func (h *MpShipmentItemHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *MpShipmentItemHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	itemID, err := parseUUID(idStr)
 	if err != nil {
@@ -172,7 +213,7 @@ func (h *MpShipmentItemHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := h.service.Update(r.Context(), itemID, req)
+	item, err := h.service.Update(r.Context(), itemID, userID, req)
 	if err != nil {
 		if err == repository.ErrMpShipmentItemNotFound {
 			log.Warn().Str("itemId", itemID.String()).Msg("Mp shipment item not found for update")
@@ -189,16 +230,43 @@ func (h *MpShipmentItemHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 			return
 		}
+		if err == repository.ErrProductArchived {
+			log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+			writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+			return
+		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
 		if err == repository.ErrInvalidQuantity {
 			log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Invalid quantity")
 			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "accepted quantity cannot exceed sent quantity")
 			return
 		}
+		if err == repository.ErrInsufficientStock {
+			log.Warn().Str("productId", req.ProductID).Str("warehouseId", req.WarehouseID).Int("sentQty", req.SentQty).Msg("Insufficient stock")
+			writeError(w, http.StatusConflict, "INSUFFICIENT_STOCK", "not enough available stock on warehouse (current stock minus reservations)")
+			return
+		}
+		if err == repository.ErrNegativeStock {
+			writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+			return
+		}
+		if err == repository.ErrStockNotPicked {
+			writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+			return
+		}
+		if err == repository.ErrShipmentResolved {
+			writeError(w, http.StatusConflict, "SHIPMENT_RESOLVED", "items of a shipment with resolved discrepancy cannot be changed")
+			return
+		}
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to update mp shipment item")
 		writeError(w, http.StatusInternalServerError, "ITEM_UPDATE_FAILED", "failed to update mp shipment item")
 		return
@@ -214,6 +282,12 @@ func (h *MpShipmentItemHandler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *MpShipmentItemHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	itemID, err := parseUUID(idStr)
 	if err != nil {
@@ -221,13 +295,17 @@ func (h *MpShipmentItemHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Delete(r.Context(), itemID)
+	err = h.service.Delete(r.Context(), itemID, userID)
 	if err != nil {
 		if err == repository.ErrMpShipmentItemNotFound {
 			log.Warn().Str("itemId", itemID.String()).Msg("Mp shipment item not found for deletion")
 			writeError(w, http.StatusNotFound, "ITEM_NOT_FOUND", "mp shipment item not found")
 			return
 		}
+		if err == repository.ErrShipmentResolved {
+			writeError(w, http.StatusConflict, "SHIPMENT_RESOLVED", "items of a shipment with resolved discrepancy cannot be changed")
+			return
+		}
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to delete mp shipment item")
 		writeError(w, http.StatusInternalServerError, "ITEM_DELETE_FAILED", "failed to delete mp shipment item")
 		return
@@ -52,6 +52,7 @@ func (h *OrderStatusHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *OrderStatusHandler) List(w http.ResponseWriter, r *http.Request) {
 	limit := parseInt(r.URL.Query().Get("limit"), 50)
 	offset := parseInt(r.URL.Query().Get("offset"), 0)
+	includeArchived := parseBool(r.URL.Query().Get("includeArchived"), false)
 
 	if limit < 1 || limit > 1000 {
 		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
@@ -62,7 +63,7 @@ func (h *OrderStatusHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	statuses, err := h.service.List(r.Context(), limit, offset)
+	statuses, err := h.service.List(r.Context(), limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load order statuses")
 		writeError(w, http.StatusInternalServerError, "STATUSES_LOAD_FAILED", "failed to load order statuses")
@@ -182,3 +183,32 @@ func (h *OrderStatusHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *OrderStatusHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	statusID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_STATUS_ID", "invalid status id")
+		return
+	}
+
+	status, err := h.service.Restore(r.Context(), statusID)
+	if err != nil {
+		if err == repository.ErrOrderStatusNotFound {
+			log.Warn().Str("statusId", statusID.String()).Msg("Order status not found for restore")
+			writeError(w, http.StatusNotFound, "STATUS_NOT_FOUND", "order status not found")
+			return
+		}
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to restore order status")
+		writeError(w, http.StatusInternalServerError, "STATUS_RESTORE_FAILED", "failed to restore order status")
+		return
+	}
+
+	response := dto.APIResponse[dto.OrderStatusResponse]{
+		Data: *status,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
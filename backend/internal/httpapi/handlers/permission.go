@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+type PermissionHandler struct {
+	service *service.PermissionService
+}
+
+func NewPermissionHandler(service *service.PermissionService) *PermissionHandler {
+	return &PermissionHandler{service: service}
+}
+
+func (h *PermissionHandler) List(w http.ResponseWriter, r *http.Request) {
+	permissions, err := h.service.List(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load permissions")
+		writeError(w, http.StatusInternalServerError, "PERMISSIONS_LOAD_FAILED", "failed to load permissions")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.PermissionResponse]{
+		Data: permissions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -52,6 +52,7 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) List(w http.ResponseWriter, r *http.Request) {
 	limit := parseInt(r.URL.Query().Get("limit"), 50)
 	offset := parseInt(r.URL.Query().Get("offset"), 0)
+	includeArchived := parseBool(r.URL.Query().Get("includeArchived"), false)
 
 	if limit < 1 || limit > 1000 {
 		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
@@ -62,7 +63,7 @@ func (h *ProductHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	products, err := h.service.List(r.Context(), limit, offset)
+	products, err := h.service.List(r.Context(), limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load products")
 		writeError(w, http.StatusInternalServerError, "PRODUCTS_LOAD_FAILED", "failed to load products")
@@ -182,3 +183,32 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *ProductHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	productID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid product id")
+		return
+	}
+
+	product, err := h.service.Restore(r.Context(), productID)
+	if err != nil {
+		if err == repository.ErrProductNotFound {
+			log.Warn().Str("productId", productID.String()).Msg("Product not found for restore")
+			writeError(w, http.StatusNotFound, "PRODUCT_NOT_FOUND", "product not found")
+			return
+		}
+		log.Error().Err(err).Str("productId", productID.String()).Msg("Failed to restore product")
+		writeError(w, http.StatusInternalServerError, "PRODUCT_RESTORE_FAILED", "failed to restore product")
+		return
+	}
+
+	response := dto.APIResponse[dto.ProductResponse]{
+		Data: *product,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
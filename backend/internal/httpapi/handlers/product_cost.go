@@ -129,6 +129,11 @@ func (h *ProductCostHandler) Create(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 			return
 		}
+		if err == repository.ErrProductCostOverlap {
+			log.Warn().Str("productId", req.ProductID).Msg("Product cost period overlaps existing period")
+			writeError(w, http.StatusConflict, "COST_PERIOD_OVERLAP", "cost period overlaps an existing period of this product")
+			return
+		}
 		if err == repository.ErrInvalidDateRange {
 			log.Warn().Time("periodStart", req.PeriodStart).Time("periodEnd", req.PeriodEnd).Msg("Invalid date range")
 			writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "period end must be after period start")
@@ -199,6 +204,11 @@ func (h *ProductCostHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 			return
 		}
+		if err == repository.ErrProductCostOverlap {
+			log.Warn().Str("productId", req.ProductID).Msg("Product cost period overlaps existing period")
+			writeError(w, http.StatusConflict, "COST_PERIOD_OVERLAP", "cost period overlaps an existing period of this product")
+			return
+		}
 		if err == repository.ErrInvalidDateRange {
 			log.Warn().Time("periodStart", req.PeriodStart).Time("periodEnd", req.PeriodEnd).Msg("Invalid date range")
 			writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "period end must be after period start")
@@ -245,3 +255,52 @@ func (h *ProductCostHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *ProductCostHandler) GetCoverage(w http.ResponseWriter, r *http.Request) {
+	dateFromStr := r.URL.Query().Get("dateFrom")
+	dateToStr := r.URL.Query().Get("dateTo")
+	if dateFromStr == "" || dateToStr == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "dateFrom and dateTo are required")
+		return
+	}
+
+	dateFrom, err := parseDate(dateFromStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_DATE_FROM", "dateFrom must be in YYYY-MM-DD format")
+		return
+	}
+	dateTo, err := parseDate(dateToStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_DATE_TO", "dateTo must be in YYYY-MM-DD format")
+		return
+	}
+	if dateTo.Before(dateFrom) {
+		writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "dateTo must not be before dateFrom")
+		return
+	}
+
+	var productID *uuid.UUID
+	if v := r.URL.Query().Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		productID = &id
+	}
+
+	coverage, err := h.service.GetCoverage(r.Context(), dateFrom, dateTo, productID)
+	if err != nil {
+		log.Error().Err(err).Str("dateFrom", dateFromStr).Str("dateTo", dateToStr).Msg("Failed to load product cost coverage")
+		writeError(w, http.StatusInternalServerError, "COVERAGE_LOAD_FAILED", "failed to load product cost coverage")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.ProductCostCoverageResponse]{
+		Data: coverage,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -182,3 +182,77 @@ func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *RoleHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	roleID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROLE_ID", "invalid role id")
+		return
+	}
+
+	permissions, err := h.service.GetPermissions(r.Context(), roleID)
+	if err != nil {
+		if err == repository.ErrRoleNotFound {
+			log.Warn().Str("roleId", roleID.String()).Msg("Role not found")
+			writeError(w, http.StatusNotFound, "ROLE_NOT_FOUND", "role not found")
+			return
+		}
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to load role permissions")
+		writeError(w, http.StatusInternalServerError, "PERMISSIONS_LOAD_FAILED", "failed to load role permissions")
+		return
+	}
+
+	response := dto.APIResponse[[]string]{
+		Data: permissions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *RoleHandler) UpdatePermissions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	roleID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ROLE_ID", "invalid role id")
+		return
+	}
+
+	var req dto.RolePermissionsUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.Permissions == nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "permissions is required")
+		return
+	}
+
+	permissions, err := h.service.UpdatePermissions(r.Context(), roleID, req)
+	if err != nil {
+		if err == repository.ErrRoleNotFound {
+			log.Warn().Str("roleId", roleID.String()).Msg("Role not found for permissions update")
+			writeError(w, http.StatusNotFound, "ROLE_NOT_FOUND", "role not found")
+			return
+		}
+		if err == repository.ErrPermissionNotFound {
+			log.Warn().Str("roleId", roleID.String()).Strs("permissions", req.Permissions).Msg("Unknown permission in request")
+			writeError(w, http.StatusBadRequest, "PERMISSION_NOT_FOUND", "one or more permissions do not exist")
+			return
+		}
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to update role permissions")
+		writeError(w, http.StatusInternalServerError, "PERMISSIONS_UPDATE_FAILED", "failed to update role permissions")
+		return
+	}
+
+	response := dto.APIResponse[[]string]{
+		Data: permissions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
 	"warehouse-backend/internal/service"
 
 	"github.com/rs/zerolog/log"
@@ -67,6 +69,160 @@ func (h *StockHandler) GetCurrentStock(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+func (h *StockHandler) GetStockAsOf(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	dateStr := q.Get("date")
+	if dateStr == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "date is required")
+		return
+	}
+	asOfDate, err := parseDate(dateStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_DATE", "date must be in YYYY-MM-DD format")
+		return
+	}
+
+	var warehouseID *uuid.UUID
+	if v := q.Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		warehouseID = &id
+	}
+
+	var productID *uuid.UUID
+	if v := q.Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		productID = &id
+	}
+
+	limit := parseInt(q.Get("limit"), 50)
+	offset := parseInt(q.Get("offset"), 0)
+
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be non-negative")
+		return
+	}
+
+	items, err := h.service.GetStockAsOf(r.Context(), asOfDate, warehouseID, productID, limit, offset)
+	if err != nil {
+		log.Error().Err(err).
+			Str("date", dateStr).
+			Interface("warehouseId", warehouseID).
+			Interface("productId", productID).
+			Msg("Failed to load stock as of date")
+		writeError(w, http.StatusInternalServerError, "STOCK_LOAD_FAILED", "failed to load stock")
+		return
+	}
+
+	resp := dto.APIResponse[[]dto.StockAsOfItemResponse]{
+		Data: items,
+		Meta: &dto.Meta{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *StockHandler) GetMovements(w http.ResponseWriter, r *http.Request) {
+	var filter repository.StockMovementFilter
+	q := r.URL.Query()
+
+	if v := q.Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		filter.ProductID = &id
+	}
+	if v := q.Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		filter.WarehouseID = &id
+	}
+	if v := q.Get("documentId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_DOCUMENT_ID", "invalid documentId")
+			return
+		}
+		filter.DocumentID = &id
+	}
+	if v := q.Get("movementType"); v != "" {
+		filter.MovementType = &v
+	}
+	if v := q.Get("dateFrom"); v != "" {
+		date, err := parseDate(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE", "dateFrom must be in YYYY-MM-DD format")
+			return
+		}
+		filter.DateFrom = &date
+	}
+	if v := q.Get("dateTo"); v != "" {
+		date, err := parseDate(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE", "dateTo must be in YYYY-MM-DD format")
+			return
+		}
+		filter.DateTo = &date
+	}
+	if filter.DateFrom != nil && filter.DateTo != nil && filter.DateTo.Before(*filter.DateFrom) {
+		writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "dateTo must not be before dateFrom")
+		return
+	}
+
+	limit := parseInt(q.Get("limit"), 50)
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+
+	movements, nextCursor, err := h.service.GetMovements(r.Context(), filter, q.Get("cursor"), limit)
+	if err != nil {
+		if err == repository.ErrInvalidMovementType {
+			writeError(w, http.StatusBadRequest, "INVALID_MOVEMENT_TYPE", "movementType must be one of SUPPLIER_RECEIPT, MP_SHIPMENT, MP_SHIPMENT_RETURN, INVENTORY_ADJUSTMENT, TRANSFER_OUT, TRANSFER_IN, MP_RETURN")
+			return
+		}
+		if err == service.ErrInvalidCursor {
+			writeError(w, http.StatusBadRequest, "INVALID_CURSOR", "invalid cursor")
+			return
+		}
+		log.Error().Err(err).Interface("filter", filter).Int("limit", limit).Msg("Failed to load stock movements")
+		writeError(w, http.StatusInternalServerError, "STOCK_MOVEMENTS_LOAD_FAILED", "failed to load stock movements")
+		return
+	}
+
+	resp := dto.APIResponse[[]dto.StockMovementResponse]{
+		Data: movements,
+		Meta: &dto.Meta{
+			Limit:      limit,
+			NextCursor: nextCursor,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 func parseInt(v string, def int) int {
 	if v == "" {
 		return def
@@ -77,10 +233,100 @@ func parseInt(v string, def int) int {
 	return def
 }
 
+func parseBool(v string, def bool) bool {
+	if v == "" {
+		return def
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	return def
+}
+
 func parseUUID(v string) (uuid.UUID, error) {
 	return uuid.Parse(v)
 }
 
+func (h *StockHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	productID, err := parseUUID(q.Get("productId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "productId is required and must be a valid id")
+		return
+	}
+	warehouseID, err := parseUUID(q.Get("warehouseId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "warehouseId is required and must be a valid id")
+		return
+	}
+
+	availability, err := h.service.GetAvailability(r.Context(), productID, warehouseID)
+	if err != nil {
+		log.Error().Err(err).Str("productId", productID.String()).Str("warehouseId", warehouseID.String()).Msg("Failed to load stock availability")
+		writeError(w, http.StatusInternalServerError, "STOCK_LOAD_FAILED", "failed to load stock availability")
+		return
+	}
+
+	resp := dto.APIResponse[dto.StockAvailabilityResponse]{
+		Data: *availability,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetNegativeStock reports negative current stock; limit caps the list of offending documents.
+func (h *StockHandler) GetNegativeStock(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var warehouseID *uuid.UUID
+	if v := q.Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		warehouseID = &id
+	}
+
+	var productID *uuid.UUID
+	if v := q.Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		productID = &id
+	}
+
+	limit := parseInt(q.Get("limit"), 100)
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+
+	report, err := h.service.GetNegativeStock(r.Context(), warehouseID, productID, limit)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Interface("productId", productID).Msg("Failed to load negative stock")
+		writeError(w, http.StatusInternalServerError, "STOCK_LOAD_FAILED", "failed to load negative stock")
+		return
+	}
+
+	resp := dto.APIResponse[dto.NegativeStockReportResponse]{
+		Data: *report,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func parseDate(v string) (time.Time, error) {
+	return time.Parse("2006-01-02", v)
+}
+
 func writeError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type StockLevelHandler struct {
+	service *service.StockLevelService
+}
+
+func NewStockLevelHandler(service *service.StockLevelService) *StockLevelHandler {
+	return &StockLevelHandler{service: service}
+}
+
+func (h *StockLevelHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	levelID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_LEVEL_ID", "invalid stock level id")
+		return
+	}
+
+	level, err := h.service.GetByID(r.Context(), levelID)
+	if err != nil {
+		if err == repository.ErrStockLevelNotFound {
+			log.Warn().Str("levelId", levelID.String()).Msg("Stock level not found")
+			writeError(w, http.StatusNotFound, "LEVEL_NOT_FOUND", "stock level not found")
+			return
+		}
+		log.Error().Err(err).Str("levelId", levelID.String()).Msg("Failed to load stock level")
+		writeError(w, http.StatusInternalServerError, "LEVEL_LOAD_FAILED", "failed to load stock level")
+		return
+	}
+
+	response := dto.APIResponse[dto.StockLevelResponse]{
+		Data: *level,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StockLevelHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := parseInt(r.URL.Query().Get("limit"), 50)
+	offset := parseInt(r.URL.Query().Get("offset"), 0)
+
+	var productID *uuid.UUID
+	if v := r.URL.Query().Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		productID = &id
+	}
+
+	var warehouseID *uuid.UUID
+	if v := r.URL.Query().Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		warehouseID = &id
+	}
+
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be non-negative")
+		return
+	}
+
+	levels, err := h.service.List(r.Context(), limit, offset, productID, warehouseID)
+	if err != nil {
+		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load stock levels")
+		writeError(w, http.StatusInternalServerError, "LEVELS_LOAD_FAILED", "failed to load stock levels")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StockLevelResponse]{
+		Data: levels,
+		Meta: &dto.Meta{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeStockLevelError maps validation errors shared by Create and Update; returns false for unknown errors.
+func writeStockLevelError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case repository.ErrStockLevelNotFound:
+		writeError(w, http.StatusNotFound, "LEVEL_NOT_FOUND", "stock level not found")
+	case repository.ErrStockLevelExists:
+		writeError(w, http.StatusConflict, "LEVEL_EXISTS", "stock level for this product and warehouse already exists")
+	case repository.ErrInvalidStockLevel:
+		writeError(w, http.StatusBadRequest, "INVALID_STOCK_LEVEL", "minQty must be non-negative and not greater than maxQty")
+	case repository.ErrProductNotFound:
+		writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
+	case repository.ErrProductArchived:
+		writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+	case repository.ErrWarehouseNotFound:
+		writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
+	case repository.ErrWarehouseArchived:
+		writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *StockLevelHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	var req dto.StockLevelCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.ProductID == "" || req.WarehouseID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "productId and warehouseId are required")
+		return
+	}
+
+	level, err := h.service.Create(r.Context(), userID, req)
+	if err != nil {
+		if writeStockLevelError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("productId", req.ProductID).Str("warehouseId", req.WarehouseID).Msg("Failed to create stock level")
+		writeError(w, http.StatusInternalServerError, "LEVEL_CREATE_FAILED", "failed to create stock level")
+		return
+	}
+
+	response := dto.APIResponse[dto.StockLevelResponse]{
+		Data: *level,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StockLevelHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	levelID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_LEVEL_ID", "invalid stock level id")
+		return
+	}
+
+	var req dto.StockLevelUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.ProductID == "" || req.WarehouseID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "productId and warehouseId are required")
+		return
+	}
+
+	level, err := h.service.Update(r.Context(), levelID, userID, req)
+	if err != nil {
+		if writeStockLevelError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("levelId", levelID.String()).Msg("Failed to update stock level")
+		writeError(w, http.StatusInternalServerError, "LEVEL_UPDATE_FAILED", "failed to update stock level")
+		return
+	}
+
+	response := dto.APIResponse[dto.StockLevelResponse]{
+		Data: *level,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StockLevelHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	levelID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_LEVEL_ID", "invalid stock level id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), levelID)
+	if err != nil {
+		if err == repository.ErrStockLevelNotFound {
+			log.Warn().Str("levelId", levelID.String()).Msg("Stock level not found for deletion")
+			writeError(w, http.StatusNotFound, "LEVEL_NOT_FOUND", "stock level not found")
+			return
+		}
+		log.Error().Err(err).Str("levelId", levelID.String()).Msg("Failed to delete stock level")
+		writeError(w, http.StatusInternalServerError, "LEVEL_DELETE_FAILED", "failed to delete stock level")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *StockLevelHandler) GetReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	var warehouseID *uuid.UUID
+	if v := r.URL.Query().Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		warehouseID = &id
+	}
+
+	var productID *uuid.UUID
+	if v := r.URL.Query().Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		productID = &id
+	}
+
+	suggestions, err := h.service.GetReorderSuggestions(r.Context(), warehouseID, productID)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Interface("productId", productID).Msg("Failed to load reorder suggestions")
+		writeError(w, http.StatusInternalServerError, "SUGGESTIONS_LOAD_FAILED", "failed to load reorder suggestions")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.ReorderSuggestionResponse]{
+		Data: suggestions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type StockLotHandler struct {
+	service *service.StockLotService
+}
+
+func NewStockLotHandler(service *service.StockLotService) *StockLotHandler {
+	return &StockLotHandler{service: service}
+}
+
+func (h *StockLotHandler) GetLots(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var productID *uuid.UUID
+	if v := q.Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		productID = &id
+	}
+
+	var warehouseID *uuid.UUID
+	if v := q.Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		warehouseID = &id
+	}
+
+	includeEmpty := parseBool(q.Get("includeEmpty"), false)
+
+	report, err := h.service.GetLots(r.Context(), productID, warehouseID, includeEmpty)
+	if err != nil {
+		log.Error().Err(err).Interface("productId", productID).Interface("warehouseId", warehouseID).Msg("Failed to load stock lots")
+		writeError(w, http.StatusInternalServerError, "LOTS_LOAD_FAILED", "failed to load stock lots")
+		return
+	}
+
+	response := dto.APIResponse[dto.StockLotReportResponse]{
+		Data: *report,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StockLotHandler) GetShipmentCosts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var dateFrom *time.Time
+	if v := q.Get("dateFrom"); v != "" {
+		date, err := parseDate(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE_FROM", "dateFrom must be in YYYY-MM-DD format")
+			return
+		}
+		dateFrom = &date
+	}
+
+	var dateTo *time.Time
+	if v := q.Get("dateTo"); v != "" {
+		date, err := parseDate(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE_TO", "dateTo must be in YYYY-MM-DD format")
+			return
+		}
+		dateTo = &date
+	}
+	if dateFrom != nil && dateTo != nil && dateTo.Before(*dateFrom) {
+		writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "dateTo must not be before dateFrom")
+		return
+	}
+
+	var storeID *uuid.UUID
+	if v := q.Get("storeId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_STORE_ID", "invalid storeId")
+			return
+		}
+		storeID = &id
+	}
+
+	costs, err := h.service.GetShipmentCosts(r.Context(), dateFrom, dateTo, storeID)
+	if err != nil {
+		log.Error().Err(err).Interface("storeId", storeID).Msg("Failed to load mp shipment costs")
+		writeError(w, http.StatusInternalServerError, "SHIPMENT_COSTS_LOAD_FAILED", "failed to load mp shipment costs")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.MpShipmentCostResponse]{
+		Data: costs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StockLotHandler) GetShipmentCost(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	shipmentID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_SHIPMENT_ID", "invalid shipment id")
+		return
+	}
+
+	cost, err := h.service.GetShipmentCost(r.Context(), shipmentID)
+	if err != nil {
+		if err == repository.ErrMpShipmentNotFound {
+			writeError(w, http.StatusNotFound, "SHIPMENT_NOT_FOUND", "mp shipment not found")
+			return
+		}
+		if err == repository.ErrShipmentNotSent {
+			writeError(w, http.StatusConflict, "SHIPMENT_NOT_SENT", "mp shipment is not sent yet")
+			return
+		}
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to load mp shipment cost")
+		writeError(w, http.StatusInternalServerError, "SHIPMENT_COST_LOAD_FAILED", "failed to load mp shipment cost")
+		return
+	}
+
+	response := dto.APIResponse[dto.MpShipmentCostResponse]{
+		Data: *cost,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -15,11 +15,12 @@ import (
 )
 
 type StockSnapshotHandler struct {
-	service *service.StockSnapshotService
+	service    *service.StockSnapshotService
+	jobService *service.StockSnapshotJobService
 }
 
-func NewStockSnapshotHandler(service *service.StockSnapshotService) *StockSnapshotHandler {
-	return &StockSnapshotHandler{service: service}
+func NewStockSnapshotHandler(service *service.StockSnapshotService, jobService *service.StockSnapshotJobService) *StockSnapshotHandler {
+	return &StockSnapshotHandler{service: service, jobService: jobService}
 }
 
 func (h *StockSnapshotHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -258,3 +259,112 @@ func (h *StockSnapshotHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Generate creates the snapshots for the end of the previous month, the same as the scheduled job.
+func (h *StockSnapshotHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	run, err := h.jobService.Generate(r.Context(), repository.SnapshotRunTriggerManual, &userID)
+	if err != nil {
+		if err == repository.ErrSnapshotGenerationLocked {
+			writeError(w, http.StatusConflict, "SNAPSHOT_GENERATION_RUNNING", "stock snapshot generation is already running")
+			return
+		}
+		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to generate stock snapshots")
+		writeError(w, http.StatusInternalServerError, "SNAPSHOT_GENERATION_FAILED", "failed to generate stock snapshots")
+		return
+	}
+
+	response := dto.APIResponse[dto.StockSnapshotRunResponse]{
+		Data: *run,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StockSnapshotHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit := parseInt(r.URL.Query().Get("limit"), 50)
+	offset := parseInt(r.URL.Query().Get("offset"), 0)
+
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be non-negative")
+		return
+	}
+
+	runs, err := h.jobService.ListRuns(r.Context(), limit, offset)
+	if err != nil {
+		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load stock snapshot runs")
+		writeError(w, http.StatusInternalServerError, "SNAPSHOT_RUNS_LOAD_FAILED", "failed to load stock snapshot runs")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StockSnapshotRunResponse]{
+		Data: runs,
+		Meta: &dto.Meta{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Verify reports snapshots that do not match the previous snapshot plus movements.
+func (h *StockSnapshotHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	h.verify(w, r, false)
+}
+
+// Repair corrects the snapshots reported by Verify.
+func (h *StockSnapshotHandler) Repair(w http.ResponseWriter, r *http.Request) {
+	h.verify(w, r, true)
+}
+
+func (h *StockSnapshotHandler) verify(w http.ResponseWriter, r *http.Request, repair bool) {
+	var warehouseID *uuid.UUID
+	if v := r.URL.Query().Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		warehouseID = &id
+	}
+
+	var productID *uuid.UUID
+	if v := r.URL.Query().Get("productId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productId")
+			return
+		}
+		productID = &id
+	}
+
+	result, err := h.service.Verify(r.Context(), warehouseID, productID, repair)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Interface("productId", productID).Bool("repair", repair).
+			Msg("Failed to verify stock snapshots")
+		writeError(w, http.StatusInternalServerError, "SNAPSHOTS_VERIFY_FAILED", "failed to verify stock snapshots")
+		return
+	}
+
+	response := dto.APIResponse[dto.StockSnapshotVerifyResponse]{
+		Data: *result,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type StorageLocationHandler struct {
+	service *service.StorageLocationService
+}
+
+func NewStorageLocationHandler(service *service.StorageLocationService) *StorageLocationHandler {
+	return &StorageLocationHandler{service: service}
+}
+
+// writeStorageLocationError maps errors shared by storage location operations; returns false for unknown errors.
+func writeStorageLocationError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case repository.ErrWarehouseNotFound:
+		writeError(w, http.StatusNotFound, "WAREHOUSE_NOT_FOUND", "warehouse not found")
+	case repository.ErrWarehouseArchived:
+		writeError(w, http.StatusConflict, "WAREHOUSE_ARCHIVED", "warehouse is archived")
+	case repository.ErrStorageLocationNotFound:
+		writeError(w, http.StatusNotFound, "LOCATION_NOT_FOUND", "storage location not found")
+	case repository.ErrStorageLocationExists:
+		writeError(w, http.StatusConflict, "LOCATION_EXISTS", "location with this code already exists under the same parent")
+	case repository.ErrStorageLocationArchived:
+		writeError(w, http.StatusConflict, "LOCATION_ARCHIVED", "parent location is archived")
+	case repository.ErrInvalidLocationType:
+		writeError(w, http.StatusBadRequest, "INVALID_LOCATION_TYPE", "locationType must be one of: zone, rack, shelf, bin")
+	case repository.ErrInvalidLocationParent:
+		writeError(w, http.StatusBadRequest, "INVALID_LOCATION_PARENT", "zones have no parent; racks, shelves and bins need a parent one level up in the same warehouse")
+	case repository.ErrLocationHasChildren:
+		writeError(w, http.StatusConflict, "LOCATION_HAS_CHILDREN", "archive child locations first")
+	case repository.ErrLocationNotEmpty:
+		writeError(w, http.StatusConflict, "LOCATION_NOT_EMPTY", "storage location still holds stock")
+	case repository.ErrSupplierOrderNotFound:
+		writeError(w, http.StatusBadRequest, "ORDER_NOT_FOUND", "specified supplier order does not exist")
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *StorageLocationHandler) List(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	includeArchived := parseBool(r.URL.Query().Get("includeArchived"), false)
+
+	locations, err := h.service.List(r.Context(), warehouseID, includeArchived)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to load storage locations")
+		writeError(w, http.StatusInternalServerError, "LOCATIONS_LOAD_FAILED", "failed to load storage locations")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StorageLocationResponse]{
+		Data: locations,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StorageLocationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	locationID, err := parseUUID(chi.URLParam(r, "locationId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_LOCATION_ID", "invalid location id")
+		return
+	}
+
+	location, err := h.service.GetByID(r.Context(), warehouseID, locationID)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("locationId", locationID.String()).Msg("Failed to load storage location")
+		writeError(w, http.StatusInternalServerError, "LOCATION_LOAD_FAILED", "failed to load storage location")
+		return
+	}
+
+	response := dto.APIResponse[dto.StorageLocationResponse]{
+		Data: *location,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StorageLocationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	var req dto.StorageLocationCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.LocationType == "" || req.Code == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "locationType and code are required")
+		return
+	}
+
+	location, err := h.service.Create(r.Context(), warehouseID, userID, req)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Str("code", req.Code).Msg("Failed to create storage location")
+		writeError(w, http.StatusInternalServerError, "LOCATION_CREATE_FAILED", "failed to create storage location")
+		return
+	}
+
+	response := dto.APIResponse[dto.StorageLocationResponse]{
+		Data: *location,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StorageLocationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	locationID, err := parseUUID(chi.URLParam(r, "locationId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_LOCATION_ID", "invalid location id")
+		return
+	}
+
+	var req dto.StorageLocationUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.Code == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "code is required")
+		return
+	}
+
+	location, err := h.service.Update(r.Context(), warehouseID, locationID, userID, req)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("locationId", locationID.String()).Msg("Failed to update storage location")
+		writeError(w, http.StatusInternalServerError, "LOCATION_UPDATE_FAILED", "failed to update storage location")
+		return
+	}
+
+	response := dto.APIResponse[dto.StorageLocationResponse]{
+		Data: *location,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StorageLocationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	locationID, err := parseUUID(chi.URLParam(r, "locationId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_LOCATION_ID", "invalid location id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), warehouseID, locationID)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("locationId", locationID.String()).Msg("Failed to delete storage location")
+		writeError(w, http.StatusInternalServerError, "LOCATION_DELETE_FAILED", "failed to delete storage location")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *StorageLocationHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+	locationID, err := parseUUID(chi.URLParam(r, "locationId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_LOCATION_ID", "invalid location id")
+		return
+	}
+
+	location, err := h.service.Restore(r.Context(), warehouseID, locationID)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("locationId", locationID.String()).Msg("Failed to restore storage location")
+		writeError(w, http.StatusInternalServerError, "LOCATION_RESTORE_FAILED", "failed to restore storage location")
+		return
+	}
+
+	response := dto.APIResponse[dto.StorageLocationResponse]{
+		Data: *location,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StorageLocationHandler) GetStock(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	stock, err := h.service.GetStock(r.Context(), warehouseID)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to load location stock")
+		writeError(w, http.StatusInternalServerError, "LOCATION_STOCK_LOAD_FAILED", "failed to load location stock")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.LocationStockResponse]{
+		Data: stock,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *StorageLocationHandler) GetPutAwaySuggestions(w http.ResponseWriter, r *http.Request) {
+	warehouseID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	var orderID *uuid.UUID
+	if v := r.URL.Query().Get("orderId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ORDER_ID", "invalid orderId")
+			return
+		}
+		orderID = &id
+	}
+
+	suggestions, err := h.service.GetPutAwaySuggestions(r.Context(), warehouseID, orderID)
+	if err != nil {
+		if writeStorageLocationError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Interface("orderId", orderID).Msg("Failed to load put-away suggestions")
+		writeError(w, http.StatusInternalServerError, "PUT_AWAY_LOAD_FAILED", "failed to load put-away suggestions")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.PutAwaySuggestionResponse]{
+		Data: suggestions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
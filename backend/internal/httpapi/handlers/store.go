@@ -52,6 +52,7 @@ func (h *StoreHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *StoreHandler) List(w http.ResponseWriter, r *http.Request) {
 	limit := parseInt(r.URL.Query().Get("limit"), 50)
 	offset := parseInt(r.URL.Query().Get("offset"), 0)
+	includeArchived := parseBool(r.URL.Query().Get("includeArchived"), false)
 
 	if limit < 1 || limit > 1000 {
 		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
@@ -62,7 +63,7 @@ func (h *StoreHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stores, err := h.service.List(r.Context(), limit, offset)
+	stores, err := h.service.List(r.Context(), limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load stores")
 		writeError(w, http.StatusInternalServerError, "STORES_LOAD_FAILED", "failed to load stores")
@@ -182,3 +183,32 @@ func (h *StoreHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *StoreHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	storeID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_STORE_ID", "invalid store id")
+		return
+	}
+
+	store, err := h.service.Restore(r.Context(), storeID)
+	if err != nil {
+		if err == repository.ErrStoreNotFound {
+			log.Warn().Str("storeId", storeID.String()).Msg("Store not found for restore")
+			writeError(w, http.StatusNotFound, "STORE_NOT_FOUND", "store not found")
+			return
+		}
+		log.Error().Err(err).Str("storeId", storeID.String()).Msg("Failed to restore store")
+		writeError(w, http.StatusInternalServerError, "STORE_RESTORE_FAILED", "failed to restore store")
+		return
+	}
+
+	response := dto.APIResponse[dto.StoreResponse]{
+		Data: *store,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -124,11 +125,24 @@ func (h *SupplierOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "ORDER_STATUS_NOT_FOUND", "specified order status does not exist")
 			return
 		}
+		if err == repository.ErrOrderStatusArchived {
+			log.Warn().Interface("statusId", req.StatusID).Msg("Order status is archived")
+			writeError(w, http.StatusBadRequest, "ORDER_STATUS_ARCHIVED", "specified order status is archived")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "document cannot be created in this status")
+			return
+		}
 		if err == repository.ErrSupplierOrderNotFound {
 			log.Warn().Interface("parentOrderId", req.ParentOrderID).Msg("Parent order not found")
 			writeError(w, http.StatusBadRequest, "PARENT_ORDER_NOT_FOUND", "specified parent order does not exist")
 			return
 		}
+		if err == repository.ErrInvalidAllocationMethod {
+			writeError(w, http.StatusBadRequest, "INVALID_ALLOCATION_METHOD", "logisticsAllocationMethod must be one of: weight, value, quantity")
+			return
+		}
 		if err == repository.ErrInvalidDateRange {
 			log.Warn().Msg("Invalid date range")
 			writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "invalid date range: planned receipt date must be after purchase date, actual receipt date must be after planned receipt date")
@@ -190,9 +204,18 @@ func (h *SupplierOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "ORDER_STATUS_NOT_FOUND", "specified order status does not exist")
 			return
 		}
+		if err == repository.ErrOrderStatusArchived {
+			log.Warn().Interface("statusId", req.StatusID).Msg("Order status is archived")
+			writeError(w, http.StatusBadRequest, "ORDER_STATUS_ARCHIVED", "specified order status is archived")
+			return
+		}
 		if err == repository.ErrInvalidParentOrder {
-			log.Warn().Str("orderId", orderID.String()).Interface("parentOrderId", req.ParentOrderID).Msg("Order cannot be parent of itself")
-			writeError(w, http.StatusBadRequest, "INVALID_PARENT_ORDER", "order cannot be parent of itself")
+			log.Warn().Str("orderId", orderID.String()).Interface("parentOrderId", req.ParentOrderID).Msg("Invalid parent order")
+			writeError(w, http.StatusBadRequest, "INVALID_PARENT_ORDER", "order cannot be parent of itself or of its ancestors")
+			return
+		}
+		if err == repository.ErrInvalidAllocationMethod {
+			writeError(w, http.StatusBadRequest, "INVALID_ALLOCATION_METHOD", "logisticsAllocationMethod must be one of: weight, value, quantity")
 			return
 		}
 		if err == repository.ErrInvalidDateRange {
@@ -200,6 +223,14 @@ func (h *SupplierOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "invalid date range: planned receipt date must be after purchase date, actual receipt date must be after planned receipt date")
 			return
 		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+			return
+		}
+		if err == repository.ErrReceiptRequired {
+			writeError(w, http.StatusConflict, "RECEIPT_REQUIRED", "use the receive endpoint to mark supplier order as received")
+			return
+		}
 		log.Error().Err(err).Str("orderId", orderID.String()).Str("userId", userID.String()).Msg("Failed to update supplier order")
 		writeError(w, http.StatusInternalServerError, "ORDER_UPDATE_FAILED", "failed to update supplier order")
 		return
@@ -236,3 +267,329 @@ func (h *SupplierOrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *SupplierOrderHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	orderID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ORDER_ID", "invalid order id")
+		return
+	}
+
+	var req dto.SupplierOrderReceiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	receipt, err := h.service.Receive(r.Context(), orderID, userID, req)
+	if err != nil {
+		if err == repository.ErrSupplierOrderNotFound {
+			log.Warn().Str("orderId", orderID.String()).Msg("Supplier order not found for receiving")
+			writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "supplier order not found")
+			return
+		}
+		if err == repository.ErrEmptyReceipt {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "items are required")
+			return
+		}
+		if err == repository.ErrSupplierOrderItemNotFound {
+			log.Warn().Str("orderId", orderID.String()).Msg("Receipt references unknown order item")
+			writeError(w, http.StatusBadRequest, "ORDER_ITEM_NOT_FOUND", "order item does not belong to this supplier order")
+			return
+		}
+		if err == repository.ErrInvalidReceivedQty {
+			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "receivedQty must be non-negative")
+			return
+		}
+		if err == repository.ErrDuplicateReceiptLine {
+			writeError(w, http.StatusBadRequest, "DUPLICATE_ORDER_ITEM", "each order item may appear only once")
+			return
+		}
+		if err == repository.ErrInvalidDateRange {
+			writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "receipt date must not be before purchase date")
+			return
+		}
+		if err == repository.ErrOrderAlreadyReceived {
+			log.Warn().Str("orderId", orderID.String()).Msg("Supplier order already received")
+			writeError(w, http.StatusConflict, "ORDER_ALREADY_RECEIVED", "supplier order is already received")
+			return
+		}
+		if err == repository.ErrOrderCancelled {
+			log.Warn().Str("orderId", orderID.String()).Msg("Cannot receive cancelled supplier order")
+			writeError(w, http.StatusConflict, "ORDER_CANCELLED", "cancelled supplier order cannot be received")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+			return
+		}
+		log.Error().Err(err).Str("orderId", orderID.String()).Str("userId", userID.String()).Msg("Failed to receive supplier order")
+		writeError(w, http.StatusInternalServerError, "ORDER_RECEIVE_FAILED", "failed to receive supplier order")
+		return
+	}
+
+	response := dto.APIResponse[dto.SupplierOrderReceiptResponse]{
+		Data: *receipt,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *SupplierOrderHandler) GetReceipts(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	orderID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ORDER_ID", "invalid order id")
+		return
+	}
+
+	receipts, err := h.service.GetReceipts(r.Context(), orderID)
+	if err != nil {
+		if err == repository.ErrSupplierOrderNotFound {
+			writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "supplier order not found")
+			return
+		}
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to load supplier order receipts")
+		writeError(w, http.StatusInternalServerError, "RECEIPTS_LOAD_FAILED", "failed to load supplier order receipts")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.SupplierOrderReceiptResponse]{
+		Data: receipts,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *SupplierOrderHandler) SplitRemainder(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	orderID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ORDER_ID", "invalid order id")
+		return
+	}
+
+	var req dto.SupplierOrderSplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	order, err := h.service.SplitRemainder(r.Context(), orderID, userID, req)
+	if err != nil {
+		if err == repository.ErrSupplierOrderNotFound {
+			writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "supplier order not found")
+			return
+		}
+		if err == repository.ErrNothingToSplit {
+			writeError(w, http.StatusConflict, "NOTHING_TO_SPLIT", "all order items are fully received")
+			return
+		}
+		if err == repository.ErrNothingReceived {
+			writeError(w, http.StatusConflict, "NOTHING_RECEIVED", "nothing has been received for the order yet")
+			return
+		}
+		if err == repository.ErrOrderCancelled {
+			writeError(w, http.StatusConflict, "ORDER_CANCELLED", "cancelled supplier order cannot be split")
+			return
+		}
+		if err == repository.ErrSupplierOrderExists {
+			writeError(w, http.StatusConflict, "ORDER_EXISTS", "supplier order with this orderNumber already exists")
+			return
+		}
+		log.Error().Err(err).Str("orderId", orderID.String()).Str("userId", userID.String()).Msg("Failed to split supplier order")
+		writeError(w, http.StatusInternalServerError, "ORDER_SPLIT_FAILED", "failed to split supplier order")
+		return
+	}
+
+	response := dto.APIResponse[dto.SupplierOrderResponse]{
+		Data: *order,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateFromReorderSuggestions creates a draft order from the current reorder suggestions.
+func (h *SupplierOrderHandler) CreateFromReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	var req dto.ReorderOrderCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	order, err := h.service.CreateFromReorderSuggestions(r.Context(), userID, req)
+	if err != nil {
+		if err == repository.ErrWarehouseNotFound {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		if err == repository.ErrProductNotFound {
+			writeError(w, http.StatusBadRequest, "INVALID_PRODUCT_ID", "invalid productIds")
+			return
+		}
+		if err == repository.ErrNoReorderSuggestions {
+			writeError(w, http.StatusConflict, "NOTHING_TO_REORDER", "no products are below their minimum stock level")
+			return
+		}
+		if err == repository.ErrSupplierOrderExists {
+			writeError(w, http.StatusConflict, "ORDER_EXISTS", "supplier order with this orderNumber already exists")
+			return
+		}
+		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to create supplier order from reorder suggestions")
+		writeError(w, http.StatusInternalServerError, "ORDER_CREATE_FAILED", "failed to create supplier order")
+		return
+	}
+
+	response := dto.APIResponse[dto.SupplierOrderResponse]{
+		Data: *order,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *SupplierOrderHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	orderID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ORDER_ID", "invalid order id")
+		return
+	}
+
+	tree, err := h.service.GetTree(r.Context(), orderID)
+	if err != nil {
+		if err == repository.ErrSupplierOrderNotFound {
+			writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "supplier order not found")
+			return
+		}
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to load supplier order tree")
+		writeError(w, http.StatusInternalServerError, "ORDER_TREE_LOAD_FAILED", "failed to load supplier order tree")
+		return
+	}
+
+	response := dto.APIResponse[dto.SupplierOrderTreeNodeResponse]{
+		Data: *tree,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *SupplierOrderHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	orderID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ORDER_ID", "invalid order id")
+		return
+	}
+
+	var req dto.StatusTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+	if req.StatusID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "statusId is required")
+		return
+	}
+
+	order, err := h.service.Transition(r.Context(), orderID, userID, req)
+	if err != nil {
+		if err == repository.ErrSupplierOrderNotFound {
+			writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "supplier order not found")
+			return
+		}
+		if err == repository.ErrOrderStatusNotFound {
+			writeError(w, http.StatusBadRequest, "ORDER_STATUS_NOT_FOUND", "specified order status does not exist")
+			return
+		}
+		if err == repository.ErrOrderStatusArchived {
+			writeError(w, http.StatusBadRequest, "ORDER_STATUS_ARCHIVED", "specified order status is archived")
+			return
+		}
+		if err == repository.ErrInvalidStatusTransition {
+			writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+			return
+		}
+		if err == repository.ErrReceiptRequired {
+			writeError(w, http.StatusConflict, "RECEIPT_REQUIRED", "use the receive endpoint to mark supplier order as received")
+			return
+		}
+		if err == repository.ErrStatusConflict {
+			writeError(w, http.StatusConflict, "STATUS_CONFLICT", "supplier order status was changed by another request")
+			return
+		}
+		log.Error().Err(err).Str("orderId", orderID.String()).Str("userId", userID.String()).Msg("Failed to change supplier order status")
+		writeError(w, http.StatusInternalServerError, "ORDER_TRANSITION_FAILED", "failed to change supplier order status")
+		return
+	}
+
+	response := dto.APIResponse[dto.SupplierOrderResponse]{
+		Data: *order,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *SupplierOrderHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	orderID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ORDER_ID", "invalid order id")
+		return
+	}
+
+	history, err := h.service.History(r.Context(), orderID)
+	if err != nil {
+		if err == repository.ErrSupplierOrderNotFound {
+			writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "supplier order not found")
+			return
+		}
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to load supplier order status history")
+		writeError(w, http.StatusInternalServerError, "HISTORY_LOAD_FAILED", "failed to load supplier order status history")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StatusHistoryResponse]{
+		Data: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
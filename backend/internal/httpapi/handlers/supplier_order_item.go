@@ -104,10 +104,6 @@ func (h *SupplierOrderItemHandler) Create(w http.ResponseWriter, r *http.Request
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "orderedQty must be non-negative")
 		return
 	}
-	if req.ReceivedQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "receivedQty must be non-negative")
-		return
-	}
 	if req.TotalWeight < 0 {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "totalWeight must be non-negative")
 		return
@@ -130,14 +126,23 @@ func (h *SupplierOrderItemHandler) Create(w http.ResponseWriter, r *http.Request
 			writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 			return
 		}
+		if err == repository.ErrProductArchived {
+			log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+			writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+			return
+		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
-		if err == repository.ErrInvalidQuantity {
-			log.Warn().Int("orderedQty", req.OrderedQty).Int("receivedQty", req.ReceivedQty).Msg("Invalid quantity")
-			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "received quantity cannot exceed ordered quantity")
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
+		if err == repository.ErrSupplierOrderClosed {
+			writeError(w, http.StatusConflict, "ORDER_CLOSED", "items of a received or cancelled supplier order cannot be changed")
 			return
 		}
 		log.Error().Err(err).Str("orderId", req.OrderID).Str("productId", req.ProductID).Str("userId", userID.String()).Msg("Failed to create supplier order item")
@@ -190,10 +195,6 @@ func (h *SupplierOrderItemHandler) Update(w http.ResponseWriter, r *http.Request
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "orderedQty must be non-negative")
 		return
 	}
-	if req.ReceivedQty < 0 {
-		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "receivedQty must be non-negative")
-		return
-	}
 	if req.TotalWeight < 0 {
 		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "totalWeight must be non-negative")
 		return
@@ -221,14 +222,23 @@ func (h *SupplierOrderItemHandler) Update(w http.ResponseWriter, r *http.Request
 			writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist")
 			return
 		}
+		if err == repository.ErrProductArchived {
+			log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+			writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+			return
+		}
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
 			writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
 			return
 		}
-		if err == repository.ErrInvalidQuantity {
-			log.Warn().Int("orderedQty", req.OrderedQty).Int("receivedQty", req.ReceivedQty).Msg("Invalid quantity")
-			writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "received quantity cannot exceed ordered quantity")
+		if err == repository.ErrWarehouseArchived {
+			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+			writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+			return
+		}
+		if err == repository.ErrSupplierOrderClosed {
+			writeError(w, http.StatusConflict, "ORDER_CLOSED", "items of a received or cancelled supplier order cannot be changed")
 			return
 		}
 		log.Error().Err(err).Str("itemId", itemID.String()).Str("userId", userID.String()).Msg("Failed to update supplier order item")
@@ -266,6 +276,10 @@ func (h *SupplierOrderItemHandler) Delete(w http.ResponseWriter, r *http.Request
 			writeError(w, http.StatusNotFound, "ITEM_NOT_FOUND", "supplier order item not found")
 			return
 		}
+		if err == repository.ErrSupplierOrderClosed {
+			writeError(w, http.StatusConflict, "ORDER_CLOSED", "items of a received or cancelled supplier order cannot be changed")
+			return
+		}
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to delete supplier order item")
 		writeError(w, http.StatusInternalServerError, "ITEM_DELETE_FAILED", "failed to delete supplier order item")
 		return
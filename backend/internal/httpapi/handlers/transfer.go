@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+	"warehouse-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type TransferHandler struct {
+	service *service.TransferService
+}
+
+func NewTransferHandler(service *service.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+func (h *TransferHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	transferID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TRANSFER_ID", "invalid transfer id")
+		return
+	}
+
+	transfer, err := h.service.GetByID(r.Context(), transferID)
+	if err != nil {
+		if err == repository.ErrTransferNotFound {
+			log.Warn().Str("transferId", transferID.String()).Msg("Transfer not found")
+			writeError(w, http.StatusNotFound, "TRANSFER_NOT_FOUND", "transfer not found")
+			return
+		}
+		log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to load transfer")
+		writeError(w, http.StatusInternalServerError, "TRANSFER_LOAD_FAILED", "failed to load transfer")
+		return
+	}
+
+	response := dto.APIResponse[dto.TransferResponse]{
+		Data: *transfer,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TransferHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := parseInt(r.URL.Query().Get("limit"), 50)
+	offset := parseInt(r.URL.Query().Get("offset"), 0)
+
+	var status *string
+	if v := r.URL.Query().Get("status"); v != "" {
+		switch v {
+		case repository.TransferStatusDraft, repository.TransferStatusInTransit, repository.TransferStatusReceived, repository.TransferStatusCancelled:
+			status = &v
+		default:
+			writeError(w, http.StatusBadRequest, "INVALID_STATUS", "status must be one of: Черновик, В пути, Получено, Отменено")
+			return
+		}
+	}
+
+	var warehouseID *uuid.UUID
+	if v := r.URL.Query().Get("warehouseId"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouseId")
+			return
+		}
+		warehouseID = &id
+	}
+
+	if limit < 1 || limit > 1000 {
+		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
+		return
+	}
+	if offset < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_OFFSET", "offset must be non-negative")
+		return
+	}
+
+	transfers, err := h.service.List(r.Context(), limit, offset, status, warehouseID)
+	if err != nil {
+		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load transfers")
+		writeError(w, http.StatusInternalServerError, "TRANSFERS_LOAD_FAILED", "failed to load transfers")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.TransferResponse]{
+		Data: transfers,
+		Meta: &dto.Meta{
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeTransferError maps errors shared by transfer operations; returns false for unknown errors.
+func writeTransferError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case repository.ErrTransferNotFound:
+		writeError(w, http.StatusNotFound, "TRANSFER_NOT_FOUND", "transfer not found")
+	case repository.ErrTransferExists:
+		writeError(w, http.StatusConflict, "TRANSFER_EXISTS", "transfer with this number already exists")
+	case repository.ErrTransferNotEditable:
+		writeError(w, http.StatusConflict, "TRANSFER_NOT_EDITABLE", "transfer is already shipped")
+	case repository.ErrSameWarehouse:
+		writeError(w, http.StatusBadRequest, "SAME_WAREHOUSE", "source and destination warehouses must differ")
+	case repository.ErrEmptyTransfer:
+		writeError(w, http.StatusBadRequest, "EMPTY_TRANSFER", "transfer must contain at least one item")
+	case repository.ErrDuplicateItem:
+		writeError(w, http.StatusBadRequest, "DUPLICATE_ITEM", "each product may be listed only once")
+	case repository.ErrReceivedExceedsShipped:
+		writeError(w, http.StatusBadRequest, "RECEIVED_EXCEEDS_SHIPPED", "received quantity cannot exceed shipped quantity")
+	case repository.ErrInvalidQuantity:
+		writeError(w, http.StatusBadRequest, "INVALID_QUANTITY", "shipped quantity must be positive and received quantity non-negative")
+	case repository.ErrInvalidDateRange:
+		writeError(w, http.StatusBadRequest, "INVALID_DATE_RANGE", "receipt date must not be before shipment date")
+	case repository.ErrProductNotFound:
+		writeError(w, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "specified product does not exist or is not in the transfer")
+	case repository.ErrProductArchived:
+		writeError(w, http.StatusBadRequest, "PRODUCT_ARCHIVED", "specified product is archived")
+	case repository.ErrWarehouseNotFound:
+		writeError(w, http.StatusBadRequest, "WAREHOUSE_NOT_FOUND", "specified warehouse does not exist")
+	case repository.ErrWarehouseArchived:
+		writeError(w, http.StatusBadRequest, "WAREHOUSE_ARCHIVED", "specified warehouse is archived")
+	case repository.ErrInvalidStatusTransition:
+		writeError(w, http.StatusConflict, "INVALID_STATUS_TRANSITION", "status change is not allowed from the current status")
+	case repository.ErrNegativeStock:
+		writeError(w, http.StatusConflict, "NEGATIVE_STOCK", "operation would make stock negative")
+	case repository.ErrStockNotPicked:
+		writeError(w, http.StatusConflict, "STOCK_NOT_PICKED", "outgoing stock is placed in bins, pick it with a location move first")
+	case repository.ErrStatusConflict:
+		writeError(w, http.StatusConflict, "STATUS_CONFLICT", "transfer status was changed by another request")
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *TransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	var req dto.TransferCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.TransferNumber == "" || req.SourceWarehouseID == "" || req.DestinationWarehouseID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "transferNumber, sourceWarehouseId and destinationWarehouseId are required")
+		return
+	}
+
+	transfer, err := h.service.Create(r.Context(), userID, req)
+	if err != nil {
+		if writeTransferError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("transferNumber", req.TransferNumber).Msg("Failed to create transfer")
+		writeError(w, http.StatusInternalServerError, "TRANSFER_CREATE_FAILED", "failed to create transfer")
+		return
+	}
+
+	response := dto.APIResponse[dto.TransferResponse]{
+		Data: *transfer,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TransferHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	transferID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TRANSFER_ID", "invalid transfer id")
+		return
+	}
+
+	var req dto.TransferUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	if req.TransferNumber == "" || req.SourceWarehouseID == "" || req.DestinationWarehouseID == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "transferNumber, sourceWarehouseId and destinationWarehouseId are required")
+		return
+	}
+
+	transfer, err := h.service.Update(r.Context(), transferID, userID, req)
+	if err != nil {
+		if writeTransferError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to update transfer")
+		writeError(w, http.StatusInternalServerError, "TRANSFER_UPDATE_FAILED", "failed to update transfer")
+		return
+	}
+
+	response := dto.APIResponse[dto.TransferResponse]{
+		Data: *transfer,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TransferHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	transferID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TRANSFER_ID", "invalid transfer id")
+		return
+	}
+
+	err = h.service.Delete(r.Context(), transferID)
+	if err != nil {
+		if err == repository.ErrTransferNotFound {
+			log.Warn().Str("transferId", transferID.String()).Msg("Transfer not found for deletion")
+			writeError(w, http.StatusNotFound, "TRANSFER_NOT_FOUND", "transfer not found")
+			return
+		}
+		if err == repository.ErrTransferNotEditable {
+			writeError(w, http.StatusConflict, "TRANSFER_NOT_EDITABLE", "only draft or cancelled transfers can be deleted")
+			return
+		}
+		log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to delete transfer")
+		writeError(w, http.StatusInternalServerError, "TRANSFER_DELETE_FAILED", "failed to delete transfer")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TransferHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	transferID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TRANSFER_ID", "invalid transfer id")
+		return
+	}
+
+	history, err := h.service.History(r.Context(), transferID)
+	if err != nil {
+		if err == repository.ErrTransferNotFound {
+			writeError(w, http.StatusNotFound, "TRANSFER_NOT_FOUND", "transfer not found")
+			return
+		}
+		log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to load transfer status history")
+		writeError(w, http.StatusInternalServerError, "HISTORY_LOAD_FAILED", "failed to load transfer status history")
+		return
+	}
+
+	response := dto.APIResponse[[]dto.StatusHistoryResponse]{
+		Data: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TransferHandler) Ship(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	transferID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TRANSFER_ID", "invalid transfer id")
+		return
+	}
+
+	var req dto.TransferShipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	transfer, err := h.service.Ship(r.Context(), transferID, userID, req)
+	if err != nil {
+		if writeTransferError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("transferId", transferID.String()).Str("userId", userID.String()).Msg("Failed to ship transfer")
+		writeError(w, http.StatusInternalServerError, "TRANSFER_SHIP_FAILED", "failed to ship transfer")
+		return
+	}
+
+	response := dto.APIResponse[dto.TransferResponse]{
+		Data: *transfer,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TransferHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	transferID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TRANSFER_ID", "invalid transfer id")
+		return
+	}
+
+	var req dto.TransferReceiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	transfer, err := h.service.Receive(r.Context(), transferID, userID, req)
+	if err != nil {
+		if writeTransferError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("transferId", transferID.String()).Str("userId", userID.String()).Msg("Failed to receive transfer")
+		writeError(w, http.StatusInternalServerError, "TRANSFER_RECEIVE_FAILED", "failed to receive transfer")
+		return
+	}
+
+	response := dto.APIResponse[dto.TransferResponse]{
+		Data: *transfer,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *TransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "user not found in context")
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	transferID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_TRANSFER_ID", "invalid transfer id")
+		return
+	}
+
+	var req dto.TransferCancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid request body")
+		return
+	}
+
+	transfer, err := h.service.Cancel(r.Context(), transferID, userID, req)
+	if err != nil {
+		if writeTransferError(w, err) {
+			return
+		}
+		log.Error().Err(err).Str("transferId", transferID.String()).Str("userId", userID.String()).Msg("Failed to cancel transfer")
+		writeError(w, http.StatusInternalServerError, "TRANSFER_CANCEL_FAILED", "failed to cancel transfer")
+		return
+	}
+
+	response := dto.APIResponse[dto.TransferResponse]{
+		Data: *transfer,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
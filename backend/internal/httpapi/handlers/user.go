@@ -52,6 +52,7 @@ func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 	limit := parseInt(r.URL.Query().Get("limit"), 50)
 	offset := parseInt(r.URL.Query().Get("offset"), 0)
+	includeArchived := parseBool(r.URL.Query().Get("includeArchived"), false)
 
 	if limit < 1 || limit > 1000 {
 		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
@@ -62,7 +63,7 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err := h.service.List(r.Context(), limit, offset)
+	users, err := h.service.List(r.Context(), limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load users")
 		writeError(w, http.StatusInternalServerError, "USERS_LOAD_FAILED", "failed to load users")
@@ -208,3 +209,32 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *UserHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	userID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_USER_ID", "invalid user id")
+		return
+	}
+
+	user, err := h.service.Restore(r.Context(), userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			log.Warn().Str("userId", userID.String()).Msg("User not found for restore")
+			writeError(w, http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+			return
+		}
+		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to restore user")
+		writeError(w, http.StatusInternalServerError, "USER_RESTORE_FAILED", "failed to restore user")
+		return
+	}
+
+	response := dto.APIResponse[dto.UserResponse]{
+		Data: *user,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -52,6 +52,7 @@ func (h *WarehouseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *WarehouseHandler) List(w http.ResponseWriter, r *http.Request) {
 	limit := parseInt(r.URL.Query().Get("limit"), 50)
 	offset := parseInt(r.URL.Query().Get("offset"), 0)
+	includeArchived := parseBool(r.URL.Query().Get("includeArchived"), false)
 
 	if limit < 1 || limit > 1000 {
 		writeError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 1000")
@@ -62,7 +63,7 @@ func (h *WarehouseHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	warehouses, err := h.service.List(r.Context(), limit, offset)
+	warehouses, err := h.service.List(r.Context(), limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to load warehouses")
 		writeError(w, http.StatusInternalServerError, "WAREHOUSES_LOAD_FAILED", "failed to load warehouses")
@@ -192,3 +193,32 @@ func (h *WarehouseHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *WarehouseHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	warehouseID, err := parseUUID(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_WAREHOUSE_ID", "invalid warehouse id")
+		return
+	}
+
+	warehouse, err := h.service.Restore(r.Context(), warehouseID)
+	if err != nil {
+		if err == repository.ErrWarehouseNotFound {
+			log.Warn().Str("warehouseId", warehouseID.String()).Msg("Warehouse not found for restore")
+			writeError(w, http.StatusNotFound, "WAREHOUSE_NOT_FOUND", "warehouse not found")
+			return
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to restore warehouse")
+		writeError(w, http.StatusInternalServerError, "WAREHOUSE_RESTORE_FAILED", "failed to restore warehouse")
+		return
+	}
+
+	response := dto.APIResponse[dto.WarehouseResponse]{
+		Data: *warehouse,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
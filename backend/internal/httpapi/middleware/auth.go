@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -10,7 +11,15 @@ import (
 	"warehouse-backend/internal/dto"
 )
 
-func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler {
+type UserChecker interface {
+	// ActiveRole returns the user's current role; active is false for archived or deleted users.
+	ActiveRole(ctx context.Context, userID uuid.UUID) (roleID uuid.UUID, active bool, err error)
+}
+
+// AuthMiddleware validates the bearer token and puts the user into the request context. The role is
+// taken from the database rather than from the token, so archiving a user or changing their role takes
+// effect immediately.
+func AuthMiddleware(jwtManager *auth.JWTManager, users UserChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -39,9 +48,13 @@ func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler
 				return
 			}
 
-			roleID, err := uuid.Parse(claims.RoleID)
+			roleID, active, err := users.ActiveRole(r.Context(), userID)
 			if err != nil {
-				writeAuthError(w, "invalid role ID in token")
+				writePermissionError(w, http.StatusInternalServerError, "AUTH_CHECK_FAILED", "failed to check user")
+				return
+			}
+			if !active {
+				writeAuthError(w, "user is archived or does not exist")
 				return
 			}
 
@@ -55,9 +68,9 @@ func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler
 	}
 }
 
-func RequireRole(jwtManager *auth.JWTManager, allowedRoles ...uuid.UUID) func(http.Handler) http.Handler {
+func RequireRole(jwtManager *auth.JWTManager, users UserChecker, allowedRoles ...uuid.UUID) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		authMw := AuthMiddleware(jwtManager)
+		authMw := AuthMiddleware(jwtManager, users)
 		handler := authMw(next)
 
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
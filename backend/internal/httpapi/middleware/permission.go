@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+
+	"github.com/rs/zerolog/log"
+)
+
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, roleID uuid.UUID, resource, action string) (bool, error)
+}
+
+// RequirePermission allows the request only if the caller's role has the given action on the resource.
+// Must be used after AuthMiddleware.
+func RequirePermission(checker PermissionChecker, resource, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !checkPermission(w, r, checker, resource, action) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireResourceAccess derives the action from the HTTP method (GET - read, POST - create,
+// PUT/PATCH - update, DELETE - delete) and checks it on the resource. Must be used after AuthMiddleware.
+func RequireResourceAccess(checker PermissionChecker, resource string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !checkPermission(w, r, checker, resource, auth.ActionForMethod(r.Method)) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func checkPermission(w http.ResponseWriter, r *http.Request, checker PermissionChecker, resource, action string) bool {
+	roleID := auth.GetRoleID(r.Context())
+	if roleID == uuid.Nil {
+		writeAuthError(w, "user role not found")
+		return false
+	}
+
+	allowed, err := checker.HasPermission(r.Context(), roleID, resource, action)
+	if err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Str("permission", auth.PermissionCode(resource, action)).Msg("Failed to check permission")
+		writePermissionError(w, http.StatusInternalServerError, "PERMISSION_CHECK_FAILED", "failed to check permissions")
+		return false
+	}
+
+	if !allowed {
+		log.Warn().
+			Str("userId", auth.GetUserID(r.Context()).String()).
+			Str("roleId", roleID.String()).
+			Str("permission", auth.PermissionCode(resource, action)).
+			Str("path", r.URL.Path).
+			Msg("Permission denied")
+		writePermissionError(w, http.StatusForbidden, "FORBIDDEN", "insufficient permissions")
+		return false
+	}
+
+	return true
+}
+
+func writePermissionError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(dto.APIResponse[any]{
+		Error: &dto.Error{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
@@ -36,34 +36,62 @@ func NewRouter(pg *db.Postgres, cfg config.Config) *chi.Mux {
 	orderStatusRepo := repository.NewOrderStatusRepository(pg.Pool)
 	shipmentStatusRepo := repository.NewShipmentStatusRepository(pg.Pool)
 	supplierOrderDocumentRepo := repository.NewSupplierOrderDocumentRepository(pg.Pool)
+	supplierOrderReceiptRepo := repository.NewSupplierOrderReceiptRepository(pg.Pool)
 	inventoryStatusRepo := repository.NewInventoryStatusRepository(pg.Pool)
 	inventoryRepo := repository.NewInventoryRepository(pg.Pool)
 	inventoryItemRepo := repository.NewInventoryItemRepository(pg.Pool)
+	inventoryCountRepo := repository.NewInventoryCountRepository(pg.Pool)
 	productCostRepo := repository.NewProductCostRepository(pg.Pool)
 	stockSnapshotRepo := repository.NewStockSnapshotRepository(pg.Pool)
-
-	stockService := service.NewStockService(stockRepo)
-	authService := service.NewAuthService(userRepo, roleRepo, jwtManager)
-	productService := service.NewProductService(productRepo, productImageRepo, cfg.BaseURL)
-	warehouseService := service.NewWarehouseService(warehouseRepo, warehouseTypeRepo)
-	warehouseTypeService := service.NewWarehouseTypeService(warehouseTypeRepo)
-	storeService := service.NewStoreService(storeRepo)
-	supplierOrderService := service.NewSupplierOrderService(supplierOrderRepo, orderStatusRepo)
-	supplierOrderItemService := service.NewSupplierOrderItemService(supplierOrderItemRepo, supplierOrderRepo, productRepo, warehouseRepo)
-	supplierOrderDocumentService := service.NewSupplierOrderDocumentService(supplierOrderDocumentRepo, supplierOrderRepo)
-	mpShipmentService := service.NewMpShipmentService(mpShipmentRepo, storeRepo, warehouseRepo, shipmentStatusRepo)
-	mpShipmentItemService := service.NewMpShipmentItemService(mpShipmentItemRepo, mpShipmentRepo, productRepo, warehouseRepo)
-	orderStatusService := service.NewOrderStatusService(orderStatusRepo)
-	shipmentStatusService := service.NewShipmentStatusService(shipmentStatusRepo)
-	inventoryStatusService := service.NewInventoryStatusService(inventoryStatusRepo)
-	inventoryService := service.NewInventoryService(inventoryRepo, inventoryStatusRepo, inventoryItemRepo)
-	inventoryItemService := service.NewInventoryItemService(inventoryItemRepo, inventoryRepo, productRepo, warehouseRepo, stockRepo)
-	productCostService := service.NewProductCostService(productCostRepo, productRepo)
-	stockSnapshotService := service.NewStockSnapshotService(stockSnapshotRepo, warehouseRepo, productRepo)
-	userService := service.NewUserService(userRepo, roleRepo)
-	roleService := service.NewRoleService(roleRepo)
+	stockSnapshotRunRepo := repository.NewStockSnapshotRunRepository(pg.Pool)
+	stockLevelRepo := repository.NewStockLevelRepository(pg.Pool)
+	stockLotRepo := repository.NewStockLotRepository(pg.Pool)
+	transferRepo := repository.NewTransferRepository(pg.Pool)
+	mpReturnRepo := repository.NewMpReturnRepository(pg.Pool)
+	storageLocationRepo := repository.NewStorageLocationRepository(pg.Pool)
+	locationMoveRepo := repository.NewLocationMoveRepository(pg.Pool)
+	permissionRepo := repository.NewPermissionRepository(pg.Pool)
+	stockReservationRepo := repository.NewStockReservationRepository(pg.Pool)
+	statusTransitionRepo := repository.NewStatusTransitionRepository(pg.Pool)
+	auditRepo := repository.NewAuditRepository(pg.Pool)
+
+	auditService := service.NewAuditService(auditRepo)
+	stockPolicyService := service.NewStockPolicyService(stockRepo, storageLocationRepo, cfg.NegativeStockPolicy)
+	stockService := service.NewStockService(stockRepo, stockReservationRepo, stockPolicyService)
+	authService := service.NewAuthService(userRepo, roleRepo, permissionRepo, jwtManager, auditService)
+	productService := service.NewProductService(productRepo, productImageRepo, cfg.BaseURL, auditService)
+	warehouseService := service.NewWarehouseService(warehouseRepo, warehouseTypeRepo, auditService)
+	warehouseTypeService := service.NewWarehouseTypeService(warehouseTypeRepo, auditService)
+	storeService := service.NewStoreService(storeRepo, auditService)
+	statusTransitionService := service.NewStatusTransitionService(statusTransitionRepo)
+	landedCostService := service.NewLandedCostService(supplierOrderRepo, supplierOrderItemRepo)
+	supplierOrderService := service.NewSupplierOrderService(supplierOrderRepo, orderStatusRepo, supplierOrderReceiptRepo, stockLevelRepo, landedCostService, statusTransitionService, auditService)
+	supplierOrderItemService := service.NewSupplierOrderItemService(supplierOrderItemRepo, supplierOrderRepo, orderStatusRepo, productRepo, warehouseRepo, landedCostService, auditService)
+	supplierOrderDocumentService := service.NewSupplierOrderDocumentService(supplierOrderDocumentRepo, supplierOrderRepo, auditService)
+	shipmentLogisticsService := service.NewShipmentLogisticsService(mpShipmentRepo, mpShipmentItemRepo, productRepo)
+	mpShipmentService := service.NewMpShipmentService(mpShipmentRepo, storeRepo, warehouseRepo, shipmentStatusRepo, stockReservationRepo, mpShipmentItemRepo, shipmentLogisticsService, stockPolicyService, statusTransitionService, auditService)
+	mpReturnService := service.NewMpReturnService(mpReturnRepo, storeRepo, mpShipmentRepo, shipmentStatusRepo, productRepo, warehouseRepo, warehouseTypeRepo, stockPolicyService, statusTransitionService, auditService)
+	mpShipmentItemService := service.NewMpShipmentItemService(mpShipmentItemRepo, mpShipmentRepo, productRepo, warehouseRepo, shipmentStatusRepo, stockReservationRepo, shipmentLogisticsService, stockPolicyService, auditService)
+	orderStatusService := service.NewOrderStatusService(orderStatusRepo, auditService)
+	shipmentStatusService := service.NewShipmentStatusService(shipmentStatusRepo, auditService)
+	inventoryStatusService := service.NewInventoryStatusService(inventoryStatusRepo, auditService)
+	inventoryService := service.NewInventoryService(inventoryRepo, inventoryStatusRepo, inventoryItemRepo, inventoryCountRepo, productRepo, warehouseRepo, stockRepo, stockPolicyService, statusTransitionService, auditService)
+	inventoryItemService := service.NewInventoryItemService(inventoryItemRepo, inventoryRepo, productRepo, warehouseRepo, auditService)
+	transferService := service.NewTransferService(transferRepo, productRepo, warehouseRepo, stockPolicyService, statusTransitionService, auditService)
+	storageLocationService := service.NewStorageLocationService(storageLocationRepo, warehouseRepo, supplierOrderRepo, auditService)
+	locationMoveService := service.NewLocationMoveService(locationMoveRepo, productRepo, storageLocationService, statusTransitionService, auditService)
+	productCostService := service.NewProductCostService(productCostRepo, productRepo, auditService)
+	stockLevelService := service.NewStockLevelService(stockLevelRepo, productRepo, warehouseRepo, auditService)
+	stockLotService := service.NewStockLotService(stockLotRepo, mpShipmentRepo, shipmentStatusRepo)
+	stockSnapshotService := service.NewStockSnapshotService(stockSnapshotRepo, warehouseRepo, productRepo, auditService)
+	stockSnapshotJobService := service.NewStockSnapshotJobService(stockSnapshotRepo, stockSnapshotRunRepo)
+	userService := service.NewUserService(userRepo, roleRepo, auditService)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, auditService)
+	permissionService := service.NewPermissionService(permissionRepo)
 
 	stockHandler := handlers.NewStockHandler(stockService)
+	stockLevelHandler := handlers.NewStockLevelHandler(stockLevelService)
+	stockLotHandler := handlers.NewStockLotHandler(stockLotService)
 	healthHandler := handlers.NewHealthHandler(pg)
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService)
@@ -76,62 +104,134 @@ func NewRouter(pg *db.Postgres, cfg config.Config) *chi.Mux {
 	supplierOrderItemHandler := handlers.NewSupplierOrderItemHandler(supplierOrderItemService)
 	mpShipmentHandler := handlers.NewMpShipmentHandler(mpShipmentService)
 	mpShipmentItemHandler := handlers.NewMpShipmentItemHandler(mpShipmentItemService)
+	mpReturnHandler := handlers.NewMpReturnHandler(mpReturnService)
 	orderStatusHandler := handlers.NewOrderStatusHandler(orderStatusService)
 	shipmentStatusHandler := handlers.NewShipmentStatusHandler(shipmentStatusService)
 	supplierOrderDocumentHandler := handlers.NewSupplierOrderDocumentHandler(supplierOrderDocumentService)
 	inventoryStatusHandler := handlers.NewInventoryStatusHandler(inventoryStatusService)
 	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
 	inventoryItemHandler := handlers.NewInventoryItemHandler(inventoryItemService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	storageLocationHandler := handlers.NewStorageLocationHandler(storageLocationService)
+	locationMoveHandler := handlers.NewLocationMoveHandler(locationMoveService)
 	productCostHandler := handlers.NewProductCostHandler(productCostService)
-	stockSnapshotHandler := handlers.NewStockSnapshotHandler(stockSnapshotService)
+	stockSnapshotHandler := handlers.NewStockSnapshotHandler(stockSnapshotService, stockSnapshotJobService)
+	permissionHandler := handlers.NewPermissionHandler(permissionService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 	uploadHandler := handlers.NewUploadHandler()
 
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Get("/health", healthHandler.DBHealth)
 
 		r.Post("/auth/login", authHandler.Login)
-		r.Post("/auth/register", authHandler.Register)
 
 		// File serving endpoint - public (but secured by path validation in handler)
 		r.Get("/files", uploadHandler.ServeFile)
 
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.AuthMiddleware(jwtManager))
+			r.Use(middleware.AuthMiddleware(jwtManager, authService))
 
 			r.Get("/auth/me", authHandler.GetMe)
-			r.Get("/stock/current", stockHandler.GetCurrentStock)
-			
+			// Регистрация выбирает роль нового пользователя, поэтому доступна только с правом на создание пользователей
+			r.With(middleware.RequirePermission(permissionService, auth.ResourceUsers, auth.ActionCreate)).
+				Post("/auth/register", authHandler.Register)
+			r.Route("/stock", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceStock))
+
+				r.Get("/current", stockHandler.GetCurrentStock)
+				r.Get("/as-of", stockHandler.GetStockAsOf)
+				r.Get("/movements", stockHandler.GetMovements)
+				r.Get("/availability", stockHandler.GetAvailability)
+				r.Get("/negative", stockHandler.GetNegativeStock)
+				r.Get("/reorder-suggestions", stockLevelHandler.GetReorderSuggestions)
+				r.Get("/lots", stockLotHandler.GetLots)
+
+				r.Get("/levels", stockLevelHandler.List)
+				r.Post("/levels", stockLevelHandler.Create)
+				r.Get("/levels/{id}", stockLevelHandler.GetByID)
+				r.Put("/levels/{id}", stockLevelHandler.Update)
+				r.Delete("/levels/{id}", stockLevelHandler.Delete)
+			})
+
 			// File upload endpoints (require auth)
-			r.Post("/upload", uploadHandler.Upload)
+			r.With(middleware.RequirePermission(permissionService, auth.ResourceFiles, auth.ActionCreate)).
+				Post("/upload", uploadHandler.Upload)
 
 			productImageUploadHandler := handlers.NewProductImageUploadHandler()
-			r.Post("/products/images/upload", productImageUploadHandler.UploadProductImage)
+			r.With(middleware.RequirePermission(permissionService, auth.ResourceFiles, auth.ActionCreate)).
+				Post("/products/images/upload", productImageUploadHandler.UploadProductImage)
 
 			productImageHandler := handlers.NewProductImageHandler(productImageRepo)
 
 			r.Route("/products", func(r chi.Router) {
-				r.Get("/", productHandler.List)
-				r.Post("/", productHandler.Create)
-				r.Get("/{id}", productHandler.GetByID)
-				r.Put("/{id}", productHandler.Update)
-				r.Delete("/{id}", productHandler.Delete)
-
-				// Product images endpoints
-				r.Get("/{productId}/images", productImageHandler.GetByProductID)
-				r.Delete("/{productId}/images/{imageId}", productImageHandler.Delete)
-				r.Put("/{productId}/images/{imageId}/order", productImageHandler.UpdateDisplayOrder)
-				r.Put("/{productId}/images/{imageId}/main", productImageHandler.SetAsMain)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceProducts))
+
+					r.Get("/", productHandler.List)
+					r.Post("/", productHandler.Create)
+					r.Get("/{id}", productHandler.GetByID)
+					r.Put("/{id}", productHandler.Update)
+					r.Delete("/{id}", productHandler.Delete)
+
+					// Product images endpoints
+					r.Get("/{productId}/images", productImageHandler.GetByProductID)
+					r.Delete("/{productId}/images/{imageId}", productImageHandler.Delete)
+					r.Put("/{productId}/images/{imageId}/order", productImageHandler.UpdateDisplayOrder)
+					r.Put("/{productId}/images/{imageId}/main", productImageHandler.SetAsMain)
+				})
+
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceProducts, auth.ActionUpdate)).
+					Post("/{id}/restore", productHandler.Restore)
 			})
 
 			r.Route("/warehouses", func(r chi.Router) {
-				r.Get("/", warehouseHandler.List)
-				r.Post("/", warehouseHandler.Create)
-				r.Get("/{id}", warehouseHandler.GetByID)
-				r.Put("/{id}", warehouseHandler.Update)
-				r.Delete("/{id}", warehouseHandler.Delete)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceWarehouses))
+
+					r.Get("/", warehouseHandler.List)
+					r.Post("/", warehouseHandler.Create)
+					r.Get("/{id}", warehouseHandler.GetByID)
+					r.Put("/{id}", warehouseHandler.Update)
+					r.Delete("/{id}", warehouseHandler.Delete)
+				})
+
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceWarehouses, auth.ActionUpdate)).
+					Post("/{id}/restore", warehouseHandler.Restore)
+
+				r.Route("/{id}/locations", func(r chi.Router) {
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceStorageLocations))
+
+						r.Get("/", storageLocationHandler.List)
+						r.Post("/", storageLocationHandler.Create)
+						r.Get("/stock", storageLocationHandler.GetStock)
+						r.Get("/put-away", storageLocationHandler.GetPutAwaySuggestions)
+						r.Post("/put-away", locationMoveHandler.CreatePutAway)
+						r.Get("/moves", locationMoveHandler.List)
+						r.Post("/moves", locationMoveHandler.Create)
+						r.Get("/moves/{moveId}", locationMoveHandler.GetByID)
+						r.Put("/moves/{moveId}", locationMoveHandler.Update)
+						r.Delete("/moves/{moveId}", locationMoveHandler.Delete)
+						r.Get("/moves/{moveId}/history", locationMoveHandler.GetHistory)
+						r.Get("/{locationId}", storageLocationHandler.GetByID)
+						r.Put("/{locationId}", storageLocationHandler.Update)
+						r.Delete("/{locationId}", storageLocationHandler.Delete)
+					})
+
+					// Восстановление ячейки, проведение и отмена перемещения меняют существующие записи
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequirePermission(permissionService, auth.ResourceStorageLocations, auth.ActionUpdate))
+
+						r.Post("/{locationId}/restore", storageLocationHandler.Restore)
+						r.Post("/moves/{moveId}/post", locationMoveHandler.Post)
+						r.Post("/moves/{moveId}/cancel", locationMoveHandler.Cancel)
+					})
+				})
 			})
 
 			r.Route("/warehouse-types", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceWarehouseTypes))
+
 				r.Get("/", warehouseTypeHandler.List)
 				r.Post("/", warehouseTypeHandler.Create)
 				r.Get("/{id}", warehouseTypeHandler.GetByID)
@@ -140,30 +240,56 @@ func NewRouter(pg *db.Postgres, cfg config.Config) *chi.Mux {
 			})
 
 			r.Route("/stores", func(r chi.Router) {
-				r.Get("/", storeHandler.List)
-				r.Post("/", storeHandler.Create)
-				r.Get("/{id}", storeHandler.GetByID)
-				r.Put("/{id}", storeHandler.Update)
-				r.Delete("/{id}", storeHandler.Delete)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceStores))
+
+					r.Get("/", storeHandler.List)
+					r.Post("/", storeHandler.Create)
+					r.Get("/{id}", storeHandler.GetByID)
+					r.Put("/{id}", storeHandler.Update)
+					r.Delete("/{id}", storeHandler.Delete)
+				})
+
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceStores, auth.ActionUpdate)).
+					Post("/{id}/restore", storeHandler.Restore)
 			})
 
 			r.Route("/supplier-orders", func(r chi.Router) {
-				r.Get("/", supplierOrderHandler.List)
-				r.Post("/", supplierOrderHandler.Create)
-				r.Get("/{id}", supplierOrderHandler.GetByID)
-				r.Put("/{id}", supplierOrderHandler.Update)
-				r.Delete("/{id}", supplierOrderHandler.Delete)
-
-				r.Route("/{orderId}/items", func(r chi.Router) {
-					r.Get("/", supplierOrderItemHandler.GetByOrderID)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceSupplierOrders))
+
+					r.Get("/", supplierOrderHandler.List)
+					r.Post("/", supplierOrderHandler.Create)
+					r.Get("/{id}", supplierOrderHandler.GetByID)
+					r.Put("/{id}", supplierOrderHandler.Update)
+					r.Delete("/{id}", supplierOrderHandler.Delete)
+					r.Get("/{id}/receipts", supplierOrderHandler.GetReceipts)
+					r.Get("/{id}/tree", supplierOrderHandler.GetTree)
+					r.Get("/{id}/history", supplierOrderHandler.GetHistory)
+					r.Post("/{id}/split", supplierOrderHandler.SplitRemainder)
+					r.Post("/reorder", supplierOrderHandler.CreateFromReorderSuggestions)
+
+					r.Route("/{orderId}/items", func(r chi.Router) {
+						r.Get("/", supplierOrderItemHandler.GetByOrderID)
+					})
+
+					r.Route("/{orderId}/documents", func(r chi.Router) {
+						r.Get("/", supplierOrderDocumentHandler.GetByOrderID)
+					})
 				})
 
-				r.Route("/{orderId}/documents", func(r chi.Router) {
-					r.Get("/", supplierOrderDocumentHandler.GetByOrderID)
+				// Приемка и смена статуса меняют существующий заказ, поэтому требуют update, а не create
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(permissionService, auth.ResourceSupplierOrders, auth.ActionUpdate))
+
+					r.Post("/{id}/receive", supplierOrderHandler.Receive)
+					r.Post("/{id}/transition", supplierOrderHandler.Transition)
 				})
 			})
 
 			r.Route("/supplier-order-items", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceSupplierOrders))
+
 				r.Get("/{id}", supplierOrderItemHandler.GetByID)
 				r.Post("/", supplierOrderItemHandler.Create)
 				r.Put("/{id}", supplierOrderItemHandler.Update)
@@ -171,41 +297,95 @@ func NewRouter(pg *db.Postgres, cfg config.Config) *chi.Mux {
 			})
 
 			r.Route("/mp-shipments", func(r chi.Router) {
-				r.Get("/", mpShipmentHandler.List)
-				r.Post("/", mpShipmentHandler.Create)
-				r.Get("/{id}", mpShipmentHandler.GetByID)
-				r.Put("/{id}", mpShipmentHandler.Update)
-				r.Delete("/{id}", mpShipmentHandler.Delete)
-
-				r.Route("/{shipmentId}/items", func(r chi.Router) {
-					r.Get("/", mpShipmentItemHandler.GetByShipmentID)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceMpShipments))
+
+					r.Get("/", mpShipmentHandler.List)
+					r.Post("/", mpShipmentHandler.Create)
+					r.Get("/costs", stockLotHandler.GetShipmentCosts)
+					r.Get("/{id}", mpShipmentHandler.GetByID)
+					r.Put("/{id}", mpShipmentHandler.Update)
+					r.Delete("/{id}", mpShipmentHandler.Delete)
+					r.Get("/{id}/history", mpShipmentHandler.GetHistory)
+					r.Get("/{id}/cost", stockLotHandler.GetShipmentCost)
+
+					r.Route("/{shipmentId}/items", func(r chi.Router) {
+						r.Get("/", mpShipmentItemHandler.GetByShipmentID)
+					})
+				})
+
+				// Смена статуса и разбор расхождения меняют существующую отгрузку
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(permissionService, auth.ResourceMpShipments, auth.ActionUpdate))
+
+					r.Post("/{id}/transition", mpShipmentHandler.Transition)
+					r.Post("/{id}/resolve-discrepancy", mpShipmentHandler.ResolveDiscrepancy)
 				})
 			})
 
 			r.Route("/mp-shipment-items", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceMpShipments))
+
 				r.Get("/{id}", mpShipmentItemHandler.GetByID)
 				r.Post("/", mpShipmentItemHandler.Create)
 				r.Put("/{id}", mpShipmentItemHandler.Update)
 				r.Delete("/{id}", mpShipmentItemHandler.Delete)
 			})
 
+			r.Route("/mp-returns", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceMpReturns))
+
+					r.Get("/", mpReturnHandler.List)
+					r.Post("/", mpReturnHandler.Create)
+					r.Get("/{id}", mpReturnHandler.GetByID)
+					r.Put("/{id}", mpReturnHandler.Update)
+					r.Delete("/{id}", mpReturnHandler.Delete)
+					r.Get("/{id}/history", mpReturnHandler.GetHistory)
+				})
+
+				// Проведение и отмена меняют существующий возврат
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(permissionService, auth.ResourceMpReturns, auth.ActionUpdate))
+
+					r.Post("/{id}/post", mpReturnHandler.Post)
+					r.Post("/{id}/cancel", mpReturnHandler.Cancel)
+				})
+			})
+
 			r.Route("/order-statuses", func(r chi.Router) {
-				r.Get("/", orderStatusHandler.List)
-				r.Post("/", orderStatusHandler.Create)
-				r.Get("/{id}", orderStatusHandler.GetByID)
-				r.Put("/{id}", orderStatusHandler.Update)
-				r.Delete("/{id}", orderStatusHandler.Delete)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceOrderStatuses))
+
+					r.Get("/", orderStatusHandler.List)
+					r.Post("/", orderStatusHandler.Create)
+					r.Get("/{id}", orderStatusHandler.GetByID)
+					r.Put("/{id}", orderStatusHandler.Update)
+					r.Delete("/{id}", orderStatusHandler.Delete)
+				})
+
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceOrderStatuses, auth.ActionUpdate)).
+					Post("/{id}/restore", orderStatusHandler.Restore)
 			})
 
 			r.Route("/shipment-statuses", func(r chi.Router) {
-				r.Get("/", shipmentStatusHandler.List)
-				r.Post("/", shipmentStatusHandler.Create)
-				r.Get("/{id}", shipmentStatusHandler.GetByID)
-				r.Put("/{id}", shipmentStatusHandler.Update)
-				r.Delete("/{id}", shipmentStatusHandler.Delete)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceShipmentStatuses))
+
+					r.Get("/", shipmentStatusHandler.List)
+					r.Post("/", shipmentStatusHandler.Create)
+					r.Get("/{id}", shipmentStatusHandler.GetByID)
+					r.Put("/{id}", shipmentStatusHandler.Update)
+					r.Delete("/{id}", shipmentStatusHandler.Delete)
+				})
+
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceShipmentStatuses, auth.ActionUpdate)).
+					Post("/{id}/restore", shipmentStatusHandler.Restore)
 			})
 
 			r.Route("/supplier-order-documents", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceSupplierOrders))
+
 				r.Get("/{id}", supplierOrderDocumentHandler.GetByID)
 				r.Post("/", supplierOrderDocumentHandler.Create)
 				r.Put("/{id}", supplierOrderDocumentHandler.Update)
@@ -213,34 +393,83 @@ func NewRouter(pg *db.Postgres, cfg config.Config) *chi.Mux {
 			})
 
 			r.Route("/inventory-statuses", func(r chi.Router) {
-				r.Get("/", inventoryStatusHandler.List)
-				r.Post("/", inventoryStatusHandler.Create)
-				r.Get("/{id}", inventoryStatusHandler.GetByID)
-				r.Put("/{id}", inventoryStatusHandler.Update)
-				r.Delete("/{id}", inventoryStatusHandler.Delete)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceInventoryStatuses))
+
+					r.Get("/", inventoryStatusHandler.List)
+					r.Post("/", inventoryStatusHandler.Create)
+					r.Get("/{id}", inventoryStatusHandler.GetByID)
+					r.Put("/{id}", inventoryStatusHandler.Update)
+					r.Delete("/{id}", inventoryStatusHandler.Delete)
+				})
+
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceInventoryStatuses, auth.ActionUpdate)).
+					Post("/{id}/restore", inventoryStatusHandler.Restore)
 			})
 
 			r.Route("/inventories", func(r chi.Router) {
-				r.Get("/", inventoryHandler.List)
-				r.Post("/", inventoryHandler.Create)
-				r.Get("/{id}", inventoryHandler.GetByID)
-				r.Put("/{id}", inventoryHandler.Update)
-				r.Delete("/{id}", inventoryHandler.Delete)
-
-				r.Route("/{inventoryId}/items", func(r chi.Router) {
-					r.Get("/", inventoryItemHandler.GetByInventoryID)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceInventories))
+
+					r.Get("/", inventoryHandler.List)
+					r.Post("/", inventoryHandler.Create)
+					r.Get("/{id}", inventoryHandler.GetByID)
+					r.Put("/{id}", inventoryHandler.Update)
+					r.Delete("/{id}", inventoryHandler.Delete)
+					r.Get("/{id}/history", inventoryHandler.GetHistory)
+					r.Get("/{id}/count", inventoryHandler.GetCount)
+
+					r.Route("/{inventoryId}/items", func(r chi.Router) {
+						r.Get("/", inventoryItemHandler.GetByInventoryID)
+					})
+				})
+
+				// Пересчет и смена статуса меняют существующую инвентаризацию
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(permissionService, auth.ResourceInventories, auth.ActionUpdate))
+
+					r.Post("/{id}/transition", inventoryHandler.Transition)
+					r.Post("/{id}/count", inventoryHandler.StartCount)
+					r.Post("/{id}/count/lines", inventoryHandler.RecordCount)
 				})
 			})
 
 			r.Route("/inventory-items", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceInventories))
+
 				r.Get("/{id}", inventoryItemHandler.GetByID)
 				r.Post("/", inventoryItemHandler.Create)
 				r.Put("/{id}", inventoryItemHandler.Update)
 				r.Delete("/{id}", inventoryItemHandler.Delete)
 			})
 
+			r.Route("/transfers", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceTransfers))
+
+					r.Get("/", transferHandler.List)
+					r.Post("/", transferHandler.Create)
+					r.Get("/{id}", transferHandler.GetByID)
+					r.Put("/{id}", transferHandler.Update)
+					r.Delete("/{id}", transferHandler.Delete)
+					r.Get("/{id}/history", transferHandler.GetHistory)
+				})
+
+				// Отгрузка, приемка и отмена меняют существующее перемещение
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(permissionService, auth.ResourceTransfers, auth.ActionUpdate))
+
+					r.Post("/{id}/ship", transferHandler.Ship)
+					r.Post("/{id}/receive", transferHandler.Receive)
+					r.Post("/{id}/cancel", transferHandler.Cancel)
+				})
+			})
+
 			r.Route("/product-costs", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceProductCosts))
+
 				r.Get("/", productCostHandler.List)
+				r.Get("/coverage", productCostHandler.GetCoverage)
 				r.Post("/", productCostHandler.Create)
 				r.Get("/{id}", productCostHandler.GetByID)
 				r.Put("/{id}", productCostHandler.Update)
@@ -248,27 +477,62 @@ func NewRouter(pg *db.Postgres, cfg config.Config) *chi.Mux {
 			})
 
 			r.Route("/stock-snapshots", func(r chi.Router) {
-				r.Get("/", stockSnapshotHandler.List)
-				r.Post("/", stockSnapshotHandler.Create)
-				r.Get("/{id}", stockSnapshotHandler.GetByID)
-				r.Put("/{id}", stockSnapshotHandler.Update)
-				r.Delete("/{id}", stockSnapshotHandler.Delete)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceStockSnapshots))
+
+					r.Get("/", stockSnapshotHandler.List)
+					r.Post("/", stockSnapshotHandler.Create)
+					r.Post("/generate", stockSnapshotHandler.Generate)
+					r.Get("/runs", stockSnapshotHandler.ListRuns)
+					r.Get("/verify", stockSnapshotHandler.Verify)
+					r.Get("/{id}", stockSnapshotHandler.GetByID)
+					r.Put("/{id}", stockSnapshotHandler.Update)
+					r.Delete("/{id}", stockSnapshotHandler.Delete)
+				})
+
+				// Исправление расхождений меняет существующие снапшоты
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceStockSnapshots, auth.ActionUpdate)).
+					Post("/verify/repair", stockSnapshotHandler.Repair)
 			})
 
 			r.Route("/users", func(r chi.Router) {
-				r.Get("/", userHandler.List)
-				r.Post("/", userHandler.Create)
-				r.Get("/{id}", userHandler.GetByID)
-				r.Put("/{id}", userHandler.Update)
-				r.Delete("/{id}", userHandler.Delete)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceUsers))
+
+					r.Get("/", userHandler.List)
+					r.Post("/", userHandler.Create)
+					r.Get("/{id}", userHandler.GetByID)
+					r.Put("/{id}", userHandler.Update)
+					r.Delete("/{id}", userHandler.Delete)
+				})
+
+				r.With(middleware.RequirePermission(permissionService, auth.ResourceUsers, auth.ActionUpdate)).
+					Post("/{id}/restore", userHandler.Restore)
 			})
 
 			r.Route("/roles", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceRoles))
+
 				r.Get("/", roleHandler.List)
 				r.Post("/", roleHandler.Create)
 				r.Get("/{id}", roleHandler.GetByID)
 				r.Put("/{id}", roleHandler.Update)
 				r.Delete("/{id}", roleHandler.Delete)
+
+				r.Get("/{id}/permissions", roleHandler.GetPermissions)
+				r.Put("/{id}/permissions", roleHandler.UpdatePermissions)
+			})
+
+			r.Route("/permissions", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceRoles))
+
+				r.Get("/", permissionHandler.List)
+			})
+
+			r.Route("/audit", func(r chi.Router) {
+				r.Use(middleware.RequireResourceAccess(permissionService, auth.ResourceAudit))
+
+				r.Get("/", auditHandler.List)
 			})
 		})
 	})
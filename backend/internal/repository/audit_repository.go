@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Действия, фиксируемые в журнале аудита
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Типы сущностей в журнале аудита
+const (
+	AuditEntityProduct               = "product"
+	AuditEntityWarehouse             = "warehouse"
+	AuditEntityWarehouseType         = "warehouse_type"
+	AuditEntityStore                 = "store"
+	AuditEntitySupplierOrder         = "supplier_order"
+	AuditEntitySupplierOrderItem     = "supplier_order_item"
+	AuditEntitySupplierOrderDocument = "supplier_order_document"
+	AuditEntityMpShipment            = "mp_shipment"
+	AuditEntityMpShipmentItem        = "mp_shipment_item"
+	AuditEntityOrderStatus           = "order_status"
+	AuditEntityShipmentStatus        = "shipment_status"
+	AuditEntityInventoryStatus       = "inventory_status"
+	AuditEntityInventory             = "inventory"
+	AuditEntityInventoryItem         = "inventory_item"
+	AuditEntityInventoryCount        = "inventory_count"
+	AuditEntityTransfer              = "transfer"
+	AuditEntityMpReturn              = "mp_return"
+	AuditEntityStorageLocation       = "storage_location"
+	AuditEntityLocationMove          = "location_move"
+	AuditEntityProductCost           = "product_cost"
+	AuditEntityStockSnapshot         = "stock_snapshot"
+	AuditEntityStockLevel            = "stock_level"
+	AuditEntityUser                  = "user"
+	AuditEntityRole                  = "role"
+)
+
+type AuditEntry struct {
+	AuditID    uuid.UUID
+	EntityType string
+	EntityID   uuid.UUID
+	Action     string
+	BeforeData []byte
+	AfterData  []byte
+	Changes    []byte
+	UserID     *uuid.UUID
+	UserEmail  *string
+	CreatedAt  time.Time
+}
+
+type AuditFilter struct {
+	EntityType *string
+	EntityID   *uuid.UUID
+	UserID     *uuid.UUID
+	Action     *string
+	DateFrom   *time.Time
+	DateTo     *time.Time
+}
+
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// Record stores one audit entry. before, after and changes are JSON documents; nil is stored as NULL.
+func (r *AuditRepository) Record(ctx context.Context, entityType string, entityID uuid.UUID, action string, before, after, changes []byte, userID *uuid.UUID) error {
+	query := `
+		INSERT INTO audit_log (entity_type, entity_id, action, before_data, after_data, changes, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, query, entityType, entityID, action, before, after, changes, userID)
+	return err
+}
+
+// List returns audit entries matching the filter, newest first. DateTo includes the whole day.
+func (r *AuditRepository) List(ctx context.Context, filter AuditFilter, limit, offset int) ([]AuditEntry, error) {
+	query := `
+		SELECT a.audit_id, a.entity_type, a.entity_id, a.action,
+		       a.before_data, a.after_data, a.changes,
+		       a.user_id, u.email, a.created_at
+		FROM audit_log a
+		LEFT JOIN users u ON u.user_id = a.user_id
+	`
+	args := []any{}
+	argPos := 1
+	conditions := []string{}
+
+	if filter.EntityType != nil {
+		conditions = append(conditions, fmt.Sprintf("a.entity_type = $%d", argPos))
+		args = append(args, *filter.EntityType)
+		argPos++
+	}
+	if filter.EntityID != nil {
+		conditions = append(conditions, fmt.Sprintf("a.entity_id = $%d", argPos))
+		args = append(args, *filter.EntityID)
+		argPos++
+	}
+	if filter.UserID != nil {
+		conditions = append(conditions, fmt.Sprintf("a.user_id = $%d", argPos))
+		args = append(args, *filter.UserID)
+		argPos++
+	}
+	if filter.Action != nil {
+		conditions = append(conditions, fmt.Sprintf("a.action = $%d", argPos))
+		args = append(args, *filter.Action)
+		argPos++
+	}
+	if filter.DateFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("a.created_at >= $%d", argPos))
+		args = append(args, *filter.DateFrom)
+		argPos++
+	}
+	if filter.DateTo != nil {
+		conditions = append(conditions, fmt.Sprintf("a.created_at < $%d", argPos))
+		args = append(args, filter.DateTo.AddDate(0, 0, 1))
+		argPos++
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += fmt.Sprintf(` ORDER BY a.created_at DESC, a.audit_id DESC LIMIT $%d OFFSET $%d`, argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(
+			&entry.AuditID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Action,
+			&entry.BeforeData,
+			&entry.AfterData,
+			&entry.Changes,
+			&entry.UserID,
+			&entry.UserEmail,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
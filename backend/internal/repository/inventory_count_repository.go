@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrInventoryCountNotFound = errors.New("inventory count not found")
+	ErrInventoryCountExists   = errors.New("inventory count already started")
+)
+
+// InventoryCount - пересчет склада в рамках инвентаризации
+type InventoryCount struct {
+	InventoryID uuid.UUID
+	WarehouseID uuid.UUID
+	StartedBy   *uuid.UUID
+	StartedAt   time.Time
+	AppliedAt   *time.Time
+}
+
+// InventoryCountLine - ожидаемое (зафиксированное при начале пересчета) и фактическое количество товара
+type InventoryCountLine struct {
+	CountLineID uuid.UUID
+	InventoryID uuid.UUID
+	ProductID   uuid.UUID
+	Article     string
+	Barcode     string
+	ExpectedQty int
+	CountedQty  *int
+	CountedBy   *uuid.UUID
+	CountedAt   *time.Time
+}
+
+type InventoryCountRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInventoryCountRepository(pool *pgxpool.Pool) *InventoryCountRepository {
+	return &InventoryCountRepository{pool: pool}
+}
+
+func (r *InventoryCountRepository) GetByInventoryID(ctx context.Context, inventoryID uuid.UUID) (*InventoryCount, error) {
+	query := `
+		SELECT inventory_id, warehouse_id, started_by, started_at, applied_at
+		FROM inventory_counts
+		WHERE inventory_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var count InventoryCount
+	err := r.pool.QueryRow(ctx, query, inventoryID).Scan(
+		&count.InventoryID,
+		&count.WarehouseID,
+		&count.StartedBy,
+		&count.StartedAt,
+		&count.AppliedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInventoryCountNotFound
+		}
+		return nil, err
+	}
+
+	return &count, nil
+}
+
+// Start creates the count of the warehouse and freezes its current stock from vw_current_stock as the
+// expected quantities, in one transaction.
+func (r *InventoryCountRepository) Start(ctx context.Context, inventoryID, warehouseID, userID uuid.UUID) (*InventoryCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var count InventoryCount
+	err = tx.QueryRow(ctx, `
+		INSERT INTO inventory_counts (inventory_id, warehouse_id, started_by)
+		VALUES ($1, $2, $3)
+		RETURNING inventory_id, warehouse_id, started_by, started_at, applied_at
+	`, inventoryID, warehouseID, userID).Scan(
+		&count.InventoryID,
+		&count.WarehouseID,
+		&count.StartedBy,
+		&count.StartedAt,
+		&count.AppliedAt,
+	)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "duplicate key") ||
+			strings.Contains(errMsg, "unique constraint") ||
+			strings.Contains(errMsg, "inventory_counts_pkey") {
+			return nil, ErrInventoryCountExists
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO inventory_count_lines (inventory_id, product_id, expected_qty)
+		SELECT $1, product_id, SUM(current_quantity)::int
+		FROM vw_current_stock
+		WHERE warehouse_id = $2
+		GROUP BY product_id
+	`, inventoryID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &count, nil
+}
+
+const inventoryCountLineColumns = `
+	l.count_line_id, l.inventory_id, l.product_id, p.article, p.barcode,
+	l.expected_qty, l.counted_qty, l.counted_by, l.counted_at
+`
+
+func scanInventoryCountLine(row pgx.Row, line *InventoryCountLine) error {
+	return row.Scan(
+		&line.CountLineID,
+		&line.InventoryID,
+		&line.ProductID,
+		&line.Article,
+		&line.Barcode,
+		&line.ExpectedQty,
+		&line.CountedQty,
+		&line.CountedBy,
+		&line.CountedAt,
+	)
+}
+
+func (r *InventoryCountRepository) GetLines(ctx context.Context, inventoryID uuid.UUID) ([]InventoryCountLine, error) {
+	query := `
+		SELECT ` + inventoryCountLineColumns + `
+		FROM inventory_count_lines l
+		JOIN products p ON p.product_id = l.product_id
+		WHERE l.inventory_id = $1
+		ORDER BY p.article
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []InventoryCountLine
+	for rows.Next() {
+		var line InventoryCountLine
+		if err := scanInventoryCountLine(rows, &line); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// RecordCount adds quantity to the counted quantity of the product, or replaces it when replace is set.
+// A product that was not expected on the warehouse gets a line with zero expected quantity.
+// Returns ErrInvalidQuantity if the counted quantity would become negative.
+func (r *InventoryCountRepository) RecordCount(ctx context.Context, inventoryID, productID uuid.UUID, quantity int, replace bool, userID uuid.UUID) (*InventoryCountLine, error) {
+	query := `
+		WITH line AS (
+			INSERT INTO inventory_count_lines AS l (inventory_id, product_id, counted_qty, counted_by, counted_at)
+			VALUES ($1, $2, $3, $5, CURRENT_TIMESTAMP)
+			ON CONFLICT (inventory_id, product_id) DO UPDATE
+			SET counted_qty = CASE WHEN $4 THEN EXCLUDED.counted_qty
+			                       ELSE COALESCE(l.counted_qty, 0) + EXCLUDED.counted_qty END,
+			    counted_by = EXCLUDED.counted_by,
+			    counted_at = EXCLUDED.counted_at
+			RETURNING l.*
+		)
+		SELECT ` + inventoryCountLineColumns + `
+		FROM line l
+		JOIN products p ON p.product_id = l.product_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var line InventoryCountLine
+	err := scanInventoryCountLine(r.pool.QueryRow(ctx, query, inventoryID, productID, quantity, replace, userID), &line)
+	if err != nil {
+		if strings.Contains(err.Error(), "check constraint") {
+			return nil, ErrInvalidQuantity
+		}
+		return nil, err
+	}
+
+	return &line, nil
+}
+
+// applyInventoryCount creates inventory_items for every line whose counted quantity differs from the expected
+// one and marks the count as applied. Lines that were never counted are treated as counted zero. An inventory
+// without adjustment date gets the current date, otherwise the items would not affect stock. Returns the
+// number of created items; an inventory without count or with an already applied count creates nothing.
+func applyInventoryCount(ctx context.Context, tx pgx.Tx, inventoryID uuid.UUID) (int64, error) {
+	var warehouseID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT warehouse_id
+		FROM inventory_counts
+		WHERE inventory_id = $1 AND applied_at IS NULL
+		FOR UPDATE
+	`, inventoryID).Scan(&warehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO inventory_items (inventory_id, product_id, warehouse_id, receipt_qty, write_off_qty, reason)
+		SELECT l.inventory_id, l.product_id, $2,
+		       GREATEST(COALESCE(l.counted_qty, 0) - l.expected_qty, 0),
+		       GREATEST(l.expected_qty - COALESCE(l.counted_qty, 0), 0),
+		       'Пересчет: ожидалось ' || l.expected_qty || ', фактически ' || COALESCE(l.counted_qty, 0)
+		FROM inventory_count_lines l
+		WHERE l.inventory_id = $1 AND COALESCE(l.counted_qty, 0) <> l.expected_qty
+	`, inventoryID, warehouseID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE inventory_counts
+		SET applied_at = CURRENT_TIMESTAMP
+		WHERE inventory_id = $1
+	`, inventoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE inventories
+		SET adjustment_date = COALESCE(adjustment_date, CURRENT_DATE)
+		WHERE inventory_id = $1
+	`, inventoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
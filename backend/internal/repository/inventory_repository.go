@@ -15,6 +15,7 @@ import (
 var (
 	ErrInventoryNotFound = errors.New("inventory not found")
 	ErrInventoryExists   = errors.New("inventory already exists")
+	ErrInventoryClosed   = errors.New("inventory is completed or cancelled")
 )
 
 type Inventory struct {
@@ -191,22 +192,153 @@ func (r *InventoryRepository) Update(ctx context.Context, inventoryID uuid.UUID,
 	return &inventory, nil
 }
 
+// Delete removes the inventory together with its items. Snapshot changes of a completed inventory are
+// reverted in the same transaction.
 func (r *InventoryRepository) Delete(ctx context.Context, inventoryID uuid.UUID) error {
-	query := `
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := revertInventorySnapshots(ctx, tx, inventoryID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
 		DELETE FROM inventories
 		WHERE inventory_id = $1
+	`, inventoryID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrInventoryNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// IsClosed reports whether the inventory is completed or cancelled, i.e. its items may no longer change.
+func (r *InventoryRepository) IsClosed(ctx context.Context, inventoryID uuid.UUID) (bool, error) {
+	query := `
+		SELECT COALESCE(s.name IN ($2, $3), FALSE)
+		FROM inventories i
+		LEFT JOIN inventory_statuses s ON s.inventory_status_id = i.status_id
+		WHERE i.inventory_id = $1
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	result, err := r.pool.Exec(ctx, query, inventoryID)
+	var closed bool
+	err := r.pool.QueryRow(ctx, query, inventoryID, InventoryStatusCompleted, InventoryStatusCancelled).Scan(&closed)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrInventoryNotFound
+		}
+		return false, err
+	}
+
+	return closed, nil
+}
+
+// UpdateStatus moves the inventory from fromStatusID to toStatusID. Returns ErrStatusConflict if the inventory
+// no longer has fromStatusID, i.e. its status was changed by a concurrent request.
+func (r *InventoryRepository) UpdateStatus(ctx context.Context, inventoryID uuid.UUID, fromStatusID *uuid.UUID, toStatusID, userID uuid.UUID) error {
+	query := `
+		UPDATE inventories
+		SET status_id = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE inventory_id = $3 AND status_id IS NOT DISTINCT FROM $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, toStatusID, userID, inventoryID, fromStatusID)
 	if err != nil {
 		return err
 	}
 
 	if result.RowsAffected() == 0 {
-		return ErrInventoryNotFound
+		return ErrStatusConflict
+	}
+
+	return nil
+}
+
+// Complete moves the inventory to toStatusID ("Завершена"), turns its count into items and writes its adjustments
+// into stock snapshots, all in one transaction, so a failed stock update leaves the inventory in its previous
+// status. Returns the number of items created from the count.
+func (r *InventoryRepository) Complete(ctx context.Context, inventoryID uuid.UUID, fromStatusID *uuid.UUID, toStatusID, userID uuid.UUID) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateInventoryStatus(ctx, tx, inventoryID, fromStatusID, toStatusID, userID); err != nil {
+		return 0, err
+	}
+
+	created, err := applyInventoryCount(ctx, tx, inventoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := applyInventorySnapshots(ctx, tx, inventoryID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+// Cancel moves the inventory to toStatusID ("Отменена") and takes its adjustments back out of stock snapshots
+// in one transaction.
+func (r *InventoryRepository) Cancel(ctx context.Context, inventoryID uuid.UUID, fromStatusID *uuid.UUID, toStatusID, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateInventoryStatus(ctx, tx, inventoryID, fromStatusID, toStatusID, userID); err != nil {
+		return err
+	}
+
+	if err := revertInventorySnapshots(ctx, tx, inventoryID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func updateInventoryStatus(ctx context.Context, tx pgx.Tx, inventoryID uuid.UUID, fromStatusID *uuid.UUID, toStatusID, userID uuid.UUID) error {
+	result, err := tx.Exec(ctx, `
+		UPDATE inventories
+		SET status_id = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE inventory_id = $3 AND status_id IS NOT DISTINCT FROM $4
+	`, toStatusID, userID, inventoryID, fromStatusID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
 	}
 
 	return nil
@@ -15,11 +15,22 @@ import (
 var (
 	ErrInventoryStatusNotFound = errors.New("inventory status not found")
 	ErrInventoryStatusExists   = errors.New("inventory status already exists")
+	ErrInventoryStatusArchived = errors.New("inventory status is archived")
+)
+
+// Названия статусов инвентаризаций, на которые опирается бизнес-логика
+const (
+	InventoryStatusDraft      = "Черновик"
+	InventoryStatusInProgress = "В процессе"
+	InventoryStatusCompleted  = "Завершена"
+	InventoryStatusCancelled  = "Отменена"
 )
 
 type InventoryStatus struct {
 	InventoryStatusID uuid.UUID
 	Name              string
+	IsArchived        bool
+	ArchivedAt        *time.Time
 }
 
 type InventoryStatusRepository struct {
@@ -32,7 +43,7 @@ func NewInventoryStatusRepository(pool *pgxpool.Pool) *InventoryStatusRepository
 
 func (r *InventoryStatusRepository) GetByID(ctx context.Context, statusID uuid.UUID) (*InventoryStatus, error) {
 	query := `
-		SELECT inventory_status_id, name
+		SELECT inventory_status_id, name, is_archived, archived_at
 		FROM inventory_statuses
 		WHERE inventory_status_id = $1
 	`
@@ -44,6 +55,8 @@ func (r *InventoryStatusRepository) GetByID(ctx context.Context, statusID uuid.U
 	err := r.pool.QueryRow(ctx, query, statusID).Scan(
 		&status.InventoryStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -56,10 +69,11 @@ func (r *InventoryStatusRepository) GetByID(ctx context.Context, statusID uuid.U
 	return &status, nil
 }
 
-func (r *InventoryStatusRepository) List(ctx context.Context, limit, offset int) ([]InventoryStatus, error) {
+func (r *InventoryStatusRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]InventoryStatus, error) {
 	query := fmt.Sprintf(`
-		SELECT inventory_status_id, name
+		SELECT inventory_status_id, name, is_archived, archived_at
 		FROM inventory_statuses
+		WHERE $3 OR NOT is_archived
 		ORDER BY inventory_status_id
 		LIMIT $1 OFFSET $2
 	`)
@@ -67,7 +81,7 @@ func (r *InventoryStatusRepository) List(ctx context.Context, limit, offset int)
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +93,8 @@ func (r *InventoryStatusRepository) List(ctx context.Context, limit, offset int)
 		if err := rows.Scan(
 			&status.InventoryStatusID,
 			&status.Name,
+			&status.IsArchived,
+			&status.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -96,7 +112,7 @@ func (r *InventoryStatusRepository) Create(ctx context.Context, name string) (*I
 	query := `
 		INSERT INTO inventory_statuses (name)
 		VALUES ($1)
-		RETURNING inventory_status_id, name
+		RETURNING inventory_status_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -106,6 +122,8 @@ func (r *InventoryStatusRepository) Create(ctx context.Context, name string) (*I
 	err := r.pool.QueryRow(ctx, query, name).Scan(
 		&status.InventoryStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -126,7 +144,7 @@ func (r *InventoryStatusRepository) Update(ctx context.Context, statusID uuid.UU
 		UPDATE inventory_statuses
 		SET name = $1
 		WHERE inventory_status_id = $2
-		RETURNING inventory_status_id, name
+		RETURNING inventory_status_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -136,6 +154,8 @@ func (r *InventoryStatusRepository) Update(ctx context.Context, statusID uuid.UU
 	err := r.pool.QueryRow(ctx, query, name, statusID).Scan(
 		&status.InventoryStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -153,9 +173,34 @@ func (r *InventoryStatusRepository) Update(ctx context.Context, statusID uuid.UU
 	return &status, nil
 }
 
-func (r *InventoryStatusRepository) Delete(ctx context.Context, statusID uuid.UUID) error {
+// Archive hides the status from lists and new documents instead of deleting it, so existing documents and
+// stock history keep their references. Archiving an archived status keeps the original archived_at.
+func (r *InventoryStatusRepository) Archive(ctx context.Context, statusID uuid.UUID) error {
 	query := `
-		DELETE FROM inventory_statuses
+		UPDATE inventory_statuses
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE inventory_status_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, statusID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrInventoryStatusNotFound
+	}
+
+	return nil
+}
+
+func (r *InventoryStatusRepository) Restore(ctx context.Context, statusID uuid.UUID) error {
+	query := `
+		UPDATE inventory_statuses
+		SET is_archived = FALSE, archived_at = NULL
 		WHERE inventory_status_id = $1
 	`
 
@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrLocationMoveNotFound      = errors.New("location move not found")
+	ErrLocationMoveExists        = errors.New("location move already exists")
+	ErrLocationMoveNotEditable   = errors.New("location move can only be changed in draft status")
+	ErrEmptyLocationMove         = errors.New("location move has no items")
+	ErrInvalidMoveLocations      = errors.New("move item needs distinct source and destination locations")
+	ErrNotBinLocation            = errors.New("stock can only be placed in bins")
+	ErrInsufficientLocationStock = errors.New("location does not hold enough stock")
+	ErrNoPutAwaySuggestions      = errors.New("nothing to put away")
+	ErrStockNotPicked            = errors.New("outgoing stock is placed in bins and has to be picked first")
+)
+
+// Статусы перемещения по ячейкам
+const (
+	LocationMoveStatusDraft     = "Черновик"
+	LocationMoveStatusPosted    = "Проведен"
+	LocationMoveStatusCancelled = "Отменен"
+)
+
+// LocationMove - перемещение товара между ячейками одного склада
+type LocationMove struct {
+	MoveID      uuid.UUID
+	MoveNumber  string
+	WarehouseID uuid.UUID
+	Status      string
+	Notes       *string
+	CreatedBy   *uuid.UUID
+	CreatedAt   time.Time
+	UpdatedBy   *uuid.UUID
+	UpdatedAt   time.Time
+}
+
+type LocationMoveItem struct {
+	MoveItemID     uuid.UUID
+	MoveID         uuid.UUID
+	ProductID      uuid.UUID
+	Article        string
+	FromLocationID *uuid.UUID
+	ToLocationID   *uuid.UUID
+	Quantity       int
+}
+
+// LocationMoveItemInput - позиция перемещения; пустое место - неразмещенный остаток склада
+type LocationMoveItemInput struct {
+	ProductID      uuid.UUID
+	FromLocationID *uuid.UUID
+	ToLocationID   *uuid.UUID
+	Quantity       int
+}
+
+type LocationMoveRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLocationMoveRepository(pool *pgxpool.Pool) *LocationMoveRepository {
+	return &LocationMoveRepository{pool: pool}
+}
+
+const locationMoveColumns = `
+	move_id, move_number, warehouse_id, status, notes, created_by, created_at, updated_by, updated_at
+`
+
+func scanLocationMove(row pgx.Row, move *LocationMove) error {
+	return row.Scan(
+		&move.MoveID,
+		&move.MoveNumber,
+		&move.WarehouseID,
+		&move.Status,
+		&move.Notes,
+		&move.CreatedBy,
+		&move.CreatedAt,
+		&move.UpdatedBy,
+		&move.UpdatedAt,
+	)
+}
+
+func locationMoveError(err error) error {
+	errMsg := err.Error()
+	if strings.Contains(errMsg, "location_move_items_locations") {
+		return ErrInvalidMoveLocations
+	}
+	if strings.Contains(errMsg, "check constraint") {
+		return ErrInvalidQuantity
+	}
+	if strings.Contains(errMsg, "duplicate key") ||
+		strings.Contains(errMsg, "unique constraint") {
+		return ErrLocationMoveExists
+	}
+	return err
+}
+
+func (r *LocationMoveRepository) GetByID(ctx context.Context, moveID uuid.UUID) (*LocationMove, error) {
+	query := `
+		SELECT ` + locationMoveColumns + `
+		FROM location_moves
+		WHERE move_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var move LocationMove
+	err := scanLocationMove(r.pool.QueryRow(ctx, query, moveID), &move)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLocationMoveNotFound
+		}
+		return nil, err
+	}
+
+	return &move, nil
+}
+
+// List returns the moves of the warehouse, optionally only in the given status.
+func (r *LocationMoveRepository) List(ctx context.Context, warehouseID uuid.UUID, limit, offset int, status *string) ([]LocationMove, error) {
+	query := `
+		SELECT ` + locationMoveColumns + `
+		FROM location_moves
+		WHERE warehouse_id = $1
+	`
+	args := []interface{}{warehouseID}
+	argPos := 2
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, *status)
+		argPos++
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC, move_id LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var moves []LocationMove
+	for rows.Next() {
+		var move LocationMove
+		if err := scanLocationMove(rows, &move); err != nil {
+			return nil, err
+		}
+		moves = append(moves, move)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return moves, nil
+}
+
+func (r *LocationMoveRepository) GetItems(ctx context.Context, moveID uuid.UUID) ([]LocationMoveItem, error) {
+	query := `
+		SELECT mi.move_item_id, mi.move_id, mi.product_id, p.article, mi.from_location_id, mi.to_location_id, mi.quantity
+		FROM location_move_items mi
+		JOIN products p ON p.product_id = mi.product_id
+		WHERE mi.move_id = $1
+		ORDER BY p.article, mi.move_item_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, moveID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []LocationMoveItem
+	for rows.Next() {
+		var item LocationMoveItem
+		if err := rows.Scan(
+			&item.MoveItemID,
+			&item.MoveID,
+			&item.ProductID,
+			&item.Article,
+			&item.FromLocationID,
+			&item.ToLocationID,
+			&item.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func insertLocationMoveItems(ctx context.Context, tx pgx.Tx, moveID uuid.UUID, items []LocationMoveItemInput) error {
+	for _, item := range items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO location_move_items (move_id, product_id, from_location_id, to_location_id, quantity)
+			VALUES ($1, $2, $3, $4, $5)
+		`, moveID, item.ProductID, item.FromLocationID, item.ToLocationID, item.Quantity)
+		if err != nil {
+			return locationMoveError(err)
+		}
+	}
+	return nil
+}
+
+// Create inserts a draft move with its items in one transaction.
+func (r *LocationMoveRepository) Create(ctx context.Context, moveNumber string, warehouseID uuid.UUID, notes *string, items []LocationMoveItemInput, createdBy uuid.UUID) (*LocationMove, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var move LocationMove
+	err = scanLocationMove(tx.QueryRow(ctx, `
+		INSERT INTO location_moves (move_number, warehouse_id, notes, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+locationMoveColumns,
+		moveNumber, warehouseID, notes, createdBy,
+	), &move)
+	if err != nil {
+		return nil, locationMoveError(err)
+	}
+
+	if err := insertLocationMoveItems(ctx, tx, move.MoveID, items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &move, nil
+}
+
+// Update replaces the number, notes and items of a draft move in one transaction. Returns
+// ErrLocationMoveNotEditable once the move has left the draft status.
+func (r *LocationMoveRepository) Update(ctx context.Context, moveID uuid.UUID, moveNumber string, notes *string, items []LocationMoveItemInput, updatedBy uuid.UUID) (*LocationMove, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status
+		FROM location_moves
+		WHERE move_id = $1
+		FOR UPDATE
+	`, moveID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLocationMoveNotFound
+		}
+		return nil, err
+	}
+	if status != LocationMoveStatusDraft {
+		return nil, ErrLocationMoveNotEditable
+	}
+
+	var move LocationMove
+	err = scanLocationMove(tx.QueryRow(ctx, `
+		UPDATE location_moves
+		SET move_number = $1, notes = $2, updated_by = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE move_id = $4
+		RETURNING `+locationMoveColumns,
+		moveNumber, notes, updatedBy, moveID,
+	), &move)
+	if err != nil {
+		return nil, locationMoveError(err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM location_move_items WHERE move_id = $1`, moveID); err != nil {
+		return nil, err
+	}
+	if err := insertLocationMoveItems(ctx, tx, moveID, items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &move, nil
+}
+
+// Delete removes a draft or cancelled move; posted moves define the stock of locations and are kept.
+func (r *LocationMoveRepository) Delete(ctx context.Context, moveID uuid.UUID) error {
+	query := `
+		DELETE FROM location_moves
+		WHERE move_id = $1 AND status IN ($2, $3)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, moveID, LocationMoveStatusDraft, LocationMoveStatusCancelled)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, moveID); err != nil {
+			return err
+		}
+		return ErrLocationMoveNotEditable
+	}
+
+	return nil
+}
+
+// LocationStockChange - изменение остатка товара в месте хранения; LocationID nil - неразмещенный остаток склада
+type LocationStockChange struct {
+	LocationID *uuid.UUID
+	ProductID  uuid.UUID
+	Quantity   int
+}
+
+// lockLocationStock serializes the changes of the location stock of the warehouse until the end of the transaction.
+func lockLocationStock(ctx context.Context, tx pgx.Tx, warehouseID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('location:' || $1::text, 0))`, warehouseID)
+	return err
+}
+
+// UpdateStatus moves the move from fromStatus to toStatus, applying changes to the location stock of the
+// warehouse. The location stock is re-checked under a transaction-level advisory lock on the warehouse, so
+// concurrent posts cannot overdraw a location. Returns ErrInsufficientLocationStock if a change would take
+// a location or the unplaced stock below zero and ErrStatusConflict if the status was changed concurrently.
+func (r *LocationMoveRepository) UpdateStatus(ctx context.Context, moveID, warehouseID uuid.UUID, fromStatus, toStatus string, changes []LocationStockChange, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockLocationStock(ctx, tx, warehouseID); err != nil {
+		return err
+	}
+
+	if len(changes) > 0 {
+		rows, err := tx.Query(ctx, locationStockQuery, warehouseID)
+		if err != nil {
+			return err
+		}
+		stock, err := scanLocationStock(rows)
+		if err != nil {
+			return err
+		}
+		current := make(map[[2]uuid.UUID]int, len(stock))
+		for _, item := range stock {
+			key := [2]uuid.UUID{uuid.Nil, item.ProductID}
+			if item.LocationID != nil {
+				key[0] = *item.LocationID
+			}
+			current[key] = item.Quantity
+		}
+		for _, change := range changes {
+			key := [2]uuid.UUID{uuid.Nil, change.ProductID}
+			if change.LocationID != nil {
+				key[0] = *change.LocationID
+			}
+			if change.Quantity < 0 && current[key]+change.Quantity < 0 {
+				return ErrInsufficientLocationStock
+			}
+		}
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE location_moves
+		SET status = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE move_id = $3 AND status = $4
+	`, toStatus, userID, moveID, fromStatus)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,462 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrMpReturnNotFound          = errors.New("mp return not found")
+	ErrMpReturnExists            = errors.New("mp return already exists")
+	ErrMpReturnNotEditable       = errors.New("mp return can only be changed in draft status")
+	ErrEmptyReturn               = errors.New("mp return has no items")
+	ErrInvalidReturnType         = errors.New("invalid return type")
+	ErrInvalidReturnCondition    = errors.New("invalid return condition")
+	ErrReturnShipmentMismatch    = errors.New("shipment belongs to another store")
+	ErrReturnExceedsShipped      = errors.New("returned quantity exceeds quantity sent in shipment")
+	ErrReturnShipmentNotSent     = errors.New("shipment has not been dispatched")
+	ErrReturnShipmentNotRejected = errors.New("rejection return requires a rejected shipment")
+	ErrNotReturnsWarehouse       = errors.New("defective returns must go to a returns warehouse")
+)
+
+// Статусы возврата с маркетплейса
+const (
+	MpReturnStatusDraft     = "Черновик"
+	MpReturnStatusPosted    = "Проведен"
+	MpReturnStatusCancelled = "Отменен"
+)
+
+// Типы возврата: отказ маркетплейса по отгрузке или возврат покупателя
+const (
+	MpReturnTypeRejection = "rejection"
+	MpReturnTypeCustomer  = "customer"
+)
+
+// Состояние возвращенного товара
+const (
+	ReturnConditionResellable = "resellable"
+	ReturnConditionDefect     = "defect"
+	ReturnConditionWriteOff   = "write_off"
+)
+
+func IsValidReturnType(returnType string) bool {
+	return returnType == MpReturnTypeRejection || returnType == MpReturnTypeCustomer
+}
+
+func IsValidReturnCondition(condition string) bool {
+	switch condition {
+	case ReturnConditionResellable, ReturnConditionDefect, ReturnConditionWriteOff:
+		return true
+	}
+	return false
+}
+
+// MpReturn - возврат товара с маркетплейса на склад возвратов
+type MpReturn struct {
+	ReturnID     uuid.UUID
+	ReturnNumber string
+	StoreID      uuid.UUID
+	ShipmentID   *uuid.UUID
+	WarehouseID  uuid.UUID
+	ReturnType   string
+	Status       string
+	ReturnDate   time.Time
+	Notes        *string
+	CreatedBy    *uuid.UUID
+	CreatedAt    time.Time
+	UpdatedBy    *uuid.UUID
+	UpdatedAt    time.Time
+}
+
+type MpReturnItem struct {
+	ReturnItemID uuid.UUID
+	ReturnID     uuid.UUID
+	ProductID    uuid.UUID
+	Article      string
+	Quantity     int
+	Condition    string
+}
+
+// MpReturnItemInput - товар, количество и состояние для позиций возврата
+type MpReturnItemInput struct {
+	ProductID uuid.UUID
+	Quantity  int
+	Condition string
+}
+
+type MpReturnRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMpReturnRepository(pool *pgxpool.Pool) *MpReturnRepository {
+	return &MpReturnRepository{pool: pool}
+}
+
+const mpReturnColumns = `
+	return_id, return_number, store_id, shipment_id, warehouse_id, return_type, status,
+	return_date, notes, created_by, created_at, updated_by, updated_at
+`
+
+func scanMpReturn(row pgx.Row, mpReturn *MpReturn) error {
+	return row.Scan(
+		&mpReturn.ReturnID,
+		&mpReturn.ReturnNumber,
+		&mpReturn.StoreID,
+		&mpReturn.ShipmentID,
+		&mpReturn.WarehouseID,
+		&mpReturn.ReturnType,
+		&mpReturn.Status,
+		&mpReturn.ReturnDate,
+		&mpReturn.Notes,
+		&mpReturn.CreatedBy,
+		&mpReturn.CreatedAt,
+		&mpReturn.UpdatedBy,
+		&mpReturn.UpdatedAt,
+	)
+}
+
+func mpReturnError(err error) error {
+	errMsg := err.Error()
+	if strings.Contains(errMsg, "mp_return_items_return_id_product_id_condition_key") {
+		return ErrDuplicateItem
+	}
+	if strings.Contains(errMsg, "mp_returns_return_type_check") {
+		return ErrInvalidReturnType
+	}
+	if strings.Contains(errMsg, "mp_return_items_condition_check") {
+		return ErrInvalidReturnCondition
+	}
+	if strings.Contains(errMsg, "check constraint") {
+		return ErrInvalidQuantity
+	}
+	if strings.Contains(errMsg, "duplicate key") ||
+		strings.Contains(errMsg, "unique constraint") {
+		return ErrMpReturnExists
+	}
+	return err
+}
+
+func (r *MpReturnRepository) GetByID(ctx context.Context, returnID uuid.UUID) (*MpReturn, error) {
+	query := `
+		SELECT ` + mpReturnColumns + `
+		FROM mp_returns
+		WHERE return_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var mpReturn MpReturn
+	err := scanMpReturn(r.pool.QueryRow(ctx, query, returnID), &mpReturn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMpReturnNotFound
+		}
+		return nil, err
+	}
+
+	return &mpReturn, nil
+}
+
+func (r *MpReturnRepository) List(ctx context.Context, limit, offset int, storeID, shipmentID *uuid.UUID, status *string) ([]MpReturn, error) {
+	query := `
+		SELECT ` + mpReturnColumns + `
+		FROM mp_returns
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argPos := 1
+
+	if storeID != nil {
+		query += fmt.Sprintf(" AND store_id = $%d", argPos)
+		args = append(args, *storeID)
+		argPos++
+	}
+	if shipmentID != nil {
+		query += fmt.Sprintf(" AND shipment_id = $%d", argPos)
+		args = append(args, *shipmentID)
+		argPos++
+	}
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, *status)
+		argPos++
+	}
+
+	query += fmt.Sprintf(" ORDER BY return_date DESC, return_id LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returns []MpReturn
+	for rows.Next() {
+		var mpReturn MpReturn
+		if err := scanMpReturn(rows, &mpReturn); err != nil {
+			return nil, err
+		}
+		returns = append(returns, mpReturn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
+
+func (r *MpReturnRepository) GetItems(ctx context.Context, returnID uuid.UUID) ([]MpReturnItem, error) {
+	query := `
+		SELECT ri.return_item_id, ri.return_id, ri.product_id, p.article, ri.quantity, ri.condition
+		FROM mp_return_items ri
+		JOIN products p ON p.product_id = ri.product_id
+		WHERE ri.return_id = $1
+		ORDER BY p.article, ri.condition
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, returnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []MpReturnItem
+	for rows.Next() {
+		var item MpReturnItem
+		if err := rows.Scan(
+			&item.ReturnItemID,
+			&item.ReturnID,
+			&item.ProductID,
+			&item.Article,
+			&item.Quantity,
+			&item.Condition,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetReturnableQuantities returns, per product of the shipment, the sent quantity minus the quantity already
+// returned or lost by the shipment's discrepancy resolution and by other returns of the shipment that are not
+// cancelled. excludeReturnID skips the return being edited.
+func (r *MpReturnRepository) GetReturnableQuantities(ctx context.Context, shipmentID uuid.UUID, excludeReturnID *uuid.UUID) (map[uuid.UUID]int, error) {
+	query := `
+		WITH sent AS (
+			SELECT product_id, SUM(sent_qty - returned_qty - lost_qty)::int AS quantity
+			FROM mp_shipment_items
+			WHERE shipment_id = $1 AND product_id IS NOT NULL
+			GROUP BY product_id
+		),
+		returned AS (
+			SELECT ri.product_id, SUM(ri.quantity)::int AS quantity
+			FROM mp_return_items ri
+			JOIN mp_returns r ON r.return_id = ri.return_id
+			WHERE r.shipment_id = $1
+			  AND r.status <> $2
+			  AND ($3::uuid IS NULL OR r.return_id <> $3)
+			GROUP BY ri.product_id
+		)
+		SELECT s.product_id, s.quantity - COALESCE(ret.quantity, 0)
+		FROM sent s
+		LEFT JOIN returned ret ON ret.product_id = s.product_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, shipmentID, MpReturnStatusCancelled, excludeReturnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quantities := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var productID uuid.UUID
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		quantities[productID] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quantities, nil
+}
+
+func insertMpReturnItems(ctx context.Context, tx pgx.Tx, returnID uuid.UUID, items []MpReturnItemInput) error {
+	for _, item := range items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO mp_return_items (return_id, product_id, quantity, condition)
+			VALUES ($1, $2, $3, $4)
+		`, returnID, item.ProductID, item.Quantity, item.Condition)
+		if err != nil {
+			return mpReturnError(err)
+		}
+	}
+	return nil
+}
+
+// Create inserts a draft return with its items in one transaction.
+func (r *MpReturnRepository) Create(ctx context.Context, returnNumber string, storeID uuid.UUID, shipmentID *uuid.UUID, warehouseID uuid.UUID, returnType string, returnDate time.Time, notes *string, items []MpReturnItemInput, createdBy uuid.UUID) (*MpReturn, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var mpReturn MpReturn
+	err = scanMpReturn(tx.QueryRow(ctx, `
+		INSERT INTO mp_returns (return_number, store_id, shipment_id, warehouse_id, return_type, return_date, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+mpReturnColumns,
+		returnNumber, storeID, shipmentID, warehouseID, returnType, returnDate, notes, createdBy,
+	), &mpReturn)
+	if err != nil {
+		return nil, mpReturnError(err)
+	}
+
+	if err := insertMpReturnItems(ctx, tx, mpReturn.ReturnID, items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &mpReturn, nil
+}
+
+// Update replaces the header and items of a draft return in one transaction. Returns
+// ErrMpReturnNotEditable once the return has left the draft status.
+func (r *MpReturnRepository) Update(ctx context.Context, returnID uuid.UUID, returnNumber string, storeID uuid.UUID, shipmentID *uuid.UUID, warehouseID uuid.UUID, returnType string, returnDate time.Time, notes *string, items []MpReturnItemInput, updatedBy uuid.UUID) (*MpReturn, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status
+		FROM mp_returns
+		WHERE return_id = $1
+		FOR UPDATE
+	`, returnID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMpReturnNotFound
+		}
+		return nil, err
+	}
+	if status != MpReturnStatusDraft {
+		return nil, ErrMpReturnNotEditable
+	}
+
+	var mpReturn MpReturn
+	err = scanMpReturn(tx.QueryRow(ctx, `
+		UPDATE mp_returns
+		SET return_number = $1, store_id = $2, shipment_id = $3, warehouse_id = $4, return_type = $5,
+		    return_date = $6, notes = $7, updated_by = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE return_id = $9
+		RETURNING `+mpReturnColumns,
+		returnNumber, storeID, shipmentID, warehouseID, returnType, returnDate, notes, updatedBy, returnID,
+	), &mpReturn)
+	if err != nil {
+		return nil, mpReturnError(err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mp_return_items WHERE return_id = $1`, returnID); err != nil {
+		return nil, err
+	}
+	if err := insertMpReturnItems(ctx, tx, returnID, items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &mpReturn, nil
+}
+
+// Delete removes a draft or cancelled return; posted returns affect stock and are kept.
+func (r *MpReturnRepository) Delete(ctx context.Context, returnID uuid.UUID) error {
+	query := `
+		DELETE FROM mp_returns
+		WHERE return_id = $1 AND status IN ($2, $3)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, returnID, MpReturnStatusDraft, MpReturnStatusCancelled)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, returnID); err != nil {
+			return err
+		}
+		return ErrMpReturnNotEditable
+	}
+
+	return nil
+}
+
+// UpdateStatus moves the return from fromStatus to toStatus. Returns ErrStatusConflict if the status
+// was changed concurrently.
+func (r *MpReturnRepository) UpdateStatus(ctx context.Context, returnID uuid.UUID, fromStatus, toStatus string, userID uuid.UUID) error {
+	query := `
+		UPDATE mp_returns
+		SET status = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE return_id = $3 AND status = $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, toStatus, userID, returnID, fromStatus)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
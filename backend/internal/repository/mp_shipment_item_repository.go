@@ -23,6 +23,8 @@ type MpShipmentItem struct {
 	SentQty          int
 	AcceptedQty      int
 	LogisticsForItem *float64
+	ReturnedQty      int
+	LostQty          int
 }
 
 type MpShipmentItemRepository struct {
@@ -36,7 +38,7 @@ func NewMpShipmentItemRepository(pool *pgxpool.Pool) *MpShipmentItemRepository {
 func (r *MpShipmentItemRepository) GetByID(ctx context.Context, itemID uuid.UUID) (*MpShipmentItem, error) {
 	query := `
 		SELECT shipment_item_id, shipment_id, product_id, warehouse_id,
-		       sent_qty, accepted_qty, logistics_for_item
+		       sent_qty, accepted_qty, logistics_for_item, returned_qty, lost_qty
 		FROM mp_shipment_items
 		WHERE shipment_item_id = $1
 	`
@@ -53,6 +55,8 @@ func (r *MpShipmentItemRepository) GetByID(ctx context.Context, itemID uuid.UUID
 		&item.SentQty,
 		&item.AcceptedQty,
 		&item.LogisticsForItem,
+		&item.ReturnedQty,
+		&item.LostQty,
 	)
 
 	if err != nil {
@@ -68,7 +72,7 @@ func (r *MpShipmentItemRepository) GetByID(ctx context.Context, itemID uuid.UUID
 func (r *MpShipmentItemRepository) GetByShipmentID(ctx context.Context, shipmentID uuid.UUID) ([]MpShipmentItem, error) {
 	query := `
 		SELECT shipment_item_id, shipment_id, product_id, warehouse_id,
-		       sent_qty, accepted_qty, logistics_for_item
+		       sent_qty, accepted_qty, logistics_for_item, returned_qty, lost_qty
 		FROM mp_shipment_items
 		WHERE shipment_id = $1
 		ORDER BY shipment_item_id
@@ -94,6 +98,8 @@ func (r *MpShipmentItemRepository) GetByShipmentID(ctx context.Context, shipment
 			&item.SentQty,
 			&item.AcceptedQty,
 			&item.LogisticsForItem,
+			&item.ReturnedQty,
+			&item.LostQty,
 		); err != nil {
 			return nil, err
 		}
@@ -114,7 +120,7 @@ func (r *MpShipmentItemRepository) Create(ctx context.Context, shipmentID, produ
 		)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING shipment_item_id, shipment_id, product_id, warehouse_id,
-		          sent_qty, accepted_qty, logistics_for_item
+		          sent_qty, accepted_qty, logistics_for_item, returned_qty, lost_qty
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -131,6 +137,8 @@ func (r *MpShipmentItemRepository) Create(ctx context.Context, shipmentID, produ
 		&item.SentQty,
 		&item.AcceptedQty,
 		&item.LogisticsForItem,
+		&item.ReturnedQty,
+		&item.LostQty,
 	)
 
 	if err != nil {
@@ -147,7 +155,7 @@ func (r *MpShipmentItemRepository) Update(ctx context.Context, itemID, shipmentI
 		    sent_qty = $4, accepted_qty = $5, logistics_for_item = $6
 		WHERE shipment_item_id = $7
 		RETURNING shipment_item_id, shipment_id, product_id, warehouse_id,
-		          sent_qty, accepted_qty, logistics_for_item
+		          sent_qty, accepted_qty, logistics_for_item, returned_qty, lost_qty
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -164,6 +172,8 @@ func (r *MpShipmentItemRepository) Update(ctx context.Context, itemID, shipmentI
 		&item.SentQty,
 		&item.AcceptedQty,
 		&item.LogisticsForItem,
+		&item.ReturnedQty,
+		&item.LostQty,
 	)
 
 	if err != nil {
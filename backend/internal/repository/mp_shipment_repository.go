@@ -15,26 +15,45 @@ import (
 var (
 	ErrMpShipmentNotFound = errors.New("mp shipment not found")
 	ErrMpShipmentExists   = errors.New("mp shipment already exists")
+	ErrShipmentNotClosed  = errors.New("mp shipment is neither accepted nor rejected")
+	ErrShipmentResolved   = errors.New("mp shipment discrepancy is already resolved")
+	ErrInvalidResolution  = errors.New("returned and lost quantities must cover the discrepancy")
+	ErrShipmentNotSent    = errors.New("mp shipment is not sent yet")
+	ErrShipmentDispatched = errors.New("date and warehouse of a dispatched mp shipment cannot be changed")
 )
 
+// IsValidShipmentAllocationMethod reports whether the method can be used to distribute shipment costs.
+// Отгрузки распределяются только по весу или количеству: цены позиций в отгрузке нет.
+func IsValidShipmentAllocationMethod(method string) bool {
+	return method == AllocationByWeight || method == AllocationByQuantity
+}
+
+// ShipmentItemLogistics is the share of the shipment logistics and acceptance cost allocated to an item.
+type ShipmentItemLogistics struct {
+	ShipmentItemID   uuid.UUID
+	LogisticsForItem float64
+}
+
 type MpShipment struct {
-	ShipmentID     uuid.UUID
-	ShipmentDate   *time.Time
-	ShipmentNumber string
-	StoreID        *uuid.UUID
-	WarehouseID    *uuid.UUID
-	StatusID       *uuid.UUID
-	LogisticsCost  *float64
-	UnitLogistics  *float64
-	AcceptanceCost *float64
-	AcceptanceDate *time.Time
-	PositionsQty   int
-	SentQty        int
-	AcceptedQty    int
-	CreatedBy      *uuid.UUID
-	CreatedAt      time.Time
-	UpdatedBy      *uuid.UUID
-	UpdatedAt      time.Time
+	ShipmentID                uuid.UUID
+	ShipmentDate              *time.Time
+	ShipmentNumber            string
+	StoreID                   *uuid.UUID
+	WarehouseID               *uuid.UUID
+	StatusID                  *uuid.UUID
+	LogisticsCost             *float64
+	UnitLogistics             *float64
+	AcceptanceCost            *float64
+	AcceptanceDate            *time.Time
+	PositionsQty              int
+	SentQty                   int
+	AcceptedQty               int
+	LogisticsAllocationMethod string
+	ResolutionDate            *time.Time
+	CreatedBy                 *uuid.UUID
+	CreatedAt                 time.Time
+	UpdatedBy                 *uuid.UUID
+	UpdatedAt                 time.Time
 }
 
 type MpShipmentRepository struct {
@@ -50,7 +69,7 @@ func (r *MpShipmentRepository) GetByID(ctx context.Context, shipmentID uuid.UUID
 		SELECT shipment_id, shipment_date, shipment_number, store_id, warehouse_id,
 		       status_id, logistics_cost, unit_logistics, acceptance_cost,
 		       acceptance_date, positions_qty, sent_qty, accepted_qty,
-		       created_by, created_at, updated_by, updated_at
+		       logistics_allocation_method, resolution_date, created_by, created_at, updated_by, updated_at
 		FROM mp_shipments
 		WHERE shipment_id = $1
 	`
@@ -73,6 +92,8 @@ func (r *MpShipmentRepository) GetByID(ctx context.Context, shipmentID uuid.UUID
 		&shipment.PositionsQty,
 		&shipment.SentQty,
 		&shipment.AcceptedQty,
+		&shipment.LogisticsAllocationMethod,
+		&shipment.ResolutionDate,
 		&shipment.CreatedBy,
 		&shipment.CreatedAt,
 		&shipment.UpdatedBy,
@@ -94,7 +115,7 @@ func (r *MpShipmentRepository) List(ctx context.Context, limit, offset int, stor
 		SELECT shipment_id, shipment_date, shipment_number, store_id, warehouse_id,
 		       status_id, logistics_cost, unit_logistics, acceptance_cost,
 		       acceptance_date, positions_qty, sent_qty, accepted_qty,
-		       created_by, created_at, updated_by, updated_at
+		       logistics_allocation_method, resolution_date, created_by, created_at, updated_by, updated_at
 		FROM mp_shipments
 	`
 	args := []any{}
@@ -150,6 +171,8 @@ func (r *MpShipmentRepository) List(ctx context.Context, limit, offset int, stor
 			&shipment.PositionsQty,
 			&shipment.SentQty,
 			&shipment.AcceptedQty,
+			&shipment.LogisticsAllocationMethod,
+			&shipment.ResolutionDate,
 			&shipment.CreatedBy,
 			&shipment.CreatedAt,
 			&shipment.UpdatedBy,
@@ -167,18 +190,20 @@ func (r *MpShipmentRepository) List(ctx context.Context, limit, offset int, stor
 	return shipments, nil
 }
 
-func (r *MpShipmentRepository) Create(ctx context.Context, shipmentDate *time.Time, shipmentNumber string, storeID, warehouseID, statusID *uuid.UUID, logisticsCost, unitLogistics, acceptanceCost *float64, acceptanceDate *time.Time, positionsQty, sentQty, acceptedQty int, createdBy *uuid.UUID) (*MpShipment, error) {
+// Create inserts a shipment without items: positions_qty, sent_qty, accepted_qty and unit_logistics start
+// empty and are maintained by ApplyItemLogistics.
+func (r *MpShipmentRepository) Create(ctx context.Context, shipmentDate *time.Time, shipmentNumber string, storeID, warehouseID, statusID *uuid.UUID, logisticsCost, acceptanceCost *float64, acceptanceDate *time.Time, logisticsAllocationMethod string, createdBy *uuid.UUID) (*MpShipment, error) {
 	query := `
 		INSERT INTO mp_shipments (
 			shipment_date, shipment_number, store_id, warehouse_id, status_id,
-			logistics_cost, unit_logistics, acceptance_cost, acceptance_date,
-			positions_qty, sent_qty, accepted_qty, created_by
+			logistics_cost, acceptance_cost, acceptance_date,
+			logistics_allocation_method, created_by
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING shipment_id, shipment_date, shipment_number, store_id, warehouse_id,
 		          status_id, logistics_cost, unit_logistics, acceptance_cost,
 		          acceptance_date, positions_qty, sent_qty, accepted_qty,
-		          created_by, created_at, updated_by, updated_at
+		          logistics_allocation_method, resolution_date, created_by, created_at, updated_by, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -187,8 +212,8 @@ func (r *MpShipmentRepository) Create(ctx context.Context, shipmentDate *time.Ti
 	var shipment MpShipment
 	err := r.pool.QueryRow(ctx, query,
 		shipmentDate, shipmentNumber, storeID, warehouseID, statusID,
-		logisticsCost, unitLogistics, acceptanceCost, acceptanceDate,
-		positionsQty, sentQty, acceptedQty, createdBy,
+		logisticsCost, acceptanceCost, acceptanceDate,
+		logisticsAllocationMethod, createdBy,
 	).Scan(
 		&shipment.ShipmentID,
 		&shipment.ShipmentDate,
@@ -203,6 +228,8 @@ func (r *MpShipmentRepository) Create(ctx context.Context, shipmentDate *time.Ti
 		&shipment.PositionsQty,
 		&shipment.SentQty,
 		&shipment.AcceptedQty,
+		&shipment.LogisticsAllocationMethod,
+		&shipment.ResolutionDate,
 		&shipment.CreatedBy,
 		&shipment.CreatedAt,
 		&shipment.UpdatedBy,
@@ -222,19 +249,19 @@ func (r *MpShipmentRepository) Create(ctx context.Context, shipmentDate *time.Ti
 	return &shipment, nil
 }
 
-func (r *MpShipmentRepository) Update(ctx context.Context, shipmentID uuid.UUID, shipmentDate *time.Time, shipmentNumber string, storeID, warehouseID, statusID *uuid.UUID, logisticsCost, unitLogistics, acceptanceCost *float64, acceptanceDate *time.Time, positionsQty, sentQty, acceptedQty int, updatedBy *uuid.UUID) (*MpShipment, error) {
+// Update changes the shipment header. Aggregates derived from items are left untouched.
+func (r *MpShipmentRepository) Update(ctx context.Context, shipmentID uuid.UUID, shipmentDate *time.Time, shipmentNumber string, storeID, warehouseID, statusID *uuid.UUID, logisticsCost, acceptanceCost *float64, acceptanceDate *time.Time, logisticsAllocationMethod string, updatedBy *uuid.UUID) (*MpShipment, error) {
 	query := `
 		UPDATE mp_shipments
 		SET shipment_date = $1, shipment_number = $2, store_id = $3, warehouse_id = $4,
-		    status_id = $5, logistics_cost = $6, unit_logistics = $7,
-		    acceptance_cost = $8, acceptance_date = $9, positions_qty = $10,
-		    sent_qty = $11, accepted_qty = $12, updated_by = $13,
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE shipment_id = $14
+		    status_id = $5, logistics_cost = $6, acceptance_cost = $7,
+		    acceptance_date = $8, logistics_allocation_method = $9,
+		    updated_by = $10, updated_at = CURRENT_TIMESTAMP
+		WHERE shipment_id = $11
 		RETURNING shipment_id, shipment_date, shipment_number, store_id, warehouse_id,
 		          status_id, logistics_cost, unit_logistics, acceptance_cost,
 		          acceptance_date, positions_qty, sent_qty, accepted_qty,
-		          created_by, created_at, updated_by, updated_at
+		          logistics_allocation_method, resolution_date, created_by, created_at, updated_by, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -243,8 +270,8 @@ func (r *MpShipmentRepository) Update(ctx context.Context, shipmentID uuid.UUID,
 	var shipment MpShipment
 	err := r.pool.QueryRow(ctx, query,
 		shipmentDate, shipmentNumber, storeID, warehouseID, statusID,
-		logisticsCost, unitLogistics, acceptanceCost, acceptanceDate,
-		positionsQty, sentQty, acceptedQty, updatedBy, shipmentID,
+		logisticsCost, acceptanceCost, acceptanceDate,
+		logisticsAllocationMethod, updatedBy, shipmentID,
 	).Scan(
 		&shipment.ShipmentID,
 		&shipment.ShipmentDate,
@@ -259,6 +286,8 @@ func (r *MpShipmentRepository) Update(ctx context.Context, shipmentID uuid.UUID,
 		&shipment.PositionsQty,
 		&shipment.SentQty,
 		&shipment.AcceptedQty,
+		&shipment.LogisticsAllocationMethod,
+		&shipment.ResolutionDate,
 		&shipment.CreatedBy,
 		&shipment.CreatedAt,
 		&shipment.UpdatedBy,
@@ -300,3 +329,199 @@ func (r *MpShipmentRepository) Delete(ctx context.Context, shipmentID uuid.UUID)
 
 	return nil
 }
+
+// ApplyItemLogistics stores the allocated logistics of the items and recalculates shipment aggregates
+// (positions, sent and accepted quantities, unit logistics) from its items in one transaction.
+func (r *MpShipmentRepository) ApplyItemLogistics(ctx context.Context, shipmentID uuid.UUID, items []ShipmentItemLogistics, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, item := range items {
+		_, err = tx.Exec(ctx, `
+			UPDATE mp_shipment_items
+			SET logistics_for_item = $1
+			WHERE shipment_item_id = $2 AND shipment_id = $3
+		`, item.LogisticsForItem, item.ShipmentItemID, shipmentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE mp_shipments s
+		SET positions_qty = agg.positions_qty,
+		    sent_qty = agg.sent_qty,
+		    accepted_qty = agg.accepted_qty,
+		    unit_logistics = CASE
+		        WHEN agg.sent_qty > 0 AND (s.logistics_cost IS NOT NULL OR s.acceptance_cost IS NOT NULL)
+		        THEN ROUND((COALESCE(s.logistics_cost, 0) + COALESCE(s.acceptance_cost, 0)) / agg.sent_qty, 2)
+		    END,
+		    updated_by = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		FROM (
+			SELECT COUNT(*)::int AS positions_qty,
+			       COALESCE(SUM(sent_qty), 0)::int AS sent_qty,
+			       COALESCE(SUM(accepted_qty), 0)::int AS accepted_qty
+			FROM mp_shipment_items
+			WHERE shipment_id = $1
+		) agg
+		WHERE s.shipment_id = $1
+	`, shipmentID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrMpShipmentNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateStatus moves the shipment from fromStatusID to toStatusID. Returns ErrStatusConflict if the shipment
+// no longer has fromStatusID, i.e. its status was changed by a concurrent request.
+func (r *MpShipmentRepository) UpdateStatus(ctx context.Context, shipmentID uuid.UUID, fromStatusID *uuid.UUID, toStatusID, userID uuid.UUID) error {
+	query := `
+		UPDATE mp_shipments
+		SET status_id = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE shipment_id = $3 AND status_id IS NOT DISTINCT FROM $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, toStatusID, userID, shipmentID, fromStatusID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
+
+// SetShipmentDate sets the date the shipment leaves the warehouse; its sent quantity is deducted on this date.
+func (r *MpShipmentRepository) SetShipmentDate(ctx context.Context, shipmentID uuid.UUID, shipmentDate time.Time, userID uuid.UUID) error {
+	query := `
+		UPDATE mp_shipments
+		SET shipment_date = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE shipment_id = $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, shipmentDate, userID, shipmentID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrMpShipmentNotFound
+	}
+
+	return nil
+}
+
+// ShipmentItemResolution - разбор расхождения позиции: сколько непринятого товара вернулось на склад и сколько утеряно
+type ShipmentItemResolution struct {
+	ShipmentItemID uuid.UUID
+	ReturnedQty    int
+	LostQty        int
+}
+
+// ResolveDiscrepancy stores the returned and lost quantities of the items and the resolution date of the
+// shipment in one transaction. Items not listed keep no returned or lost quantity.
+func (r *MpShipmentRepository) ResolveDiscrepancy(ctx context.Context, shipmentID uuid.UUID, resolutionDate time.Time, items []ShipmentItemResolution, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE mp_shipments
+		SET resolution_date = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE shipment_id = $3
+	`, resolutionDate, userID, shipmentID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrMpShipmentNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE mp_shipment_items
+		SET returned_qty = 0, lost_qty = 0
+		WHERE shipment_id = $1
+	`, shipmentID); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		result, err := tx.Exec(ctx, `
+			UPDATE mp_shipment_items
+			SET returned_qty = $1, lost_qty = $2
+			WHERE shipment_item_id = $3 AND shipment_id = $4
+		`, item.ReturnedQty, item.LostQty, item.ShipmentItemID, shipmentID)
+		if err != nil {
+			if strings.Contains(err.Error(), "mp_shipment_items_resolution") {
+				return ErrInvalidResolution
+			}
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrMpShipmentItemNotFound
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetReturnedByDocuments returns, per product, the quantity returned by not cancelled marketplace returns
+// linked to the shipment.
+func (r *MpShipmentRepository) GetReturnedByDocuments(ctx context.Context, shipmentID uuid.UUID) (map[uuid.UUID]int, error) {
+	query := `
+		SELECT ri.product_id, SUM(ri.quantity)::int
+		FROM mp_return_items ri
+		JOIN mp_returns r ON r.return_id = ri.return_id
+		WHERE r.shipment_id = $1 AND r.status <> $2
+		GROUP BY ri.product_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, shipmentID, MpReturnStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quantities := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var productID uuid.UUID
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		quantities[productID] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quantities, nil
+}
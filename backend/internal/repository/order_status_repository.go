@@ -15,11 +15,23 @@ import (
 var (
 	ErrOrderStatusNotFound = errors.New("order status not found")
 	ErrOrderStatusExists   = errors.New("order status already exists")
+	ErrOrderStatusArchived = errors.New("order status is archived")
+)
+
+// Названия статусов заказов поставщикам, на которые опирается бизнес-логика
+const (
+	OrderStatusDraft          = "Черновик"
+	OrderStatusAwaitingSupply = "Ожидает поставки"
+	OrderStatusInTransit      = "В пути"
+	OrderStatusReceived       = "Получен"
+	OrderStatusCancelled      = "Отменен"
 )
 
 type OrderStatus struct {
 	OrderStatusID uuid.UUID
 	Name          string
+	IsArchived    bool
+	ArchivedAt    *time.Time
 }
 
 type OrderStatusRepository struct {
@@ -32,7 +44,7 @@ func NewOrderStatusRepository(pool *pgxpool.Pool) *OrderStatusRepository {
 
 func (r *OrderStatusRepository) GetByID(ctx context.Context, statusID uuid.UUID) (*OrderStatus, error) {
 	query := `
-		SELECT order_status_id, name
+		SELECT order_status_id, name, is_archived, archived_at
 		FROM order_statuses
 		WHERE order_status_id = $1
 	`
@@ -44,6 +56,36 @@ func (r *OrderStatusRepository) GetByID(ctx context.Context, statusID uuid.UUID)
 	err := r.pool.QueryRow(ctx, query, statusID).Scan(
 		&status.OrderStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderStatusNotFound
+		}
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func (r *OrderStatusRepository) GetByName(ctx context.Context, name string) (*OrderStatus, error) {
+	query := `
+		SELECT order_status_id, name, is_archived, archived_at
+		FROM order_statuses
+		WHERE name = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var status OrderStatus
+	err := r.pool.QueryRow(ctx, query, name).Scan(
+		&status.OrderStatusID,
+		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -56,10 +98,11 @@ func (r *OrderStatusRepository) GetByID(ctx context.Context, statusID uuid.UUID)
 	return &status, nil
 }
 
-func (r *OrderStatusRepository) List(ctx context.Context, limit, offset int) ([]OrderStatus, error) {
+func (r *OrderStatusRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]OrderStatus, error) {
 	query := fmt.Sprintf(`
-		SELECT order_status_id, name
+		SELECT order_status_id, name, is_archived, archived_at
 		FROM order_statuses
+		WHERE $3 OR NOT is_archived
 		ORDER BY order_status_id
 		LIMIT $1 OFFSET $2
 	`)
@@ -67,7 +110,7 @@ func (r *OrderStatusRepository) List(ctx context.Context, limit, offset int) ([]
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +122,8 @@ func (r *OrderStatusRepository) List(ctx context.Context, limit, offset int) ([]
 		if err := rows.Scan(
 			&status.OrderStatusID,
 			&status.Name,
+			&status.IsArchived,
+			&status.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -96,7 +141,7 @@ func (r *OrderStatusRepository) Create(ctx context.Context, name string) (*Order
 	query := `
 		INSERT INTO order_statuses (name)
 		VALUES ($1)
-		RETURNING order_status_id, name
+		RETURNING order_status_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -106,6 +151,8 @@ func (r *OrderStatusRepository) Create(ctx context.Context, name string) (*Order
 	err := r.pool.QueryRow(ctx, query, name).Scan(
 		&status.OrderStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -126,7 +173,7 @@ func (r *OrderStatusRepository) Update(ctx context.Context, statusID uuid.UUID,
 		UPDATE order_statuses
 		SET name = $1
 		WHERE order_status_id = $2
-		RETURNING order_status_id, name
+		RETURNING order_status_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -136,6 +183,8 @@ func (r *OrderStatusRepository) Update(ctx context.Context, statusID uuid.UUID,
 	err := r.pool.QueryRow(ctx, query, name, statusID).Scan(
 		&status.OrderStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -153,9 +202,34 @@ func (r *OrderStatusRepository) Update(ctx context.Context, statusID uuid.UUID,
 	return &status, nil
 }
 
-func (r *OrderStatusRepository) Delete(ctx context.Context, statusID uuid.UUID) error {
+// Archive hides the status from lists and new documents instead of deleting it, so existing documents and
+// stock history keep their references. Archiving an archived status keeps the original archived_at.
+func (r *OrderStatusRepository) Archive(ctx context.Context, statusID uuid.UUID) error {
 	query := `
-		DELETE FROM order_statuses
+		UPDATE order_statuses
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE order_status_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, statusID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrOrderStatusNotFound
+	}
+
+	return nil
+}
+
+func (r *OrderStatusRepository) Restore(ctx context.Context, statusID uuid.UUID) error {
+	query := `
+		UPDATE order_statuses
+		SET is_archived = FALSE, archived_at = NULL
 		WHERE order_status_id = $1
 	`
 
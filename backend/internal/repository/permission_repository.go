@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrPermissionNotFound = errors.New("permission not found")
+)
+
+type Permission struct {
+	PermissionID uuid.UUID
+	Resource     string
+	Action       string
+}
+
+type PermissionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPermissionRepository(pool *pgxpool.Pool) *PermissionRepository {
+	return &PermissionRepository{pool: pool}
+}
+
+func (r *PermissionRepository) List(ctx context.Context) ([]Permission, error) {
+	query := `
+		SELECT permission_id, resource, action
+		FROM permissions
+		ORDER BY resource, action
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []Permission
+	for rows.Next() {
+		var permission Permission
+		if err := rows.Scan(
+			&permission.PermissionID,
+			&permission.Resource,
+			&permission.Action,
+		); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (r *PermissionRepository) GetByRoleID(ctx context.Context, roleID uuid.UUID) ([]Permission, error) {
+	query := `
+		SELECT p.permission_id, p.resource, p.action
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.resource, p.action
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []Permission
+	for rows.Next() {
+		var permission Permission
+		if err := rows.Scan(
+			&permission.PermissionID,
+			&permission.Resource,
+			&permission.Action,
+		); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (r *PermissionRepository) HasPermission(ctx context.Context, roleID uuid.UUID, resource, action string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM role_permissions rp
+			JOIN permissions p ON p.permission_id = rp.permission_id
+			WHERE rp.role_id = $1 AND p.resource = $2 AND p.action = $3
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var allowed bool
+	if err := r.pool.QueryRow(ctx, query, roleID, resource, action).Scan(&allowed); err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+// ReplaceRolePermissions atomically replaces the role's permission set with the given permission IDs.
+func (r *PermissionRepository) ReplaceRolePermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM role_permissions
+		WHERE role_id = $1
+	`, roleID)
+	if err != nil {
+		return err
+	}
+
+	if len(permissionIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT $1, unnest($2::uuid[])
+			ON CONFLICT DO NOTHING
+		`, roleID, permissionIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -15,6 +15,7 @@ import (
 var (
 	ErrProductCostNotFound = errors.New("product cost not found")
 	ErrProductCostExists   = errors.New("product cost already exists")
+	ErrProductCostOverlap  = errors.New("product cost period overlaps an existing period")
 )
 
 type ProductCost struct {
@@ -24,6 +25,7 @@ type ProductCost struct {
 	PeriodEnd           time.Time
 	UnitCostToWarehouse float64
 	Notes               *string
+	SourceOrderID       *uuid.UUID
 	CreatedBy           *uuid.UUID
 	CreatedAt           time.Time
 	UpdatedBy           *uuid.UUID
@@ -40,7 +42,7 @@ func NewProductCostRepository(pool *pgxpool.Pool) *ProductCostRepository {
 
 func (r *ProductCostRepository) GetByID(ctx context.Context, costID uuid.UUID) (*ProductCost, error) {
 	query := `
-		SELECT cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, created_by, created_at, updated_by, updated_at
+		SELECT cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, source_order_id, created_by, created_at, updated_by, updated_at
 		FROM product_costs
 		WHERE cost_id = $1
 	`
@@ -56,6 +58,7 @@ func (r *ProductCostRepository) GetByID(ctx context.Context, costID uuid.UUID) (
 		&cost.PeriodEnd,
 		&cost.UnitCostToWarehouse,
 		&cost.Notes,
+		&cost.SourceOrderID,
 		&cost.CreatedBy,
 		&cost.CreatedAt,
 		&cost.UpdatedBy,
@@ -74,7 +77,7 @@ func (r *ProductCostRepository) GetByID(ctx context.Context, costID uuid.UUID) (
 
 func (r *ProductCostRepository) List(ctx context.Context, limit, offset int, productID *uuid.UUID) ([]ProductCost, error) {
 	query := `
-		SELECT cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, created_by, created_at, updated_by, updated_at
+		SELECT cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, source_order_id, created_by, created_at, updated_by, updated_at
 		FROM product_costs
 	`
 	args := []interface{}{}
@@ -108,6 +111,7 @@ func (r *ProductCostRepository) List(ctx context.Context, limit, offset int, pro
 			&cost.PeriodEnd,
 			&cost.UnitCostToWarehouse,
 			&cost.Notes,
+			&cost.SourceOrderID,
 			&cost.CreatedBy,
 			&cost.CreatedAt,
 			&cost.UpdatedBy,
@@ -125,24 +129,37 @@ func (r *ProductCostRepository) List(ctx context.Context, limit, offset int, pro
 	return costs, nil
 }
 
-func (r *ProductCostRepository) Create(ctx context.Context, productID uuid.UUID, periodStart, periodEnd time.Time, unitCostToWarehouse float64, notes *string, createdBy *uuid.UUID) (*ProductCost, error) {
+// Create inserts a cost period. Periods listed in trimIDs are shortened to end the day before periodStart
+// in the same transaction, so a failed insert leaves them intact.
+func (r *ProductCostRepository) Create(ctx context.Context, productID uuid.UUID, periodStart, periodEnd time.Time, unitCostToWarehouse float64, notes *string, createdBy *uuid.UUID, trimIDs []uuid.UUID) (*ProductCost, error) {
 	query := `
 		INSERT INTO product_costs (product_id, period_start, period_end, unit_cost_to_warehouse, notes, created_by)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, created_by, created_at, updated_by, updated_at
+		RETURNING cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, source_order_id, created_by, created_at, updated_by, updated_at
 	`
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := trimPeriodEnds(ctx, tx, trimIDs, periodStart.AddDate(0, 0, -1), createdBy); err != nil {
+		return nil, err
+	}
+
 	var productCost ProductCost
-	err := r.pool.QueryRow(ctx, query, productID, periodStart, periodEnd, unitCostToWarehouse, notes, createdBy).Scan(
+	err = tx.QueryRow(ctx, query, productID, periodStart, periodEnd, unitCostToWarehouse, notes, createdBy).Scan(
 		&productCost.CostID,
 		&productCost.ProductID,
 		&productCost.PeriodStart,
 		&productCost.PeriodEnd,
 		&productCost.UnitCostToWarehouse,
 		&productCost.Notes,
+		&productCost.SourceOrderID,
 		&productCost.CreatedBy,
 		&productCost.CreatedAt,
 		&productCost.UpdatedBy,
@@ -151,6 +168,9 @@ func (r *ProductCostRepository) Create(ctx context.Context, productID uuid.UUID,
 
 	if err != nil {
 		errMsg := err.Error()
+		if strings.Contains(errMsg, "exclusion constraint") {
+			return nil, ErrProductCostOverlap
+		}
 		if strings.Contains(errMsg, "duplicate key") ||
 			strings.Contains(errMsg, "unique constraint") {
 			return nil, ErrProductCostExists
@@ -158,28 +178,45 @@ func (r *ProductCostRepository) Create(ctx context.Context, productID uuid.UUID,
 		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return &productCost, nil
 }
 
-func (r *ProductCostRepository) Update(ctx context.Context, costID uuid.UUID, productID uuid.UUID, periodStart, periodEnd time.Time, unitCostToWarehouse float64, notes *string, updatedBy *uuid.UUID) (*ProductCost, error) {
+// Update replaces a cost period. Periods listed in trimIDs are shortened to end the day before periodStart
+// in the same transaction.
+func (r *ProductCostRepository) Update(ctx context.Context, costID uuid.UUID, productID uuid.UUID, periodStart, periodEnd time.Time, unitCostToWarehouse float64, notes *string, updatedBy *uuid.UUID, trimIDs []uuid.UUID) (*ProductCost, error) {
 	query := `
 		UPDATE product_costs
 		SET product_id = $1, period_start = $2, period_end = $3, unit_cost_to_warehouse = $4, notes = $5, updated_by = $6, updated_at = NOW()
 		WHERE cost_id = $7
-		RETURNING cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, created_by, created_at, updated_by, updated_at
+		RETURNING cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, source_order_id, created_by, created_at, updated_by, updated_at
 	`
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := trimPeriodEnds(ctx, tx, trimIDs, periodStart.AddDate(0, 0, -1), updatedBy); err != nil {
+		return nil, err
+	}
+
 	var productCost ProductCost
-	err := r.pool.QueryRow(ctx, query, productID, periodStart, periodEnd, unitCostToWarehouse, notes, updatedBy, costID).Scan(
+	err = tx.QueryRow(ctx, query, productID, periodStart, periodEnd, unitCostToWarehouse, notes, updatedBy, costID).Scan(
 		&productCost.CostID,
 		&productCost.ProductID,
 		&productCost.PeriodStart,
 		&productCost.PeriodEnd,
 		&productCost.UnitCostToWarehouse,
 		&productCost.Notes,
+		&productCost.SourceOrderID,
 		&productCost.CreatedBy,
 		&productCost.CreatedAt,
 		&productCost.UpdatedBy,
@@ -191,6 +228,9 @@ func (r *ProductCostRepository) Update(ctx context.Context, costID uuid.UUID, pr
 			return nil, ErrProductCostNotFound
 		}
 		errMsg := err.Error()
+		if strings.Contains(errMsg, "exclusion constraint") {
+			return nil, ErrProductCostOverlap
+		}
 		if strings.Contains(errMsg, "duplicate key") ||
 			strings.Contains(errMsg, "unique constraint") {
 			return nil, ErrProductCostExists
@@ -198,6 +238,10 @@ func (r *ProductCostRepository) Update(ctx context.Context, costID uuid.UUID, pr
 		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return &productCost, nil
 }
 
@@ -221,3 +265,121 @@ func (r *ProductCostRepository) Delete(ctx context.Context, costID uuid.UUID) er
 
 	return nil
 }
+
+// FindOverlapping returns the product's periods intersecting [periodStart, periodEnd], optionally excluding one period.
+func (r *ProductCostRepository) FindOverlapping(ctx context.Context, productID uuid.UUID, periodStart, periodEnd time.Time, excludeID *uuid.UUID) ([]ProductCost, error) {
+	query := `
+		SELECT cost_id, product_id, period_start, period_end, unit_cost_to_warehouse, notes, source_order_id, created_by, created_at, updated_by, updated_at
+		FROM product_costs
+		WHERE product_id = $1
+		  AND period_start <= $3
+		  AND period_end >= $2
+		  AND ($4::uuid IS NULL OR cost_id <> $4)
+		ORDER BY period_start
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, productID, periodStart, periodEnd, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var costs []ProductCost
+	for rows.Next() {
+		var cost ProductCost
+		if err := rows.Scan(
+			&cost.CostID,
+			&cost.ProductID,
+			&cost.PeriodStart,
+			&cost.PeriodEnd,
+			&cost.UnitCostToWarehouse,
+			&cost.Notes,
+			&cost.SourceOrderID,
+			&cost.CreatedBy,
+			&cost.CreatedAt,
+			&cost.UpdatedBy,
+			&cost.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		costs = append(costs, cost)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return costs, nil
+}
+
+func trimPeriodEnds(ctx context.Context, tx pgx.Tx, costIDs []uuid.UUID, periodEnd time.Time, updatedBy *uuid.UUID) error {
+	for _, costID := range costIDs {
+		result, err := tx.Exec(ctx, `
+			UPDATE product_costs
+			SET period_end = $1, updated_by = $2, updated_at = NOW()
+			WHERE cost_id = $3
+		`, periodEnd, updatedBy, costID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrProductCostNotFound
+		}
+	}
+	return nil
+}
+
+// ProductCostPeriod is a cost period of a product within a coverage range; PeriodStart/PeriodEnd are nil
+// for products without any period in the range.
+type ProductCostPeriod struct {
+	ProductID   uuid.UUID
+	Article     string
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+}
+
+// GetCoveragePeriods returns every product (or only productID) with its cost periods intersecting [dateFrom, dateTo].
+func (r *ProductCostRepository) GetCoveragePeriods(ctx context.Context, dateFrom, dateTo time.Time, productID *uuid.UUID) ([]ProductCostPeriod, error) {
+	query := `
+		SELECT p.product_id, p.article, pc.period_start, pc.period_end
+		FROM products p
+		LEFT JOIN product_costs pc
+		    ON pc.product_id = p.product_id
+		   AND pc.period_start <= $2
+		   AND pc.period_end >= $1
+		WHERE $3::uuid IS NULL OR p.product_id = $3
+		ORDER BY p.article, p.product_id, pc.period_start
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, dateFrom, dateTo, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []ProductCostPeriod
+	for rows.Next() {
+		var period ProductCostPeriod
+		if err := rows.Scan(
+			&period.ProductID,
+			&period.Article,
+			&period.PeriodStart,
+			&period.PeriodEnd,
+		); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
@@ -14,6 +14,7 @@ import (
 var (
 	ErrProductNotFound = errors.New("product not found")
 	ErrProductExists   = errors.New("product already exists")
+	ErrProductArchived = errors.New("product is archived")
 )
 
 type Product struct {
@@ -24,6 +25,8 @@ type Product struct {
 	UnitCost       *float64
 	PurchasePrice  *float64
 	ProcessingPrice *float64
+	IsArchived     bool
+	ArchivedAt     *time.Time
 }
 
 type ProductRepository struct {
@@ -36,7 +39,7 @@ func NewProductRepository(pool *pgxpool.Pool) *ProductRepository {
 
 func (r *ProductRepository) GetByID(ctx context.Context, productID uuid.UUID) (*Product, error) {
 	query := `
-		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price
+		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price, is_archived, archived_at
 		FROM products
 		WHERE product_id = $1
 	`
@@ -53,6 +56,8 @@ func (r *ProductRepository) GetByID(ctx context.Context, productID uuid.UUID) (*
 		&product.UnitCost,
 		&product.PurchasePrice,
 		&product.ProcessingPrice,
+		&product.IsArchived,
+		&product.ArchivedAt,
 	)
 
 	if err != nil {
@@ -67,7 +72,7 @@ func (r *ProductRepository) GetByID(ctx context.Context, productID uuid.UUID) (*
 
 func (r *ProductRepository) GetByArticle(ctx context.Context, article string) (*Product, error) {
 	query := `
-		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price
+		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price, is_archived, archived_at
 		FROM products
 		WHERE article = $1
 	`
@@ -84,6 +89,8 @@ func (r *ProductRepository) GetByArticle(ctx context.Context, article string) (*
 		&product.UnitCost,
 		&product.PurchasePrice,
 		&product.ProcessingPrice,
+		&product.IsArchived,
+		&product.ArchivedAt,
 	)
 
 	if err != nil {
@@ -98,7 +105,7 @@ func (r *ProductRepository) GetByArticle(ctx context.Context, article string) (*
 
 func (r *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (*Product, error) {
 	query := `
-		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price
+		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price, is_archived, archived_at
 		FROM products
 		WHERE barcode = $1
 	`
@@ -115,6 +122,8 @@ func (r *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (*
 		&product.UnitCost,
 		&product.PurchasePrice,
 		&product.ProcessingPrice,
+		&product.IsArchived,
+		&product.ArchivedAt,
 	)
 
 	if err != nil {
@@ -127,10 +136,11 @@ func (r *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (*
 	return &product, nil
 }
 
-func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]Product, error) {
+func (r *ProductRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]Product, error) {
 	query := `
-		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price
+		SELECT product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price, is_archived, archived_at
 		FROM products
+		WHERE $3 OR NOT is_archived
 		ORDER BY product_id
 		LIMIT $1 OFFSET $2
 	`
@@ -138,7 +148,7 @@ func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]Prod
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +165,8 @@ func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]Prod
 			&product.UnitCost,
 			&product.PurchasePrice,
 			&product.ProcessingPrice,
+			&product.IsArchived,
+			&product.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -172,7 +184,7 @@ func (r *ProductRepository) Create(ctx context.Context, article, barcode string,
 	query := `
 		INSERT INTO products (article, barcode, unit_weight, unit_cost, purchase_price, processing_price)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price
+		RETURNING product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -187,6 +199,8 @@ func (r *ProductRepository) Create(ctx context.Context, article, barcode string,
 		&product.UnitCost,
 		&product.PurchasePrice,
 		&product.ProcessingPrice,
+		&product.IsArchived,
+		&product.ArchivedAt,
 	)
 
 	if err != nil {
@@ -208,7 +222,7 @@ func (r *ProductRepository) Update(ctx context.Context, productID uuid.UUID, art
 		UPDATE products
 		SET article = $1, barcode = $2, unit_weight = $3, unit_cost = $4, purchase_price = $5, processing_price = $6
 		WHERE product_id = $7
-		RETURNING product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price
+		RETURNING product_id, article, barcode, unit_weight, unit_cost, purchase_price, processing_price, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -223,6 +237,8 @@ func (r *ProductRepository) Update(ctx context.Context, productID uuid.UUID, art
 		&product.UnitCost,
 		&product.PurchasePrice,
 		&product.ProcessingPrice,
+		&product.IsArchived,
+		&product.ArchivedAt,
 	)
 
 	if err != nil {
@@ -240,9 +256,34 @@ func (r *ProductRepository) Update(ctx context.Context, productID uuid.UUID, art
 	return &product, nil
 }
 
-func (r *ProductRepository) Delete(ctx context.Context, productID uuid.UUID) error {
+// Archive hides the product from lists and new documents instead of deleting it, so existing documents and
+// stock history keep their references. Archiving an archived product keeps the original archived_at.
+func (r *ProductRepository) Archive(ctx context.Context, productID uuid.UUID) error {
 	query := `
-		DELETE FROM products
+		UPDATE products
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE product_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, productID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+func (r *ProductRepository) Restore(ctx context.Context, productID uuid.UUID) error {
+	query := `
+		UPDATE products
+		SET is_archived = FALSE, archived_at = NULL
 		WHERE product_id = $1
 	`
 
@@ -15,11 +15,33 @@ import (
 var (
 	ErrShipmentStatusNotFound = errors.New("shipment status not found")
 	ErrShipmentStatusExists   = errors.New("shipment status already exists")
+	ErrShipmentStatusArchived = errors.New("shipment status is archived")
 )
 
+// Названия статусов отгрузок, на которые опирается бизнес-логика
+const (
+	ShipmentStatusCreated   = "Создан"
+	ShipmentStatusSent      = "Отправлен"
+	ShipmentStatusInTransit = "В пути"
+	ShipmentStatusAccepted  = "Принят"
+	ShipmentStatusRejected  = "Отклонен"
+)
+
+// IsShipmentDispatched reports whether a shipment in the status has left the warehouse: from "Отправлен"
+// on its sent quantity is deducted from stock on the shipment date.
+func IsShipmentDispatched(statusName string) bool {
+	switch statusName {
+	case ShipmentStatusSent, ShipmentStatusInTransit, ShipmentStatusAccepted, ShipmentStatusRejected:
+		return true
+	}
+	return false
+}
+
 type ShipmentStatus struct {
 	ShipmentStatusID uuid.UUID
 	Name             string
+	IsArchived       bool
+	ArchivedAt       *time.Time
 }
 
 type ShipmentStatusRepository struct {
@@ -32,7 +54,7 @@ func NewShipmentStatusRepository(pool *pgxpool.Pool) *ShipmentStatusRepository {
 
 func (r *ShipmentStatusRepository) GetByID(ctx context.Context, statusID uuid.UUID) (*ShipmentStatus, error) {
 	query := `
-		SELECT shipment_status_id, name
+		SELECT shipment_status_id, name, is_archived, archived_at
 		FROM shipment_statuses
 		WHERE shipment_status_id = $1
 	`
@@ -44,6 +66,8 @@ func (r *ShipmentStatusRepository) GetByID(ctx context.Context, statusID uuid.UU
 	err := r.pool.QueryRow(ctx, query, statusID).Scan(
 		&status.ShipmentStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -56,10 +80,39 @@ func (r *ShipmentStatusRepository) GetByID(ctx context.Context, statusID uuid.UU
 	return &status, nil
 }
 
-func (r *ShipmentStatusRepository) List(ctx context.Context, limit, offset int) ([]ShipmentStatus, error) {
+func (r *ShipmentStatusRepository) GetByName(ctx context.Context, name string) (*ShipmentStatus, error) {
+	query := `
+		SELECT shipment_status_id, name, is_archived, archived_at
+		FROM shipment_statuses
+		WHERE name = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var status ShipmentStatus
+	err := r.pool.QueryRow(ctx, query, name).Scan(
+		&status.ShipmentStatusID,
+		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShipmentStatusNotFound
+		}
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func (r *ShipmentStatusRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]ShipmentStatus, error) {
 	query := fmt.Sprintf(`
-		SELECT shipment_status_id, name
+		SELECT shipment_status_id, name, is_archived, archived_at
 		FROM shipment_statuses
+		WHERE $3 OR NOT is_archived
 		ORDER BY shipment_status_id
 		LIMIT $1 OFFSET $2
 	`)
@@ -67,7 +120,7 @@ func (r *ShipmentStatusRepository) List(ctx context.Context, limit, offset int)
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +132,8 @@ func (r *ShipmentStatusRepository) List(ctx context.Context, limit, offset int)
 		if err := rows.Scan(
 			&status.ShipmentStatusID,
 			&status.Name,
+			&status.IsArchived,
+			&status.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -96,7 +151,7 @@ func (r *ShipmentStatusRepository) Create(ctx context.Context, name string) (*Sh
 	query := `
 		INSERT INTO shipment_statuses (name)
 		VALUES ($1)
-		RETURNING shipment_status_id, name
+		RETURNING shipment_status_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -106,6 +161,8 @@ func (r *ShipmentStatusRepository) Create(ctx context.Context, name string) (*Sh
 	err := r.pool.QueryRow(ctx, query, name).Scan(
 		&status.ShipmentStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -126,7 +183,7 @@ func (r *ShipmentStatusRepository) Update(ctx context.Context, statusID uuid.UUI
 		UPDATE shipment_statuses
 		SET name = $1
 		WHERE shipment_status_id = $2
-		RETURNING shipment_status_id, name
+		RETURNING shipment_status_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -136,6 +193,8 @@ func (r *ShipmentStatusRepository) Update(ctx context.Context, statusID uuid.UUI
 	err := r.pool.QueryRow(ctx, query, name, statusID).Scan(
 		&status.ShipmentStatusID,
 		&status.Name,
+		&status.IsArchived,
+		&status.ArchivedAt,
 	)
 
 	if err != nil {
@@ -153,9 +212,34 @@ func (r *ShipmentStatusRepository) Update(ctx context.Context, statusID uuid.UUI
 	return &status, nil
 }
 
-func (r *ShipmentStatusRepository) Delete(ctx context.Context, statusID uuid.UUID) error {
+// Archive hides the status from lists and new documents instead of deleting it, so existing documents and
+// stock history keep their references. Archiving an archived status keeps the original archived_at.
+func (r *ShipmentStatusRepository) Archive(ctx context.Context, statusID uuid.UUID) error {
+	query := `
+		UPDATE shipment_statuses
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE shipment_status_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, statusID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrShipmentStatusNotFound
+	}
+
+	return nil
+}
+
+func (r *ShipmentStatusRepository) Restore(ctx context.Context, statusID uuid.UUID) error {
 	query := `
-		DELETE FROM shipment_statuses
+		UPDATE shipment_statuses
+		SET is_archived = FALSE, archived_at = NULL
 		WHERE shipment_status_id = $1
 	`
 
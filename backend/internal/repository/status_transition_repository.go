@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("status transition is not allowed")
+	ErrStatusConflict          = errors.New("status was changed concurrently")
+)
+
+// Типы документов, для которых настраиваются переходы статусов
+const (
+	StatusEntitySupplierOrder = "supplier_order"
+	StatusEntityMpShipment    = "mp_shipment"
+	StatusEntityInventory     = "inventory"
+	StatusEntityTransfer      = "transfer"
+	StatusEntityMpReturn      = "mp_return"
+	StatusEntityLocationMove  = "location_move"
+)
+
+type StatusTransitionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatusTransitionRepository(pool *pgxpool.Pool) *StatusTransitionRepository {
+	return &StatusTransitionRepository{pool: pool}
+}
+
+func (r *StatusTransitionRepository) IsAllowed(ctx context.Context, entityType, fromStatus, toStatus string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM status_transitions
+			WHERE entity_type = $1 AND from_status = $2 AND to_status = $3
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var allowed bool
+	if err := r.pool.QueryRow(ctx, query, entityType, fromStatus, toStatus).Scan(&allowed); err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+// IsTarget reports whether some transition of the document type leads to status. A status no transition leads
+// to is an initial one: documents are created in it.
+func (r *StatusTransitionRepository) IsTarget(ctx context.Context, entityType, status string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM status_transitions
+			WHERE entity_type = $1 AND to_status = $2
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var target bool
+	if err := r.pool.QueryRow(ctx, query, entityType, status).Scan(&target); err != nil {
+		return false, err
+	}
+
+	return target, nil
+}
+
+// ListTargets returns the statuses a document of the given type can move to from fromStatus.
+func (r *StatusTransitionRepository) ListTargets(ctx context.Context, entityType, fromStatus string) ([]string, error) {
+	query := `
+		SELECT to_status
+		FROM status_transitions
+		WHERE entity_type = $1 AND from_status = $2
+		ORDER BY to_status
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, entityType, fromStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []string
+	for rows.Next() {
+		var target string
+		if err := rows.Scan(&target); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+type StatusHistoryEntry struct {
+	HistoryID      uuid.UUID
+	EntityType     string
+	EntityID       uuid.UUID
+	FromStatus     *string
+	ToStatus       string
+	Comment        *string
+	ChangedBy      *uuid.UUID
+	ChangedByEmail *string
+	ChangedAt      time.Time
+}
+
+func (r *StatusTransitionRepository) RecordHistory(ctx context.Context, entityType string, entityID uuid.UUID, fromStatus *string, toStatus string, comment *string, changedBy *uuid.UUID) error {
+	query := `
+		INSERT INTO status_history (entity_type, entity_id, from_status, to_status, comment, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, query, entityType, entityID, fromStatus, toStatus, comment, changedBy)
+	return err
+}
+
+// GetHistory returns status changes of the document in chronological order.
+func (r *StatusTransitionRepository) GetHistory(ctx context.Context, entityType string, entityID uuid.UUID) ([]StatusHistoryEntry, error) {
+	query := `
+		SELECT h.history_id, h.entity_type, h.entity_id, h.from_status, h.to_status,
+		       h.comment, h.changed_by, u.email, h.changed_at
+		FROM status_history h
+		LEFT JOIN users u ON u.user_id = h.changed_by
+		WHERE h.entity_type = $1 AND h.entity_id = $2
+		ORDER BY h.changed_at, h.history_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []StatusHistoryEntry
+	for rows.Next() {
+		var entry StatusHistoryEntry
+		if err := rows.Scan(
+			&entry.HistoryID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.Comment,
+			&entry.ChangedBy,
+			&entry.ChangedByEmail,
+			&entry.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrStockLevelNotFound   = errors.New("stock level not found")
+	ErrStockLevelExists     = errors.New("stock level already exists")
+	ErrInvalidStockLevel    = errors.New("invalid stock level")
+	ErrNoReorderSuggestions = errors.New("no reorder suggestions")
+)
+
+// StockLevel - минимальный и максимальный остаток товара на складе
+type StockLevel struct {
+	LevelID     uuid.UUID
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	MinQty      int
+	MaxQty      int
+	CreatedBy   *uuid.UUID
+	CreatedAt   time.Time
+	UpdatedBy   *uuid.UUID
+	UpdatedAt   time.Time
+}
+
+// ReorderSuggestion is a stock level whose current stock plus the undelivered quantity of open supplier
+// orders (ProjectedQty) is below MinQty; SuggestedQty brings the projection up to MaxQty.
+type ReorderSuggestion struct {
+	LevelID       uuid.UUID
+	ProductID     uuid.UUID
+	Article       string
+	WarehouseID   uuid.UUID
+	MinQty        int
+	MaxQty        int
+	CurrentQty    int
+	IncomingQty   int
+	ProjectedQty  int
+	SuggestedQty  int
+	PurchasePrice *float64
+	UnitWeight    int
+}
+
+type StockLevelRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStockLevelRepository(pool *pgxpool.Pool) *StockLevelRepository {
+	return &StockLevelRepository{pool: pool}
+}
+
+const stockLevelColumns = `level_id, product_id, warehouse_id, min_qty, max_qty, created_by, created_at, updated_by, updated_at`
+
+func scanStockLevel(row pgx.Row, level *StockLevel) error {
+	return row.Scan(
+		&level.LevelID,
+		&level.ProductID,
+		&level.WarehouseID,
+		&level.MinQty,
+		&level.MaxQty,
+		&level.CreatedBy,
+		&level.CreatedAt,
+		&level.UpdatedBy,
+		&level.UpdatedAt,
+	)
+}
+
+func stockLevelError(err error) error {
+	errMsg := err.Error()
+	if strings.Contains(errMsg, "check constraint") {
+		return ErrInvalidStockLevel
+	}
+	if strings.Contains(errMsg, "duplicate key") ||
+		strings.Contains(errMsg, "unique constraint") {
+		return ErrStockLevelExists
+	}
+	return err
+}
+
+func (r *StockLevelRepository) GetByID(ctx context.Context, levelID uuid.UUID) (*StockLevel, error) {
+	query := `
+		SELECT ` + stockLevelColumns + `
+		FROM stock_levels
+		WHERE level_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var level StockLevel
+	err := scanStockLevel(r.pool.QueryRow(ctx, query, levelID), &level)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStockLevelNotFound
+		}
+		return nil, err
+	}
+
+	return &level, nil
+}
+
+func (r *StockLevelRepository) List(ctx context.Context, limit, offset int, productID, warehouseID *uuid.UUID) ([]StockLevel, error) {
+	query := `
+		SELECT ` + stockLevelColumns + `
+		FROM stock_levels
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argPos := 1
+
+	if productID != nil {
+		query += fmt.Sprintf(" AND product_id = $%d", argPos)
+		args = append(args, *productID)
+		argPos++
+	}
+	if warehouseID != nil {
+		query += fmt.Sprintf(" AND warehouse_id = $%d", argPos)
+		args = append(args, *warehouseID)
+		argPos++
+	}
+
+	query += fmt.Sprintf(" ORDER BY level_id LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var levels []StockLevel
+	for rows.Next() {
+		var level StockLevel
+		if err := scanStockLevel(rows, &level); err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+func (r *StockLevelRepository) Create(ctx context.Context, productID, warehouseID uuid.UUID, minQty, maxQty int, createdBy *uuid.UUID) (*StockLevel, error) {
+	query := `
+		INSERT INTO stock_levels (product_id, warehouse_id, min_qty, max_qty, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + stockLevelColumns
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var level StockLevel
+	err := scanStockLevel(r.pool.QueryRow(ctx, query, productID, warehouseID, minQty, maxQty, createdBy), &level)
+	if err != nil {
+		return nil, stockLevelError(err)
+	}
+
+	return &level, nil
+}
+
+func (r *StockLevelRepository) Update(ctx context.Context, levelID, productID, warehouseID uuid.UUID, minQty, maxQty int, updatedBy *uuid.UUID) (*StockLevel, error) {
+	query := `
+		UPDATE stock_levels
+		SET product_id = $1, warehouse_id = $2, min_qty = $3, max_qty = $4, updated_by = $5, updated_at = NOW()
+		WHERE level_id = $6
+		RETURNING ` + stockLevelColumns
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var level StockLevel
+	err := scanStockLevel(r.pool.QueryRow(ctx, query, productID, warehouseID, minQty, maxQty, updatedBy, levelID), &level)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStockLevelNotFound
+		}
+		return nil, stockLevelError(err)
+	}
+
+	return &level, nil
+}
+
+func (r *StockLevelRepository) Delete(ctx context.Context, levelID uuid.UUID) error {
+	query := `
+		DELETE FROM stock_levels
+		WHERE level_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, levelID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStockLevelNotFound
+	}
+
+	return nil
+}
+
+// GetReorderSuggestions compares current stock from vw_current_stock plus the undelivered remainder of
+// supplier orders that are neither received nor cancelled (drafts included, so an order created from
+// suggestions is not suggested again) against the stock levels. Archived products and warehouses are skipped.
+func (r *StockLevelRepository) GetReorderSuggestions(ctx context.Context, warehouseID, productID *uuid.UUID) ([]ReorderSuggestion, error) {
+	query := `
+		WITH stock AS (
+			SELECT product_id, warehouse_id, SUM(current_quantity)::int AS quantity
+			FROM vw_current_stock
+			GROUP BY product_id, warehouse_id
+		),
+		incoming AS (
+			SELECT i.product_id, i.warehouse_id, SUM(GREATEST(i.ordered_qty - i.received_qty, 0))::int AS quantity
+			FROM supplier_order_items i
+			JOIN supplier_orders o ON o.order_id = i.order_id
+			LEFT JOIN order_statuses os ON os.order_status_id = o.status_id
+			WHERE i.product_id IS NOT NULL
+			  AND (os.name IS NULL OR os.name NOT IN ($3, $4))
+			GROUP BY i.product_id, i.warehouse_id
+		)
+		SELECT l.level_id, l.product_id, p.article, l.warehouse_id, l.min_qty, l.max_qty,
+		       COALESCE(st.quantity, 0), COALESCE(inc.quantity, 0),
+		       p.purchase_price, p.unit_weight
+		FROM stock_levels l
+		JOIN products p ON p.product_id = l.product_id
+		JOIN warehouses w ON w.warehouse_id = l.warehouse_id
+		LEFT JOIN stock st ON st.product_id = l.product_id AND st.warehouse_id = l.warehouse_id
+		LEFT JOIN incoming inc ON inc.product_id = l.product_id AND inc.warehouse_id = l.warehouse_id
+		WHERE NOT p.is_archived
+		  AND NOT w.is_archived
+		  AND COALESCE(st.quantity, 0) + COALESCE(inc.quantity, 0) < l.min_qty
+		  AND ($1::uuid IS NULL OR l.warehouse_id = $1)
+		  AND ($2::uuid IS NULL OR l.product_id = $2)
+		ORDER BY p.article, l.warehouse_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, warehouseID, productID, OrderStatusReceived, OrderStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []ReorderSuggestion
+	for rows.Next() {
+		var suggestion ReorderSuggestion
+		if err := rows.Scan(
+			&suggestion.LevelID,
+			&suggestion.ProductID,
+			&suggestion.Article,
+			&suggestion.WarehouseID,
+			&suggestion.MinQty,
+			&suggestion.MaxQty,
+			&suggestion.CurrentQty,
+			&suggestion.IncomingQty,
+			&suggestion.PurchasePrice,
+			&suggestion.UnitWeight,
+		); err != nil {
+			return nil, err
+		}
+		suggestion.ProjectedQty = suggestion.CurrentQty + suggestion.IncomingQty
+		suggestion.SuggestedQty = suggestion.MaxQty - suggestion.ProjectedQty
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
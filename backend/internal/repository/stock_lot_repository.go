@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Движения, которые двигают партии по FIFO
+const (
+	LotMovementShipment       = "MP_SHIPMENT"
+	LotMovementShipmentReturn = "MP_SHIPMENT_RETURN"
+	LotMovementWriteOff       = "WRITE_OFF"
+	LotMovementTransferOut    = "TRANSFER_OUT"
+	LotMovementTransferIn     = "TRANSFER_IN"
+)
+
+// StockLot - партия товара из позиции полученного заказа поставщику. Начальная партия (Opening) - остаток
+// пары товар/склад по самому раннему снапшоту: у нее нет заказа и себестоимости, LotID - идентификатор снапшота
+type StockLot struct {
+	LotID       uuid.UUID
+	OrderID     uuid.UUID
+	OrderNumber string
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	ReceiptDate time.Time
+	Quantity    int
+	UnitCost    *float64
+	Opening     bool
+}
+
+// LotMovement is a stock movement that consumes lots (shipment, write-off, transfer out) or brings consumed
+// lots back (shipment return, transfer in). Quantity is always positive; ItemID is the document item the
+// movement belongs to, so that returns and transfer receipts find what their item consumed.
+type LotMovement struct {
+	MovementType string
+	ProductID    uuid.UUID
+	WarehouseID  uuid.UUID
+	MovementDate time.Time
+	Quantity     int
+	DocumentID   uuid.UUID
+	ItemID       uuid.UUID
+}
+
+// LotShipment - отгрузка на маркетплейс, уже списанная с остатка
+type LotShipment struct {
+	ShipmentID     uuid.UUID
+	ShipmentNumber string
+	ShipmentDate   time.Time
+	StoreID        *uuid.UUID
+	WarehouseID    *uuid.UUID
+}
+
+type StockLotRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStockLotRepository(pool *pgxpool.Pool) *StockLotRepository {
+	return &StockLotRepository{pool: pool}
+}
+
+// GetLots returns the lots of the product (of all products if productID is nil) in FIFO order.
+func (r *StockLotRepository) GetLots(ctx context.Context, productID *uuid.UUID) ([]StockLot, error) {
+	query := `
+		SELECT lot_id, order_id, order_number, product_id, warehouse_id, receipt_date, quantity, unit_cost
+		FROM vw_stock_lots
+		WHERE $1::uuid IS NULL OR product_id = $1
+		ORDER BY receipt_date, order_number, lot_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []StockLot
+	for rows.Next() {
+		var lot StockLot
+		if err := rows.Scan(
+			&lot.LotID,
+			&lot.OrderID,
+			&lot.OrderNumber,
+			&lot.ProductID,
+			&lot.WarehouseID,
+			&lot.ReceiptDate,
+			&lot.Quantity,
+			&lot.UnitCost,
+		); err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GetOpeningLots returns the earliest snapshot of every product/warehouse pair of the product (of all products
+// if productID is nil) as an opening lot, ordered by snapshot date.
+func (r *StockLotRepository) GetOpeningLots(ctx context.Context, productID *uuid.UUID) ([]StockLot, error) {
+	query := `
+		SELECT snapshot_id, product_id, warehouse_id, snapshot_date, quantity
+		FROM (
+			SELECT DISTINCT ON (product_id, warehouse_id)
+				snapshot_id, product_id, warehouse_id, snapshot_date, quantity
+			FROM stock_snapshots
+			WHERE $1::uuid IS NULL OR product_id = $1
+			ORDER BY product_id, warehouse_id, snapshot_date
+		) earliest
+		ORDER BY snapshot_date, product_id, warehouse_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []StockLot
+	for rows.Next() {
+		lot := StockLot{Opening: true}
+		if err := rows.Scan(
+			&lot.LotID,
+			&lot.ProductID,
+			&lot.WarehouseID,
+			&lot.ReceiptDate,
+			&lot.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GetLotMovements returns the movements that consume or bring back lots of the product (of all products if
+// productID is nil) ordered by date. On the same date consumptions go before returns, so that a transfer
+// received on the day it was shipped finds its lots.
+func (r *StockLotRepository) GetLotMovements(ctx context.Context, productID *uuid.UUID) ([]LotMovement, error) {
+	query := `
+		SELECT movement_type, product_id, warehouse_id, movement_date, quantity, document_id, item_id
+		FROM (
+			SELECT $2::text AS movement_type, 1 AS priority, msi.product_id, msi.warehouse_id,
+			       ms.shipment_date AS movement_date, msi.sent_qty AS quantity,
+			       ms.shipment_id AS document_id, msi.shipment_item_id AS item_id
+			FROM mp_shipment_items msi
+			JOIN mp_shipments ms ON ms.shipment_id = msi.shipment_id
+			JOIN shipment_statuses sst ON sst.shipment_status_id = ms.status_id
+			WHERE sst.name IN ($7, $8, $9, $10)
+			  AND ms.shipment_date IS NOT NULL
+			  AND msi.sent_qty > 0
+
+			UNION ALL
+
+			SELECT $3::text, 2, msi.product_id, msi.warehouse_id,
+			       ms.resolution_date, msi.returned_qty,
+			       ms.shipment_id, msi.shipment_item_id
+			FROM mp_shipment_items msi
+			JOIN mp_shipments ms ON ms.shipment_id = msi.shipment_id
+			WHERE ms.resolution_date IS NOT NULL
+			  AND msi.returned_qty > 0
+
+			UNION ALL
+
+			SELECT $4::text, 1, ii.product_id, ii.warehouse_id,
+			       i.adjustment_date, ii.write_off_qty,
+			       i.inventory_id, ii.inventory_item_id
+			FROM inventory_items ii
+			JOIN inventories i ON i.inventory_id = ii.inventory_id
+			JOIN inventory_statuses ist ON ist.inventory_status_id = i.status_id
+			WHERE ist.name = $11
+			  AND i.adjustment_date IS NOT NULL
+			  AND ii.product_id IS NOT NULL
+			  AND ii.write_off_qty > 0
+
+			UNION ALL
+
+			SELECT $5::text, 1, ti.product_id, t.source_warehouse_id,
+			       t.shipment_date, ti.shipped_qty,
+			       t.transfer_id, ti.transfer_item_id
+			FROM transfer_items ti
+			JOIN transfers t ON t.transfer_id = ti.transfer_id
+			WHERE t.status IN ($12, $13)
+			  AND t.shipment_date IS NOT NULL
+
+			UNION ALL
+
+			SELECT $6::text, 2, ti.product_id, t.destination_warehouse_id,
+			       t.receipt_date, ti.received_qty,
+			       t.transfer_id, ti.transfer_item_id
+			FROM transfer_items ti
+			JOIN transfers t ON t.transfer_id = ti.transfer_id
+			WHERE t.status = $13
+			  AND t.receipt_date IS NOT NULL
+			  AND ti.received_qty > 0
+		) m
+		WHERE $1::uuid IS NULL OR m.product_id = $1
+		ORDER BY m.movement_date, m.priority, m.item_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query,
+		productID,
+		LotMovementShipment,
+		LotMovementShipmentReturn,
+		LotMovementWriteOff,
+		LotMovementTransferOut,
+		LotMovementTransferIn,
+		ShipmentStatusSent,
+		ShipmentStatusInTransit,
+		ShipmentStatusAccepted,
+		ShipmentStatusRejected,
+		InventoryStatusCompleted,
+		TransferStatusInTransit,
+		TransferStatusReceived,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []LotMovement
+	for rows.Next() {
+		var movement LotMovement
+		if err := rows.Scan(
+			&movement.MovementType,
+			&movement.ProductID,
+			&movement.WarehouseID,
+			&movement.MovementDate,
+			&movement.Quantity,
+			&movement.DocumentID,
+			&movement.ItemID,
+		); err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// GetShipments returns the dispatched marketplace shipments with shipment date in the range (bounds are
+// optional), newest first.
+func (r *StockLotRepository) GetShipments(ctx context.Context, dateFrom, dateTo *time.Time, storeID *uuid.UUID) ([]LotShipment, error) {
+	query := `
+		SELECT ms.shipment_id, ms.shipment_number, ms.shipment_date, ms.store_id, ms.warehouse_id
+		FROM mp_shipments ms
+		JOIN shipment_statuses sst ON sst.shipment_status_id = ms.status_id
+		WHERE sst.name IN ($4, $5, $6, $7)
+		  AND ms.shipment_date IS NOT NULL
+		  AND ($1::date IS NULL OR ms.shipment_date >= $1)
+		  AND ($2::date IS NULL OR ms.shipment_date <= $2)
+		  AND ($3::uuid IS NULL OR ms.store_id = $3)
+		ORDER BY ms.shipment_date DESC, ms.shipment_number
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, dateFrom, dateTo, storeID,
+		ShipmentStatusSent, ShipmentStatusInTransit, ShipmentStatusAccepted, ShipmentStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shipments []LotShipment
+	for rows.Next() {
+		var shipment LotShipment
+		if err := rows.Scan(
+			&shipment.ShipmentID,
+			&shipment.ShipmentNumber,
+			&shipment.ShipmentDate,
+			&shipment.StoreID,
+			&shipment.WarehouseID,
+		); err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, shipment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
@@ -1,20 +1,100 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrInvalidMovementType = errors.New("invalid movement type")
+	ErrNegativeStock       = errors.New("operation would make stock negative")
+)
+
+// Типы движений из vw_stock_movements
+const (
+	MovementTypeSupplierReceipt     = "SUPPLIER_RECEIPT"
+	MovementTypeMpShipment          = "MP_SHIPMENT"
+	MovementTypeMpShipmentReturn    = "MP_SHIPMENT_RETURN"
+	MovementTypeInventoryAdjustment = "INVENTORY_ADJUSTMENT"
+	MovementTypeTransferOut         = "TRANSFER_OUT"
+	MovementTypeTransferIn          = "TRANSFER_IN"
+	MovementTypeMpReturn            = "MP_RETURN"
+)
+
+func IsValidMovementType(movementType string) bool {
+	switch movementType {
+	case MovementTypeSupplierReceipt, MovementTypeMpShipment, MovementTypeMpShipmentReturn, MovementTypeInventoryAdjustment,
+		MovementTypeTransferOut, MovementTypeTransferIn, MovementTypeMpReturn:
+		return true
+	}
+	return false
+}
+
 type StockItem struct {
 	ProductID       uuid.UUID
 	WarehouseID     uuid.UUID
 	CurrentQuantity int
 }
 
+type StockMovement struct {
+	MovementID     uuid.UUID
+	ProductID      *uuid.UUID
+	WarehouseID    uuid.UUID
+	MovementDate   time.Time
+	Quantity       int
+	MovementType   string
+	DocumentID     uuid.UUID
+	RunningBalance int
+}
+
+// StockAsOfItem is the quantity of a product in a warehouse at the end of AsOfDate:
+// the latest snapshot on or before that date plus movements after the snapshot up to the date.
+type StockAsOfItem struct {
+	ProductID         uuid.UUID
+	WarehouseID       uuid.UUID
+	SnapshotDate      *time.Time
+	SnapshotQuantity  int
+	MovementsQuantity int
+	Quantity          int
+}
+
+// StockChange - изменение остатка пары товар/склад документом
+type StockChange struct {
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	Quantity    int
+}
+
+// StockShortage - пара товар/склад, остаток которой изменение сделает отрицательным
+type StockShortage struct {
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	OnHand      int
+	Change      int
+	Projected   int
+}
+
+// StockMovementFilter narrows the ledger; AfterDate/AfterID is the keyset of the last row already returned.
+type StockMovementFilter struct {
+	ProductID    *uuid.UUID
+	WarehouseID  *uuid.UUID
+	MovementType *string
+	DocumentID   *uuid.UUID
+	DateFrom     *time.Time
+	DateTo       *time.Time
+	AfterDate    *time.Time
+	AfterID      *uuid.UUID
+}
+
 type StockRepository struct {
 	pool *pgxpool.Pool
 }
@@ -76,33 +156,615 @@ func (r *StockRepository) GetCurrentStock(
 	return result, nil
 }
 
-func (r *StockRepository) UpdateStockByInventoryItem(ctx context.Context, productID *uuid.UUID, warehouseID uuid.UUID, adjustmentDate *time.Time, createdBy *uuid.UUID) error {
-	if productID == nil || adjustmentDate == nil {
-		return nil
+// stockAsOfCTE defines stock_as_of: the stock of every product/warehouse pair at the end of date $1, i.e. its
+// latest snapshot on or before $1 plus the movements after that snapshot up to $1. Pairs without a snapshot
+// start from 0.
+const stockAsOfCTE = `
+		WITH base_stock AS (
+			SELECT DISTINCT ON (product_id, warehouse_id)
+				product_id,
+				warehouse_id,
+				snapshot_date,
+				quantity
+			FROM stock_snapshots
+			WHERE snapshot_date <= $1
+			ORDER BY product_id, warehouse_id, snapshot_date DESC
+		),
+		movements AS (
+			SELECT
+				m.product_id,
+				m.warehouse_id,
+				SUM(m.quantity) AS quantity
+			FROM vw_stock_movements m
+			LEFT JOIN base_stock bs
+				ON bs.product_id = m.product_id
+			   AND bs.warehouse_id = m.warehouse_id
+			WHERE m.product_id IS NOT NULL
+			  AND m.movement_date <= $1
+			  AND (bs.snapshot_date IS NULL OR m.movement_date > bs.snapshot_date)
+			GROUP BY m.product_id, m.warehouse_id
+		),
+		stock_as_of AS (
+			SELECT
+				COALESCE(bs.product_id, mv.product_id) AS product_id,
+				COALESCE(bs.warehouse_id, mv.warehouse_id) AS warehouse_id,
+				bs.snapshot_date,
+				COALESCE(bs.quantity, 0) AS snapshot_quantity,
+				COALESCE(mv.quantity, 0) AS movements_quantity
+			FROM base_stock bs
+			FULL OUTER JOIN movements mv
+				ON mv.product_id = bs.product_id
+			   AND mv.warehouse_id = bs.warehouse_id
+		)
+`
+
+// GetStockAsOf computes stock at the end of the given date. Like vw_current_stock it also
+// includes product/warehouse pairs that have movements but no snapshot yet (base quantity 0).
+func (r *StockRepository) GetStockAsOf(
+	ctx context.Context,
+	asOfDate time.Time,
+	warehouseID *uuid.UUID,
+	productID *uuid.UUID,
+	limit int,
+	offset int,
+) ([]StockAsOfItem, error) {
+
+	query := stockAsOfCTE + `
+		SELECT product_id, warehouse_id, snapshot_date, snapshot_quantity,
+		       movements_quantity, snapshot_quantity + movements_quantity AS quantity
+		FROM stock_as_of
+	`
+
+	args := []any{asOfDate}
+	argPos := 2
+	where := []string{}
+
+	if warehouseID != nil {
+		where = append(where, fmt.Sprintf("warehouse_id = $%d", argPos))
+		args = append(args, *warehouseID)
+		argPos++
 	}
+	if productID != nil {
+		where = append(where, fmt.Sprintf("product_id = $%d", argPos))
+		args = append(args, *productID)
+		argPos++
+	}
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	query += fmt.Sprintf(` ORDER BY product_id, warehouse_id LIMIT $%d OFFSET $%d`, argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []StockAsOfItem
+	for rows.Next() {
+		var item StockAsOfItem
+		if err := rows.Scan(
+			&item.ProductID,
+			&item.WarehouseID,
+			&item.SnapshotDate,
+			&item.SnapshotQuantity,
+			&item.MovementsQuantity,
+			&item.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
 
+// stockLedgerQuery opens the ledger CTE over vw_stock_movements with the running balance of every movement;
+// the caller appends the WHERE of the ledger and closes the CTE.
+const stockLedgerQuery = `
+		WITH ledger AS (
+			SELECT
+				m.movement_id,
+				m.product_id,
+				m.warehouse_id,
+				m.movement_date,
+				m.quantity,
+				m.movement_type,
+				m.document_id,
+				COALESCE(ss.quantity, 0) + SUM(m.quantity) OVER (
+					PARTITION BY m.product_id, m.warehouse_id, ss.snapshot_date
+					ORDER BY m.movement_date, m.movement_id
+				) AS running_balance
+			FROM vw_stock_movements m
+			LEFT JOIN LATERAL (
+				SELECT s.snapshot_date, s.quantity
+				FROM stock_snapshots s
+				WHERE s.product_id = m.product_id
+				  AND s.warehouse_id = m.warehouse_id
+				  AND s.snapshot_date < m.movement_date
+				ORDER BY s.snapshot_date DESC
+				LIMIT 1
+			) ss ON TRUE
+`
+
+// GetMovements returns the stock ledger from vw_stock_movements ordered by (movement_date, movement_id).
+// RunningBalance is the latest snapshot before the movement plus all movements after that snapshot
+// up to and including this one, i.e. the same arithmetic vw_current_stock uses. Product and warehouse
+// filters narrow the balance partitions; the remaining filters only hide rows and do not affect balances.
+func (r *StockRepository) GetMovements(ctx context.Context, filter StockMovementFilter, limit int) ([]StockMovement, error) {
+	ledgerWhere := []string{}
+	where := []string{}
+	args := []any{}
+	argPos := 1
+
+	if filter.ProductID != nil {
+		ledgerWhere = append(ledgerWhere, fmt.Sprintf("m.product_id = $%d", argPos))
+		args = append(args, *filter.ProductID)
+		argPos++
+	}
+	if filter.WarehouseID != nil {
+		ledgerWhere = append(ledgerWhere, fmt.Sprintf("m.warehouse_id = $%d", argPos))
+		args = append(args, *filter.WarehouseID)
+		argPos++
+	}
+	if filter.MovementType != nil {
+		where = append(where, fmt.Sprintf("movement_type = $%d", argPos))
+		args = append(args, *filter.MovementType)
+		argPos++
+	}
+	if filter.DocumentID != nil {
+		where = append(where, fmt.Sprintf("document_id = $%d", argPos))
+		args = append(args, *filter.DocumentID)
+		argPos++
+	}
+	if filter.DateFrom != nil {
+		where = append(where, fmt.Sprintf("movement_date >= $%d", argPos))
+		args = append(args, *filter.DateFrom)
+		argPos++
+	}
+	if filter.DateTo != nil {
+		where = append(where, fmt.Sprintf("movement_date <= $%d", argPos))
+		args = append(args, *filter.DateTo)
+		argPos++
+	}
+	if filter.AfterDate != nil && filter.AfterID != nil {
+		where = append(where, fmt.Sprintf("(movement_date, movement_id) > ($%d, $%d)", argPos, argPos+1))
+		args = append(args, *filter.AfterDate, *filter.AfterID)
+		argPos += 2
+	}
+
+	query := stockLedgerQuery
+	if len(ledgerWhere) > 0 {
+		query += ` WHERE ` + strings.Join(ledgerWhere, " AND ")
+	}
+	query += `
+		)
+		SELECT movement_id, product_id, warehouse_id, movement_date, quantity,
+		       movement_type, document_id, running_balance
+		FROM ledger
+	`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(` ORDER BY movement_date, movement_id LIMIT $%d`, argPos)
+	args = append(args, limit)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []StockMovement
+	for rows.Next() {
+		var movement StockMovement
+		if err := rows.Scan(
+			&movement.MovementID,
+			&movement.ProductID,
+			&movement.WarehouseID,
+			&movement.MovementDate,
+			&movement.Quantity,
+			&movement.MovementType,
+			&movement.DocumentID,
+			&movement.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetNegativeStock returns the pairs whose current stock in vw_current_stock is below zero.
+func (r *StockRepository) GetNegativeStock(ctx context.Context, warehouseID, productID *uuid.UUID) ([]StockItem, error) {
 	query := `
-		INSERT INTO stock_snapshots (product_id, warehouse_id, snapshot_date, quantity, created_by)
-		VALUES ($1, $2, $3, 
-			COALESCE(
-				(SELECT quantity FROM stock_snapshots 
-				 WHERE product_id = $1 AND warehouse_id = $2 
-				 ORDER BY snapshot_date DESC LIMIT 1),
-				0
-			),
-			$4
+		SELECT product_id, warehouse_id, current_quantity
+		FROM vw_current_stock
+		WHERE current_quantity < 0
+		  AND ($1::uuid IS NULL OR warehouse_id = $1)
+		  AND ($2::uuid IS NULL OR product_id = $2)
+		ORDER BY current_quantity, product_id, warehouse_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, warehouseID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []StockItem
+	for rows.Next() {
+		var item StockItem
+		if err := rows.Scan(&item.ProductID, &item.WarehouseID, &item.CurrentQuantity); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetNegativeStockMovements returns the outgoing movements after which the running balance of their pair was
+// below zero, i.e. the documents that took stock negative, newest first.
+func (r *StockRepository) GetNegativeStockMovements(ctx context.Context, warehouseID, productID *uuid.UUID, limit int) ([]StockMovement, error) {
+	query := stockLedgerQuery + `
+			WHERE m.product_id IS NOT NULL
+			  AND ($1::uuid IS NULL OR m.warehouse_id = $1)
+			  AND ($2::uuid IS NULL OR m.product_id = $2)
 		)
-		ON CONFLICT (product_id, warehouse_id, snapshot_date)
-		DO NOTHING
+		SELECT movement_id, product_id, warehouse_id, movement_date, quantity,
+		       movement_type, document_id, running_balance
+		FROM ledger
+		WHERE quantity < 0 AND running_balance < 0
+		ORDER BY movement_date DESC, movement_id DESC
+		LIMIT $3
 	`
 
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, warehouseID, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []StockMovement
+	for rows.Next() {
+		var movement StockMovement
+		if err := rows.Scan(
+			&movement.MovementID,
+			&movement.ProductID,
+			&movement.WarehouseID,
+			&movement.MovementDate,
+			&movement.Quantity,
+			&movement.MovementType,
+			&movement.DocumentID,
+			&movement.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindShortages returns the pairs whose current stock the changes would take below zero. Only decreasing
+// changes are checked: a document that reduces a deficit is never a shortage.
+func (r *StockRepository) FindShortages(ctx context.Context, changes []StockChange) ([]StockShortage, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	_, err := r.pool.Exec(ctx, query, *productID, warehouseID, adjustmentDate, createdBy)
-	return err
+	var shortages []StockShortage
+	for _, change := range changes {
+		if change.Quantity >= 0 {
+			continue
+		}
+
+		var onHand int
+		err := r.pool.QueryRow(ctx, `
+			SELECT COALESCE(SUM(current_quantity), 0)::int
+			FROM vw_current_stock
+			WHERE product_id = $1 AND warehouse_id = $2
+		`, change.ProductID, change.WarehouseID).Scan(&onHand)
+		if err != nil {
+			return nil, err
+		}
+
+		if projected := onHand + change.Quantity; projected < 0 {
+			shortages = append(shortages, StockShortage{
+				ProductID:   change.ProductID,
+				WarehouseID: change.WarehouseID,
+				OnHand:      onHand,
+				Change:      change.Quantity,
+				Projected:   projected,
+			})
+		}
+	}
+
+	return shortages, nil
+}
+
+type stockKey struct {
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+}
+
+// snapshotChange - сдвиг снапшота остатков на дату Date на величину корректировки инвентаризации
+type snapshotChange struct {
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	Date        time.Time
+	Delta       int
+}
+
+// inventoryStockDeltas sums receipt minus write-off of the items per product and warehouse.
+// Items without product and pairs whose adjustments cancel out are skipped.
+func inventoryStockDeltas(items []InventoryItem) map[stockKey]int {
+	deltas := make(map[stockKey]int)
+	for _, item := range items {
+		if item.ProductID == nil {
+			continue
+		}
+		deltas[stockKey{*item.ProductID, item.WarehouseID}] += item.ReceiptQty - item.WriteOffQty
+	}
+	for key, delta := range deltas {
+		if delta == 0 {
+			delete(deltas, key)
+		}
+	}
+	return deltas
 }
 
-func (r *StockRepository) RevertStockByInventoryItem(ctx context.Context, productID *uuid.UUID, warehouseID uuid.UUID, adjustmentDate *time.Time) error {
+// planInventorySnapshots returns the snapshot changes that apply deltas dated date: every snapshot of an affected
+// pair on or after date is shifted by the delta. No snapshot is created: snapshots count only the movements after
+// their date, so a snapshot on date would hide the movements posted later that day, while a pair without
+// a snapshot on or after date gets the adjustment from vw_stock_movements. Changes are ordered by pair and date.
+func planInventorySnapshots(date time.Time, deltas map[stockKey]int, snapshots []StockSnapshot) []snapshotChange {
+	var changes []snapshotChange
+	for _, snapshot := range snapshots {
+		key := stockKey{snapshot.ProductID, snapshot.WarehouseID}
+		delta, ok := deltas[key]
+		if !ok || snapshot.SnapshotDate.Before(date) {
+			continue
+		}
+		changes = append(changes, snapshotChange{
+			ProductID:   key.ProductID,
+			WarehouseID: key.WarehouseID,
+			Date:        snapshot.SnapshotDate,
+			Delta:       delta,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if c := bytes.Compare(a.ProductID[:], b.ProductID[:]); c != 0 {
+			return c < 0
+		}
+		if c := bytes.Compare(a.WarehouseID[:], b.WarehouseID[:]); c != 0 {
+			return c < 0
+		}
+		return a.Date.Before(b.Date)
+	})
+
+	return changes
+}
+
+// applyInventorySnapshots writes the adjustments of a completed inventory into stock_snapshots, so that snapshots
+// taken on or after the adjustment date include them. The adjustment of every pair is recorded in
+// inventory_snapshot_adjustments on the adjustment date to be reverted later, also when the pair has no snapshot
+// to shift yet; an inventory that was already applied is skipped.
+func applyInventorySnapshots(ctx context.Context, tx pgx.Tx, inventoryID uuid.UUID) error {
+	var adjustmentDate *time.Time
+	err := tx.QueryRow(ctx, `
+		SELECT adjustment_date
+		FROM inventories
+		WHERE inventory_id = $1
+		FOR UPDATE
+	`, inventoryID).Scan(&adjustmentDate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInventoryNotFound
+		}
+		return err
+	}
+	if adjustmentDate == nil {
+		return nil
+	}
+
+	var applied bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM inventory_snapshot_adjustments WHERE inventory_id = $1)
+	`, inventoryID).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT inventory_item_id, inventory_id, product_id, warehouse_id, receipt_qty, write_off_qty, reason
+		FROM inventory_items
+		WHERE inventory_id = $1
+	`, inventoryID)
+	if err != nil {
+		return err
+	}
+	var items []InventoryItem
+	for rows.Next() {
+		var item InventoryItem
+		if err := rows.Scan(
+			&item.InventoryItemID,
+			&item.InventoryID,
+			&item.ProductID,
+			&item.WarehouseID,
+			&item.ReceiptQty,
+			&item.WriteOffQty,
+			&item.Reason,
+		); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	deltas := inventoryStockDeltas(items)
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(deltas))
+	warehouseIDs := make([]uuid.UUID, 0, len(deltas))
+	for key := range deltas {
+		if err := lockStock(ctx, tx, key.ProductID, key.WarehouseID); err != nil {
+			return err
+		}
+		productIDs = append(productIDs, key.ProductID)
+		warehouseIDs = append(warehouseIDs, key.WarehouseID)
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT s.snapshot_id, s.product_id, s.warehouse_id, s.snapshot_date, s.quantity, s.created_by, s.created_at
+		FROM stock_snapshots s
+		JOIN UNNEST($1::uuid[], $2::uuid[]) AS k(product_id, warehouse_id)
+			ON k.product_id = s.product_id
+		   AND k.warehouse_id = s.warehouse_id
+		WHERE s.snapshot_date >= $3
+	`, productIDs, warehouseIDs, *adjustmentDate)
+	if err != nil {
+		return err
+	}
+	var snapshots []StockSnapshot
+	for rows.Next() {
+		var snapshot StockSnapshot
+		if err := rows.Scan(
+			&snapshot.SnapshotID,
+			&snapshot.ProductID,
+			&snapshot.WarehouseID,
+			&snapshot.SnapshotDate,
+			&snapshot.Quantity,
+			&snapshot.CreatedBy,
+			&snapshot.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, change := range planInventorySnapshots(*adjustmentDate, deltas, snapshots) {
+		_, err = tx.Exec(ctx, `
+			UPDATE stock_snapshots
+			SET quantity = quantity + $4
+			WHERE product_id = $1 AND warehouse_id = $2 AND snapshot_date = $3
+		`, change.ProductID, change.WarehouseID, change.Date, change.Delta)
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, delta := range deltas {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO inventory_snapshot_adjustments (inventory_id, product_id, warehouse_id, snapshot_date, delta)
+			VALUES ($1, $2, $3, $4, $5)
+		`, inventoryID, key.ProductID, key.WarehouseID, *adjustmentDate, delta)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// revertInventorySnapshots takes the inventory's delta back out of every snapshot of an affected pair dated on or
+// after the adjustment date and forgets the recorded changes. This also covers snapshots taken after the inventory
+// was applied (month-end job, manual snapshots), which already include it.
+func revertInventorySnapshots(ctx context.Context, tx pgx.Tx, inventoryID uuid.UUID) error {
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT product_id, warehouse_id
+		FROM inventory_snapshot_adjustments
+		WHERE inventory_id = $1
+	`, inventoryID)
+	if err != nil {
+		return err
+	}
+	var keys []stockKey
+	for rows.Next() {
+		var key stockKey
+		if err := rows.Scan(&key.ProductID, &key.WarehouseID); err != nil {
+			rows.Close()
+			return err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := lockStock(ctx, tx, key.ProductID, key.WarehouseID); err != nil {
+			return err
+		}
+	}
+
+	// на пару записана одна корректировка на дату инвентаризации; MIN оставлен для записей, сделанных
+	// по каждому сдвинутому снапшоту
+	_, err = tx.Exec(ctx, `
+		UPDATE stock_snapshots s
+		SET quantity = s.quantity - a.delta
+		FROM (
+			SELECT product_id, warehouse_id, MIN(snapshot_date) AS adjustment_date, MIN(delta) AS delta
+			FROM inventory_snapshot_adjustments
+			WHERE inventory_id = $1
+			GROUP BY product_id, warehouse_id
+		) a
+		WHERE s.product_id = a.product_id
+		  AND s.warehouse_id = a.warehouse_id
+		  AND s.snapshot_date >= a.adjustment_date
+	`, inventoryID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM inventory_snapshot_adjustments WHERE inventory_id = $1`, inventoryID)
+	return err
+}
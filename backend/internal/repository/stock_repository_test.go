@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestInventoryStockDeltas(t *testing.T) {
+	product := uuid.New()
+	other := uuid.New()
+	warehouse := uuid.New()
+
+	items := []InventoryItem{
+		{ProductID: &product, WarehouseID: warehouse, ReceiptQty: 5},
+		{ProductID: &product, WarehouseID: warehouse, WriteOffQty: 2},
+		{ProductID: &other, WarehouseID: warehouse, ReceiptQty: 3, WriteOffQty: 3},
+		{ProductID: nil, WarehouseID: warehouse, ReceiptQty: 10},
+	}
+
+	got := inventoryStockDeltas(items)
+	want := map[stockKey]int{{product, warehouse}: 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("inventoryStockDeltas() = %v, want %v", got, want)
+	}
+}
+
+func TestPlanInventorySnapshotsShiftsSnapshotsOnAndAfterDate(t *testing.T) {
+	product := uuid.New()
+	warehouse := uuid.New()
+	key := stockKey{product, warehouse}
+
+	snapshots := []StockSnapshot{
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-03-31"), Quantity: 40},
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-02-28"), Quantity: 20},
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-02-15"), Quantity: 25},
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-01-31"), Quantity: 10},
+	}
+
+	got := planInventorySnapshots(date("2025-02-15"), map[stockKey]int{key: -4}, snapshots)
+	want := []snapshotChange{
+		{ProductID: product, WarehouseID: warehouse, Date: date("2025-02-15"), Delta: -4},
+		{ProductID: product, WarehouseID: warehouse, Date: date("2025-02-28"), Delta: -4},
+		{ProductID: product, WarehouseID: warehouse, Date: date("2025-03-31"), Delta: -4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("planInventorySnapshots() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanInventorySnapshotsCreatesNoSnapshotOnDate(t *testing.T) {
+	product := uuid.New()
+	warehouse := uuid.New()
+	key := stockKey{product, warehouse}
+
+	snapshots := []StockSnapshot{
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-01-31"), Quantity: 12},
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-02-28"), Quantity: 20},
+	}
+
+	// Снапшот на дату инвентаризации скрыл бы движения того же дня, проведенные после нее
+	got := planInventorySnapshots(date("2025-02-10"), map[stockKey]int{key: 7}, snapshots)
+	want := []snapshotChange{
+		{ProductID: product, WarehouseID: warehouse, Date: date("2025-02-28"), Delta: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("planInventorySnapshots() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanInventorySnapshotsSameDayWithoutLaterSnapshot(t *testing.T) {
+	product := uuid.New()
+	warehouse := uuid.New()
+	key := stockKey{product, warehouse}
+
+	snapshots := []StockSnapshot{
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-01-31"), Quantity: 12},
+	}
+
+	// Корректировка и движения того же дня идут в остаток через vw_stock_movements
+	if got := planInventorySnapshots(date("2025-02-10"), map[stockKey]int{key: -3}, snapshots); len(got) != 0 {
+		t.Fatalf("planInventorySnapshots() = %+v, want no changes", got)
+	}
+}
+
+func TestPlanInventorySnapshotsIgnoresUnaffectedPairs(t *testing.T) {
+	product := uuid.New()
+	other := uuid.New()
+	warehouse := uuid.New()
+	key := stockKey{product, warehouse}
+
+	snapshots := []StockSnapshot{
+		{ProductID: other, WarehouseID: warehouse, SnapshotDate: date("2025-02-28"), Quantity: 20},
+	}
+
+	if got := planInventorySnapshots(date("2025-02-10"), map[stockKey]int{key: -3}, snapshots); len(got) != 0 {
+		t.Fatalf("planInventorySnapshots() = %+v, want no changes", got)
+	}
+}
+
+func TestPlanInventorySnapshotsWithoutDeltas(t *testing.T) {
+	product := uuid.New()
+	warehouse := uuid.New()
+
+	snapshots := []StockSnapshot{
+		{ProductID: product, WarehouseID: warehouse, SnapshotDate: date("2025-02-28"), Quantity: 20},
+	}
+
+	if got := planInventorySnapshots(date("2025-02-10"), map[stockKey]int{}, snapshots); len(got) != 0 {
+		t.Fatalf("planInventorySnapshots() = %+v, want no changes", got)
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// StockAvailability - остаток товара на складе за вычетом активных резервов
+type StockAvailability struct {
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	OnHand      int
+	Reserved    int
+	Available   int
+}
+
+type StockReservationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStockReservationRepository(pool *pgxpool.Pool) *StockReservationRepository {
+	return &StockReservationRepository{pool: pool}
+}
+
+const stockAvailabilityQuery = `
+	SELECT
+		COALESCE((
+			SELECT SUM(current_quantity)
+			FROM vw_current_stock
+			WHERE product_id = $1 AND warehouse_id = $2
+		), 0)::int,
+		COALESCE((
+			SELECT SUM(quantity)
+			FROM stock_reservations
+			WHERE product_id = $1 AND warehouse_id = $2
+			  AND released_at IS NULL
+			  AND ($3::uuid IS NULL OR shipment_item_id <> $3)
+		), 0)::int
+`
+
+// GetAvailability returns on-hand stock and active reservations; the reservation of excludeItemID
+// (the shipment item being edited) is not counted.
+func (r *StockReservationRepository) GetAvailability(ctx context.Context, productID, warehouseID uuid.UUID, excludeItemID *uuid.UUID) (*StockAvailability, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	availability := StockAvailability{ProductID: productID, WarehouseID: warehouseID}
+	err := r.pool.QueryRow(ctx, stockAvailabilityQuery, productID, warehouseID, excludeItemID).Scan(
+		&availability.OnHand,
+		&availability.Reserved,
+	)
+	if err != nil {
+		return nil, err
+	}
+	availability.Available = availability.OnHand - availability.Reserved
+
+	return &availability, nil
+}
+
+// Reserve creates or updates the reservation of a shipment item. Availability is re-checked under a
+// transaction-level advisory lock on (product, warehouse), so concurrent reservations cannot oversell.
+// On ErrInsufficientStock the returned availability explains the shortage.
+func (r *StockReservationRepository) Reserve(ctx context.Context, shipmentItemID, productID, warehouseID uuid.UUID, quantity int) (*StockAvailability, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockStock(ctx, tx, productID, warehouseID); err != nil {
+		return nil, err
+	}
+
+	availability := StockAvailability{ProductID: productID, WarehouseID: warehouseID}
+	err = tx.QueryRow(ctx, stockAvailabilityQuery, productID, warehouseID, shipmentItemID).Scan(
+		&availability.OnHand,
+		&availability.Reserved,
+	)
+	if err != nil {
+		return nil, err
+	}
+	availability.Available = availability.OnHand - availability.Reserved
+
+	if quantity > availability.Available {
+		return &availability, ErrInsufficientStock
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO stock_reservations (shipment_item_id, product_id, warehouse_id, quantity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (shipment_item_id) DO UPDATE
+		SET product_id = EXCLUDED.product_id,
+		    warehouse_id = EXCLUDED.warehouse_id,
+		    quantity = EXCLUDED.quantity,
+		    created_at = CURRENT_TIMESTAMP,
+		    released_at = NULL
+	`, shipmentItemID, productID, warehouseID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	availability.Reserved += quantity
+	availability.Available -= quantity
+	return &availability, nil
+}
+
+func (r *StockReservationRepository) Release(ctx context.Context, shipmentItemID uuid.UUID) error {
+	query := `
+		UPDATE stock_reservations
+		SET released_at = CURRENT_TIMESTAMP
+		WHERE shipment_item_id = $1 AND released_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, query, shipmentItemID)
+	return err
+}
+
+// ReleaseByShipment releases all active reservations of the shipment's items and returns how many were released.
+func (r *StockReservationRepository) ReleaseByShipment(ctx context.Context, shipmentID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE stock_reservations sr
+		SET released_at = CURRENT_TIMESTAMP
+		FROM mp_shipment_items msi
+		WHERE msi.shipment_item_id = sr.shipment_item_id
+		  AND msi.shipment_id = $1
+		  AND sr.released_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, shipmentID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// lockStock serializes stock-changing operations on one (product, warehouse) pair until the transaction ends.
+func lockStock(ctx context.Context, tx pgx.Tx, productID, warehouseID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1::text || ':' || $2::text, 0))`, productID, warehouseID)
+	return err
+}
@@ -15,6 +15,8 @@ import (
 var (
 	ErrStockSnapshotNotFound = errors.New("stock snapshot not found")
 	ErrStockSnapshotExists   = errors.New("stock snapshot already exists")
+	// ErrSnapshotGenerationLocked - снапшоты в этот момент формирует другой запуск (возможно, на другой реплике)
+	ErrSnapshotGenerationLocked = errors.New("stock snapshot generation is already running")
 )
 
 type StockSnapshot struct {
@@ -215,3 +217,216 @@ func (r *StockSnapshotRepository) Delete(ctx context.Context, snapshotID uuid.UU
 
 	return nil
 }
+
+// Generate stores the stock as of the end of snapshotDate (stockAsOfCTE, so movements dated later are not
+// included) as snapshots dated snapshotDate, for every pair with a snapshot or movements up to that date.
+// Existing snapshots on that date are kept. The transaction holds a Postgres advisory lock, so concurrent runs (on any replica)
+// do not overlap: a run that cannot take the lock returns ErrSnapshotGenerationLocked.
+// Returns the number of created snapshots.
+func (r *StockSnapshotRepository) Generate(ctx context.Context, snapshotDate time.Time, createdBy *uuid.UUID) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended('stock_snapshots:generate', 0))`).Scan(&locked)
+	if err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, ErrSnapshotGenerationLocked
+	}
+
+	result, err := tx.Exec(ctx, stockAsOfCTE+`
+		INSERT INTO stock_snapshots (product_id, warehouse_id, snapshot_date, quantity, created_by)
+		SELECT product_id, warehouse_id, $1, snapshot_quantity + movements_quantity, $2
+		FROM stock_as_of
+		ON CONFLICT (product_id, warehouse_id, snapshot_date)
+		DO NOTHING
+	`, snapshotDate, createdBy)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// StockSnapshotMismatch - снапшот, количество которого не сходится с предыдущим снапшотом и движениями между ними
+type StockSnapshotMismatch struct {
+	SnapshotID        uuid.UUID
+	ProductID         uuid.UUID
+	WarehouseID       uuid.UUID
+	SnapshotDate      time.Time
+	PreviousDate      time.Time
+	PreviousQuantity  int // уже с учетом исправления предыдущего снапшота
+	MovementsQuantity int
+	Quantity          int
+	ExpectedQuantity  int
+	Repaired          bool
+}
+
+// stockSnapshotCheck - снапшот вместе с предыдущим снапшотом той же пары товар/склад и суммой движений между ними
+type stockSnapshotCheck struct {
+	SnapshotID        uuid.UUID
+	ProductID         uuid.UUID
+	WarehouseID       uuid.UUID
+	SnapshotDate      time.Time
+	Quantity          int
+	PreviousDate      time.Time
+	MovementsQuantity int
+	First             bool
+}
+
+// findSnapshotMismatches walks the snapshots of every pair in date order. The first snapshot of a pair is the
+// opening balance and is trusted; every later one is expected to equal the previous snapshot plus the movements
+// between them. The previous quantity is taken as corrected, so drift carried forward into later snapshots
+// is reported as well and repairing all mismatches leaves the chain consistent.
+// checks must be ordered by product, warehouse and date.
+func findSnapshotMismatches(checks []stockSnapshotCheck) []StockSnapshotMismatch {
+	var mismatches []StockSnapshotMismatch
+	var previous int
+	for _, check := range checks {
+		if check.First {
+			previous = check.Quantity
+			continue
+		}
+
+		expected := previous + check.MovementsQuantity
+		if check.Quantity != expected {
+			mismatches = append(mismatches, StockSnapshotMismatch{
+				SnapshotID:        check.SnapshotID,
+				ProductID:         check.ProductID,
+				WarehouseID:       check.WarehouseID,
+				SnapshotDate:      check.SnapshotDate,
+				PreviousDate:      check.PreviousDate,
+				PreviousQuantity:  previous,
+				MovementsQuantity: check.MovementsQuantity,
+				Quantity:          check.Quantity,
+				ExpectedQuantity:  expected,
+			})
+		}
+		previous = expected
+	}
+	return mismatches
+}
+
+func (r *StockSnapshotRepository) loadChecks(ctx context.Context, tx pgx.Tx, warehouseID, productID *uuid.UUID) ([]stockSnapshotCheck, error) {
+	rows, err := tx.Query(ctx, `
+		WITH s AS (
+			SELECT snapshot_id, product_id, warehouse_id, snapshot_date, quantity,
+			       LAG(snapshot_date) OVER w AS previous_date
+			FROM stock_snapshots
+			WHERE ($1::uuid IS NULL OR warehouse_id = $1)
+			  AND ($2::uuid IS NULL OR product_id = $2)
+			WINDOW w AS (PARTITION BY product_id, warehouse_id ORDER BY snapshot_date)
+		)
+		SELECT s.snapshot_id, s.product_id, s.warehouse_id, s.snapshot_date, s.quantity,
+		       s.previous_date,
+		       COALESCE((
+		           SELECT SUM(m.quantity)
+		           FROM vw_stock_movements m
+		           WHERE m.product_id = s.product_id
+		             AND m.warehouse_id = s.warehouse_id
+		             AND m.movement_date > s.previous_date
+		             AND m.movement_date <= s.snapshot_date
+		       ), 0)::int AS movements_quantity
+		FROM s
+		ORDER BY s.product_id, s.warehouse_id, s.snapshot_date
+	`, warehouseID, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checks []stockSnapshotCheck
+	for rows.Next() {
+		var check stockSnapshotCheck
+		var previousDate *time.Time
+		if err := rows.Scan(
+			&check.SnapshotID,
+			&check.ProductID,
+			&check.WarehouseID,
+			&check.SnapshotDate,
+			&check.Quantity,
+			&previousDate,
+			&check.MovementsQuantity,
+		); err != nil {
+			return nil, err
+		}
+		if previousDate == nil {
+			check.First = true
+		} else {
+			check.PreviousDate = *previousDate
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, rows.Err()
+}
+
+// Verify recomputes every snapshot (optionally of one warehouse and/or product) from the previous snapshot and
+// vw_stock_movements and returns the mismatches. With repair the mismatched snapshots are set to the expected
+// quantity in the same transaction; the affected stock is locked, so concurrent inventory adjustments wait.
+func (r *StockSnapshotRepository) Verify(ctx context.Context, warehouseID, productID *uuid.UUID, repair bool) ([]StockSnapshotMismatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	checks, err := r.loadChecks(ctx, tx, warehouseID, productID)
+	if err != nil {
+		return nil, err
+	}
+	mismatches := findSnapshotMismatches(checks)
+	if !repair || len(mismatches) == 0 {
+		return mismatches, nil
+	}
+
+	// снапшоты перечитываются под блокировкой: до нее их могла изменить инвентаризация
+	locked := make(map[stockKey]bool)
+	for _, m := range mismatches {
+		key := stockKey{m.ProductID, m.WarehouseID}
+		if locked[key] {
+			continue
+		}
+		if err := lockStock(ctx, tx, m.ProductID, m.WarehouseID); err != nil {
+			return nil, err
+		}
+		locked[key] = true
+	}
+	if checks, err = r.loadChecks(ctx, tx, warehouseID, productID); err != nil {
+		return nil, err
+	}
+	mismatches = findSnapshotMismatches(checks)
+
+	for i := range mismatches {
+		_, err := tx.Exec(ctx, `
+			UPDATE stock_snapshots
+			SET quantity = $2
+			WHERE snapshot_id = $1
+		`, mismatches[i].SnapshotID, mismatches[i].ExpectedQuantity)
+		if err != nil {
+			return nil, err
+		}
+		mismatches[i].Repaired = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return mismatches, nil
+}
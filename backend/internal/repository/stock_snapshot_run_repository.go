@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrStockSnapshotRunNotFound = errors.New("stock snapshot run not found")
+)
+
+// Источник запуска формирования снапшотов
+const (
+	SnapshotRunTriggerSchedule = "schedule"
+	SnapshotRunTriggerManual   = "manual"
+)
+
+// Статусы запуска. skipped - формирование уже выполнялось другим запуском (например, на другой реплике)
+const (
+	SnapshotRunStatusRunning   = "running"
+	SnapshotRunStatusSucceeded = "succeeded"
+	SnapshotRunStatusFailed    = "failed"
+	SnapshotRunStatusSkipped   = "skipped"
+)
+
+type StockSnapshotRun struct {
+	RunID            uuid.UUID
+	Trigger          string
+	SnapshotDate     time.Time
+	Status           string
+	SnapshotsCreated int
+	Error            *string
+	StartedBy        *uuid.UUID
+	StartedAt        time.Time
+	FinishedAt       *time.Time
+}
+
+type StockSnapshotRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStockSnapshotRunRepository(pool *pgxpool.Pool) *StockSnapshotRunRepository {
+	return &StockSnapshotRunRepository{pool: pool}
+}
+
+const stockSnapshotRunColumns = `
+	run_id, trigger, snapshot_date, status, snapshots_created, error, started_by, started_at, finished_at
+`
+
+func scanStockSnapshotRun(row pgx.Row, run *StockSnapshotRun) error {
+	return row.Scan(
+		&run.RunID,
+		&run.Trigger,
+		&run.SnapshotDate,
+		&run.Status,
+		&run.SnapshotsCreated,
+		&run.Error,
+		&run.StartedBy,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+}
+
+func (r *StockSnapshotRunRepository) Create(ctx context.Context, trigger string, snapshotDate time.Time, startedBy *uuid.UUID) (*StockSnapshotRun, error) {
+	query := `
+		INSERT INTO stock_snapshot_runs (trigger, snapshot_date, started_by)
+		VALUES ($1, $2, $3)
+		RETURNING ` + stockSnapshotRunColumns
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var run StockSnapshotRun
+	if err := scanStockSnapshotRun(r.pool.QueryRow(ctx, query, trigger, snapshotDate, startedBy), &run); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// Finish stores the outcome of the run.
+func (r *StockSnapshotRunRepository) Finish(ctx context.Context, runID uuid.UUID, status string, snapshotsCreated int64, errMsg *string) (*StockSnapshotRun, error) {
+	query := `
+		UPDATE stock_snapshot_runs
+		SET status = $2, snapshots_created = $3, error = $4, finished_at = CURRENT_TIMESTAMP
+		WHERE run_id = $1
+		RETURNING ` + stockSnapshotRunColumns
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var run StockSnapshotRun
+	err := scanStockSnapshotRun(r.pool.QueryRow(ctx, query, runID, status, snapshotsCreated, errMsg), &run)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStockSnapshotRunNotFound
+		}
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (r *StockSnapshotRunRepository) List(ctx context.Context, limit, offset int) ([]StockSnapshotRun, error) {
+	query := `
+		SELECT ` + stockSnapshotRunColumns + `
+		FROM stock_snapshot_runs
+		ORDER BY started_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []StockSnapshotRun
+	for rows.Next() {
+		var run StockSnapshotRun
+		if err := scanStockSnapshotRun(rows, &run); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
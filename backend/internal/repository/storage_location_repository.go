@@ -0,0 +1,392 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrStorageLocationNotFound = errors.New("storage location not found")
+	ErrStorageLocationExists   = errors.New("storage location with this code already exists")
+	ErrStorageLocationArchived = errors.New("storage location is archived")
+	ErrInvalidLocationType     = errors.New("invalid storage location type")
+	ErrInvalidLocationParent   = errors.New("location type does not fit the parent location")
+	ErrLocationHasChildren     = errors.New("storage location has active child locations")
+	ErrLocationNotEmpty        = errors.New("storage location holds stock")
+)
+
+// Уровни адресного хранения, от зоны склада до ячейки
+const (
+	LocationTypeZone  = "zone"
+	LocationTypeRack  = "rack"
+	LocationTypeShelf = "shelf"
+	LocationTypeBin   = "bin"
+)
+
+var locationTypeLevels = []string{LocationTypeZone, LocationTypeRack, LocationTypeShelf, LocationTypeBin}
+
+func IsValidLocationType(locationType string) bool {
+	for _, t := range locationTypeLevels {
+		if t == locationType {
+			return true
+		}
+	}
+	return false
+}
+
+// ChildLocationType returns the type a child of the given location type must have, or "" for a bin.
+func ChildLocationType(parentType string) string {
+	for i, t := range locationTypeLevels {
+		if t == parentType && i+1 < len(locationTypeLevels) {
+			return locationTypeLevels[i+1]
+		}
+	}
+	return ""
+}
+
+// StorageLocation - место хранения внутри склада (зона, стеллаж, полка или ячейка)
+type StorageLocation struct {
+	LocationID   uuid.UUID
+	WarehouseID  uuid.UUID
+	ParentID     *uuid.UUID
+	LocationType string
+	Code         string
+	Name         *string
+	IsArchived   bool
+	ArchivedAt   *time.Time
+	CreatedBy    *uuid.UUID
+	CreatedAt    time.Time
+	UpdatedBy    *uuid.UUID
+	UpdatedAt    time.Time
+}
+
+// LocationStock is the quantity of a product in a storage location. LocationID is nil for the unplaced
+// stock of the warehouse: current stock not yet put away into any location.
+type LocationStock struct {
+	LocationID *uuid.UUID
+	ProductID  uuid.UUID
+	Article    string
+	Quantity   int
+}
+
+type StorageLocationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStorageLocationRepository(pool *pgxpool.Pool) *StorageLocationRepository {
+	return &StorageLocationRepository{pool: pool}
+}
+
+const storageLocationColumns = `
+	location_id, warehouse_id, parent_id, location_type, code, name, is_archived, archived_at,
+	created_by, created_at, updated_by, updated_at
+`
+
+func scanStorageLocation(row pgx.Row, location *StorageLocation) error {
+	return row.Scan(
+		&location.LocationID,
+		&location.WarehouseID,
+		&location.ParentID,
+		&location.LocationType,
+		&location.Code,
+		&location.Name,
+		&location.IsArchived,
+		&location.ArchivedAt,
+		&location.CreatedBy,
+		&location.CreatedAt,
+		&location.UpdatedBy,
+		&location.UpdatedAt,
+	)
+}
+
+func storageLocationError(err error) error {
+	errMsg := err.Error()
+	if strings.Contains(errMsg, "storage_locations_zone_root") {
+		return ErrInvalidLocationParent
+	}
+	if strings.Contains(errMsg, "check constraint") {
+		return ErrInvalidLocationType
+	}
+	if strings.Contains(errMsg, "duplicate key") ||
+		strings.Contains(errMsg, "unique constraint") {
+		return ErrStorageLocationExists
+	}
+	return err
+}
+
+func (r *StorageLocationRepository) GetByID(ctx context.Context, locationID uuid.UUID) (*StorageLocation, error) {
+	query := `
+		SELECT ` + storageLocationColumns + `
+		FROM storage_locations
+		WHERE location_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var location StorageLocation
+	err := scanStorageLocation(r.pool.QueryRow(ctx, query, locationID), &location)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStorageLocationNotFound
+		}
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+// ListByWarehouse returns all locations of the warehouse; archived ones only if includeArchived is set.
+func (r *StorageLocationRepository) ListByWarehouse(ctx context.Context, warehouseID uuid.UUID, includeArchived bool) ([]StorageLocation, error) {
+	query := `
+		SELECT ` + storageLocationColumns + `
+		FROM storage_locations
+		WHERE warehouse_id = $1
+		  AND ($2 OR NOT is_archived)
+		ORDER BY code, location_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, warehouseID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []StorageLocation
+	for rows.Next() {
+		var location StorageLocation
+		if err := scanStorageLocation(rows, &location); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+func (r *StorageLocationRepository) Create(ctx context.Context, warehouseID uuid.UUID, parentID *uuid.UUID, locationType, code string, name *string, createdBy uuid.UUID) (*StorageLocation, error) {
+	query := `
+		INSERT INTO storage_locations (warehouse_id, parent_id, location_type, code, name, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + storageLocationColumns
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var location StorageLocation
+	err := scanStorageLocation(r.pool.QueryRow(ctx, query, warehouseID, parentID, locationType, code, name, createdBy), &location)
+	if err != nil {
+		return nil, storageLocationError(err)
+	}
+
+	return &location, nil
+}
+
+// Update changes the code and name of the location; its place in the hierarchy is fixed at creation.
+func (r *StorageLocationRepository) Update(ctx context.Context, locationID uuid.UUID, code string, name *string, updatedBy uuid.UUID) (*StorageLocation, error) {
+	query := `
+		UPDATE storage_locations
+		SET code = $1, name = $2, updated_by = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE location_id = $4
+		RETURNING ` + storageLocationColumns
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var location StorageLocation
+	err := scanStorageLocation(r.pool.QueryRow(ctx, query, code, name, updatedBy, locationID), &location)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrStorageLocationNotFound
+		}
+		return nil, storageLocationError(err)
+	}
+
+	return &location, nil
+}
+
+// Archive hides an empty location without active children, so that moves referencing it keep their history.
+// Returns ErrLocationHasChildren or ErrLocationNotEmpty otherwise.
+func (r *StorageLocationRepository) Archive(ctx context.Context, locationID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var hasChildren, hasStock bool
+	err = tx.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM storage_locations WHERE parent_id = sl.location_id AND NOT is_archived),
+			EXISTS (SELECT 1 FROM vw_location_stock WHERE location_id = sl.location_id)
+		FROM storage_locations sl
+		WHERE sl.location_id = $1
+		FOR UPDATE OF sl
+	`, locationID).Scan(&hasChildren, &hasStock)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrStorageLocationNotFound
+		}
+		return err
+	}
+	if hasChildren {
+		return ErrLocationHasChildren
+	}
+	if hasStock {
+		return ErrLocationNotEmpty
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE storage_locations
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE location_id = $1
+	`, locationID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *StorageLocationRepository) Restore(ctx context.Context, locationID uuid.UUID) error {
+	query := `
+		UPDATE storage_locations
+		SET is_archived = FALSE, archived_at = NULL
+		WHERE location_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, locationID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStorageLocationNotFound
+	}
+
+	return nil
+}
+
+// locationStockQuery returns the stock of warehouse $1 by location (see GetStock). Warehouse stock comes from
+// vw_current_stock, which counts a pair without a snapshot from all its movements, so goods received into
+// a new product/warehouse pair are unplaced stock right away and can be put away.
+const locationStockQuery = `
+		WITH placed AS (
+			SELECT location_id, product_id, quantity
+			FROM vw_location_stock
+			WHERE warehouse_id = $1
+		),
+		unplaced AS (
+			SELECT
+				COALESCE(cs.product_id, p.product_id) AS product_id,
+				COALESCE(cs.current_quantity, 0) - COALESCE(p.quantity, 0) AS quantity
+			FROM (
+				SELECT product_id, current_quantity
+				FROM vw_current_stock
+				WHERE warehouse_id = $1
+			) cs
+			FULL JOIN (
+				SELECT product_id, SUM(quantity) AS quantity
+				FROM placed
+				GROUP BY product_id
+			) p ON p.product_id = cs.product_id
+		)
+		SELECT s.location_id, s.product_id, pr.article, s.quantity
+		FROM (
+			SELECT location_id, product_id, quantity FROM placed
+			UNION ALL
+			SELECT NULL::uuid, product_id, quantity FROM unplaced WHERE quantity <> 0
+		) s
+		JOIN products pr ON pr.product_id = s.product_id
+		ORDER BY s.location_id NULLS FIRST, pr.article
+`
+
+// GetStock returns the stock of the warehouse by location from vw_location_stock plus, with nil LocationID,
+// the unplaced remainder of vw_current_stock. Outgoing documents may take only unplaced stock (goods are
+// picked from their bins by a location move first), so the remainder is negative only together with
+// negative warehouse stock.
+func (r *StorageLocationRepository) GetStock(ctx context.Context, warehouseID uuid.UUID) ([]LocationStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, locationStockQuery, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	return scanLocationStock(rows)
+}
+
+func scanLocationStock(rows pgx.Rows) ([]LocationStock, error) {
+	defer rows.Close()
+
+	var stock []LocationStock
+	for rows.Next() {
+		var item LocationStock
+		if err := rows.Scan(&item.LocationID, &item.ProductID, &item.Article, &item.Quantity); err != nil {
+			return nil, err
+		}
+		stock = append(stock, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+// GetReceivedQuantities returns the quantities of the supplier order received into the warehouse by product.
+func (r *StorageLocationRepository) GetReceivedQuantities(ctx context.Context, orderID, warehouseID uuid.UUID) (map[uuid.UUID]int, error) {
+	query := `
+		SELECT soi.product_id, SUM(soi.received_qty)
+		FROM supplier_order_items soi
+		WHERE soi.order_id = $1
+		  AND soi.warehouse_id = $2
+		  AND soi.product_id IS NOT NULL
+		  AND soi.received_qty > 0
+		GROUP BY soi.product_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, orderID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	received := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var productID uuid.UUID
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		received[productID] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return received, nil
+}
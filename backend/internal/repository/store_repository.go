@@ -14,11 +14,14 @@ import (
 var (
 	ErrStoreNotFound = errors.New("store not found")
 	ErrStoreExists   = errors.New("store already exists")
+	ErrStoreArchived = errors.New("store is archived")
 )
 
 type Store struct {
-	StoreID uuid.UUID
-	Name    string
+	StoreID    uuid.UUID
+	Name       string
+	IsArchived bool
+	ArchivedAt *time.Time
 }
 
 type StoreRepository struct {
@@ -31,7 +34,7 @@ func NewStoreRepository(pool *pgxpool.Pool) *StoreRepository {
 
 func (r *StoreRepository) GetByID(ctx context.Context, storeID uuid.UUID) (*Store, error) {
 	query := `
-		SELECT store_id, name
+		SELECT store_id, name, is_archived, archived_at
 		FROM stores
 		WHERE store_id = $1
 	`
@@ -43,6 +46,8 @@ func (r *StoreRepository) GetByID(ctx context.Context, storeID uuid.UUID) (*Stor
 	err := r.pool.QueryRow(ctx, query, storeID).Scan(
 		&store.StoreID,
 		&store.Name,
+		&store.IsArchived,
+		&store.ArchivedAt,
 	)
 
 	if err != nil {
@@ -55,10 +60,11 @@ func (r *StoreRepository) GetByID(ctx context.Context, storeID uuid.UUID) (*Stor
 	return &store, nil
 }
 
-func (r *StoreRepository) List(ctx context.Context, limit, offset int) ([]Store, error) {
+func (r *StoreRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]Store, error) {
 	query := `
-		SELECT store_id, name
+		SELECT store_id, name, is_archived, archived_at
 		FROM stores
+		WHERE $3 OR NOT is_archived
 		ORDER BY store_id
 		LIMIT $1 OFFSET $2
 	`
@@ -66,7 +72,7 @@ func (r *StoreRepository) List(ctx context.Context, limit, offset int) ([]Store,
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +84,8 @@ func (r *StoreRepository) List(ctx context.Context, limit, offset int) ([]Store,
 		if err := rows.Scan(
 			&store.StoreID,
 			&store.Name,
+			&store.IsArchived,
+			&store.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -95,7 +103,7 @@ func (r *StoreRepository) Create(ctx context.Context, name string) (*Store, erro
 	query := `
 		INSERT INTO stores (name)
 		VALUES ($1)
-		RETURNING store_id, name
+		RETURNING store_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -105,6 +113,8 @@ func (r *StoreRepository) Create(ctx context.Context, name string) (*Store, erro
 	err := r.pool.QueryRow(ctx, query, name).Scan(
 		&store.StoreID,
 		&store.Name,
+		&store.IsArchived,
+		&store.ArchivedAt,
 	)
 
 	if err != nil {
@@ -125,7 +135,7 @@ func (r *StoreRepository) Update(ctx context.Context, storeID uuid.UUID, name st
 		UPDATE stores
 		SET name = $1
 		WHERE store_id = $2
-		RETURNING store_id, name
+		RETURNING store_id, name, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -135,6 +145,8 @@ func (r *StoreRepository) Update(ctx context.Context, storeID uuid.UUID, name st
 	err := r.pool.QueryRow(ctx, query, name, storeID).Scan(
 		&store.StoreID,
 		&store.Name,
+		&store.IsArchived,
+		&store.ArchivedAt,
 	)
 
 	if err != nil {
@@ -152,9 +164,34 @@ func (r *StoreRepository) Update(ctx context.Context, storeID uuid.UUID, name st
 	return &store, nil
 }
 
-func (r *StoreRepository) Delete(ctx context.Context, storeID uuid.UUID) error {
+// Archive hides the store from lists and new documents instead of deleting it, so existing documents and
+// stock history keep their references. Archiving an archived store keeps the original archived_at.
+func (r *StoreRepository) Archive(ctx context.Context, storeID uuid.UUID) error {
 	query := `
-		DELETE FROM stores
+		UPDATE stores
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE store_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, storeID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStoreNotFound
+	}
+
+	return nil
+}
+
+func (r *StoreRepository) Restore(ctx context.Context, storeID uuid.UUID) error {
+	query := `
+		UPDATE stores
+		SET is_archived = FALSE, archived_at = NULL
 		WHERE store_id = $1
 	`
 
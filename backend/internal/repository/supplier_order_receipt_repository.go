@@ -0,0 +1,457 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrOrderAlreadyReceived = errors.New("supplier order already received")
+	ErrOrderCancelled       = errors.New("supplier order is cancelled")
+	ErrInvalidReceivedQty   = errors.New("received quantity must be non-negative")
+	ErrDuplicateReceiptLine = errors.New("duplicate order item in receipt")
+	ErrEmptyReceipt         = errors.New("receipt has no items")
+	ErrReceiptRequired      = errors.New("supplier order can be received only with a receipt")
+)
+
+type SupplierOrderReceipt struct {
+	ReceiptID   uuid.UUID
+	OrderID     uuid.UUID
+	ReceiptDate time.Time
+	Notes       *string
+	ReceivedBy  *uuid.UUID
+	CreatedAt   time.Time
+	Items       []SupplierOrderReceiptItem
+	// Costs - периоды себестоимости, открытые этой приемкой (заполняется только в Receive)
+	Costs []ReceiptCost
+}
+
+// ReceiptCost describes the cost period opened for a product by a receipt.
+type ReceiptCost struct {
+	CostID           uuid.UUID
+	ProductID        uuid.UUID
+	PreviousUnitCost *float64
+	ExistingQty      int
+	ReceivedQty      int
+	ReceivedUnitCost float64
+	UnitCost         float64
+	PeriodStart      time.Time
+	PeriodEnd        time.Time
+}
+
+// OpenCostPeriodEnd is period_end of an auto-generated cost period with no later period after it.
+var OpenCostPeriodEnd = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type SupplierOrderReceiptItem struct {
+	ReceiptItemID  uuid.UUID
+	OrderItemID    uuid.UUID
+	ProductID      uuid.UUID
+	WarehouseID    uuid.UUID
+	OrderedQty     int
+	ReceivedQty    int
+	DiscrepancyQty int
+}
+
+// ReceiptLine is the received quantity reported for one order item.
+type ReceiptLine struct {
+	OrderItemID uuid.UUID
+	ReceivedQty int
+}
+
+type SupplierOrderReceiptRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSupplierOrderReceiptRepository(pool *pgxpool.Pool) *SupplierOrderReceiptRepository {
+	return &SupplierOrderReceiptRepository{pool: pool}
+}
+
+// Receive applies received quantities to the order items, sets the order's status and
+// actual_receipt_date and stores a receipt with per-item discrepancies, all in one transaction.
+// Items not present in lines keep their current received_qty but are still listed in the receipt.
+func (r *SupplierOrderReceiptRepository) Receive(ctx context.Context, orderID, receivedStatusID uuid.UUID, receiptDate time.Time, lines []ReceiptLine, notes *string, userID uuid.UUID) (*SupplierOrderReceipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var orderNumber string
+	var currentStatusID *uuid.UUID
+	var currentStatusName *string
+	err = tx.QueryRow(ctx, `
+		SELECT o.order_number, o.status_id, os.name
+		FROM supplier_orders o
+		LEFT JOIN order_statuses os ON os.order_status_id = o.status_id
+		WHERE o.order_id = $1
+		FOR UPDATE OF o
+	`, orderID).Scan(&orderNumber, &currentStatusID, &currentStatusName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSupplierOrderNotFound
+		}
+		return nil, err
+	}
+	if currentStatusID != nil && *currentStatusID == receivedStatusID {
+		return nil, ErrOrderAlreadyReceived
+	}
+	if currentStatusName != nil && *currentStatusName == OrderStatusCancelled {
+		return nil, ErrOrderCancelled
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT order_item_id, product_id, warehouse_id, ordered_qty, received_qty
+		FROM supplier_order_items
+		WHERE order_id = $1
+		ORDER BY order_item_id
+		FOR UPDATE
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []SupplierOrderReceiptItem
+	for rows.Next() {
+		var item SupplierOrderReceiptItem
+		if err := rows.Scan(
+			&item.OrderItemID,
+			&item.ProductID,
+			&item.WarehouseID,
+			&item.OrderedQty,
+			&item.ReceivedQty,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Остаток на дату приемки (по всем складам, без самого заказа) нужен для средневзвешенной себестоимости.
+	// Считается как в GetStockAsOf: последний снапшот не позже даты плюс движения после него, поэтому
+	// учитываются и пары без снапшотов
+	existingQty := make(map[uuid.UUID]int)
+	stockRows, err := tx.Query(ctx, `
+		WITH order_products AS (
+			SELECT DISTINCT product_id
+			FROM supplier_order_items
+			WHERE order_id = $1 AND product_id IS NOT NULL
+		),
+		base_stock AS (
+			SELECT DISTINCT ON (s.product_id, s.warehouse_id)
+				s.product_id,
+				s.warehouse_id,
+				s.snapshot_date,
+				s.quantity
+			FROM stock_snapshots s
+			JOIN order_products op ON op.product_id = s.product_id
+			WHERE s.snapshot_date <= $2
+			ORDER BY s.product_id, s.warehouse_id, s.snapshot_date DESC
+		),
+		movements AS (
+			SELECT m.product_id, SUM(m.quantity) AS quantity
+			FROM vw_stock_movements m
+			JOIN order_products op ON op.product_id = m.product_id
+			LEFT JOIN base_stock bs
+				ON bs.product_id = m.product_id
+			   AND bs.warehouse_id = m.warehouse_id
+			WHERE m.movement_date <= $2
+			  AND m.document_id IS DISTINCT FROM $1
+			  AND (bs.snapshot_date IS NULL OR m.movement_date > bs.snapshot_date)
+			GROUP BY m.product_id
+		)
+		SELECT op.product_id,
+		       (COALESCE((SELECT SUM(bs.quantity) FROM base_stock bs WHERE bs.product_id = op.product_id), 0)
+		        + COALESCE(mv.quantity, 0))::int
+		FROM order_products op
+		LEFT JOIN movements mv ON mv.product_id = op.product_id
+	`, orderID, receiptDate)
+	if err != nil {
+		return nil, err
+	}
+	for stockRows.Next() {
+		var productID uuid.UUID
+		var qty int
+		if err := stockRows.Scan(&productID, &qty); err != nil {
+			stockRows.Close()
+			return nil, err
+		}
+		existingQty[productID] = qty
+	}
+	stockRows.Close()
+	if err := stockRows.Err(); err != nil {
+		return nil, err
+	}
+
+	index := make(map[uuid.UUID]int, len(items))
+	for i, item := range items {
+		index[item.OrderItemID] = i
+	}
+
+	for _, line := range lines {
+		i, ok := index[line.OrderItemID]
+		if !ok {
+			return nil, ErrSupplierOrderItemNotFound
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE supplier_order_items
+			SET received_qty = $1
+			WHERE order_item_id = $2
+		`, line.ReceivedQty, line.OrderItemID)
+		if err != nil {
+			return nil, err
+		}
+		items[i].ReceivedQty = line.ReceivedQty
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE supplier_orders
+		SET status_id = $1,
+		    actual_receipt_date = $2,
+		    updated_by = $3,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE order_id = $4
+	`, receivedStatusID, receiptDate, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := SupplierOrderReceipt{OrderID: orderID}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO supplier_order_receipts (order_id, receipt_date, notes, received_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING receipt_id, receipt_date, notes, received_by, created_at
+	`, orderID, receiptDate, notes, userID).Scan(
+		&receipt.ReceiptID,
+		&receipt.ReceiptDate,
+		&receipt.Notes,
+		&receipt.ReceivedBy,
+		&receipt.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].DiscrepancyQty = items[i].ReceivedQty - items[i].OrderedQty
+		err = tx.QueryRow(ctx, `
+			INSERT INTO supplier_order_receipt_items (receipt_id, order_item_id, ordered_qty, received_qty, discrepancy_qty)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING receipt_item_id
+		`, receipt.ReceiptID, items[i].OrderItemID, items[i].OrderedQty, items[i].ReceivedQty, items[i].DiscrepancyQty).Scan(
+			&items[i].ReceiptItemID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	receipt.Items = items
+
+	receipt.Costs, err = applyReceiptCosts(ctx, tx, orderID, orderNumber, receiptDate, existingQty, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+func (r *SupplierOrderReceiptRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]SupplierOrderReceipt, error) {
+	query := `
+		SELECT r.receipt_id, r.order_id, r.receipt_date, r.notes, r.received_by, r.created_at,
+		       ri.receipt_item_id, ri.order_item_id, soi.product_id, soi.warehouse_id,
+		       ri.ordered_qty, ri.received_qty, ri.discrepancy_qty
+		FROM supplier_order_receipts r
+		JOIN supplier_order_receipt_items ri ON ri.receipt_id = r.receipt_id
+		JOIN supplier_order_items soi ON soi.order_item_id = ri.order_item_id
+		WHERE r.order_id = $1
+		ORDER BY r.created_at, r.receipt_id, ri.order_item_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []SupplierOrderReceipt
+	for rows.Next() {
+		var receipt SupplierOrderReceipt
+		var item SupplierOrderReceiptItem
+		if err := rows.Scan(
+			&receipt.ReceiptID,
+			&receipt.OrderID,
+			&receipt.ReceiptDate,
+			&receipt.Notes,
+			&receipt.ReceivedBy,
+			&receipt.CreatedAt,
+			&item.ReceiptItemID,
+			&item.OrderItemID,
+			&item.ProductID,
+			&item.WarehouseID,
+			&item.OrderedQty,
+			&item.ReceivedQty,
+			&item.DiscrepancyQty,
+		); err != nil {
+			return nil, err
+		}
+
+		if n := len(receipts); n > 0 && receipts[n-1].ReceiptID == receipt.ReceiptID {
+			receipts[n-1].Items = append(receipts[n-1].Items, item)
+			continue
+		}
+		receipt.Items = []SupplierOrderReceiptItem{item}
+		receipts = append(receipts, receipt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return receipts, nil
+}
+
+// applyReceiptCosts opens a new product_costs period from receiptDate for every product received by the order.
+// The new unit cost is the weighted average of the stock on hand on receiptDate (at the cost of the period
+// covering receiptDate) and the received quantity at its unit_self_cost; without such a period the received
+// cost is used as is. The covering period is closed the day before receiptDate; a period already starting
+// on receiptDate is updated in place.
+func applyReceiptCosts(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, orderNumber string, receiptDate time.Time, existingQty map[uuid.UUID]int, userID uuid.UUID) ([]ReceiptCost, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT product_id,
+		       SUM(received_qty)::int,
+		       SUM(unit_self_cost * received_qty) / SUM(received_qty)
+		FROM supplier_order_items
+		WHERE order_id = $1
+		  AND product_id IS NOT NULL
+		  AND received_qty > 0
+		  AND unit_self_cost IS NOT NULL
+		GROUP BY product_id
+		ORDER BY product_id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var costs []ReceiptCost
+	for rows.Next() {
+		cost := ReceiptCost{PeriodStart: receiptDate}
+		if err := rows.Scan(&cost.ProductID, &cost.ReceivedQty, &cost.ReceivedUnitCost); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		cost.ExistingQty = existingQty[cost.ProductID]
+		costs = append(costs, cost)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	notes := "Автоматически по приемке заказа " + orderNumber
+	dayBefore := receiptDate.AddDate(0, 0, -1)
+
+	for i := range costs {
+		cost := &costs[i]
+
+		var prevID *uuid.UUID
+		var prevStart, prevEnd time.Time
+		var prevCost float64
+		err = tx.QueryRow(ctx, `
+			SELECT cost_id, period_start, period_end, unit_cost_to_warehouse
+			FROM product_costs
+			WHERE product_id = $1 AND period_start <= $2 AND period_end >= $2
+			ORDER BY period_start DESC
+			LIMIT 1
+			FOR UPDATE
+		`, cost.ProductID, receiptDate).Scan(&prevID, &prevStart, &prevEnd, &prevCost)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+
+		cost.UnitCost = cost.ReceivedUnitCost
+		if prevID != nil {
+			cost.PreviousUnitCost = &prevCost
+			if cost.ExistingQty > 0 {
+				total := float64(cost.ExistingQty)*prevCost + float64(cost.ReceivedQty)*cost.ReceivedUnitCost
+				cost.UnitCost = total / float64(cost.ExistingQty+cost.ReceivedQty)
+			}
+		}
+		cost.UnitCost = math.Round(cost.UnitCost*100) / 100
+
+		if prevID != nil && prevStart.Equal(receiptDate) {
+			cost.CostID = *prevID
+			cost.PeriodEnd = prevEnd
+			_, err = tx.Exec(ctx, `
+				UPDATE product_costs
+				SET unit_cost_to_warehouse = $1,
+				    notes = $2,
+				    source_order_id = $3,
+				    updated_by = $4,
+				    updated_at = NOW()
+				WHERE cost_id = $5
+			`, cost.UnitCost, notes, orderID, userID, cost.CostID)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var nextStart *time.Time
+		err = tx.QueryRow(ctx, `
+			SELECT MIN(period_start)
+			FROM product_costs
+			WHERE product_id = $1 AND period_start > $2
+		`, cost.ProductID, receiptDate).Scan(&nextStart)
+		if err != nil {
+			return nil, err
+		}
+		cost.PeriodEnd = OpenCostPeriodEnd
+		if nextStart != nil {
+			cost.PeriodEnd = nextStart.AddDate(0, 0, -1)
+		}
+
+		if prevID != nil {
+			_, err = tx.Exec(ctx, `
+				UPDATE product_costs
+				SET period_end = $1,
+				    updated_by = $2,
+				    updated_at = NOW()
+				WHERE cost_id = $3
+			`, dayBefore, userID, *prevID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO product_costs (product_id, period_start, period_end, unit_cost_to_warehouse, notes, source_order_id, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING cost_id
+		`, cost.ProductID, cost.PeriodStart, cost.PeriodEnd, cost.UnitCost, notes, orderID, userID).Scan(&cost.CostID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return costs, nil
+}
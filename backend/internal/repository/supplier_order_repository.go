@@ -13,12 +13,31 @@ import (
 )
 
 var (
-	ErrSupplierOrderNotFound = errors.New("supplier order not found")
-	ErrSupplierOrderExists   = errors.New("supplier order already exists")
-	ErrInvalidDateRange      = errors.New("invalid date range")
-	ErrInvalidParentOrder    = errors.New("invalid parent order")
+	ErrSupplierOrderNotFound   = errors.New("supplier order not found")
+	ErrSupplierOrderExists     = errors.New("supplier order already exists")
+	ErrInvalidDateRange        = errors.New("invalid date range")
+	ErrInvalidParentOrder      = errors.New("invalid parent order")
+	ErrNothingToSplit          = errors.New("supplier order has no undelivered remainder")
+	ErrNothingReceived         = errors.New("supplier order has nothing received yet")
+	ErrInvalidAllocationMethod = errors.New("invalid logistics allocation method")
+	ErrSupplierOrderClosed     = errors.New("items of a received or cancelled supplier order cannot be changed")
 )
 
+// Способы распределения логистики заказа по позициям
+const (
+	AllocationByWeight   = "weight"
+	AllocationByValue    = "value"
+	AllocationByQuantity = "quantity"
+)
+
+func IsValidAllocationMethod(method string) bool {
+	switch method {
+	case AllocationByWeight, AllocationByValue, AllocationByQuantity:
+		return true
+	}
+	return false
+}
+
 type SupplierOrder struct {
 	OrderID             uuid.UUID
 	OrderNumber         string
@@ -35,11 +54,13 @@ type SupplierOrder struct {
 	PositionsQty        int
 	TotalQty            int
 	OrderItemWeight     *float64
-	ParentOrderID       *uuid.UUID
-	CreatedBy           *uuid.UUID
-	CreatedAt           time.Time
-	UpdatedBy           *uuid.UUID
-	UpdatedAt           time.Time
+	// LogisticsAllocationMethod - база распределения логистики заказа по позициям: weight, value или quantity
+	LogisticsAllocationMethod string
+	ParentOrderID             *uuid.UUID
+	CreatedBy                 *uuid.UUID
+	CreatedAt                 time.Time
+	UpdatedBy                 *uuid.UUID
+	UpdatedAt                 time.Time
 }
 
 type SupplierOrderRepository struct {
@@ -56,7 +77,7 @@ func (r *SupplierOrderRepository) GetByID(ctx context.Context, orderID uuid.UUID
 		       planned_receipt_date, actual_receipt_date, logistics_china_msk,
 		       logistics_msk_kzn, logistics_additional, logistics_total,
 		       order_item_cost, positions_qty, total_qty, order_item_weight,
-		       parent_order_id, created_by, created_at, updated_by, updated_at
+		       logistics_allocation_method, parent_order_id, created_by, created_at, updated_by, updated_at
 		FROM supplier_orders
 		WHERE order_id = $1
 	`
@@ -81,6 +102,7 @@ func (r *SupplierOrderRepository) GetByID(ctx context.Context, orderID uuid.UUID
 		&order.PositionsQty,
 		&order.TotalQty,
 		&order.OrderItemWeight,
+		&order.LogisticsAllocationMethod,
 		&order.ParentOrderID,
 		&order.CreatedBy,
 		&order.CreatedAt,
@@ -104,7 +126,7 @@ func (r *SupplierOrderRepository) List(ctx context.Context, limit, offset int, s
 		       planned_receipt_date, actual_receipt_date, logistics_china_msk,
 		       logistics_msk_kzn, logistics_additional, logistics_total,
 		       order_item_cost, positions_qty, total_qty, order_item_weight,
-		       parent_order_id, created_by, created_at, updated_by, updated_at
+		       logistics_allocation_method, parent_order_id, created_by, created_at, updated_by, updated_at
 		FROM supplier_orders
 	`
 	args := []any{}
@@ -147,6 +169,7 @@ func (r *SupplierOrderRepository) List(ctx context.Context, limit, offset int, s
 			&order.PositionsQty,
 			&order.TotalQty,
 			&order.OrderItemWeight,
+			&order.LogisticsAllocationMethod,
 			&order.ParentOrderID,
 			&order.CreatedBy,
 			&order.CreatedAt,
@@ -165,20 +188,21 @@ func (r *SupplierOrderRepository) List(ctx context.Context, limit, offset int, s
 	return orders, nil
 }
 
-func (r *SupplierOrderRepository) Create(ctx context.Context, orderNumber string, buyer *string, statusID *uuid.UUID, purchaseDate, plannedReceiptDate, actualReceiptDate *time.Time, logisticsChinaMsk, logisticsMskKzn, logisticsAdditional, logisticsTotal, orderItemCost, orderItemWeight *float64, positionsQty, totalQty int, parentOrderID, createdBy *uuid.UUID) (*SupplierOrder, error) {
+func (r *SupplierOrderRepository) Create(ctx context.Context, orderNumber string, buyer *string, statusID *uuid.UUID, purchaseDate, plannedReceiptDate, actualReceiptDate *time.Time, logisticsChinaMsk, logisticsMskKzn, logisticsAdditional, logisticsTotal, orderItemCost, orderItemWeight *float64, positionsQty, totalQty int, logisticsAllocationMethod string, parentOrderID, createdBy *uuid.UUID) (*SupplierOrder, error) {
 	query := `
 		INSERT INTO supplier_orders (
 			order_number, buyer, status_id, purchase_date, planned_receipt_date,
 			actual_receipt_date, logistics_china_msk, logistics_msk_kzn,
 			logistics_additional, logistics_total, order_item_cost,
-			positions_qty, total_qty, order_item_weight, parent_order_id, created_by
+			positions_qty, total_qty, order_item_weight, logistics_allocation_method,
+			parent_order_id, created_by
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING order_id, order_number, buyer, status_id, purchase_date,
 		          planned_receipt_date, actual_receipt_date, logistics_china_msk,
 		          logistics_msk_kzn, logistics_additional, logistics_total,
 		          order_item_cost, positions_qty, total_qty, order_item_weight,
-		          parent_order_id, created_by, created_at, updated_by, updated_at
+		          logistics_allocation_method, parent_order_id, created_by, created_at, updated_by, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -189,7 +213,7 @@ func (r *SupplierOrderRepository) Create(ctx context.Context, orderNumber string
 		orderNumber, buyer, statusID, purchaseDate, plannedReceiptDate,
 		actualReceiptDate, logisticsChinaMsk, logisticsMskKzn,
 		logisticsAdditional, logisticsTotal, orderItemCost,
-		positionsQty, totalQty, orderItemWeight, parentOrderID, createdBy,
+		positionsQty, totalQty, orderItemWeight, logisticsAllocationMethod, parentOrderID, createdBy,
 	).Scan(
 		&order.OrderID,
 		&order.OrderNumber,
@@ -206,6 +230,7 @@ func (r *SupplierOrderRepository) Create(ctx context.Context, orderNumber string
 		&order.PositionsQty,
 		&order.TotalQty,
 		&order.OrderItemWeight,
+		&order.LogisticsAllocationMethod,
 		&order.ParentOrderID,
 		&order.CreatedBy,
 		&order.CreatedAt,
@@ -226,7 +251,7 @@ func (r *SupplierOrderRepository) Create(ctx context.Context, orderNumber string
 	return &order, nil
 }
 
-func (r *SupplierOrderRepository) Update(ctx context.Context, orderID uuid.UUID, orderNumber string, buyer *string, statusID *uuid.UUID, purchaseDate, plannedReceiptDate, actualReceiptDate *time.Time, logisticsChinaMsk, logisticsMskKzn, logisticsAdditional, logisticsTotal, orderItemCost, orderItemWeight *float64, positionsQty, totalQty int, parentOrderID, updatedBy *uuid.UUID) (*SupplierOrder, error) {
+func (r *SupplierOrderRepository) Update(ctx context.Context, orderID uuid.UUID, orderNumber string, buyer *string, statusID *uuid.UUID, purchaseDate, plannedReceiptDate, actualReceiptDate *time.Time, logisticsChinaMsk, logisticsMskKzn, logisticsAdditional, logisticsTotal, orderItemCost, orderItemWeight *float64, positionsQty, totalQty int, logisticsAllocationMethod string, parentOrderID, updatedBy *uuid.UUID) (*SupplierOrder, error) {
 	query := `
 		UPDATE supplier_orders
 		SET order_number = $1, buyer = $2, status_id = $3, purchase_date = $4,
@@ -234,14 +259,15 @@ func (r *SupplierOrderRepository) Update(ctx context.Context, orderID uuid.UUID,
 		    logistics_china_msk = $7, logistics_msk_kzn = $8,
 		    logistics_additional = $9, logistics_total = $10,
 		    order_item_cost = $11, positions_qty = $12, total_qty = $13,
-		    order_item_weight = $14, parent_order_id = $15, updated_by = $16,
+		    order_item_weight = $14, logistics_allocation_method = $15,
+		    parent_order_id = $16, updated_by = $17,
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE order_id = $17
+		WHERE order_id = $18
 		RETURNING order_id, order_number, buyer, status_id, purchase_date,
 		          planned_receipt_date, actual_receipt_date, logistics_china_msk,
 		          logistics_msk_kzn, logistics_additional, logistics_total,
 		          order_item_cost, positions_qty, total_qty, order_item_weight,
-		          parent_order_id, created_by, created_at, updated_by, updated_at
+		          logistics_allocation_method, parent_order_id, created_by, created_at, updated_by, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -252,7 +278,7 @@ func (r *SupplierOrderRepository) Update(ctx context.Context, orderID uuid.UUID,
 		orderNumber, buyer, statusID, purchaseDate, plannedReceiptDate,
 		actualReceiptDate, logisticsChinaMsk, logisticsMskKzn,
 		logisticsAdditional, logisticsTotal, orderItemCost,
-		positionsQty, totalQty, orderItemWeight, parentOrderID, updatedBy, orderID,
+		positionsQty, totalQty, orderItemWeight, logisticsAllocationMethod, parentOrderID, updatedBy, orderID,
 	).Scan(
 		&order.OrderID,
 		&order.OrderNumber,
@@ -269,6 +295,7 @@ func (r *SupplierOrderRepository) Update(ctx context.Context, orderID uuid.UUID,
 		&order.PositionsQty,
 		&order.TotalQty,
 		&order.OrderItemWeight,
+		&order.LogisticsAllocationMethod,
 		&order.ParentOrderID,
 		&order.CreatedBy,
 		&order.CreatedAt,
@@ -340,3 +367,444 @@ func (r *SupplierOrderRepository) UpdateAggregates(ctx context.Context, orderID
 
 	return nil
 }
+
+// SupplierOrderTreeNode is one order of a parent/child tree with its own item totals.
+type SupplierOrderTreeNode struct {
+	OrderID       uuid.UUID
+	OrderNumber   string
+	StatusID      *uuid.UUID
+	ParentOrderID *uuid.UUID
+	Depth         int
+	OrderedQty    int
+	ReceivedQty   int
+}
+
+// SplitRemainder moves the undelivered remainder (ordered_qty - received_qty) of every item into a new
+// child order linked via parent_order_id. Parent items are reduced to the received quantity; total_*
+// amounts are split proportionally, unit prices are copied. Order logistics (china-msk, msk-kzn,
+// additional) is split in the share of item logistics moved to the child (by quantity if no logistics
+// was allocated yet), so reallocating it keeps the self cost of both orders. Aggregates of both orders
+// are recalculated. Returns ErrNothingReceived if no item has been received yet.
+func (r *SupplierOrderRepository) SplitRemainder(ctx context.Context, orderID uuid.UUID, childOrderNumber string, childStatusID *uuid.UUID, userID uuid.UUID) (*SupplierOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var parent SupplierOrder
+	err = tx.QueryRow(ctx, `
+		SELECT order_id, buyer, purchase_date, planned_receipt_date
+		FROM supplier_orders
+		WHERE order_id = $1
+		FOR UPDATE
+	`, orderID).Scan(
+		&parent.OrderID,
+		&parent.Buyer,
+		&parent.PurchaseDate,
+		&parent.PlannedReceiptDate,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSupplierOrderNotFound
+		}
+		return nil, err
+	}
+
+	var remainderItems, receivedQty int
+	err = tx.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE ordered_qty > received_qty),
+			COALESCE(SUM(received_qty), 0)
+		FROM supplier_order_items
+		WHERE order_id = $1
+	`, orderID).Scan(&remainderItems, &receivedQty)
+	if err != nil {
+		return nil, err
+	}
+	if remainderItems == 0 {
+		return nil, ErrNothingToSplit
+	}
+	// Без принятого товара разделение переносит в дочерний заказ все позиции и оставляет пустой родитель
+	if receivedQty == 0 {
+		return nil, ErrNothingReceived
+	}
+
+	var childShare float64
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(CASE
+			WHEN COALESCE(SUM(total_logistics), 0) > 0
+				THEN SUM(total_logistics * (ordered_qty - received_qty) / ordered_qty) / SUM(total_logistics)
+			ELSE SUM(ordered_qty - received_qty)::numeric / SUM(ordered_qty)
+		END, 0)::float8
+		FROM supplier_order_items
+		WHERE order_id = $1 AND ordered_qty > 0
+	`, orderID).Scan(&childShare)
+	if err != nil {
+		return nil, err
+	}
+
+	var childID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO supplier_orders (
+			order_number, buyer, status_id, purchase_date, planned_receipt_date,
+			parent_order_id, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING order_id
+	`, childOrderNumber, parent.Buyer, childStatusID, parent.PurchaseDate, parent.PlannedReceiptDate, orderID, userID).Scan(&childID)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "duplicate key") ||
+			strings.Contains(errMsg, "unique constraint") {
+			return nil, ErrSupplierOrderExists
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO supplier_order_items (
+			order_id, product_id, warehouse_id, ordered_qty, received_qty,
+			purchase_price, total_price, total_weight, total_logistics,
+			unit_logistics, unit_self_cost, total_self_cost, fulfillment_cost
+		)
+		SELECT $1, product_id, warehouse_id, ordered_qty - received_qty, 0,
+		       purchase_price,
+		       total_price * (ordered_qty - received_qty) / ordered_qty,
+		       ROUND(total_weight::numeric * (ordered_qty - received_qty) / ordered_qty)::int,
+		       total_logistics * (ordered_qty - received_qty) / ordered_qty,
+		       unit_logistics, unit_self_cost,
+		       total_self_cost * (ordered_qty - received_qty) / ordered_qty,
+		       fulfillment_cost
+		FROM supplier_order_items
+		WHERE order_id = $2 AND ordered_qty > received_qty
+	`, childID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE supplier_orders c
+		SET logistics_china_msk = ROUND(p.logistics_china_msk * $3::numeric, 2),
+		    logistics_msk_kzn = ROUND(p.logistics_msk_kzn * $3::numeric, 2),
+		    logistics_additional = ROUND(p.logistics_additional * $3::numeric, 2)
+		FROM supplier_orders p
+		WHERE c.order_id = $1 AND p.order_id = $2
+	`, childID, orderID, childShare)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE supplier_orders p
+		SET logistics_china_msk = p.logistics_china_msk - c.logistics_china_msk,
+		    logistics_msk_kzn = p.logistics_msk_kzn - c.logistics_msk_kzn,
+		    logistics_additional = p.logistics_additional - c.logistics_additional
+		FROM supplier_orders c
+		WHERE p.order_id = $1 AND c.order_id = $2
+	`, orderID, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE supplier_order_items
+		SET total_price = total_price * received_qty / ordered_qty,
+		    total_weight = ROUND(total_weight::numeric * received_qty / ordered_qty)::int,
+		    total_logistics = total_logistics * received_qty / ordered_qty,
+		    total_self_cost = total_self_cost * received_qty / ordered_qty,
+		    ordered_qty = received_qty
+		WHERE order_id = $1 AND ordered_qty > received_qty
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range []uuid.UUID{orderID, childID} {
+		if err := updateAggregatesFromItems(ctx, tx, id, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, childID)
+}
+
+// SupplierOrderDraftItem - позиция заказа, создаваемого вместе с позициями (например, из предложений дозаказа)
+type SupplierOrderDraftItem struct {
+	ProductID     uuid.UUID
+	WarehouseID   uuid.UUID
+	OrderedQty    int
+	PurchasePrice *float64
+	TotalWeight   int
+}
+
+// CreateWithItems creates an order together with its items and recalculates the order aggregates, in one
+// transaction. Item total prices are purchase price × quantity; logistics is left to LandedCostService.
+func (r *SupplierOrderRepository) CreateWithItems(ctx context.Context, orderNumber string, buyer *string, statusID *uuid.UUID, purchaseDate *time.Time, items []SupplierOrderDraftItem, userID uuid.UUID) (*SupplierOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO supplier_orders (order_number, buyer, status_id, purchase_date, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING order_id
+	`, orderNumber, buyer, statusID, purchaseDate, userID).Scan(&orderID)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "duplicate key") ||
+			strings.Contains(errMsg, "unique constraint") {
+			return nil, ErrSupplierOrderExists
+		}
+		return nil, err
+	}
+
+	for _, item := range items {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO supplier_order_items (
+				order_id, product_id, warehouse_id, ordered_qty, purchase_price, total_price, total_weight
+			)
+			VALUES ($1, $2, $3, $4, $5, $5::numeric * $4::int, $6)
+		`, orderID, item.ProductID, item.WarehouseID, item.OrderedQty, item.PurchasePrice, item.TotalWeight)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := updateAggregatesFromItems(ctx, tx, orderID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, orderID)
+}
+
+// updateAggregatesFromItems recalculates order totals from its items inside a transaction,
+// the same way SupplierOrderItemService does after item changes.
+func updateAggregatesFromItems(ctx context.Context, tx pgx.Tx, orderID, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE supplier_orders o
+		SET positions_qty = a.positions_qty,
+		    total_qty = a.total_qty,
+		    order_item_weight = CASE WHEN a.positions_qty > 0 THEN a.total_weight END,
+		    order_item_cost = CASE WHEN a.positions_qty > 0 THEN a.total_cost END,
+		    logistics_total = CASE WHEN a.positions_qty > 0 THEN a.total_logistics END,
+		    updated_by = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		FROM (
+			SELECT COUNT(*) AS positions_qty,
+			       COALESCE(SUM(ordered_qty), 0) AS total_qty,
+			       COALESCE(SUM(total_weight), 0) AS total_weight,
+			       COALESCE(SUM(total_price), 0) AS total_cost,
+			       COALESCE(SUM(total_logistics), 0) AS total_logistics
+			FROM supplier_order_items
+			WHERE order_id = $1
+		) a
+		WHERE o.order_id = $1
+	`, orderID, userID)
+	return err
+}
+
+// GetTree returns the whole tree the order belongs to, starting from its root ancestor.
+// Nodes are ordered by depth; the path arrays guard against cycles in parent_order_id.
+func (r *SupplierOrderRepository) GetTree(ctx context.Context, orderID uuid.UUID) ([]SupplierOrderTreeNode, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT order_id, parent_order_id, 0 AS depth, ARRAY[order_id] AS path
+			FROM supplier_orders
+			WHERE order_id = $1
+			UNION ALL
+			SELECT o.order_id, o.parent_order_id, a.depth + 1, a.path || o.order_id
+			FROM supplier_orders o
+			JOIN ancestors a ON o.order_id = a.parent_order_id
+			WHERE NOT o.order_id = ANY(a.path)
+		),
+		root AS (
+			SELECT order_id
+			FROM ancestors
+			ORDER BY depth DESC
+			LIMIT 1
+		),
+		tree AS (
+			SELECT o.order_id, 0 AS depth, ARRAY[o.order_id] AS path
+			FROM supplier_orders o
+			JOIN root ON root.order_id = o.order_id
+			UNION ALL
+			SELECT o.order_id, t.depth + 1, t.path || o.order_id
+			FROM supplier_orders o
+			JOIN tree t ON o.parent_order_id = t.order_id
+			WHERE NOT o.order_id = ANY(t.path)
+		)
+		SELECT so.order_id, so.order_number, so.status_id, so.parent_order_id, t.depth,
+		       COALESCE(SUM(i.ordered_qty), 0), COALESCE(SUM(i.received_qty), 0)
+		FROM tree t
+		JOIN supplier_orders so ON so.order_id = t.order_id
+		LEFT JOIN supplier_order_items i ON i.order_id = so.order_id
+		GROUP BY so.order_id, so.order_number, so.status_id, so.parent_order_id, t.depth, so.created_at
+		ORDER BY t.depth, so.created_at, so.order_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []SupplierOrderTreeNode
+	for rows.Next() {
+		var node SupplierOrderTreeNode
+		if err := rows.Scan(
+			&node.OrderID,
+			&node.OrderNumber,
+			&node.StatusID,
+			&node.ParentOrderID,
+			&node.Depth,
+			&node.OrderedQty,
+			&node.ReceivedQty,
+		); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, ErrSupplierOrderNotFound
+	}
+
+	return nodes, nil
+}
+
+// IsDescendant reports whether candidateID is orderID itself or lies below it in the parent_order_id tree.
+func (r *SupplierOrderRepository) IsDescendant(ctx context.Context, orderID, candidateID uuid.UUID) (bool, error) {
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT order_id
+			FROM supplier_orders
+			WHERE order_id = $1
+			UNION
+			SELECT o.order_id
+			FROM supplier_orders o
+			JOIN descendants d ON o.parent_order_id = d.order_id
+		)
+		SELECT EXISTS (SELECT 1 FROM descendants WHERE order_id = $2)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var found bool
+	if err := r.pool.QueryRow(ctx, query, orderID, candidateID).Scan(&found); err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// CountChildren returns the number of direct child orders.
+func (r *SupplierOrderRepository) CountChildren(ctx context.Context, orderID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM supplier_orders
+		WHERE parent_order_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, orderID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ItemLandedCost holds server-computed cost fields of one order item.
+type ItemLandedCost struct {
+	OrderItemID    uuid.UUID
+	TotalPrice     *float64
+	TotalLogistics float64
+	UnitLogistics  *float64
+	UnitSelfCost   *float64
+	TotalSelfCost  *float64
+}
+
+// ApplyLandedCosts writes computed item costs and recalculates order aggregates in one transaction.
+func (r *SupplierOrderRepository) ApplyLandedCosts(ctx context.Context, orderID uuid.UUID, costs []ItemLandedCost, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, cost := range costs {
+		_, err = tx.Exec(ctx, `
+			UPDATE supplier_order_items
+			SET total_price = $1,
+			    total_logistics = $2,
+			    unit_logistics = $3,
+			    unit_self_cost = $4,
+			    total_self_cost = $5
+			WHERE order_item_id = $6 AND order_id = $7
+		`, cost.TotalPrice, cost.TotalLogistics, cost.UnitLogistics, cost.UnitSelfCost, cost.TotalSelfCost, cost.OrderItemID, orderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := updateAggregatesFromItems(ctx, tx, orderID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateStatus moves the order from fromStatusID to toStatusID. Returns ErrStatusConflict if the order
+// no longer has fromStatusID, i.e. its status was changed by a concurrent request.
+func (r *SupplierOrderRepository) UpdateStatus(ctx context.Context, orderID uuid.UUID, fromStatusID *uuid.UUID, toStatusID, userID uuid.UUID) error {
+	query := `
+		UPDATE supplier_orders
+		SET status_id = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE order_id = $3 AND status_id IS NOT DISTINCT FROM $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, toStatusID, userID, orderID, fromStatusID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrTransferNotFound       = errors.New("transfer not found")
+	ErrTransferExists         = errors.New("transfer already exists")
+	ErrTransferNotEditable    = errors.New("transfer can only be changed in draft status")
+	ErrSameWarehouse          = errors.New("source and destination warehouses must differ")
+	ErrEmptyTransfer          = errors.New("transfer has no items")
+	ErrDuplicateItem          = errors.New("product is listed more than once")
+	ErrReceivedExceedsShipped = errors.New("received quantity exceeds shipped quantity")
+)
+
+// Статусы перемещения между складами
+const (
+	TransferStatusDraft     = "Черновик"
+	TransferStatusInTransit = "В пути"
+	TransferStatusReceived  = "Получено"
+	TransferStatusCancelled = "Отменено"
+)
+
+// Transfer - перемещение товаров со склада-отправителя на склад-получатель
+type Transfer struct {
+	TransferID             uuid.UUID
+	TransferNumber         string
+	SourceWarehouseID      uuid.UUID
+	DestinationWarehouseID uuid.UUID
+	Status                 string
+	ShipmentDate           *time.Time
+	ReceiptDate            *time.Time
+	Notes                  *string
+	CreatedBy              *uuid.UUID
+	CreatedAt              time.Time
+	UpdatedBy              *uuid.UUID
+	UpdatedAt              time.Time
+}
+
+type TransferItem struct {
+	TransferItemID uuid.UUID
+	TransferID     uuid.UUID
+	ProductID      uuid.UUID
+	Article        string
+	ShippedQty     int
+	ReceivedQty    int
+}
+
+// TransferItemInput - товар и количество для позиций перемещения
+type TransferItemInput struct {
+	ProductID uuid.UUID
+	Quantity  int
+}
+
+type TransferRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTransferRepository(pool *pgxpool.Pool) *TransferRepository {
+	return &TransferRepository{pool: pool}
+}
+
+const transferColumns = `
+	transfer_id, transfer_number, source_warehouse_id, destination_warehouse_id, status,
+	shipment_date, receipt_date, notes, created_by, created_at, updated_by, updated_at
+`
+
+func scanTransfer(row pgx.Row, transfer *Transfer) error {
+	return row.Scan(
+		&transfer.TransferID,
+		&transfer.TransferNumber,
+		&transfer.SourceWarehouseID,
+		&transfer.DestinationWarehouseID,
+		&transfer.Status,
+		&transfer.ShipmentDate,
+		&transfer.ReceiptDate,
+		&transfer.Notes,
+		&transfer.CreatedBy,
+		&transfer.CreatedAt,
+		&transfer.UpdatedBy,
+		&transfer.UpdatedAt,
+	)
+}
+
+func transferError(err error) error {
+	errMsg := err.Error()
+	if strings.Contains(errMsg, "transfers_distinct_warehouses") {
+		return ErrSameWarehouse
+	}
+	if strings.Contains(errMsg, "transfers_dates") {
+		return ErrInvalidDateRange
+	}
+	if strings.Contains(errMsg, "transfer_items_transfer_id_product_id_key") {
+		return ErrDuplicateItem
+	}
+	if strings.Contains(errMsg, "transfer_items_received_qty") {
+		return ErrReceivedExceedsShipped
+	}
+	if strings.Contains(errMsg, "check constraint") {
+		return ErrInvalidQuantity
+	}
+	if strings.Contains(errMsg, "duplicate key") ||
+		strings.Contains(errMsg, "unique constraint") {
+		return ErrTransferExists
+	}
+	return err
+}
+
+func (r *TransferRepository) GetByID(ctx context.Context, transferID uuid.UUID) (*Transfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM transfers
+		WHERE transfer_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var transfer Transfer
+	err := scanTransfer(r.pool.QueryRow(ctx, query, transferID), &transfer)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// List returns transfers, optionally only in the given status and/or touching the warehouse as source or destination.
+func (r *TransferRepository) List(ctx context.Context, limit, offset int, status *string, warehouseID *uuid.UUID) ([]Transfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM transfers
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argPos := 1
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, *status)
+		argPos++
+	}
+	if warehouseID != nil {
+		query += fmt.Sprintf(" AND (source_warehouse_id = $%d OR destination_warehouse_id = $%d)", argPos, argPos)
+		args = append(args, *warehouseID)
+		argPos++
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC, transfer_id LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []Transfer
+	for rows.Next() {
+		var transfer Transfer
+		if err := scanTransfer(rows, &transfer); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (r *TransferRepository) GetItems(ctx context.Context, transferID uuid.UUID) ([]TransferItem, error) {
+	query := `
+		SELECT ti.transfer_item_id, ti.transfer_id, ti.product_id, p.article, ti.shipped_qty, ti.received_qty
+		FROM transfer_items ti
+		JOIN products p ON p.product_id = ti.product_id
+		WHERE ti.transfer_id = $1
+		ORDER BY p.article
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, query, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []TransferItem
+	for rows.Next() {
+		var item TransferItem
+		if err := rows.Scan(
+			&item.TransferItemID,
+			&item.TransferID,
+			&item.ProductID,
+			&item.Article,
+			&item.ShippedQty,
+			&item.ReceivedQty,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func insertTransferItems(ctx context.Context, tx pgx.Tx, transferID uuid.UUID, items []TransferItemInput) error {
+	for _, item := range items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO transfer_items (transfer_id, product_id, shipped_qty)
+			VALUES ($1, $2, $3)
+		`, transferID, item.ProductID, item.Quantity)
+		if err != nil {
+			return transferError(err)
+		}
+	}
+	return nil
+}
+
+// Create inserts a draft transfer with its items in one transaction.
+func (r *TransferRepository) Create(ctx context.Context, transferNumber string, sourceWarehouseID, destinationWarehouseID uuid.UUID, notes *string, items []TransferItemInput, createdBy uuid.UUID) (*Transfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var transfer Transfer
+	err = scanTransfer(tx.QueryRow(ctx, `
+		INSERT INTO transfers (transfer_number, source_warehouse_id, destination_warehouse_id, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+transferColumns,
+		transferNumber, sourceWarehouseID, destinationWarehouseID, notes, createdBy,
+	), &transfer)
+	if err != nil {
+		return nil, transferError(err)
+	}
+
+	if err := insertTransferItems(ctx, tx, transfer.TransferID, items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// Update replaces the header and items of a draft transfer in one transaction. Returns
+// ErrTransferNotEditable once the transfer has left the draft status.
+func (r *TransferRepository) Update(ctx context.Context, transferID uuid.UUID, transferNumber string, sourceWarehouseID, destinationWarehouseID uuid.UUID, notes *string, items []TransferItemInput, updatedBy uuid.UUID) (*Transfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status
+		FROM transfers
+		WHERE transfer_id = $1
+		FOR UPDATE
+	`, transferID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+	if status != TransferStatusDraft {
+		return nil, ErrTransferNotEditable
+	}
+
+	var transfer Transfer
+	err = scanTransfer(tx.QueryRow(ctx, `
+		UPDATE transfers
+		SET transfer_number = $1, source_warehouse_id = $2, destination_warehouse_id = $3, notes = $4,
+		    updated_by = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE transfer_id = $6
+		RETURNING `+transferColumns,
+		transferNumber, sourceWarehouseID, destinationWarehouseID, notes, updatedBy, transferID,
+	), &transfer)
+	if err != nil {
+		return nil, transferError(err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM transfer_items WHERE transfer_id = $1`, transferID); err != nil {
+		return nil, err
+	}
+	if err := insertTransferItems(ctx, tx, transferID, items); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// Delete removes a draft or cancelled transfer; shipped and received transfers affect stock and are kept.
+func (r *TransferRepository) Delete(ctx context.Context, transferID uuid.UUID) error {
+	query := `
+		DELETE FROM transfers
+		WHERE transfer_id = $1 AND status IN ($2, $3)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, transferID, TransferStatusDraft, TransferStatusCancelled)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, transferID); err != nil {
+			return err
+		}
+		return ErrTransferNotEditable
+	}
+
+	return nil
+}
+
+// Ship moves a draft transfer to "В пути": its shipped quantities leave the source warehouse on shipmentDate.
+func (r *TransferRepository) Ship(ctx context.Context, transferID uuid.UUID, shipmentDate time.Time, userID uuid.UUID) error {
+	query := `
+		UPDATE transfers
+		SET status = $1, shipment_date = $2, updated_by = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE transfer_id = $4 AND status = $5
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, TransferStatusInTransit, shipmentDate, userID, transferID, TransferStatusDraft)
+	if err != nil {
+		return transferError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
+
+// Receive stores the received quantities (items missing from received get their shipped quantity) and moves
+// an in-transit transfer to "Получено" dated receiptDate, in one transaction.
+func (r *TransferRepository) Receive(ctx context.Context, transferID uuid.UUID, receiptDate time.Time, received map[uuid.UUID]int, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE transfers
+		SET status = $1, receipt_date = $2, updated_by = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE transfer_id = $4 AND status = $5
+	`, TransferStatusReceived, receiptDate, userID, transferID, TransferStatusInTransit)
+	if err != nil {
+		return transferError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE transfer_items
+		SET received_qty = shipped_qty
+		WHERE transfer_id = $1
+	`, transferID); err != nil {
+		return err
+	}
+
+	for productID, quantity := range received {
+		result, err := tx.Exec(ctx, `
+			UPDATE transfer_items
+			SET received_qty = $1
+			WHERE transfer_id = $2 AND product_id = $3
+		`, quantity, transferID, productID)
+		if err != nil {
+			return transferError(err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrProductNotFound
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Cancel moves a draft or in-transit transfer to "Отменено"; a cancelled transfer no longer affects stock.
+func (r *TransferRepository) Cancel(ctx context.Context, transferID uuid.UUID, fromStatus string, userID uuid.UUID) error {
+	query := `
+		UPDATE transfers
+		SET status = $1, updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE transfer_id = $3 AND status = $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, TransferStatusCancelled, userID, transferID, fromStatus)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
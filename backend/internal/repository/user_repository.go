@@ -14,6 +14,7 @@ import (
 var (
 	ErrUserNotFound = errors.New("user not found")
 	ErrUserExists   = errors.New("user already exists")
+	ErrUserArchived = errors.New("user is archived")
 )
 
 type User struct {
@@ -24,6 +25,8 @@ type User struct {
 	Patronymic   *string
 	PasswordHash string
 	RoleID       uuid.UUID
+	IsArchived   bool
+	ArchivedAt   *time.Time
 }
 
 type UserRepository struct {
@@ -36,7 +39,7 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
-		SELECT user_id, email, name, surname, patronymic, password_hash, role_id
+		SELECT user_id, email, name, surname, patronymic, password_hash, role_id, is_archived, archived_at
 		FROM users
 		WHERE email = $1
 	`
@@ -53,6 +56,8 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*User, e
 		&user.Patronymic,
 		&user.PasswordHash,
 		&user.RoleID,
+		&user.IsArchived,
+		&user.ArchivedAt,
 	)
 
 	if err != nil {
@@ -67,7 +72,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*User, e
 
 func (r *UserRepository) GetByID(ctx context.Context, userID uuid.UUID) (*User, error) {
 	query := `
-		SELECT user_id, email, name, surname, patronymic, password_hash, role_id
+		SELECT user_id, email, name, surname, patronymic, password_hash, role_id, is_archived, archived_at
 		FROM users
 		WHERE user_id = $1
 	`
@@ -84,6 +89,8 @@ func (r *UserRepository) GetByID(ctx context.Context, userID uuid.UUID) (*User,
 		&user.Patronymic,
 		&user.PasswordHash,
 		&user.RoleID,
+		&user.IsArchived,
+		&user.ArchivedAt,
 	)
 
 	if err != nil {
@@ -96,10 +103,11 @@ func (r *UserRepository) GetByID(ctx context.Context, userID uuid.UUID) (*User,
 	return &user, nil
 }
 
-func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]User, error) {
+func (r *UserRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]User, error) {
 	query := `
-		SELECT user_id, email, name, surname, patronymic, password_hash, role_id
+		SELECT user_id, email, name, surname, patronymic, password_hash, role_id, is_archived, archived_at
 		FROM users
+		WHERE $3 OR NOT is_archived
 		ORDER BY user_id
 		LIMIT $1 OFFSET $2
 	`
@@ -107,7 +115,7 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]User, e
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +132,8 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]User, e
 			&user.Patronymic,
 			&user.PasswordHash,
 			&user.RoleID,
+			&user.IsArchived,
+			&user.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -141,7 +151,7 @@ func (r *UserRepository) Create(ctx context.Context, email, passwordHash string,
 	query := `
 		INSERT INTO users (email, password_hash, role_id, name, surname, patronymic)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING user_id, email, name, surname, patronymic, password_hash, role_id
+		RETURNING user_id, email, name, surname, patronymic, password_hash, role_id, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -156,6 +166,8 @@ func (r *UserRepository) Create(ctx context.Context, email, passwordHash string,
 		&user.Patronymic,
 		&user.PasswordHash,
 		&user.RoleID,
+		&user.IsArchived,
+		&user.ArchivedAt,
 	)
 
 	if err != nil {
@@ -185,7 +197,7 @@ func (r *UserRepository) Update(ctx context.Context, userID uuid.UUID, email str
 			UPDATE users
 			SET email = $1, role_id = $2, name = $3, surname = $4, patronymic = $5, password_hash = $6
 			WHERE user_id = $7
-			RETURNING user_id, email, name, surname, patronymic, password_hash, role_id
+			RETURNING user_id, email, name, surname, patronymic, password_hash, role_id, is_archived, archived_at
 		`
 		err = r.pool.QueryRow(ctx, query, email, roleID, name, surname, patronymic, passwordHash, userID).Scan(
 			&user.UserID,
@@ -195,13 +207,15 @@ func (r *UserRepository) Update(ctx context.Context, userID uuid.UUID, email str
 			&user.Patronymic,
 			&user.PasswordHash,
 			&user.RoleID,
+			&user.IsArchived,
+			&user.ArchivedAt,
 		)
 	} else {
 		query = `
 			UPDATE users
 			SET email = $1, role_id = $2, name = $3, surname = $4, patronymic = $5
 			WHERE user_id = $6
-			RETURNING user_id, email, name, surname, patronymic, password_hash, role_id
+			RETURNING user_id, email, name, surname, patronymic, password_hash, role_id, is_archived, archived_at
 		`
 		err = r.pool.QueryRow(ctx, query, email, roleID, name, surname, patronymic, userID).Scan(
 			&user.UserID,
@@ -211,6 +225,8 @@ func (r *UserRepository) Update(ctx context.Context, userID uuid.UUID, email str
 			&user.Patronymic,
 			&user.PasswordHash,
 			&user.RoleID,
+			&user.IsArchived,
+			&user.ArchivedAt,
 		)
 	}
 
@@ -230,9 +246,34 @@ func (r *UserRepository) Update(ctx context.Context, userID uuid.UUID, email str
 	return &user, nil
 }
 
-func (r *UserRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+// Archive hides the user from lists and new documents instead of deleting it, so existing documents and
+// stock history keep their references. Archiving an archived user keeps the original archived_at.
+func (r *UserRepository) Archive(ctx context.Context, userID uuid.UUID) error {
 	query := `
-		DELETE FROM users
+		UPDATE users
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE user_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepository) Restore(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET is_archived = FALSE, archived_at = NULL
 		WHERE user_id = $1
 	`
 
@@ -14,6 +14,7 @@ import (
 var (
 	ErrWarehouseNotFound = errors.New("warehouse not found")
 	ErrWarehouseExists   = errors.New("warehouse already exists")
+	ErrWarehouseArchived = errors.New("warehouse is archived")
 )
 
 type Warehouse struct {
@@ -21,6 +22,8 @@ type Warehouse struct {
 	Name            string
 	WarehouseTypeID *uuid.UUID
 	Location        *string
+	IsArchived      bool
+	ArchivedAt      *time.Time
 }
 
 type WarehouseRepository struct {
@@ -33,7 +36,7 @@ func NewWarehouseRepository(pool *pgxpool.Pool) *WarehouseRepository {
 
 func (r *WarehouseRepository) GetByID(ctx context.Context, warehouseID uuid.UUID) (*Warehouse, error) {
 	query := `
-		SELECT warehouse_id, name, warehouse_type_id, location
+		SELECT warehouse_id, name, warehouse_type_id, location, is_archived, archived_at
 		FROM warehouses
 		WHERE warehouse_id = $1
 	`
@@ -47,6 +50,8 @@ func (r *WarehouseRepository) GetByID(ctx context.Context, warehouseID uuid.UUID
 		&warehouse.Name,
 		&warehouse.WarehouseTypeID,
 		&warehouse.Location,
+		&warehouse.IsArchived,
+		&warehouse.ArchivedAt,
 	)
 
 	if err != nil {
@@ -59,10 +64,11 @@ func (r *WarehouseRepository) GetByID(ctx context.Context, warehouseID uuid.UUID
 	return &warehouse, nil
 }
 
-func (r *WarehouseRepository) List(ctx context.Context, limit, offset int) ([]Warehouse, error) {
+func (r *WarehouseRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]Warehouse, error) {
 	query := `
-		SELECT warehouse_id, name, warehouse_type_id, location
+		SELECT warehouse_id, name, warehouse_type_id, location, is_archived, archived_at
 		FROM warehouses
+		WHERE $3 OR NOT is_archived
 		ORDER BY warehouse_id
 		LIMIT $1 OFFSET $2
 	`
@@ -70,7 +76,7 @@ func (r *WarehouseRepository) List(ctx context.Context, limit, offset int) ([]Wa
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +90,8 @@ func (r *WarehouseRepository) List(ctx context.Context, limit, offset int) ([]Wa
 			&warehouse.Name,
 			&warehouse.WarehouseTypeID,
 			&warehouse.Location,
+			&warehouse.IsArchived,
+			&warehouse.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -101,7 +109,7 @@ func (r *WarehouseRepository) Create(ctx context.Context, name string, warehouse
 	query := `
 		INSERT INTO warehouses (name, warehouse_type_id, location)
 		VALUES ($1, $2, $3)
-		RETURNING warehouse_id, name, warehouse_type_id, location
+		RETURNING warehouse_id, name, warehouse_type_id, location, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -113,6 +121,8 @@ func (r *WarehouseRepository) Create(ctx context.Context, name string, warehouse
 		&warehouse.Name,
 		&warehouse.WarehouseTypeID,
 		&warehouse.Location,
+		&warehouse.IsArchived,
+		&warehouse.ArchivedAt,
 	)
 
 	if err != nil {
@@ -133,7 +143,7 @@ func (r *WarehouseRepository) Update(ctx context.Context, warehouseID uuid.UUID,
 		UPDATE warehouses
 		SET name = $1, warehouse_type_id = $2, location = $3
 		WHERE warehouse_id = $4
-		RETURNING warehouse_id, name, warehouse_type_id, location
+		RETURNING warehouse_id, name, warehouse_type_id, location, is_archived, archived_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -145,6 +155,8 @@ func (r *WarehouseRepository) Update(ctx context.Context, warehouseID uuid.UUID,
 		&warehouse.Name,
 		&warehouse.WarehouseTypeID,
 		&warehouse.Location,
+		&warehouse.IsArchived,
+		&warehouse.ArchivedAt,
 	)
 
 	if err != nil {
@@ -162,9 +174,34 @@ func (r *WarehouseRepository) Update(ctx context.Context, warehouseID uuid.UUID,
 	return &warehouse, nil
 }
 
-func (r *WarehouseRepository) Delete(ctx context.Context, warehouseID uuid.UUID) error {
+// Archive hides the warehouse from lists and new documents instead of deleting it, so existing documents and
+// stock history keep their references. Archiving an archived warehouse keeps the original archived_at.
+func (r *WarehouseRepository) Archive(ctx context.Context, warehouseID uuid.UUID) error {
 	query := `
-		DELETE FROM warehouses
+		UPDATE warehouses
+		SET is_archived = TRUE, archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE warehouse_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.pool.Exec(ctx, query, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrWarehouseNotFound
+	}
+
+	return nil
+}
+
+func (r *WarehouseRepository) Restore(ctx context.Context, warehouseID uuid.UUID) error {
+	query := `
+		UPDATE warehouses
+		SET is_archived = FALSE, archived_at = NULL
 		WHERE warehouse_id = $1
 	`
 
@@ -17,6 +17,9 @@ var (
 	ErrWarehouseTypeExists   = errors.New("warehouse type already exists")
 )
 
+// WarehouseTypeReturns - тип склада, на который принимается брак из возвратов маркетплейсов
+const WarehouseTypeReturns = "Склад возвратов"
+
 type WarehouseType struct {
 	WarehouseTypeID uuid.UUID
 	Name            string
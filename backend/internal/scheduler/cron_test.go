@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func minute(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"month start", "5 0 1 * *", minute("2024-01-15 10:00"), minute("2024-02-01 00:05")},
+		{"strictly after the matching minute", "5 0 1 * *", minute("2024-02-01 00:05"), minute("2024-03-01 00:05")},
+		{"seconds are truncated", "5 0 1 * *", minute("2024-02-01 00:04").Add(30 * time.Second), minute("2024-02-01 00:05")},
+		{"every 15 minutes", "*/15 * * * *", minute("2024-01-01 10:07"), minute("2024-01-01 10:15")},
+		{"step from value", "5/20 * * * *", minute("2024-01-01 10:06"), minute("2024-01-01 10:25")},
+		{"range with step", "0-30/10 8 * * *", minute("2024-01-01 08:25"), minute("2024-01-01 08:30")},
+		{"range with step wraps to next day", "0-30/10 8 * * *", minute("2024-01-01 08:31"), minute("2024-01-02 08:00")},
+		{"weekdays skip weekend", "0 9 * * 1-5", minute("2024-01-05 10:00"), minute("2024-01-08 09:00")},
+		{"sunday as 0", "0 0 * * 0", minute("2024-01-01 00:00"), minute("2024-01-07 00:00")},
+		{"sunday as 7", "0 0 * * 7", minute("2024-01-01 00:00"), minute("2024-01-07 00:00")},
+		{"day of month only", "0 0 13 * *", minute("2024-01-01 00:00"), minute("2024-01-13 00:00")},
+		{"day of week only", "0 0 * * 5", minute("2024-01-01 00:00"), minute("2024-01-05 00:00")},
+		{"day of month or day of week", "0 0 13 * 6", minute("2024-01-01 00:00"), minute("2024-01-06 00:00")},
+		{"day of month or day of week, month day first", "0 0 2 * 5", minute("2024-01-01 00:00"), minute("2024-01-02 00:00")},
+		{"month list", "0 12 1 1,7 *", minute("2024-02-01 00:00"), minute("2024-07-01 12:00")},
+		{"year end", "30 23 31 12 *", minute("2024-12-31 23:30"), minute("2025-12-31 23:30")},
+		{"leap day", "0 0 29 2 *", minute("2024-03-01 00:00"), minute("2028-02-29 00:00")},
+		{"never matches", "0 0 30 2 *", minute("2024-01-01 00:00"), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error: %v", tt.expr, err)
+			}
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Fatalf("Next(%s) for %q = %s, want %s", tt.from, tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	schedule, err := ParseCron("5 0 1 * *")
+	if err != nil {
+		t.Fatalf("ParseCron error: %v", err)
+	}
+
+	got := schedule.Next(time.Date(2024, 1, 31, 23, 0, 0, 0, loc))
+	want := time.Date(2024, 2, 1, 0, 5, 0, 0, loc)
+	if !got.Equal(want) || got.Location() != loc {
+		t.Fatalf("Next() = %s, want %s", got, want)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"1,,2 * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
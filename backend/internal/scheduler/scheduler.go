@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job - периодическая задача. Ошибка только логируется, следующий запуск выполняется по расписанию
+type Job func(ctx context.Context) error
+
+// Run runs job on the schedule until ctx is cancelled. Runs never overlap: a run that takes longer than
+// the interval skips the missed times. Meant to be started in its own goroutine.
+func Run(ctx context.Context, name string, schedule *Schedule, job Job) {
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Warn().Str("job", name).Msg("Schedule has no upcoming runs, job stopped")
+			return
+		}
+		log.Info().Str("job", name).Time("next", next).Msg("Job scheduled")
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		log.Info().Str("job", name).Msg("Job started")
+		if err := job(ctx); err != nil {
+			log.Error().Err(err).Str("job", name).Msg("Job failed")
+			continue
+		}
+		log.Info().Str("job", name).Msg("Job finished")
+	}
+}
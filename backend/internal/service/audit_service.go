@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AuditService пишет журнал изменений сущностей: кто, когда и что поменял.
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+func NewAuditService(repo *repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record saves the state of the entity before and after a change (nil for create and delete respectively)
+// together with the fields that differ. The user is taken from the request context. The change itself is
+// already saved, so a failure is logged and not returned.
+func (s *AuditService) Record(ctx context.Context, entityType string, entityID uuid.UUID, action string, before, after any) {
+	beforeData, err := marshalAuditState(before)
+	if err != nil {
+		log.Error().Err(err).Str("entityType", entityType).Str("entityId", entityID.String()).Msg("Failed to encode audit state")
+		return
+	}
+	afterData, err := marshalAuditState(after)
+	if err != nil {
+		log.Error().Err(err).Str("entityType", entityType).Str("entityId", entityID.String()).Msg("Failed to encode audit state")
+		return
+	}
+
+	changes, err := auditChanges(beforeData, afterData)
+	if err != nil {
+		log.Error().Err(err).Str("entityType", entityType).Str("entityId", entityID.String()).Msg("Failed to compute audit changes")
+		return
+	}
+
+	var userID *uuid.UUID
+	if id := auth.GetUserID(ctx); id != uuid.Nil {
+		userID = &id
+	}
+
+	if err := s.repo.Record(ctx, entityType, entityID, action, beforeData, afterData, changes, userID); err != nil {
+		log.Error().Err(err).
+			Str("entityType", entityType).
+			Str("entityId", entityID.String()).
+			Str("action", action).
+			Msg("Failed to record audit entry")
+	}
+}
+
+func (s *AuditService) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]dto.AuditEntryResponse, error) {
+	entries, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Interface("filter", filter).Msg("Failed to list audit entries")
+		return nil, err
+	}
+
+	result := make([]dto.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		var userIDStr *string
+		if entry.UserID != nil {
+			str := entry.UserID.String()
+			userIDStr = &str
+		}
+
+		result = append(result, dto.AuditEntryResponse{
+			AuditID:    entry.AuditID.String(),
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID.String(),
+			Action:     entry.Action,
+			Before:     entry.BeforeData,
+			After:      entry.AfterData,
+			Changes:    entry.Changes,
+			UserID:     userIDStr,
+			UserEmail:  entry.UserEmail,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func marshalAuditState(state any) ([]byte, error) {
+	if state == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(state)
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
+
+// auditChanges compares the top-level fields of two JSON objects and returns the changed ones as
+// {"field": {"old": ..., "new": ...}}. A missing state counts as an object without fields.
+func auditChanges(before, after []byte) ([]byte, error) {
+	oldFields := map[string]any{}
+	newFields := map[string]any{}
+	if before != nil {
+		if err := json.Unmarshal(before, &oldFields); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		if err := json.Unmarshal(after, &newFields); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := map[string]dto.AuditFieldChange{}
+	for field, oldValue := range oldFields {
+		if newValue := newFields[field]; !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = dto.AuditFieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	for field, newValue := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes[field] = dto.AuditFieldChange{Old: nil, New: newValue}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(changes)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
 	"warehouse-backend/internal/repository"
 
 	"github.com/rs/zerolog/log"
@@ -20,16 +21,20 @@ var (
 )
 
 type AuthService struct {
-	userRepo   *repository.UserRepository
-	roleRepo   *repository.RoleRepository
-	jwtManager *auth.JWTManager
+	userRepo       *repository.UserRepository
+	roleRepo       *repository.RoleRepository
+	permissionRepo *repository.PermissionRepository
+	jwtManager     *auth.JWTManager
+	audit          *AuditService
 }
 
-func NewAuthService(userRepo *repository.UserRepository, roleRepo *repository.RoleRepository, jwtManager *auth.JWTManager) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, roleRepo *repository.RoleRepository, permissionRepo *repository.PermissionRepository, jwtManager *auth.JWTManager, audit *AuditService) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		roleRepo:   roleRepo,
-		jwtManager: jwtManager,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		jwtManager:     jwtManager,
+		audit:          audit,
 	}
 }
 
@@ -49,6 +54,11 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (string
 		return "", nil, ErrInvalidCredentials
 	}
 
+	if user.IsArchived {
+		log.Warn().Str("userId", user.UserID.String()).Str("email", email).Msg("Login failed: user is archived")
+		return "", nil, ErrInvalidCredentials
+	}
+
 	token, err := s.jwtManager.GenerateToken(user.UserID, user.Email, user.RoleID)
 	if err != nil {
 		log.Error().Err(err).Str("userId", user.UserID.String()).Msg("Failed to generate JWT token")
@@ -86,9 +96,38 @@ func (s *AuthService) Register(ctx context.Context, email, password string, role
 	}
 
 	log.Info().Str("userId", user.UserID.String()).Str("email", email).Msg("User registered successfully")
+	s.audit.Record(ctx, repository.AuditEntityUser, user.UserID, repository.AuditActionCreate, nil, &dto.UserResponse{
+		UserID:     user.UserID.String(),
+		Email:      user.Email,
+		Name:       user.Name,
+		Surname:    user.Surname,
+		Patronymic: user.Patronymic,
+		RoleID:     user.RoleID.String(),
+	})
 	return user, nil
 }
 
+// ActiveRole implements middleware.UserChecker: it returns the user's current role, and active = false
+// if the user was archived or deleted after the token was issued.
+func (s *AuthService) ActiveRole(ctx context.Context, userID uuid.UUID) (uuid.UUID, bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			log.Warn().Str("userId", userID.String()).Msg("Token of a deleted user rejected")
+			return uuid.Nil, false, nil
+		}
+		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to get user for token check")
+		return uuid.Nil, false, err
+	}
+
+	if user.IsArchived {
+		log.Warn().Str("userId", userID.String()).Msg("Token of an archived user rejected")
+		return uuid.Nil, false, nil
+	}
+
+	return user.RoleID, true, nil
+}
+
 func (s *AuthService) GetCurrentUser(ctx context.Context, userID uuid.UUID) (*repository.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -97,3 +136,12 @@ func (s *AuthService) GetCurrentUser(ctx context.Context, userID uuid.UUID) (*re
 	}
 	return user, nil
 }
+
+func (s *AuthService) GetPermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	permissions, err := s.permissionRepo.GetByRoleID(ctx, roleID)
+	if err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to get permissions for role")
+		return nil, err
+	}
+	return permissionCodes(permissions), nil
+}
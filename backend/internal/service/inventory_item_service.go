@@ -15,16 +15,16 @@ type InventoryItemService struct {
 	inventoryRepo *repository.InventoryRepository
 	productRepo   *repository.ProductRepository
 	warehouseRepo *repository.WarehouseRepository
-	stockRepo     *repository.StockRepository
+	audit         *AuditService
 }
 
-func NewInventoryItemService(repo *repository.InventoryItemRepository, inventoryRepo *repository.InventoryRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, stockRepo *repository.StockRepository) *InventoryItemService {
+func NewInventoryItemService(repo *repository.InventoryItemRepository, inventoryRepo *repository.InventoryRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, audit *AuditService) *InventoryItemService {
 	return &InventoryItemService{
 		repo:          repo,
 		inventoryRepo: inventoryRepo,
 		productRepo:   productRepo,
 		warehouseRepo: warehouseRepo,
-		stockRepo:     stockRepo,
+		audit:         audit,
 	}
 }
 
@@ -96,6 +96,9 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 		log.Error().Err(err).Str("inventoryId", req.InventoryID).Msg("Failed to validate inventory")
 		return nil, err
 	}
+	if err := s.checkOpen(ctx, inventoryID); err != nil {
+		return nil, err
+	}
 
 	var productID *uuid.UUID
 	if req.ProductID != nil && *req.ProductID != "" {
@@ -106,7 +109,7 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 		}
 		productID = &id
 
-		_, err = s.productRepo.GetByID(ctx, id)
+		product, err := s.productRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrProductNotFound {
 				log.Warn().Str("productId", *req.ProductID).Msg("Product not found")
@@ -115,6 +118,10 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 			log.Error().Err(err).Str("productId", *req.ProductID).Msg("Failed to validate product")
 			return nil, err
 		}
+		if product.IsArchived {
+			log.Warn().Str("productId", *req.ProductID).Msg("Product is archived")
+			return nil, repository.ErrProductArchived
+		}
 	}
 
 	warehouseID, err := uuid.Parse(req.WarehouseID)
@@ -122,7 +129,7 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
 		return nil, repository.ErrWarehouseNotFound
 	}
-	_, err = s.warehouseRepo.GetByID(ctx, warehouseID)
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
 	if err != nil {
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
@@ -131,6 +138,10 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 		log.Error().Err(err).Str("warehouseId", req.WarehouseID).Msg("Failed to validate warehouse")
 		return nil, err
 	}
+	if warehouse.IsArchived {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
+	}
 
 	if req.ReceiptQty < 0 {
 		log.Warn().Int("receiptQty", req.ReceiptQty).Msg("Receipt quantity must be non-negative")
@@ -148,14 +159,6 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 		return nil, err
 	}
 
-	inventory, err := s.inventoryRepo.GetByID(ctx, inventoryID)
-	if err == nil {
-		createdBy := &inventory.CreatedBy
-		if err := s.stockRepo.UpdateStockByInventoryItem(ctx, productID, warehouseID, inventory.AdjustmentDate, createdBy); err != nil {
-			log.Error().Err(err).Str("inventoryItemId", item.InventoryItemID.String()).Msg("Failed to update stock snapshot")
-		}
-	}
-
 	var productIDStr *string
 	if item.ProductID != nil {
 		str := item.ProductID.String()
@@ -163,7 +166,7 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 	}
 
 	log.Info().Str("inventoryItemId", item.InventoryItemID.String()).Str("inventoryId", req.InventoryID).Str("warehouseId", req.WarehouseID).Msg("Inventory item created successfully")
-	return &dto.InventoryItemResponse{
+	result := &dto.InventoryItemResponse{
 		InventoryItemID: item.InventoryItemID.String(),
 		InventoryID:     item.InventoryID.String(),
 		ProductID:       productIDStr,
@@ -171,10 +174,17 @@ func (s *InventoryItemService) Create(ctx context.Context, req dto.InventoryItem
 		ReceiptQty:      item.ReceiptQty,
 		WriteOffQty:     item.WriteOffQty,
 		Reason:          item.Reason,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventoryItem, item.InventoryItemID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req dto.InventoryItemUpdateRequest) (*dto.InventoryItemResponse, error) {
+	before, err := s.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
 	inventoryID, err := uuid.Parse(req.InventoryID)
 	if err != nil {
 		log.Warn().Str("inventoryId", req.InventoryID).Msg("Invalid inventory ID format")
@@ -189,6 +199,14 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 		log.Error().Err(err).Str("inventoryId", req.InventoryID).Msg("Failed to validate inventory")
 		return nil, err
 	}
+	if err := s.checkOpen(ctx, inventoryID); err != nil {
+		return nil, err
+	}
+	if before.InventoryID != inventoryID.String() {
+		if err := s.checkOpen(ctx, uuid.MustParse(before.InventoryID)); err != nil {
+			return nil, err
+		}
+	}
 
 	var productID *uuid.UUID
 	if req.ProductID != nil && *req.ProductID != "" {
@@ -199,7 +217,7 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 		}
 		productID = &id
 
-		_, err = s.productRepo.GetByID(ctx, id)
+		product, err := s.productRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrProductNotFound {
 				log.Warn().Str("productId", *req.ProductID).Msg("Product not found")
@@ -208,6 +226,10 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 			log.Error().Err(err).Str("productId", *req.ProductID).Msg("Failed to validate product")
 			return nil, err
 		}
+		if product.IsArchived && (before.ProductID == nil || *before.ProductID != id.String()) {
+			log.Warn().Str("productId", *req.ProductID).Msg("Product is archived")
+			return nil, repository.ErrProductArchived
+		}
 	}
 
 	warehouseID, err := uuid.Parse(req.WarehouseID)
@@ -215,7 +237,7 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
 		return nil, repository.ErrWarehouseNotFound
 	}
-	_, err = s.warehouseRepo.GetByID(ctx, warehouseID)
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
 	if err != nil {
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
@@ -224,6 +246,10 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 		log.Error().Err(err).Str("warehouseId", req.WarehouseID).Msg("Failed to validate warehouse")
 		return nil, err
 	}
+	if warehouse.IsArchived && before.WarehouseID != warehouseID.String() {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
+	}
 
 	if req.ReceiptQty < 0 {
 		log.Warn().Int("receiptQty", req.ReceiptQty).Msg("Receipt quantity must be non-negative")
@@ -241,17 +267,6 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 		return nil, err
 	}
 
-	inventory, err := s.inventoryRepo.GetByID(ctx, inventoryID)
-	if err == nil {
-		updatedBy := inventory.UpdatedBy
-		if updatedBy == nil {
-			updatedBy = &inventory.CreatedBy
-		}
-		if err := s.stockRepo.UpdateStockByInventoryItem(ctx, productID, warehouseID, inventory.AdjustmentDate, updatedBy); err != nil {
-			log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to update stock snapshot")
-		}
-	}
-
 	var productIDStr *string
 	if item.ProductID != nil {
 		str := item.ProductID.String()
@@ -259,7 +274,7 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 	}
 
 	log.Info().Str("itemId", itemID.String()).Msg("Inventory item updated successfully")
-	return &dto.InventoryItemResponse{
+	result := &dto.InventoryItemResponse{
 		InventoryItemID: item.InventoryItemID.String(),
 		InventoryID:     item.InventoryID.String(),
 		ProductID:       productIDStr,
@@ -267,16 +282,24 @@ func (s *InventoryItemService) Update(ctx context.Context, itemID uuid.UUID, req
 		ReceiptQty:      item.ReceiptQty,
 		WriteOffQty:     item.WriteOffQty,
 		Reason:          item.Reason,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventoryItem, itemID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
 func (s *InventoryItemService) Delete(ctx context.Context, itemID uuid.UUID) error {
-	// Get item before deletion to get inventory_id for stock snapshot update
 	item, err := s.repo.GetByID(ctx, itemID)
 	if err != nil {
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to get inventory item for deletion")
 		return err
 	}
+	if err := s.checkOpen(ctx, item.InventoryID); err != nil {
+		return err
+	}
+	before, err := s.GetByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
 
 	err = s.repo.Delete(ctx, itemID)
 	if err != nil {
@@ -284,13 +307,22 @@ func (s *InventoryItemService) Delete(ctx context.Context, itemID uuid.UUID) err
 		return err
 	}
 
-	inventory, err := s.inventoryRepo.GetByID(ctx, item.InventoryID)
-	if err == nil {
-		if err := s.stockRepo.RevertStockByInventoryItem(ctx, item.ProductID, item.WarehouseID, inventory.AdjustmentDate); err != nil {
-			log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to update stock snapshot")
-		}
-	}
-
 	log.Info().Str("itemId", itemID.String()).Msg("Inventory item deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityInventoryItem, itemID, repository.AuditActionDelete, before, nil)
+	return nil
+}
+
+// checkOpen returns ErrInventoryClosed if the inventory is completed or cancelled: its items are already
+// reflected in stock snapshots (or deliberately not) and may no longer change.
+func (s *InventoryItemService) checkOpen(ctx context.Context, inventoryID uuid.UUID) error {
+	closed, err := s.inventoryRepo.IsClosed(ctx, inventoryID)
+	if err != nil {
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to check inventory status")
+		return err
+	}
+	if closed {
+		log.Warn().Str("inventoryId", inventoryID.String()).Msg("Inventory is closed")
+		return repository.ErrInventoryClosed
+	}
 	return nil
 }
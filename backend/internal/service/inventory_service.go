@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"warehouse-backend/internal/dto"
@@ -14,14 +15,36 @@ type InventoryService struct {
 	repo                *repository.InventoryRepository
 	inventoryStatusRepo *repository.InventoryStatusRepository
 	inventoryItemRepo   *repository.InventoryItemRepository
+	countRepo           *repository.InventoryCountRepository
+	productRepo         *repository.ProductRepository
+	warehouseRepo       *repository.WarehouseRepository
+	stockRepo           *repository.StockRepository
+	negativeStock       *StockPolicyService
+	transitions         *StatusTransitionService
+	hooks               map[string][]StatusHook
+	audit               *AuditService
 }
 
-func NewInventoryService(repo *repository.InventoryRepository, inventoryStatusRepo *repository.InventoryStatusRepository, inventoryItemRepo *repository.InventoryItemRepository) *InventoryService {
-	return &InventoryService{
+func NewInventoryService(repo *repository.InventoryRepository, inventoryStatusRepo *repository.InventoryStatusRepository, inventoryItemRepo *repository.InventoryItemRepository, countRepo *repository.InventoryCountRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, stockRepo *repository.StockRepository, negativeStock *StockPolicyService, transitions *StatusTransitionService, audit *AuditService) *InventoryService {
+	s := &InventoryService{
 		repo:                repo,
 		inventoryStatusRepo: inventoryStatusRepo,
 		inventoryItemRepo:   inventoryItemRepo,
+		countRepo:           countRepo,
+		productRepo:         productRepo,
+		warehouseRepo:       warehouseRepo,
+		stockRepo:           stockRepo,
+		negativeStock:       negativeStock,
+		transitions:         transitions,
+		hooks:               make(map[string][]StatusHook),
+		audit:               audit,
 	}
+	return s
+}
+
+// OnStatus registers a hook that runs after an inventory is moved to the given status.
+func (s *InventoryService) OnStatus(status string, hook StatusHook) {
+	s.hooks[status] = append(s.hooks[status], hook)
 }
 
 func (s *InventoryService) GetByID(ctx context.Context, inventoryID uuid.UUID) (*dto.InventoryResponse, error) {
@@ -114,7 +137,7 @@ func (s *InventoryService) Create(ctx context.Context, userID uuid.UUID, req dto
 		log.Warn().Str("statusId", req.StatusID).Msg("Invalid status ID format")
 		return nil, repository.ErrInventoryStatusNotFound
 	}
-	_, err = s.inventoryStatusRepo.GetByID(ctx, statusID)
+	status, err := s.inventoryStatusRepo.GetByID(ctx, statusID)
 	if err != nil {
 		if err == repository.ErrInventoryStatusNotFound {
 			log.Warn().Str("statusId", req.StatusID).Msg("Inventory status not found")
@@ -123,6 +146,13 @@ func (s *InventoryService) Create(ctx context.Context, userID uuid.UUID, req dto
 		log.Error().Err(err).Str("statusId", req.StatusID).Msg("Failed to validate inventory status")
 		return nil, err
 	}
+	if status.IsArchived {
+		log.Warn().Str("statusId", req.StatusID).Msg("Inventory status is archived")
+		return nil, repository.ErrInventoryStatusArchived
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityInventory, nil, status.Name); err != nil {
+		return nil, err
+	}
 
 	inventory, err := s.repo.Create(ctx, req.AdjustmentDate, statusID, req.Notes, &userID)
 	if err != nil {
@@ -130,6 +160,8 @@ func (s *InventoryService) Create(ctx context.Context, userID uuid.UUID, req dto
 		return nil, err
 	}
 
+	s.transitions.Record(ctx, repository.StatusEntityInventory, inventory.InventoryID, nil, status.Name, nil, userID)
+
 	var updatedByStr *string
 	if inventory.UpdatedBy != nil {
 		str := inventory.UpdatedBy.String()
@@ -137,7 +169,7 @@ func (s *InventoryService) Create(ctx context.Context, userID uuid.UUID, req dto
 	}
 
 	log.Info().Str("inventoryId", inventory.InventoryID.String()).Str("statusId", req.StatusID).Str("userId", userID.String()).Msg("Inventory created successfully")
-	return &dto.InventoryResponse{
+	result := &dto.InventoryResponse{
 		InventoryID:    inventory.InventoryID.String(),
 		AdjustmentDate: inventory.AdjustmentDate,
 		StatusID:       inventory.StatusID.String(),
@@ -146,16 +178,23 @@ func (s *InventoryService) Create(ctx context.Context, userID uuid.UUID, req dto
 		CreatedAt:      inventory.CreatedAt,
 		UpdatedBy:      updatedByStr,
 		UpdatedAt:      inventory.UpdatedAt,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventory, inventory.InventoryID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *InventoryService) Update(ctx context.Context, inventoryID, userID uuid.UUID, req dto.InventoryUpdateRequest) (*dto.InventoryResponse, error) {
+	before, err := s.GetByID(ctx, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+
 	statusID, err := uuid.Parse(req.StatusID)
 	if err != nil {
 		log.Warn().Str("statusId", req.StatusID).Msg("Invalid status ID format")
 		return nil, repository.ErrInventoryStatusNotFound
 	}
-	_, err = s.inventoryStatusRepo.GetByID(ctx, statusID)
+	status, err := s.inventoryStatusRepo.GetByID(ctx, statusID)
 	if err != nil {
 		if err == repository.ErrInventoryStatusNotFound {
 			log.Warn().Str("statusId", req.StatusID).Msg("Inventory status not found")
@@ -165,12 +204,66 @@ func (s *InventoryService) Update(ctx context.Context, inventoryID, userID uuid.
 		return nil, err
 	}
 
-	inventory, err := s.repo.Update(ctx, inventoryID, req.AdjustmentDate, statusID, req.Notes, &userID)
+	existing, err := s.repo.GetByID(ctx, inventoryID)
+	if err != nil {
+		if err != repository.ErrInventoryNotFound {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to load inventory before update")
+		}
+		return nil, err
+	}
+	if !sameDate(existing.AdjustmentDate, req.AdjustmentDate) {
+		closed, err := s.isClosed(ctx, existing)
+		if err != nil {
+			return nil, err
+		}
+		if closed {
+			log.Warn().Str("inventoryId", inventoryID.String()).Msg("Cannot change adjustment date of a closed inventory")
+			return nil, repository.ErrInventoryClosed
+		}
+	}
+
+	statusChanged := existing.StatusID != statusID
+	var fromStatus *string
+	if statusChanged {
+		if status.IsArchived {
+			log.Warn().Str("statusId", req.StatusID).Msg("Inventory status is archived")
+			return nil, repository.ErrInventoryStatusArchived
+		}
+		fromStatus, err = s.statusName(ctx, existing.StatusID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.transitions.Check(ctx, repository.StatusEntityInventory, fromStatus, status.Name); err != nil {
+			return nil, err
+		}
+		if status.Name == repository.InventoryStatusCompleted {
+			if err := s.checkWriteOffs(ctx, inventoryID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Статус меняется отдельно от остальных полей, чтобы завершение и отмена прошли через changeStatus
+	inventory, err := s.repo.Update(ctx, inventoryID, req.AdjustmentDate, existing.StatusID, req.Notes, &userID)
 	if err != nil {
 		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Failed to update inventory")
 		return nil, err
 	}
 
+	if statusChanged {
+		if err := s.changeStatus(ctx, inventoryID, &existing.StatusID, statusID, status.Name, userID); err != nil {
+			return nil, err
+		}
+		s.transitions.Record(ctx, repository.StatusEntityInventory, inventoryID, fromStatus, status.Name, nil, userID)
+		runStatusHooks(ctx, s.hooks, repository.StatusEntityInventory, status.Name, inventoryID, userID)
+
+		inventory, err = s.repo.GetByID(ctx, inventoryID)
+		if err != nil {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to reload inventory after status change")
+			return nil, err
+		}
+	}
+
 	var updatedByStr *string
 	if inventory.UpdatedBy != nil {
 		str := inventory.UpdatedBy.String()
@@ -178,7 +271,7 @@ func (s *InventoryService) Update(ctx context.Context, inventoryID, userID uuid.
 	}
 
 	log.Info().Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Inventory updated successfully")
-	return &dto.InventoryResponse{
+	result := &dto.InventoryResponse{
 		InventoryID:    inventory.InventoryID.String(),
 		AdjustmentDate: inventory.AdjustmentDate,
 		StatusID:       inventory.StatusID.String(),
@@ -187,16 +280,368 @@ func (s *InventoryService) Update(ctx context.Context, inventoryID, userID uuid.
 		CreatedAt:      inventory.CreatedAt,
 		UpdatedBy:      updatedByStr,
 		UpdatedAt:      inventory.UpdatedAt,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventory, inventoryID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+func (s *InventoryService) statusName(ctx context.Context, statusID uuid.UUID) (*string, error) {
+	status, err := s.inventoryStatusRepo.GetByID(ctx, statusID)
+	if err != nil {
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to get inventory status")
+		return nil, err
+	}
+	return &status.Name, nil
+}
+
+// Transition moves the inventory to another status if the move is allowed by status_transitions and runs
+// the hooks registered for the new status.
+func (s *InventoryService) Transition(ctx context.Context, inventoryID, userID uuid.UUID, req dto.StatusTransitionRequest) (*dto.InventoryResponse, error) {
+	toStatusID, err := uuid.Parse(req.StatusID)
+	if err != nil {
+		log.Warn().Str("statusId", req.StatusID).Msg("Invalid status ID format")
+		return nil, repository.ErrInventoryStatusNotFound
+	}
+	toStatus, err := s.inventoryStatusRepo.GetByID(ctx, toStatusID)
+	if err != nil {
+		if err != repository.ErrInventoryStatusNotFound {
+			log.Error().Err(err).Str("statusId", req.StatusID).Msg("Failed to validate inventory status")
+		}
+		return nil, err
+	}
+
+	inventory, err := s.repo.GetByID(ctx, inventoryID)
+	if err != nil {
+		if err != repository.ErrInventoryNotFound {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory for status transition")
+		}
+		return nil, err
+	}
+	if inventory.StatusID == toStatusID {
+		return s.GetByID(ctx, inventoryID)
+	}
+	if toStatus.IsArchived {
+		log.Warn().Str("statusId", req.StatusID).Msg("Inventory status is archived")
+		return nil, repository.ErrInventoryStatusArchived
+	}
+
+	fromStatus, err := s.statusName(ctx, inventory.StatusID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityInventory, fromStatus, toStatus.Name); err != nil {
+		return nil, err
+	}
+	if toStatus.Name == repository.InventoryStatusCompleted {
+		if err := s.checkWriteOffs(ctx, inventoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	before, err := s.GetByID(ctx, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.changeStatus(ctx, inventoryID, &inventory.StatusID, toStatusID, toStatus.Name, userID); err != nil {
+		return nil, err
+	}
+
+	s.transitions.Record(ctx, repository.StatusEntityInventory, inventoryID, fromStatus, toStatus.Name, req.Comment, userID)
+	runStatusHooks(ctx, s.hooks, repository.StatusEntityInventory, toStatus.Name, inventoryID, userID)
+
+	log.Info().Str("inventoryId", inventoryID.String()).Str("status", toStatus.Name).Str("userId", userID.String()).Msg("Inventory status changed")
+	result, err := s.GetByID(ctx, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventory, inventoryID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+// changeStatus saves the new status of the inventory. Completion and cancellation change stock snapshots in the
+// same transaction as the status.
+func (s *InventoryService) changeStatus(ctx context.Context, inventoryID uuid.UUID, fromStatusID *uuid.UUID, toStatusID uuid.UUID, toStatus string, userID uuid.UUID) error {
+	switch toStatus {
+	case repository.InventoryStatusCompleted:
+		created, err := s.repo.Complete(ctx, inventoryID, fromStatusID, toStatusID, userID)
+		if err != nil {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to complete inventory")
+			return err
+		}
+		if created > 0 {
+			log.Info().Str("inventoryId", inventoryID.String()).Int64("items", created).Str("userId", userID.String()).Msg("Inventory count adjustments created")
+		}
+		log.Info().Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Inventory applied to stock snapshots")
+	case repository.InventoryStatusCancelled:
+		if err := s.repo.Cancel(ctx, inventoryID, fromStatusID, toStatusID, userID); err != nil {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to cancel inventory")
+			return err
+		}
+		log.Info().Str("inventoryId", inventoryID.String()).Str("userId", userID.String()).Msg("Inventory reverted from stock snapshots")
+	default:
+		if err := s.repo.UpdateStatus(ctx, inventoryID, fromStatusID, toStatusID, userID); err != nil {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("status", toStatus).Msg("Failed to change inventory status")
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *InventoryService) History(ctx context.Context, inventoryID uuid.UUID) ([]dto.StatusHistoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, inventoryID); err != nil {
+		if err != repository.ErrInventoryNotFound {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory for status history")
+		}
+		return nil, err
+	}
+	return s.transitions.History(ctx, repository.StatusEntityInventory, inventoryID)
 }
 
 func (s *InventoryService) Delete(ctx context.Context, inventoryID uuid.UUID) error {
-	err := s.repo.Delete(ctx, inventoryID)
+	before, err := s.GetByID(ctx, inventoryID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, inventoryID)
 	if err != nil {
 		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to delete inventory")
 		return err
 	}
 
 	log.Info().Str("inventoryId", inventoryID.String()).Msg("Inventory deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityInventory, inventoryID, repository.AuditActionDelete, before, nil)
 	return nil
 }
+
+// isClosed reports whether the inventory is completed or cancelled, so its count can no longer change.
+func (s *InventoryService) isClosed(ctx context.Context, inventory *repository.Inventory) (bool, error) {
+	status, err := s.statusName(ctx, inventory.StatusID)
+	if err != nil {
+		return false, err
+	}
+	return *status == repository.InventoryStatusCompleted || *status == repository.InventoryStatusCancelled, nil
+}
+
+// StartCount starts the count of a warehouse: its current stock is frozen as the expected quantities.
+func (s *InventoryService) StartCount(ctx context.Context, inventoryID, userID uuid.UUID, req dto.InventoryCountStartRequest) (*dto.InventoryCountResponse, error) {
+	inventory, err := s.repo.GetByID(ctx, inventoryID)
+	if err != nil {
+		if err != repository.ErrInventoryNotFound {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory for count")
+		}
+		return nil, err
+	}
+	closed, err := s.isClosed(ctx, inventory)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		log.Warn().Str("inventoryId", inventoryID.String()).Msg("Cannot start count of a closed inventory")
+		return nil, repository.ErrInventoryClosed
+	}
+
+	warehouseID, err := uuid.Parse(req.WarehouseID)
+	if err != nil {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
+		return nil, repository.ErrWarehouseNotFound
+	}
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
+	if err != nil {
+		if err == repository.ErrWarehouseNotFound {
+			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
+			return nil, repository.ErrWarehouseNotFound
+		}
+		log.Error().Err(err).Str("warehouseId", req.WarehouseID).Msg("Failed to validate warehouse")
+		return nil, err
+	}
+	if warehouse.IsArchived {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
+	}
+
+	if _, err := s.countRepo.Start(ctx, inventoryID, warehouseID, userID); err != nil {
+		if err != repository.ErrInventoryCountExists {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("warehouseId", req.WarehouseID).Msg("Failed to start inventory count")
+		}
+		return nil, err
+	}
+
+	log.Info().Str("inventoryId", inventoryID.String()).Str("warehouseId", req.WarehouseID).Str("userId", userID.String()).Msg("Inventory count started")
+	result, err := s.GetCount(ctx, inventoryID)
+	if err != nil {
+		return nil, err
+	}
+	summary := *result
+	summary.Lines = nil
+	s.audit.Record(ctx, repository.AuditEntityInventoryCount, inventoryID, repository.AuditActionCreate, nil, &summary)
+	return result, nil
+}
+
+func (s *InventoryService) GetCount(ctx context.Context, inventoryID uuid.UUID) (*dto.InventoryCountResponse, error) {
+	count, err := s.countRepo.GetByInventoryID(ctx, inventoryID)
+	if err != nil {
+		if err != repository.ErrInventoryCountNotFound {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory count")
+		}
+		return nil, err
+	}
+
+	lines, err := s.countRepo.GetLines(ctx, inventoryID)
+	if err != nil {
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory count lines")
+		return nil, err
+	}
+
+	var startedByStr *string
+	if count.StartedBy != nil {
+		str := count.StartedBy.String()
+		startedByStr = &str
+	}
+
+	result := &dto.InventoryCountResponse{
+		InventoryID: count.InventoryID.String(),
+		WarehouseID: count.WarehouseID.String(),
+		StartedBy:   startedByStr,
+		StartedAt:   count.StartedAt,
+		AppliedAt:   count.AppliedAt,
+		TotalLines:  len(lines),
+		Lines:       make([]dto.InventoryCountLineResponse, 0, len(lines)),
+	}
+	for i := range lines {
+		if lines[i].CountedQty != nil {
+			result.CountedLines++
+		}
+		result.Lines = append(result.Lines, inventoryCountLineResponse(&lines[i]))
+	}
+
+	return result, nil
+}
+
+// RecordCount saves the counted quantity of a product given by id or barcode. By default the quantity is
+// added to what was already counted (e.g. one scan - one unit); req.Replace overwrites it.
+func (s *InventoryService) RecordCount(ctx context.Context, inventoryID, userID uuid.UUID, req dto.InventoryCountRecordRequest) (*dto.InventoryCountLineResponse, error) {
+	count, err := s.countRepo.GetByInventoryID(ctx, inventoryID)
+	if err != nil {
+		if err != repository.ErrInventoryCountNotFound {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory count")
+		}
+		return nil, err
+	}
+	if count.AppliedAt != nil {
+		log.Warn().Str("inventoryId", inventoryID.String()).Msg("Inventory count is already applied")
+		return nil, repository.ErrInventoryClosed
+	}
+
+	inventory, err := s.repo.GetByID(ctx, inventoryID)
+	if err != nil {
+		if err != repository.ErrInventoryNotFound {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory for count")
+		}
+		return nil, err
+	}
+	closed, err := s.isClosed(ctx, inventory)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		log.Warn().Str("inventoryId", inventoryID.String()).Msg("Cannot count a closed inventory")
+		return nil, repository.ErrInventoryClosed
+	}
+
+	if (req.Replace && req.Quantity < 0) || (!req.Replace && req.Quantity == 0) {
+		log.Warn().Int("quantity", req.Quantity).Bool("replace", req.Replace).Msg("Invalid counted quantity")
+		return nil, repository.ErrInvalidQuantity
+	}
+
+	var product *repository.Product
+	if req.ProductID != nil && *req.ProductID != "" {
+		productID, err := uuid.Parse(*req.ProductID)
+		if err != nil {
+			log.Warn().Str("productId", *req.ProductID).Msg("Invalid product ID format")
+			return nil, repository.ErrProductNotFound
+		}
+		product, err = s.productRepo.GetByID(ctx, productID)
+		if err != nil {
+			if err != repository.ErrProductNotFound {
+				log.Error().Err(err).Str("productId", *req.ProductID).Msg("Failed to validate product")
+			}
+			return nil, err
+		}
+	} else {
+		product, err = s.productRepo.GetByBarcode(ctx, *req.Barcode)
+		if err != nil {
+			if err != repository.ErrProductNotFound {
+				log.Error().Err(err).Str("barcode", *req.Barcode).Msg("Failed to find product by barcode")
+			}
+			return nil, err
+		}
+	}
+
+	line, err := s.countRepo.RecordCount(ctx, inventoryID, product.ProductID, req.Quantity, req.Replace, userID)
+	if err != nil {
+		if err != repository.ErrInvalidQuantity {
+			log.Error().Err(err).Str("inventoryId", inventoryID.String()).Str("productId", product.ProductID.String()).Msg("Failed to record counted quantity")
+		}
+		return nil, err
+	}
+
+	result := inventoryCountLineResponse(line)
+	return &result, nil
+}
+
+// checkWriteOffs applies the negative stock policy to the items of an inventory about to be completed.
+// Items created from the count on completion bring stock to the counted quantity and are not checked.
+func (s *InventoryService) checkWriteOffs(ctx context.Context, inventoryID uuid.UUID) error {
+	items, err := s.inventoryItemRepo.GetByInventoryID(ctx, inventoryID)
+	if err != nil {
+		log.Error().Err(err).Str("inventoryId", inventoryID.String()).Msg("Failed to get inventory items")
+		return err
+	}
+
+	changes := make([]repository.StockChange, 0, len(items))
+	for _, item := range items {
+		if item.ProductID == nil {
+			continue
+		}
+		changes = append(changes, repository.StockChange{ProductID: *item.ProductID, WarehouseID: item.WarehouseID, Quantity: item.ReceiptQty - item.WriteOffQty})
+	}
+	return s.negativeStock.Check(ctx, repository.StatusEntityInventory, inventoryID, stockChanges(changes))
+}
+
+// sameDate compares optional dates by calendar day, ignoring time of day.
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func inventoryCountLineResponse(line *repository.InventoryCountLine) dto.InventoryCountLineResponse {
+	var difference *int
+	if line.CountedQty != nil {
+		diff := *line.CountedQty - line.ExpectedQty
+		difference = &diff
+	}
+
+	var countedByStr *string
+	if line.CountedBy != nil {
+		str := line.CountedBy.String()
+		countedByStr = &str
+	}
+
+	return dto.InventoryCountLineResponse{
+		CountLineID: line.CountLineID.String(),
+		ProductID:   line.ProductID.String(),
+		Article:     line.Article,
+		Barcode:     line.Barcode,
+		ExpectedQty: line.ExpectedQty,
+		CountedQty:  line.CountedQty,
+		Difference:  difference,
+		CountedBy:   countedByStr,
+		CountedAt:   line.CountedAt,
+	}
+}
@@ -11,11 +11,12 @@ import (
 )
 
 type InventoryStatusService struct {
-	repo *repository.InventoryStatusRepository
+	repo  *repository.InventoryStatusRepository
+	audit *AuditService
 }
 
-func NewInventoryStatusService(repo *repository.InventoryStatusRepository) *InventoryStatusService {
-	return &InventoryStatusService{repo: repo}
+func NewInventoryStatusService(repo *repository.InventoryStatusRepository, audit *AuditService) *InventoryStatusService {
+	return &InventoryStatusService{repo: repo, audit: audit}
 }
 
 func (s *InventoryStatusService) GetByID(ctx context.Context, statusID uuid.UUID) (*dto.InventoryStatusResponse, error) {
@@ -28,11 +29,13 @@ func (s *InventoryStatusService) GetByID(ctx context.Context, statusID uuid.UUID
 	return &dto.InventoryStatusResponse{
 		InventoryStatusID: status.InventoryStatusID.String(),
 		Name:              status.Name,
+		IsArchived:        status.IsArchived,
+		ArchivedAt:        status.ArchivedAt,
 	}, nil
 }
 
-func (s *InventoryStatusService) List(ctx context.Context, limit, offset int) ([]dto.InventoryStatusResponse, error) {
-	statuses, err := s.repo.List(ctx, limit, offset)
+func (s *InventoryStatusService) List(ctx context.Context, limit, offset int, includeArchived bool) ([]dto.InventoryStatusResponse, error) {
+	statuses, err := s.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to list inventory statuses")
 		return nil, err
@@ -43,6 +46,8 @@ func (s *InventoryStatusService) List(ctx context.Context, limit, offset int) ([
 		result = append(result, dto.InventoryStatusResponse{
 			InventoryStatusID: status.InventoryStatusID.String(),
 			Name:              status.Name,
+			IsArchived:        status.IsArchived,
+			ArchivedAt:        status.ArchivedAt,
 		})
 	}
 
@@ -57,13 +62,22 @@ func (s *InventoryStatusService) Create(ctx context.Context, req dto.InventorySt
 	}
 
 	log.Info().Str("statusId", status.InventoryStatusID.String()).Str("name", status.Name).Msg("Inventory status created successfully")
-	return &dto.InventoryStatusResponse{
+	result := &dto.InventoryStatusResponse{
 		InventoryStatusID: status.InventoryStatusID.String(),
 		Name:              status.Name,
-	}, nil
+		IsArchived:        status.IsArchived,
+		ArchivedAt:        status.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventoryStatus, status.InventoryStatusID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *InventoryStatusService) Update(ctx context.Context, statusID uuid.UUID, req dto.InventoryStatusUpdateRequest) (*dto.InventoryStatusResponse, error) {
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+
 	status, err := s.repo.Update(ctx, statusID, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to update inventory status")
@@ -71,19 +85,53 @@ func (s *InventoryStatusService) Update(ctx context.Context, statusID uuid.UUID,
 	}
 
 	log.Info().Str("statusId", statusID.String()).Msg("Inventory status updated successfully")
-	return &dto.InventoryStatusResponse{
+	result := &dto.InventoryStatusResponse{
 		InventoryStatusID: status.InventoryStatusID.String(),
 		Name:              status.Name,
-	}, nil
+		IsArchived:        status.IsArchived,
+		ArchivedAt:        status.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventoryStatus, statusID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
+// Delete archives the inventory status: it disappears from lists and cannot be used in new documents, but
+// documents and stock history that reference it stay intact. Use Restore to bring it back.
 func (s *InventoryStatusService) Delete(ctx context.Context, statusID uuid.UUID) error {
-	err := s.repo.Delete(ctx, statusID)
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Archive(ctx, statusID)
 	if err != nil {
-		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to delete inventory status")
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to archive inventory status")
 		return err
 	}
 
-	log.Info().Str("statusId", statusID.String()).Msg("Inventory status deleted successfully")
+	log.Info().Str("statusId", statusID.String()).Msg("Inventory status archived successfully")
+	if after, err := s.GetByID(ctx, statusID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityInventoryStatus, statusID, repository.AuditActionDelete, before, after)
+	}
 	return nil
 }
+
+func (s *InventoryStatusService) Restore(ctx context.Context, statusID uuid.UUID) (*dto.InventoryStatusResponse, error) {
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, statusID); err != nil {
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to restore inventory status")
+		return nil, err
+	}
+
+	log.Info().Str("statusId", statusID.String()).Msg("Inventory status restored successfully")
+	result, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityInventoryStatus, statusID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
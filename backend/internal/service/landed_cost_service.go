@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LandedCostService распределяет логистику заказа поставщику по позициям и считает себестоимость.
+type LandedCostService struct {
+	orderRepo *repository.SupplierOrderRepository
+	itemRepo  *repository.SupplierOrderItemRepository
+}
+
+func NewLandedCostService(orderRepo *repository.SupplierOrderRepository, itemRepo *repository.SupplierOrderItemRepository) *LandedCostService {
+	return &LandedCostService{
+		orderRepo: orderRepo,
+		itemRepo:  itemRepo,
+	}
+}
+
+// Recalculate allocates the order's logistics (china-msk + msk-kzn + additional) to its items using the
+// order's allocation method and stores total/unit logistics and self cost of every item together with
+// the order aggregates. When the chosen basis is zero for all items (e.g. no weights entered),
+// logistics is allocated by quantity.
+func (s *LandedCostService) Recalculate(ctx context.Context, orderID, userID uuid.UUID) error {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to load supplier order for cost allocation")
+		return err
+	}
+
+	items, err := s.itemRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to load order items for cost allocation")
+		return err
+	}
+
+	var logistics float64
+	for _, part := range []*float64{order.LogisticsChinaMsk, order.LogisticsMskKzn, order.LogisticsAdditional} {
+		if part != nil {
+			logistics += *part
+		}
+	}
+
+	totalPrices := make([]*float64, len(items))
+	for i, item := range items {
+		totalPrices[i] = item.TotalPrice
+		if item.PurchasePrice != nil {
+			totalPrice := roundMoney(*item.PurchasePrice * float64(item.OrderedQty))
+			totalPrices[i] = &totalPrice
+		}
+	}
+
+	basis := make([]float64, len(items))
+	quantities := make([]float64, len(items))
+	for i, item := range items {
+		quantities[i] = float64(item.OrderedQty)
+		switch order.LogisticsAllocationMethod {
+		case repository.AllocationByValue:
+			if totalPrices[i] != nil {
+				basis[i] = *totalPrices[i]
+			}
+		case repository.AllocationByQuantity:
+			basis[i] = float64(item.OrderedQty)
+		default:
+			basis[i] = float64(item.TotalWeight)
+		}
+	}
+
+	shares := allocateByBasis(logistics, basis)
+	if shares == nil {
+		shares = allocateByBasis(logistics, quantities)
+	}
+	if shares == nil {
+		shares = make([]float64, len(items))
+	}
+
+	costs := make([]repository.ItemLandedCost, 0, len(items))
+	for i, item := range items {
+		cost := repository.ItemLandedCost{
+			OrderItemID:    item.OrderItemID,
+			TotalPrice:     totalPrices[i],
+			TotalLogistics: shares[i],
+		}
+		if totalPrices[i] != nil {
+			totalSelfCost := roundMoney(*totalPrices[i] + shares[i])
+			cost.TotalSelfCost = &totalSelfCost
+		}
+		if item.OrderedQty > 0 {
+			unitLogistics := roundMoney(shares[i] / float64(item.OrderedQty))
+			cost.UnitLogistics = &unitLogistics
+			if cost.TotalSelfCost != nil {
+				unitSelfCost := roundMoney(*cost.TotalSelfCost / float64(item.OrderedQty))
+				cost.UnitSelfCost = &unitSelfCost
+			}
+		}
+		costs = append(costs, cost)
+	}
+
+	if err := s.orderRepo.ApplyLandedCosts(ctx, orderID, costs, userID); err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to save allocated order costs")
+		return err
+	}
+
+	return nil
+}
+
+// allocateByBasis splits amount proportionally to basis in whole kopecks. The rounding remainder goes to
+// the parts with the largest fractional shares, so the parts always sum up to the rounded amount.
+// Returns nil if the basis sums to zero.
+func allocateByBasis(amount float64, basis []float64) []float64 {
+	var sum float64
+	for _, b := range basis {
+		if b > 0 {
+			sum += b
+		}
+	}
+	if sum == 0 {
+		return nil
+	}
+
+	total := int64(math.Round(amount * 100))
+	cents := make([]int64, len(basis))
+	fractions := make([]float64, len(basis))
+	var allocated int64
+	for i, b := range basis {
+		if b <= 0 {
+			continue
+		}
+		exact := float64(total) * b / sum
+		cents[i] = int64(math.Floor(exact))
+		fractions[i] = exact - float64(cents[i])
+		allocated += cents[i]
+	}
+
+	for remainder := total - allocated; remainder > 0; remainder-- {
+		best := -1
+		for i := range basis {
+			if basis[i] > 0 && (best == -1 || fractions[i] > fractions[best]) {
+				best = i
+			}
+		}
+		cents[best]++
+		fractions[best] = -1
+	}
+
+	shares := make([]float64, len(basis))
+	for i, c := range cents {
+		shares[i] = float64(c) / 100
+	}
+	return shares
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
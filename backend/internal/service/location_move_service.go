@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+type LocationMoveService struct {
+	repo        *repository.LocationMoveRepository
+	productRepo *repository.ProductRepository
+	locations   *StorageLocationService
+	transitions *StatusTransitionService
+	audit       *AuditService
+}
+
+func NewLocationMoveService(repo *repository.LocationMoveRepository, productRepo *repository.ProductRepository, locations *StorageLocationService, transitions *StatusTransitionService, audit *AuditService) *LocationMoveService {
+	return &LocationMoveService{
+		repo:        repo,
+		productRepo: productRepo,
+		locations:   locations,
+		transitions: transitions,
+		audit:       audit,
+	}
+}
+
+func toLocationMoveResponse(move *repository.LocationMove, items []repository.LocationMoveItem, addresses map[uuid.UUID]string) *dto.LocationMoveResponse {
+	var createdByStr *string
+	if move.CreatedBy != nil {
+		str := move.CreatedBy.String()
+		createdByStr = &str
+	}
+	var updatedByStr *string
+	if move.UpdatedBy != nil {
+		str := move.UpdatedBy.String()
+		updatedByStr = &str
+	}
+
+	result := &dto.LocationMoveResponse{
+		MoveID:      move.MoveID.String(),
+		MoveNumber:  move.MoveNumber,
+		WarehouseID: move.WarehouseID.String(),
+		Status:      move.Status,
+		Notes:       move.Notes,
+		CreatedBy:   createdByStr,
+		CreatedAt:   move.CreatedAt,
+		UpdatedBy:   updatedByStr,
+		UpdatedAt:   move.UpdatedAt,
+	}
+
+	for _, item := range items {
+		row := dto.LocationMoveItemResponse{
+			MoveItemID: item.MoveItemID.String(),
+			ProductID:  item.ProductID.String(),
+			Article:    item.Article,
+			Quantity:   item.Quantity,
+		}
+		if item.FromLocationID != nil {
+			str := item.FromLocationID.String()
+			address := addresses[*item.FromLocationID]
+			row.FromLocationID = &str
+			row.FromAddress = &address
+		}
+		if item.ToLocationID != nil {
+			str := item.ToLocationID.String()
+			address := addresses[*item.ToLocationID]
+			row.ToLocationID = &str
+			row.ToAddress = &address
+		}
+		result.Items = append(result.Items, row)
+	}
+
+	return result
+}
+
+// GetByID returns the move of the warehouse; moves of other warehouses are reported as not found.
+func (s *LocationMoveService) GetByID(ctx context.Context, warehouseID, moveID uuid.UUID) (*dto.LocationMoveResponse, error) {
+	move, err := s.repo.GetByID(ctx, moveID)
+	if err != nil {
+		if err != repository.ErrLocationMoveNotFound {
+			log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to get location move by ID")
+		}
+		return nil, err
+	}
+	if move.WarehouseID != warehouseID {
+		log.Warn().Str("moveId", moveID.String()).Str("warehouseId", warehouseID.String()).Msg("Location move belongs to another warehouse")
+		return nil, repository.ErrLocationMoveNotFound
+	}
+
+	items, err := s.repo.GetItems(ctx, moveID)
+	if err != nil {
+		log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to get location move items")
+		return nil, err
+	}
+
+	_, addresses, err := s.locations.addresses(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toLocationMoveResponse(move, items, addresses), nil
+}
+
+// List returns moves of the warehouse without items.
+func (s *LocationMoveService) List(ctx context.Context, warehouseID uuid.UUID, limit, offset int, status *string) ([]dto.LocationMoveResponse, error) {
+	if err := s.locations.checkWarehouse(ctx, warehouseID, false); err != nil {
+		return nil, err
+	}
+
+	moves, err := s.repo.List(ctx, warehouseID, limit, offset, status)
+	if err != nil {
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Int("limit", limit).Int("offset", offset).
+			Interface("status", status).Msg("Failed to list location moves")
+		return nil, err
+	}
+
+	result := make([]dto.LocationMoveResponse, 0, len(moves))
+	for i := range moves {
+		result = append(result, *toLocationMoveResponse(&moves[i], nil, nil))
+	}
+
+	return result, nil
+}
+
+// validateLocation parses a location of a move item: it has to be a bin of the warehouse, and a destination
+// must not be archived.
+func (s *LocationMoveService) validateLocation(ctx context.Context, warehouseID uuid.UUID, locationIDStr *string, destination bool) (*uuid.UUID, error) {
+	if locationIDStr == nil || *locationIDStr == "" {
+		return nil, nil
+	}
+	locationID, err := uuid.Parse(*locationIDStr)
+	if err != nil {
+		log.Warn().Str("locationId", *locationIDStr).Msg("Invalid storage location ID format")
+		return nil, repository.ErrStorageLocationNotFound
+	}
+	location, err := s.locations.getLocation(ctx, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if location.LocationType != repository.LocationTypeBin {
+		log.Warn().Str("locationId", *locationIDStr).Str("locationType", location.LocationType).Msg("Stock can only be placed in bins")
+		return nil, repository.ErrNotBinLocation
+	}
+	if destination && location.IsArchived {
+		log.Warn().Str("locationId", *locationIDStr).Msg("Storage location is archived")
+		return nil, repository.ErrStorageLocationArchived
+	}
+	return &locationID, nil
+}
+
+// validate checks the items of a draft move and converts them for the repository.
+func (s *LocationMoveService) validate(ctx context.Context, warehouseID uuid.UUID, reqItems []dto.LocationMoveItemRequest) ([]repository.LocationMoveItemInput, error) {
+	if len(reqItems) == 0 {
+		return nil, repository.ErrEmptyLocationMove
+	}
+
+	items := make([]repository.LocationMoveItemInput, 0, len(reqItems))
+	for _, reqItem := range reqItems {
+		productID, err := uuid.Parse(reqItem.ProductID)
+		if err != nil {
+			log.Warn().Str("productId", reqItem.ProductID).Msg("Invalid product ID format")
+			return nil, repository.ErrProductNotFound
+		}
+		if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+			if err == repository.ErrProductNotFound {
+				log.Warn().Str("productId", reqItem.ProductID).Msg("Product not found")
+				return nil, repository.ErrProductNotFound
+			}
+			log.Error().Err(err).Str("productId", reqItem.ProductID).Msg("Failed to validate product")
+			return nil, err
+		}
+
+		fromID, err := s.validateLocation(ctx, warehouseID, reqItem.FromLocationID, false)
+		if err != nil {
+			return nil, err
+		}
+		toID, err := s.validateLocation(ctx, warehouseID, reqItem.ToLocationID, true)
+		if err != nil {
+			return nil, err
+		}
+		if (fromID == nil && toID == nil) || (fromID != nil && toID != nil && *fromID == *toID) {
+			log.Warn().Str("productId", reqItem.ProductID).Msg("Move item needs distinct source and destination locations")
+			return nil, repository.ErrInvalidMoveLocations
+		}
+
+		if reqItem.Quantity <= 0 {
+			log.Warn().Int("quantity", reqItem.Quantity).Msg("Move quantity must be positive")
+			return nil, repository.ErrInvalidQuantity
+		}
+
+		items = append(items, repository.LocationMoveItemInput{
+			ProductID:      productID,
+			FromLocationID: fromID,
+			ToLocationID:   toID,
+			Quantity:       reqItem.Quantity,
+		})
+	}
+
+	return items, nil
+}
+
+func (s *LocationMoveService) Create(ctx context.Context, warehouseID, userID uuid.UUID, req dto.LocationMoveCreateRequest) (*dto.LocationMoveResponse, error) {
+	if err := s.locations.checkWarehouse(ctx, warehouseID, true); err != nil {
+		return nil, err
+	}
+
+	items, err := s.validate(ctx, warehouseID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.create(ctx, warehouseID, userID, req.MoveNumber, req.Notes, items, nil)
+}
+
+func (s *LocationMoveService) create(ctx context.Context, warehouseID, userID uuid.UUID, moveNumber string, notes *string, items []repository.LocationMoveItemInput, comment *string) (*dto.LocationMoveResponse, error) {
+	move, err := s.repo.Create(ctx, moveNumber, warehouseID, notes, items, userID)
+	if err != nil {
+		if err != repository.ErrLocationMoveExists {
+			log.Error().Err(err).Str("moveNumber", moveNumber).Str("userId", userID.String()).Msg("Failed to create location move")
+		}
+		return nil, err
+	}
+
+	s.transitions.Record(ctx, repository.StatusEntityLocationMove, move.MoveID, nil, move.Status, comment, userID)
+
+	log.Info().Str("moveId", move.MoveID.String()).Str("moveNumber", move.MoveNumber).Str("userId", userID.String()).Msg("Location move created successfully")
+	result, err := s.GetByID(ctx, warehouseID, move.MoveID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityLocationMove, move.MoveID, repository.AuditActionCreate, nil, result)
+	return result, nil
+}
+
+// CreatePutAway creates a draft move that puts the unplaced stock away into the suggested bins (see
+// StorageLocationService.putAway). Suggestions without a free bin are left out.
+func (s *LocationMoveService) CreatePutAway(ctx context.Context, warehouseID, userID uuid.UUID, req dto.PutAwayCreateRequest) (*dto.LocationMoveResponse, error) {
+	if err := s.locations.checkWarehouse(ctx, warehouseID, true); err != nil {
+		return nil, err
+	}
+
+	var orderID *uuid.UUID
+	if req.OrderID != nil && *req.OrderID != "" {
+		id, err := uuid.Parse(*req.OrderID)
+		if err != nil {
+			log.Warn().Str("orderId", *req.OrderID).Msg("Invalid supplier order ID format")
+			return nil, repository.ErrSupplierOrderNotFound
+		}
+		orderID = &id
+	}
+
+	suggestions, _, err := s.locations.putAway(ctx, warehouseID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]repository.LocationMoveItemInput, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if suggestion.LocationID == nil {
+			continue
+		}
+		items = append(items, repository.LocationMoveItemInput{
+			ProductID:    suggestion.ProductID,
+			ToLocationID: suggestion.LocationID,
+			Quantity:     suggestion.Quantity,
+		})
+	}
+	if len(items) == 0 {
+		log.Warn().Str("warehouseId", warehouseID.String()).Interface("orderId", orderID).Msg("Nothing to put away")
+		return nil, repository.ErrNoPutAwaySuggestions
+	}
+
+	moveNumber := fmt.Sprintf("PUTAWAY-%s", time.Now().Format("20060102-150405"))
+	if req.MoveNumber != nil && *req.MoveNumber != "" {
+		moveNumber = *req.MoveNumber
+	}
+
+	comment := "Создано по предложениям размещения"
+	return s.create(ctx, warehouseID, userID, moveNumber, req.Notes, items, &comment)
+}
+
+func (s *LocationMoveService) Update(ctx context.Context, warehouseID, moveID, userID uuid.UUID, req dto.LocationMoveUpdateRequest) (*dto.LocationMoveResponse, error) {
+	before, err := s.GetByID(ctx, warehouseID, moveID)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != repository.LocationMoveStatusDraft {
+		log.Warn().Str("moveId", moveID.String()).Str("status", before.Status).Msg("Only draft location moves can be edited")
+		return nil, repository.ErrLocationMoveNotEditable
+	}
+
+	items, err := s.validate(ctx, warehouseID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Update(ctx, moveID, req.MoveNumber, req.Notes, items, userID); err != nil {
+		if err != repository.ErrLocationMoveExists && err != repository.ErrLocationMoveNotEditable {
+			log.Error().Err(err).Str("moveId", moveID.String()).Str("userId", userID.String()).Msg("Failed to update location move")
+		}
+		return nil, err
+	}
+
+	log.Info().Str("moveId", moveID.String()).Str("userId", userID.String()).Msg("Location move updated successfully")
+	result, err := s.GetByID(ctx, warehouseID, moveID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityLocationMove, moveID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+func (s *LocationMoveService) Delete(ctx context.Context, warehouseID, moveID uuid.UUID) error {
+	before, err := s.GetByID(ctx, warehouseID, moveID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, moveID); err != nil {
+		if err != repository.ErrLocationMoveNotEditable {
+			log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to delete location move")
+		}
+		return err
+	}
+
+	log.Info().Str("moveId", moveID.String()).Msg("Location move deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityLocationMove, moveID, repository.AuditActionDelete, before, nil)
+	return nil
+}
+
+func (s *LocationMoveService) History(ctx context.Context, warehouseID, moveID uuid.UUID) ([]dto.StatusHistoryResponse, error) {
+	if _, err := s.GetByID(ctx, warehouseID, moveID); err != nil {
+		return nil, err
+	}
+	return s.transitions.History(ctx, repository.StatusEntityLocationMove, moveID)
+}
+
+// locationStockChanges sums what applying the items (reversed if sign is -1) changes in every location of
+// the warehouse; uuid.Nil stands for the unplaced stock. Unlike warehouse stock, location stock is not subject
+// to the negative stock policy: a picker cannot take from a bin what is not there, so the repository rejects
+// changes that take a location or the unplaced stock below zero.
+func locationStockChanges(items []dto.LocationMoveItemResponse, sign int) ([]repository.LocationStockChange, error) {
+	sums := make(map[[2]uuid.UUID]int)
+	var keys [][2]uuid.UUID
+	add := func(locationID, productID uuid.UUID, qty int) {
+		key := [2]uuid.UUID{locationID, productID}
+		if _, ok := sums[key]; !ok {
+			keys = append(keys, key)
+		}
+		sums[key] += qty
+	}
+	for _, item := range items {
+		productID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		from, to := uuid.Nil, uuid.Nil
+		if item.FromLocationID != nil {
+			if from, err = uuid.Parse(*item.FromLocationID); err != nil {
+				return nil, err
+			}
+		}
+		if item.ToLocationID != nil {
+			if to, err = uuid.Parse(*item.ToLocationID); err != nil {
+				return nil, err
+			}
+		}
+		add(from, productID, -sign*item.Quantity)
+		add(to, productID, sign*item.Quantity)
+	}
+
+	changes := make([]repository.LocationStockChange, 0, len(keys))
+	for _, key := range keys {
+		change := repository.LocationStockChange{ProductID: key[1], Quantity: sums[key]}
+		if key[0] != uuid.Nil {
+			locationID := key[0]
+			change.LocationID = &locationID
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Post moves the goods of a draft move between locations. Source bins (or the unplaced stock) must hold
+// the moved quantities.
+func (s *LocationMoveService) Post(ctx context.Context, warehouseID, moveID, userID uuid.UUID, req dto.LocationMoveActionRequest) (*dto.LocationMoveResponse, error) {
+	before, err := s.GetByID(ctx, warehouseID, moveID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityLocationMove, &before.Status, repository.LocationMoveStatusPosted); err != nil {
+		return nil, err
+	}
+	if len(before.Items) == 0 {
+		return nil, repository.ErrEmptyLocationMove
+	}
+
+	changes, err := locationStockChanges(before.Items, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, moveID, warehouseID, before.Status, repository.LocationMoveStatusPosted, changes, userID); err != nil {
+		if err == repository.ErrInsufficientLocationStock {
+			log.Warn().Str("moveId", moveID.String()).Msg("Source location does not hold enough stock")
+		} else {
+			log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to post location move")
+		}
+		return nil, err
+	}
+
+	return s.afterTransition(ctx, warehouseID, moveID, userID, before, repository.LocationMoveStatusPosted, req.Comment)
+}
+
+// Cancel cancels a draft or posted move. Cancelling a posted move returns the goods to their source
+// locations, so the destination bins must still hold them.
+func (s *LocationMoveService) Cancel(ctx context.Context, warehouseID, moveID, userID uuid.UUID, req dto.LocationMoveActionRequest) (*dto.LocationMoveResponse, error) {
+	before, err := s.GetByID(ctx, warehouseID, moveID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityLocationMove, &before.Status, repository.LocationMoveStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	var changes []repository.LocationStockChange
+	if before.Status == repository.LocationMoveStatusPosted {
+		if changes, err = locationStockChanges(before.Items, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateStatus(ctx, moveID, warehouseID, before.Status, repository.LocationMoveStatusCancelled, changes, userID); err != nil {
+		if err == repository.ErrInsufficientLocationStock {
+			log.Warn().Str("moveId", moveID.String()).Msg("Destination location no longer holds the moved stock")
+		} else {
+			log.Error().Err(err).Str("moveId", moveID.String()).Msg("Failed to cancel location move")
+		}
+		return nil, err
+	}
+
+	return s.afterTransition(ctx, warehouseID, moveID, userID, before, repository.LocationMoveStatusCancelled, req.Comment)
+}
+
+func (s *LocationMoveService) afterTransition(ctx context.Context, warehouseID, moveID, userID uuid.UUID, before *dto.LocationMoveResponse, toStatus string, comment *string) (*dto.LocationMoveResponse, error) {
+	s.transitions.Record(ctx, repository.StatusEntityLocationMove, moveID, &before.Status, toStatus, comment, userID)
+
+	log.Info().Str("moveId", moveID.String()).Str("status", toStatus).Str("userId", userID.String()).Msg("Location move status changed")
+	result, err := s.GetByID(ctx, warehouseID, moveID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityLocationMove, moveID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
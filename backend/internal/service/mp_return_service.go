@@ -0,0 +1,543 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+type MpReturnService struct {
+	repo               *repository.MpReturnRepository
+	storeRepo          *repository.StoreRepository
+	shipmentRepo       *repository.MpShipmentRepository
+	shipmentStatusRepo *repository.ShipmentStatusRepository
+	productRepo        *repository.ProductRepository
+	warehouseRepo      *repository.WarehouseRepository
+	warehouseTypeRepo  *repository.WarehouseTypeRepository
+	negativeStock      *StockPolicyService
+	transitions        *StatusTransitionService
+	audit              *AuditService
+}
+
+func NewMpReturnService(repo *repository.MpReturnRepository, storeRepo *repository.StoreRepository, shipmentRepo *repository.MpShipmentRepository, shipmentStatusRepo *repository.ShipmentStatusRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, warehouseTypeRepo *repository.WarehouseTypeRepository, negativeStock *StockPolicyService, transitions *StatusTransitionService, audit *AuditService) *MpReturnService {
+	return &MpReturnService{
+		repo:               repo,
+		storeRepo:          storeRepo,
+		shipmentRepo:       shipmentRepo,
+		shipmentStatusRepo: shipmentStatusRepo,
+		productRepo:        productRepo,
+		warehouseRepo:      warehouseRepo,
+		warehouseTypeRepo:  warehouseTypeRepo,
+		negativeStock:      negativeStock,
+		transitions:        transitions,
+		audit:              audit,
+	}
+}
+
+// mpReturnFields - проверенные поля шапки и позиции возврата
+type mpReturnFields struct {
+	storeID     uuid.UUID
+	shipmentID  *uuid.UUID
+	warehouseID uuid.UUID
+	returnType  string
+	returnDate  time.Time
+	items       []repository.MpReturnItemInput
+}
+
+func toMpReturnResponse(mpReturn *repository.MpReturn, items []repository.MpReturnItem) *dto.MpReturnResponse {
+	var shipmentIDStr *string
+	if mpReturn.ShipmentID != nil {
+		str := mpReturn.ShipmentID.String()
+		shipmentIDStr = &str
+	}
+	var createdByStr *string
+	if mpReturn.CreatedBy != nil {
+		str := mpReturn.CreatedBy.String()
+		createdByStr = &str
+	}
+	var updatedByStr *string
+	if mpReturn.UpdatedBy != nil {
+		str := mpReturn.UpdatedBy.String()
+		updatedByStr = &str
+	}
+
+	result := &dto.MpReturnResponse{
+		ReturnID:     mpReturn.ReturnID.String(),
+		ReturnNumber: mpReturn.ReturnNumber,
+		StoreID:      mpReturn.StoreID.String(),
+		ShipmentID:   shipmentIDStr,
+		WarehouseID:  mpReturn.WarehouseID.String(),
+		ReturnType:   mpReturn.ReturnType,
+		Status:       mpReturn.Status,
+		ReturnDate:   mpReturn.ReturnDate,
+		Notes:        mpReturn.Notes,
+		CreatedBy:    createdByStr,
+		CreatedAt:    mpReturn.CreatedAt,
+		UpdatedBy:    updatedByStr,
+		UpdatedAt:    mpReturn.UpdatedAt,
+	}
+
+	for _, item := range items {
+		result.Items = append(result.Items, dto.MpReturnItemResponse{
+			ReturnItemID: item.ReturnItemID.String(),
+			ProductID:    item.ProductID.String(),
+			Article:      item.Article,
+			Quantity:     item.Quantity,
+			Condition:    item.Condition,
+		})
+	}
+
+	return result
+}
+
+func (s *MpReturnService) GetByID(ctx context.Context, returnID uuid.UUID) (*dto.MpReturnResponse, error) {
+	mpReturn, err := s.repo.GetByID(ctx, returnID)
+	if err != nil {
+		if err != repository.ErrMpReturnNotFound {
+			log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to get mp return by ID")
+		}
+		return nil, err
+	}
+
+	items, err := s.repo.GetItems(ctx, returnID)
+	if err != nil {
+		log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to get mp return items")
+		return nil, err
+	}
+
+	return toMpReturnResponse(mpReturn, items), nil
+}
+
+// List returns returns without items.
+func (s *MpReturnService) List(ctx context.Context, limit, offset int, storeID, shipmentID *uuid.UUID, status *string) ([]dto.MpReturnResponse, error) {
+	returns, err := s.repo.List(ctx, limit, offset, storeID, shipmentID, status)
+	if err != nil {
+		log.Error().Err(err).Int("limit", limit).Int("offset", offset).
+			Interface("storeId", storeID).Interface("shipmentId", shipmentID).Interface("status", status).Msg("Failed to list mp returns")
+		return nil, err
+	}
+
+	result := make([]dto.MpReturnResponse, 0, len(returns))
+	for i := range returns {
+		result = append(result, *toMpReturnResponse(&returns[i], nil))
+	}
+
+	return result, nil
+}
+
+// isReturnsWarehouse reports whether the warehouse has the "Склад возвратов" type.
+func (s *MpReturnService) isReturnsWarehouse(ctx context.Context, warehouse *repository.Warehouse) (bool, error) {
+	if warehouse.WarehouseTypeID == nil {
+		return false, nil
+	}
+	warehouseType, err := s.warehouseTypeRepo.GetByID(ctx, *warehouse.WarehouseTypeID)
+	if err != nil {
+		if err == repository.ErrWarehouseTypeNotFound {
+			return false, nil
+		}
+		log.Error().Err(err).Str("warehouseTypeId", warehouse.WarehouseTypeID.String()).Msg("Failed to get warehouse type")
+		return false, err
+	}
+	return warehouseType.Name == repository.WarehouseTypeReturns, nil
+}
+
+// checkShipmentStatus ensures that the shipment has left the warehouse, so its sent quantity was deducted
+// from stock; a rejection return additionally requires the shipment to be rejected by the marketplace.
+func (s *MpReturnService) checkShipmentStatus(ctx context.Context, shipment *repository.MpShipment, returnType string) error {
+	var statusName string
+	if shipment.StatusID != nil {
+		status, err := s.shipmentStatusRepo.GetByID(ctx, *shipment.StatusID)
+		if err != nil {
+			log.Error().Err(err).Str("statusId", shipment.StatusID.String()).Msg("Failed to get shipment status")
+			return err
+		}
+		statusName = status.Name
+	}
+
+	if !repository.IsShipmentDispatched(statusName) {
+		log.Warn().Str("shipmentId", shipment.ShipmentID.String()).Str("status", statusName).Msg("Shipment has not been dispatched")
+		return repository.ErrReturnShipmentNotSent
+	}
+	if returnType == repository.MpReturnTypeRejection && statusName != repository.ShipmentStatusRejected {
+		log.Warn().Str("shipmentId", shipment.ShipmentID.String()).Str("status", statusName).Msg("Rejection return requires a rejected shipment")
+		return repository.ErrReturnShipmentNotRejected
+	}
+
+	return nil
+}
+
+// validate checks the header and items of a draft return. returnID is the return being edited, nil on create.
+func (s *MpReturnService) validate(ctx context.Context, returnID *uuid.UUID, storeIDStr string, shipmentIDStr *string, warehouseIDStr string, returnType *string, returnDate *time.Time, reqItems []dto.MpReturnItemRequest) (*mpReturnFields, error) {
+	fields := &mpReturnFields{
+		returnType: repository.MpReturnTypeCustomer,
+		returnDate: today(),
+	}
+	if returnType != nil && *returnType != "" {
+		if !repository.IsValidReturnType(*returnType) {
+			log.Warn().Str("returnType", *returnType).Msg("Invalid return type")
+			return nil, repository.ErrInvalidReturnType
+		}
+		fields.returnType = *returnType
+	}
+	if returnDate != nil {
+		fields.returnDate = *returnDate
+	}
+
+	storeID, err := uuid.Parse(storeIDStr)
+	if err != nil {
+		log.Warn().Str("storeId", storeIDStr).Msg("Invalid store ID format")
+		return nil, repository.ErrStoreNotFound
+	}
+	store, err := s.storeRepo.GetByID(ctx, storeID)
+	if err != nil {
+		if err == repository.ErrStoreNotFound {
+			log.Warn().Str("storeId", storeIDStr).Msg("Store not found")
+			return nil, repository.ErrStoreNotFound
+		}
+		log.Error().Err(err).Str("storeId", storeIDStr).Msg("Failed to validate store")
+		return nil, err
+	}
+	if store.IsArchived {
+		log.Warn().Str("storeId", storeIDStr).Msg("Store is archived")
+		return nil, repository.ErrStoreArchived
+	}
+	fields.storeID = storeID
+
+	if shipmentIDStr != nil && *shipmentIDStr != "" {
+		shipmentID, err := uuid.Parse(*shipmentIDStr)
+		if err != nil {
+			log.Warn().Str("shipmentId", *shipmentIDStr).Msg("Invalid shipment ID format")
+			return nil, repository.ErrMpShipmentNotFound
+		}
+		shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+		if err != nil {
+			if err == repository.ErrMpShipmentNotFound {
+				log.Warn().Str("shipmentId", *shipmentIDStr).Msg("Shipment not found")
+				return nil, repository.ErrMpShipmentNotFound
+			}
+			log.Error().Err(err).Str("shipmentId", *shipmentIDStr).Msg("Failed to validate shipment")
+			return nil, err
+		}
+		if shipment.StoreID == nil || *shipment.StoreID != storeID {
+			log.Warn().Str("shipmentId", *shipmentIDStr).Str("storeId", storeIDStr).Msg("Shipment belongs to another store")
+			return nil, repository.ErrReturnShipmentMismatch
+		}
+		if err := s.checkShipmentStatus(ctx, shipment, fields.returnType); err != nil {
+			return nil, err
+		}
+		fields.shipmentID = &shipmentID
+	}
+
+	warehouseID, err := uuid.Parse(warehouseIDStr)
+	if err != nil {
+		log.Warn().Str("warehouseId", warehouseIDStr).Msg("Invalid warehouse ID format")
+		return nil, repository.ErrWarehouseNotFound
+	}
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
+	if err != nil {
+		if err == repository.ErrWarehouseNotFound {
+			log.Warn().Str("warehouseId", warehouseIDStr).Msg("Warehouse not found")
+			return nil, repository.ErrWarehouseNotFound
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseIDStr).Msg("Failed to validate warehouse")
+		return nil, err
+	}
+	if warehouse.IsArchived {
+		log.Warn().Str("warehouseId", warehouseIDStr).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
+	}
+	fields.warehouseID = warehouseID
+
+	if len(reqItems) == 0 {
+		return nil, repository.ErrEmptyReturn
+	}
+
+	type itemKey struct {
+		productID uuid.UUID
+		condition string
+	}
+	seen := make(map[itemKey]bool, len(reqItems))
+	for _, reqItem := range reqItems {
+		productID, err := uuid.Parse(reqItem.ProductID)
+		if err != nil {
+			log.Warn().Str("productId", reqItem.ProductID).Msg("Invalid product ID format")
+			return nil, repository.ErrProductNotFound
+		}
+		if !repository.IsValidReturnCondition(reqItem.Condition) {
+			log.Warn().Str("condition", reqItem.Condition).Msg("Invalid return condition")
+			return nil, repository.ErrInvalidReturnCondition
+		}
+		key := itemKey{productID: productID, condition: reqItem.Condition}
+		if seen[key] {
+			log.Warn().Str("productId", reqItem.ProductID).Str("condition", reqItem.Condition).Msg("Product is listed twice with the same condition")
+			return nil, repository.ErrDuplicateItem
+		}
+		seen[key] = true
+
+		product, err := s.productRepo.GetByID(ctx, productID)
+		if err != nil {
+			if err == repository.ErrProductNotFound {
+				log.Warn().Str("productId", reqItem.ProductID).Msg("Product not found")
+				return nil, repository.ErrProductNotFound
+			}
+			log.Error().Err(err).Str("productId", reqItem.ProductID).Msg("Failed to validate product")
+			return nil, err
+		}
+		if product.IsArchived {
+			log.Warn().Str("productId", reqItem.ProductID).Msg("Product is archived")
+			return nil, repository.ErrProductArchived
+		}
+
+		if reqItem.Quantity <= 0 {
+			log.Warn().Int("quantity", reqItem.Quantity).Msg("Returned quantity must be positive")
+			return nil, repository.ErrInvalidQuantity
+		}
+
+		fields.items = append(fields.items, repository.MpReturnItemInput{
+			ProductID: productID,
+			Quantity:  reqItem.Quantity,
+			Condition: reqItem.Condition,
+		})
+	}
+
+	// брак поступает в остатки склада, поэтому принимается только на склад возвратов, чтобы его не зарезервировали и не отгрузили
+	for _, item := range fields.items {
+		if item.Condition != repository.ReturnConditionDefect {
+			continue
+		}
+		isReturns, err := s.isReturnsWarehouse(ctx, warehouse)
+		if err != nil {
+			return nil, err
+		}
+		if !isReturns {
+			log.Warn().Str("warehouseId", warehouseIDStr).Msg("Defective returns require a returns warehouse")
+			return nil, repository.ErrNotReturnsWarehouse
+		}
+		break
+	}
+
+	if fields.shipmentID != nil {
+		if err := s.checkReturnable(ctx, *fields.shipmentID, returnID, fields.items); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+// checkReturnable ensures that together with other not cancelled returns of the shipment no product is
+// returned in a larger quantity than was sent in the shipment.
+func (s *MpReturnService) checkReturnable(ctx context.Context, shipmentID uuid.UUID, returnID *uuid.UUID, items []repository.MpReturnItemInput) error {
+	returnable, err := s.repo.GetReturnableQuantities(ctx, shipmentID, returnID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get returnable quantities")
+		return err
+	}
+
+	requested := make(map[uuid.UUID]int)
+	for _, item := range items {
+		requested[item.ProductID] += item.Quantity
+	}
+	for productID, quantity := range requested {
+		if quantity > returnable[productID] {
+			log.Warn().Str("shipmentId", shipmentID.String()).Str("productId", productID.String()).
+				Int("quantity", quantity).Int("returnable", returnable[productID]).Msg("Returned quantity exceeds quantity sent in shipment")
+			return repository.ErrReturnExceedsShipped
+		}
+	}
+
+	return nil
+}
+
+func (s *MpReturnService) Create(ctx context.Context, userID uuid.UUID, req dto.MpReturnCreateRequest) (*dto.MpReturnResponse, error) {
+	fields, err := s.validate(ctx, nil, req.StoreID, req.ShipmentID, req.WarehouseID, req.ReturnType, req.ReturnDate, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	mpReturn, err := s.repo.Create(ctx, req.ReturnNumber, fields.storeID, fields.shipmentID, fields.warehouseID, fields.returnType, fields.returnDate, req.Notes, fields.items, userID)
+	if err != nil {
+		log.Error().Err(err).Str("returnNumber", req.ReturnNumber).Str("userId", userID.String()).Msg("Failed to create mp return")
+		return nil, err
+	}
+
+	s.transitions.Record(ctx, repository.StatusEntityMpReturn, mpReturn.ReturnID, nil, mpReturn.Status, nil, userID)
+
+	log.Info().Str("returnId", mpReturn.ReturnID.String()).Str("returnNumber", mpReturn.ReturnNumber).Str("userId", userID.String()).Msg("Mp return created successfully")
+	result, err := s.GetByID(ctx, mpReturn.ReturnID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpReturn, mpReturn.ReturnID, repository.AuditActionCreate, nil, result)
+	return result, nil
+}
+
+func (s *MpReturnService) Update(ctx context.Context, returnID, userID uuid.UUID, req dto.MpReturnUpdateRequest) (*dto.MpReturnResponse, error) {
+	before, err := s.GetByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != repository.MpReturnStatusDraft {
+		log.Warn().Str("returnId", returnID.String()).Str("status", before.Status).Msg("Only draft returns can be edited")
+		return nil, repository.ErrMpReturnNotEditable
+	}
+
+	fields, err := s.validate(ctx, &returnID, req.StoreID, req.ShipmentID, req.WarehouseID, req.ReturnType, req.ReturnDate, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Update(ctx, returnID, req.ReturnNumber, fields.storeID, fields.shipmentID, fields.warehouseID, fields.returnType, fields.returnDate, req.Notes, fields.items, userID); err != nil {
+		log.Error().Err(err).Str("returnId", returnID.String()).Str("userId", userID.String()).Msg("Failed to update mp return")
+		return nil, err
+	}
+
+	log.Info().Str("returnId", returnID.String()).Str("userId", userID.String()).Msg("Mp return updated successfully")
+	result, err := s.GetByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpReturn, returnID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+func (s *MpReturnService) Delete(ctx context.Context, returnID uuid.UUID) error {
+	before, err := s.GetByID(ctx, returnID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, returnID); err != nil {
+		if err != repository.ErrMpReturnNotEditable {
+			log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to delete mp return")
+		}
+		return err
+	}
+
+	log.Info().Str("returnId", returnID.String()).Msg("Mp return deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityMpReturn, returnID, repository.AuditActionDelete, before, nil)
+	return nil
+}
+
+func (s *MpReturnService) History(ctx context.Context, returnID uuid.UUID) ([]dto.StatusHistoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, returnID); err != nil {
+		if err != repository.ErrMpReturnNotFound {
+			log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to get mp return for status history")
+		}
+		return nil, err
+	}
+	return s.transitions.History(ctx, repository.StatusEntityMpReturn, returnID)
+}
+
+// Post posts a draft return: resellable and defective units enter the returns warehouse on the return date.
+func (s *MpReturnService) Post(ctx context.Context, returnID, userID uuid.UUID, req dto.MpReturnActionRequest) (*dto.MpReturnResponse, error) {
+	before, err := s.GetByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityMpReturn, &before.Status, repository.MpReturnStatusPosted); err != nil {
+		return nil, err
+	}
+	if len(before.Items) == 0 {
+		return nil, repository.ErrEmptyReturn
+	}
+
+	// Другие возвраты по отгрузке могли быть созданы после сохранения черновика
+	if before.ShipmentID != nil {
+		shipmentID, err := uuid.Parse(*before.ShipmentID)
+		if err != nil {
+			return nil, err
+		}
+		shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+		if err != nil {
+			log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get shipment")
+			return nil, err
+		}
+		if err := s.checkShipmentStatus(ctx, shipment, before.ReturnType); err != nil {
+			return nil, err
+		}
+		items, err := mpReturnItemInputs(before.Items)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkReturnable(ctx, shipmentID, &returnID, items); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateStatus(ctx, returnID, before.Status, repository.MpReturnStatusPosted, userID); err != nil {
+		log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to post mp return")
+		return nil, err
+	}
+
+	return s.afterTransition(ctx, returnID, userID, before, repository.MpReturnStatusPosted, req.Comment)
+}
+
+// Cancel cancels a draft or posted return. Cancelling a posted return takes its units back out of the
+// returns warehouse, so the negative stock policy is applied.
+func (s *MpReturnService) Cancel(ctx context.Context, returnID, userID uuid.UUID, req dto.MpReturnActionRequest) (*dto.MpReturnResponse, error) {
+	before, err := s.GetByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityMpReturn, &before.Status, repository.MpReturnStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	if before.Status == repository.MpReturnStatusPosted {
+		warehouseID, err := uuid.Parse(before.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		var changes []repository.StockChange
+		for _, item := range before.Items {
+			if item.Condition == repository.ReturnConditionWriteOff {
+				continue
+			}
+			productID, err := uuid.Parse(item.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, repository.StockChange{ProductID: productID, WarehouseID: warehouseID, Quantity: -item.Quantity})
+		}
+		if err := s.negativeStock.Check(ctx, repository.StatusEntityMpReturn, returnID, stockChanges(changes)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateStatus(ctx, returnID, before.Status, repository.MpReturnStatusCancelled, userID); err != nil {
+		log.Error().Err(err).Str("returnId", returnID.String()).Msg("Failed to cancel mp return")
+		return nil, err
+	}
+
+	return s.afterTransition(ctx, returnID, userID, before, repository.MpReturnStatusCancelled, req.Comment)
+}
+
+func (s *MpReturnService) afterTransition(ctx context.Context, returnID, userID uuid.UUID, before *dto.MpReturnResponse, toStatus string, comment *string) (*dto.MpReturnResponse, error) {
+	s.transitions.Record(ctx, repository.StatusEntityMpReturn, returnID, &before.Status, toStatus, comment, userID)
+
+	log.Info().Str("returnId", returnID.String()).Str("status", toStatus).Str("userId", userID.String()).Msg("Mp return status changed")
+	result, err := s.GetByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpReturn, returnID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+func mpReturnItemInputs(items []dto.MpReturnItemResponse) ([]repository.MpReturnItemInput, error) {
+	result := make([]repository.MpReturnItemInput, 0, len(items))
+	for _, item := range items {
+		productID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, repository.MpReturnItemInput{ProductID: productID, Quantity: item.Quantity, Condition: item.Condition})
+	}
+	return result, nil
+}
@@ -15,17 +15,145 @@ type MpShipmentItemService struct {
 	shipmentRepo  *repository.MpShipmentRepository
 	productRepo   *repository.ProductRepository
 	warehouseRepo *repository.WarehouseRepository
+	statusRepo    *repository.ShipmentStatusRepository
+	reservations  *repository.StockReservationRepository
+	logistics     *ShipmentLogisticsService
+	negativeStock *StockPolicyService
+	audit         *AuditService
 }
 
-func NewMpShipmentItemService(repo *repository.MpShipmentItemRepository, shipmentRepo *repository.MpShipmentRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository) *MpShipmentItemService {
+func NewMpShipmentItemService(repo *repository.MpShipmentItemRepository, shipmentRepo *repository.MpShipmentRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, statusRepo *repository.ShipmentStatusRepository, reservations *repository.StockReservationRepository, logistics *ShipmentLogisticsService, negativeStock *StockPolicyService, audit *AuditService) *MpShipmentItemService {
 	return &MpShipmentItemService{
 		repo:          repo,
 		shipmentRepo:  shipmentRepo,
 		productRepo:   productRepo,
 		warehouseRepo: warehouseRepo,
+		statusRepo:    statusRepo,
+		reservations:  reservations,
+		logistics:     logistics,
+		negativeStock: negativeStock,
+		audit:         audit,
 	}
 }
 
+// recalcShipmentAggregates redistributes the shipment logistics over its items and refreshes shipment totals.
+func (s *MpShipmentItemService) recalcShipmentAggregates(ctx context.Context, shipmentID, userID uuid.UUID) error {
+	return s.logistics.Recalculate(ctx, shipmentID, userID)
+}
+
+// reloadItem returns the item as stored after aggregates recalculation, falling back to the given one.
+func (s *MpShipmentItemService) reloadItem(ctx context.Context, item *repository.MpShipmentItem) *repository.MpShipmentItem {
+	reloaded, err := s.repo.GetByID(ctx, item.ShipmentItemID)
+	if err != nil {
+		log.Warn().Err(err).Str("itemId", item.ShipmentItemID.String()).Msg("Failed to reload mp shipment item after recalculation")
+		return item
+	}
+	return reloaded
+}
+
+// stockPolicy tells how an item of the shipment affects stock: items of shipments in "Создан" (or without
+// status yet) hold a reservation; items of dispatched shipments are already deducted from stock, so they are
+// covered by the negative stock policy instead of the availability check.
+func (s *MpShipmentItemService) stockPolicy(ctx context.Context, shipment *repository.MpShipment) (reserve, check bool, err error) {
+	if shipment.StatusID == nil {
+		return true, true, nil
+	}
+
+	status, err := s.statusRepo.GetByID(ctx, *shipment.StatusID)
+	if err != nil {
+		log.Error().Err(err).Str("statusId", shipment.StatusID.String()).Msg("Failed to get shipment status")
+		return false, false, err
+	}
+
+	switch {
+	case status.Name == repository.ShipmentStatusCreated:
+		return true, true, nil
+	case repository.IsShipmentDispatched(status.Name):
+		return false, false, nil
+	default:
+		return false, true, nil
+	}
+}
+
+// dispatched reports whether the shipment's sent quantity is already deducted from stock.
+func (s *MpShipmentItemService) dispatched(ctx context.Context, shipment *repository.MpShipment) (bool, error) {
+	if shipment.StatusID == nil || shipment.ShipmentDate == nil {
+		return false, nil
+	}
+	status, err := s.statusRepo.GetByID(ctx, *shipment.StatusID)
+	if err != nil {
+		log.Error().Err(err).Str("statusId", shipment.StatusID.String()).Msg("Failed to get shipment status")
+		return false, err
+	}
+	return repository.IsShipmentDispatched(status.Name), nil
+}
+
+// checkNotResolved rejects item changes of a shipment whose discrepancy is already resolved: returned and lost
+// quantities are stored on the items and would no longer match.
+func (s *MpShipmentItemService) checkNotResolved(ctx context.Context, shipmentID uuid.UUID) error {
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get mp shipment of item")
+		return err
+	}
+	if shipment.ResolutionDate != nil {
+		log.Warn().Str("shipmentId", shipmentID.String()).Msg("Mp shipment discrepancy is already resolved")
+		return repository.ErrShipmentResolved
+	}
+	return nil
+}
+
+func (s *MpShipmentItemService) checkAvailability(ctx context.Context, productID, warehouseID uuid.UUID, excludeItemID *uuid.UUID, qty int) error {
+	availability, err := s.reservations.GetAvailability(ctx, productID, warehouseID, excludeItemID)
+	if err != nil {
+		log.Error().Err(err).Str("productId", productID.String()).Str("warehouseId", warehouseID.String()).Msg("Failed to check stock availability")
+		return err
+	}
+	if qty > availability.Available {
+		log.Warn().
+			Str("productId", productID.String()).
+			Str("warehouseId", warehouseID.String()).
+			Int("requested", qty).
+			Int("onHand", availability.OnHand).
+			Int("reserved", availability.Reserved).
+			Msg("Insufficient stock for shipment item")
+		return repository.ErrInsufficientStock
+	}
+	return nil
+}
+
+// checkNegativeStock applies the negative stock policy to the sent quantity of an item of a dispatched
+// shipment, which is what moves stock. previous is the item before the update.
+func (s *MpShipmentItemService) checkNegativeStock(ctx context.Context, shipment *repository.MpShipment, productID, warehouseID uuid.UUID, sentQty int, previous *repository.MpShipmentItem) error {
+	var changes []repository.StockChange
+	if previous != nil {
+		previousShipment := shipment
+		if previous.ShipmentID != shipment.ShipmentID {
+			var err error
+			previousShipment, err = s.shipmentRepo.GetByID(ctx, previous.ShipmentID)
+			if err != nil {
+				log.Error().Err(err).Str("shipmentId", previous.ShipmentID.String()).Msg("Failed to get previous mp shipment of item")
+				return err
+			}
+		}
+		moved, err := s.dispatched(ctx, previousShipment)
+		if err != nil {
+			return err
+		}
+		if moved {
+			changes = append(changes, repository.StockChange{ProductID: previous.ProductID, WarehouseID: previous.WarehouseID, Quantity: previous.SentQty})
+		}
+	}
+	moved, err := s.dispatched(ctx, shipment)
+	if err != nil {
+		return err
+	}
+	if moved {
+		changes = append(changes, repository.StockChange{ProductID: productID, WarehouseID: warehouseID, Quantity: -sentQty})
+	}
+	return s.negativeStock.Check(ctx, repository.StatusEntityMpShipment, shipment.ShipmentID, stockChanges(changes))
+}
+
 func (s *MpShipmentItemService) GetByID(ctx context.Context, itemID uuid.UUID) (*dto.MpShipmentItemResponse, error) {
 	item, err := s.repo.GetByID(ctx, itemID)
 	if err != nil {
@@ -41,6 +169,8 @@ func (s *MpShipmentItemService) GetByID(ctx context.Context, itemID uuid.UUID) (
 		SentQty:          item.SentQty,
 		AcceptedQty:      item.AcceptedQty,
 		LogisticsForItem: item.LogisticsForItem,
+		ReturnedQty:      item.ReturnedQty,
+		LostQty:          item.LostQty,
 	}, nil
 }
 
@@ -61,19 +191,21 @@ func (s *MpShipmentItemService) GetByShipmentID(ctx context.Context, shipmentID
 			SentQty:          item.SentQty,
 			AcceptedQty:      item.AcceptedQty,
 			LogisticsForItem: item.LogisticsForItem,
+			ReturnedQty:      item.ReturnedQty,
+			LostQty:          item.LostQty,
 		})
 	}
 
 	return result, nil
 }
 
-func (s *MpShipmentItemService) Create(ctx context.Context, req dto.MpShipmentItemCreateRequest) (*dto.MpShipmentItemResponse, error) {
+func (s *MpShipmentItemService) Create(ctx context.Context, userID uuid.UUID, req dto.MpShipmentItemCreateRequest) (*dto.MpShipmentItemResponse, error) {
 	shipmentID, err := uuid.Parse(req.ShipmentID)
 	if err != nil {
 		log.Warn().Str("shipmentId", req.ShipmentID).Msg("Invalid shipment ID format")
 		return nil, repository.ErrMpShipmentNotFound
 	}
-	_, err = s.shipmentRepo.GetByID(ctx, shipmentID)
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
 	if err != nil {
 		if err == repository.ErrMpShipmentNotFound {
 			log.Warn().Str("shipmentId", req.ShipmentID).Msg("Mp shipment not found")
@@ -82,13 +214,17 @@ func (s *MpShipmentItemService) Create(ctx context.Context, req dto.MpShipmentIt
 		log.Error().Err(err).Str("shipmentId", req.ShipmentID).Msg("Failed to validate mp shipment")
 		return nil, err
 	}
+	if shipment.ResolutionDate != nil {
+		log.Warn().Str("shipmentId", req.ShipmentID).Msg("Mp shipment discrepancy is already resolved")
+		return nil, repository.ErrShipmentResolved
+	}
 
 	productID, err := uuid.Parse(req.ProductID)
 	if err != nil {
 		log.Warn().Str("productId", req.ProductID).Msg("Invalid product ID format")
 		return nil, repository.ErrProductNotFound
 	}
-	_, err = s.productRepo.GetByID(ctx, productID)
+	product, err := s.productRepo.GetByID(ctx, productID)
 	if err != nil {
 		if err == repository.ErrProductNotFound {
 			log.Warn().Str("productId", req.ProductID).Msg("Product not found")
@@ -97,13 +233,17 @@ func (s *MpShipmentItemService) Create(ctx context.Context, req dto.MpShipmentIt
 		log.Error().Err(err).Str("productId", req.ProductID).Msg("Failed to validate product")
 		return nil, err
 	}
+	if product.IsArchived {
+		log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+		return nil, repository.ErrProductArchived
+	}
 
 	warehouseID, err := uuid.Parse(req.WarehouseID)
 	if err != nil {
 		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
 		return nil, repository.ErrWarehouseNotFound
 	}
-	_, err = s.warehouseRepo.GetByID(ctx, warehouseID)
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
 	if err != nil {
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
@@ -112,27 +252,60 @@ func (s *MpShipmentItemService) Create(ctx context.Context, req dto.MpShipmentIt
 		log.Error().Err(err).Str("warehouseId", req.WarehouseID).Msg("Failed to validate warehouse")
 		return nil, err
 	}
+	if warehouse.IsArchived {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
+	}
 
 	if req.AcceptedQty > req.SentQty {
 		log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Accepted quantity cannot exceed sent quantity")
 		return nil, repository.ErrInvalidQuantity
 	}
 
+	reserve, check, err := s.stockPolicy(ctx, shipment)
+	if err != nil {
+		return nil, err
+	}
+	if check {
+		if err := s.checkAvailability(ctx, productID, warehouseID, nil, req.SentQty); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkNegativeStock(ctx, shipment, productID, warehouseID, req.SentQty, nil); err != nil {
+		return nil, err
+	}
+
 	item, err := s.repo.Create(ctx,
 		shipmentID,
 		productID,
 		warehouseID,
 		req.SentQty,
 		req.AcceptedQty,
-		req.LogisticsForItem,
+		nil,
 	)
 	if err != nil {
 		log.Error().Err(err).Str("shipmentId", req.ShipmentID).Str("productId", req.ProductID).Msg("Failed to create mp shipment item")
 		return nil, err
 	}
 
+	if reserve {
+		if _, err := s.reservations.Reserve(ctx, item.ShipmentItemID, productID, warehouseID, item.SentQty); err != nil {
+			log.Warn().Err(err).Str("shipmentItemId", item.ShipmentItemID.String()).Msg("Failed to reserve stock, removing shipment item")
+			if delErr := s.repo.Delete(ctx, item.ShipmentItemID); delErr != nil {
+				log.Error().Err(delErr).Str("shipmentItemId", item.ShipmentItemID.String()).Msg("Failed to remove shipment item after reservation failure")
+			}
+			return nil, err
+		}
+	}
+
+	if aggErr := s.recalcShipmentAggregates(ctx, shipmentID, userID); aggErr != nil {
+		log.Error().Err(aggErr).Str("shipmentId", req.ShipmentID).Msg("Failed to recalc shipment aggregates after item create")
+	} else {
+		item = s.reloadItem(ctx, item)
+	}
+
 	log.Info().Str("shipmentItemId", item.ShipmentItemID.String()).Str("shipmentId", req.ShipmentID).Str("productId", req.ProductID).Msg("Mp shipment item created successfully")
-	return &dto.MpShipmentItemResponse{
+	result := &dto.MpShipmentItemResponse{
 		ShipmentItemID:   item.ShipmentItemID.String(),
 		ShipmentID:       item.ShipmentID.String(),
 		ProductID:        item.ProductID.String(),
@@ -140,16 +313,25 @@ func (s *MpShipmentItemService) Create(ctx context.Context, req dto.MpShipmentIt
 		SentQty:          item.SentQty,
 		AcceptedQty:      item.AcceptedQty,
 		LogisticsForItem: item.LogisticsForItem,
-	}, nil
+		ReturnedQty:      item.ReturnedQty,
+		LostQty:          item.LostQty,
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpShipmentItem, item.ShipmentItemID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
-func (s *MpShipmentItemService) Update(ctx context.Context, itemID uuid.UUID, req dto.MpShipmentItemUpdateRequest) (*dto.MpShipmentItemResponse, error) {
+func (s *MpShipmentItemService) Update(ctx context.Context, itemID, userID uuid.UUID, req dto.MpShipmentItemUpdateRequest) (*dto.MpShipmentItemResponse, error) {
+	before, err := s.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
 	shipmentID, err := uuid.Parse(req.ShipmentID)
 	if err != nil {
 		log.Warn().Str("shipmentId", req.ShipmentID).Msg("Invalid shipment ID format")
 		return nil, repository.ErrMpShipmentNotFound
 	}
-	_, err = s.shipmentRepo.GetByID(ctx, shipmentID)
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
 	if err != nil {
 		if err == repository.ErrMpShipmentNotFound {
 			log.Warn().Str("shipmentId", req.ShipmentID).Msg("Mp shipment not found")
@@ -158,13 +340,17 @@ func (s *MpShipmentItemService) Update(ctx context.Context, itemID uuid.UUID, re
 		log.Error().Err(err).Str("shipmentId", req.ShipmentID).Msg("Failed to validate mp shipment")
 		return nil, err
 	}
+	if shipment.ResolutionDate != nil {
+		log.Warn().Str("shipmentId", req.ShipmentID).Msg("Mp shipment discrepancy is already resolved")
+		return nil, repository.ErrShipmentResolved
+	}
 
 	productID, err := uuid.Parse(req.ProductID)
 	if err != nil {
 		log.Warn().Str("productId", req.ProductID).Msg("Invalid product ID format")
 		return nil, repository.ErrProductNotFound
 	}
-	_, err = s.productRepo.GetByID(ctx, productID)
+	product, err := s.productRepo.GetByID(ctx, productID)
 	if err != nil {
 		if err == repository.ErrProductNotFound {
 			log.Warn().Str("productId", req.ProductID).Msg("Product not found")
@@ -173,13 +359,17 @@ func (s *MpShipmentItemService) Update(ctx context.Context, itemID uuid.UUID, re
 		log.Error().Err(err).Str("productId", req.ProductID).Msg("Failed to validate product")
 		return nil, err
 	}
+	if product.IsArchived && before.ProductID != productID.String() {
+		log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+		return nil, repository.ErrProductArchived
+	}
 
 	warehouseID, err := uuid.Parse(req.WarehouseID)
 	if err != nil {
 		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
 		return nil, repository.ErrWarehouseNotFound
 	}
-	_, err = s.warehouseRepo.GetByID(ctx, warehouseID)
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
 	if err != nil {
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
@@ -188,27 +378,85 @@ func (s *MpShipmentItemService) Update(ctx context.Context, itemID uuid.UUID, re
 		log.Error().Err(err).Str("warehouseId", req.WarehouseID).Msg("Failed to validate warehouse")
 		return nil, err
 	}
+	if warehouse.IsArchived && before.WarehouseID != warehouseID.String() {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
+	}
 
 	if req.AcceptedQty > req.SentQty {
 		log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Accepted quantity cannot exceed sent quantity")
 		return nil, repository.ErrInvalidQuantity
 	}
 
+	existing, err := s.repo.GetByID(ctx, itemID)
+	if err != nil {
+		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to load mp shipment item before update")
+		return nil, err
+	}
+	if existing.ShipmentID != shipmentID {
+		if err := s.checkNotResolved(ctx, existing.ShipmentID); err != nil {
+			return nil, err
+		}
+	}
+
+	reserve, check, err := s.stockPolicy(ctx, shipment)
+	if err != nil {
+		return nil, err
+	}
+	if check {
+		if err := s.checkAvailability(ctx, productID, warehouseID, &itemID, req.SentQty); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkNegativeStock(ctx, shipment, productID, warehouseID, req.SentQty, existing); err != nil {
+		return nil, err
+	}
+
 	item, err := s.repo.Update(ctx, itemID,
 		shipmentID,
 		productID,
 		warehouseID,
 		req.SentQty,
 		req.AcceptedQty,
-		req.LogisticsForItem,
+		nil,
 	)
 	if err != nil {
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to update mp shipment item")
 		return nil, err
 	}
 
+	if reserve {
+		if _, err := s.reservations.Reserve(ctx, itemID, productID, warehouseID, item.SentQty); err != nil {
+			log.Warn().Err(err).Str("itemId", itemID.String()).Msg("Failed to reserve stock, restoring shipment item")
+			if _, revertErr := s.repo.Update(ctx, itemID,
+				existing.ShipmentID,
+				existing.ProductID,
+				existing.WarehouseID,
+				existing.SentQty,
+				existing.AcceptedQty,
+				existing.LogisticsForItem,
+			); revertErr != nil {
+				log.Error().Err(revertErr).Str("itemId", itemID.String()).Msg("Failed to restore shipment item after reservation failure")
+			}
+			return nil, err
+		}
+	} else if err := s.reservations.Release(ctx, itemID); err != nil {
+		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to release stock reservation")
+	}
+
+	if aggErr := s.recalcShipmentAggregates(ctx, shipmentID, userID); aggErr != nil {
+		log.Error().Err(aggErr).Str("shipmentId", req.ShipmentID).Msg("Failed to recalc shipment aggregates after item update")
+	} else {
+		item = s.reloadItem(ctx, item)
+	}
+	if existing.ShipmentID != shipmentID {
+		if aggErr := s.recalcShipmentAggregates(ctx, existing.ShipmentID, userID); aggErr != nil {
+			log.Error().Err(aggErr).Str("shipmentId", existing.ShipmentID.String()).Msg("Failed to recalc previous shipment aggregates after item move")
+		}
+	}
+
 	log.Info().Str("itemId", itemID.String()).Msg("Mp shipment item updated successfully")
-	return &dto.MpShipmentItemResponse{
+	result := &dto.MpShipmentItemResponse{
 		ShipmentItemID:   item.ShipmentItemID.String(),
 		ShipmentID:       item.ShipmentID.String(),
 		ProductID:        item.ProductID.String(),
@@ -216,16 +464,38 @@ func (s *MpShipmentItemService) Update(ctx context.Context, itemID uuid.UUID, re
 		SentQty:          item.SentQty,
 		AcceptedQty:      item.AcceptedQty,
 		LogisticsForItem: item.LogisticsForItem,
-	}, nil
+		ReturnedQty:      item.ReturnedQty,
+		LostQty:          item.LostQty,
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpShipmentItem, itemID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
-func (s *MpShipmentItemService) Delete(ctx context.Context, itemID uuid.UUID) error {
-	err := s.repo.Delete(ctx, itemID)
+func (s *MpShipmentItemService) Delete(ctx context.Context, itemID, userID uuid.UUID) error {
+	item, err := s.repo.GetByID(ctx, itemID)
+	if err != nil {
+		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to load mp shipment item before deletion")
+		return err
+	}
+	if err := s.checkNotResolved(ctx, item.ShipmentID); err != nil {
+		return err
+	}
+	before, err := s.GetByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, itemID)
 	if err != nil {
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to delete mp shipment item")
 		return err
 	}
 
+	if aggErr := s.recalcShipmentAggregates(ctx, item.ShipmentID, userID); aggErr != nil {
+		log.Error().Err(aggErr).Str("shipmentId", item.ShipmentID.String()).Msg("Failed to recalc shipment aggregates after item delete")
+	}
+
 	log.Info().Str("itemId", itemID.String()).Msg("Mp shipment item deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityMpShipmentItem, itemID, repository.AuditActionDelete, before, nil)
 	return nil
 }
@@ -15,15 +15,79 @@ type MpShipmentService struct {
 	storeRepo          *repository.StoreRepository
 	warehouseRepo      *repository.WarehouseRepository
 	shipmentStatusRepo *repository.ShipmentStatusRepository
+	reservations       *repository.StockReservationRepository
+	itemRepo           *repository.MpShipmentItemRepository
+	logistics          *ShipmentLogisticsService
+	negativeStock      *StockPolicyService
+	transitions        *StatusTransitionService
+	hooks              map[string][]StatusHook
+	audit              *AuditService
 }
 
-func NewMpShipmentService(repo *repository.MpShipmentRepository, storeRepo *repository.StoreRepository, warehouseRepo *repository.WarehouseRepository, shipmentStatusRepo *repository.ShipmentStatusRepository) *MpShipmentService {
-	return &MpShipmentService{
+func NewMpShipmentService(repo *repository.MpShipmentRepository, storeRepo *repository.StoreRepository, warehouseRepo *repository.WarehouseRepository, shipmentStatusRepo *repository.ShipmentStatusRepository, reservations *repository.StockReservationRepository, itemRepo *repository.MpShipmentItemRepository, logistics *ShipmentLogisticsService, negativeStock *StockPolicyService, transitions *StatusTransitionService, audit *AuditService) *MpShipmentService {
+	s := &MpShipmentService{
 		repo:               repo,
 		storeRepo:          storeRepo,
 		warehouseRepo:      warehouseRepo,
 		shipmentStatusRepo: shipmentStatusRepo,
+		reservations:       reservations,
+		itemRepo:           itemRepo,
+		logistics:          logistics,
+		negativeStock:      negativeStock,
+		transitions:        transitions,
+		hooks:              make(map[string][]StatusHook),
+		audit:              audit,
 	}
+	// Отправленная отгрузка больше не держит резерв: отправленное количество уже списано с остатка
+	s.OnStatus(repository.ShipmentStatusSent, s.releaseReservations)
+	s.OnStatus(repository.ShipmentStatusAccepted, s.releaseReservations)
+	s.OnStatus(repository.ShipmentStatusRejected, s.releaseReservations)
+	return s
+}
+
+// OnStatus registers a hook that runs after a shipment is moved to the given status.
+func (s *MpShipmentService) OnStatus(status string, hook StatusHook) {
+	s.hooks[status] = append(s.hooks[status], hook)
+}
+
+func (s *MpShipmentService) releaseReservations(ctx context.Context, shipmentID, userID uuid.UUID) error {
+	released, err := s.reservations.ReleaseByShipment(ctx, shipmentID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to release stock reservations")
+		return err
+	}
+	if released > 0 {
+		log.Info().Str("shipmentId", shipmentID.String()).Int64("released", released).Msg("Stock reservations released")
+	}
+	return nil
+}
+
+// checkDispatch applies the negative stock policy to the sent quantities of the shipment's items,
+// which leave the warehouse once the shipment is sent.
+func (s *MpShipmentService) checkDispatch(ctx context.Context, shipmentID uuid.UUID) error {
+	items, err := s.itemRepo.GetByShipmentID(ctx, shipmentID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get mp shipment items")
+		return err
+	}
+
+	changes := make([]repository.StockChange, 0, len(items))
+	for _, item := range items {
+		changes = append(changes, repository.StockChange{ProductID: item.ProductID, WarehouseID: item.WarehouseID, Quantity: -item.SentQty})
+	}
+	return s.negativeStock.Check(ctx, repository.StatusEntityMpShipment, shipmentID, stockChanges(changes))
+}
+
+func (s *MpShipmentService) statusName(ctx context.Context, statusID *uuid.UUID) (*string, error) {
+	if statusID == nil {
+		return nil, nil
+	}
+	status, err := s.shipmentStatusRepo.GetByID(ctx, *statusID)
+	if err != nil {
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to get shipment status")
+		return nil, err
+	}
+	return &status.Name, nil
 }
 
 func (s *MpShipmentService) GetByID(ctx context.Context, shipmentID uuid.UUID) (*dto.MpShipmentResponse, error) {
@@ -60,23 +124,25 @@ func (s *MpShipmentService) GetByID(ctx context.Context, shipmentID uuid.UUID) (
 	}
 
 	return &dto.MpShipmentResponse{
-		ShipmentID:     shipment.ShipmentID.String(),
-		ShipmentDate:   shipment.ShipmentDate,
-		ShipmentNumber: shipment.ShipmentNumber,
-		StoreID:        storeIDStr,
-		WarehouseID:    warehouseIDStr,
-		StatusID:       statusIDStr,
-		LogisticsCost:  shipment.LogisticsCost,
-		UnitLogistics:  shipment.UnitLogistics,
-		AcceptanceCost: shipment.AcceptanceCost,
-		AcceptanceDate: shipment.AcceptanceDate,
-		PositionsQty:   shipment.PositionsQty,
-		SentQty:        shipment.SentQty,
-		AcceptedQty:    shipment.AcceptedQty,
-		CreatedBy:      createdByStr,
-		CreatedAt:      shipment.CreatedAt,
-		UpdatedBy:      updatedByStr,
-		UpdatedAt:      shipment.UpdatedAt,
+		ShipmentID:                shipment.ShipmentID.String(),
+		ShipmentDate:              shipment.ShipmentDate,
+		ShipmentNumber:            shipment.ShipmentNumber,
+		StoreID:                   storeIDStr,
+		WarehouseID:               warehouseIDStr,
+		StatusID:                  statusIDStr,
+		LogisticsCost:             shipment.LogisticsCost,
+		UnitLogistics:             shipment.UnitLogistics,
+		AcceptanceCost:            shipment.AcceptanceCost,
+		AcceptanceDate:            shipment.AcceptanceDate,
+		PositionsQty:              shipment.PositionsQty,
+		SentQty:                   shipment.SentQty,
+		AcceptedQty:               shipment.AcceptedQty,
+		LogisticsAllocationMethod: shipment.LogisticsAllocationMethod,
+		ResolutionDate:            shipment.ResolutionDate,
+		CreatedBy:                 createdByStr,
+		CreatedAt:                 shipment.CreatedAt,
+		UpdatedBy:                 updatedByStr,
+		UpdatedAt:                 shipment.UpdatedAt,
 	}, nil
 }
 
@@ -118,23 +184,25 @@ func (s *MpShipmentService) List(ctx context.Context, limit, offset int, storeID
 		}
 
 		result = append(result, dto.MpShipmentResponse{
-			ShipmentID:     shipment.ShipmentID.String(),
-			ShipmentDate:   shipment.ShipmentDate,
-			ShipmentNumber: shipment.ShipmentNumber,
-			StoreID:        storeIDStr,
-			WarehouseID:    warehouseIDStr,
-			StatusID:       statusIDStr,
-			LogisticsCost:  shipment.LogisticsCost,
-			UnitLogistics:  shipment.UnitLogistics,
-			AcceptanceCost: shipment.AcceptanceCost,
-			AcceptanceDate: shipment.AcceptanceDate,
-			PositionsQty:   shipment.PositionsQty,
-			SentQty:        shipment.SentQty,
-			AcceptedQty:    shipment.AcceptedQty,
-			CreatedBy:      createdByStr,
-			CreatedAt:      shipment.CreatedAt,
-			UpdatedBy:      updatedByStr,
-			UpdatedAt:      shipment.UpdatedAt,
+			ShipmentID:                shipment.ShipmentID.String(),
+			ShipmentDate:              shipment.ShipmentDate,
+			ShipmentNumber:            shipment.ShipmentNumber,
+			StoreID:                   storeIDStr,
+			WarehouseID:               warehouseIDStr,
+			StatusID:                  statusIDStr,
+			LogisticsCost:             shipment.LogisticsCost,
+			UnitLogistics:             shipment.UnitLogistics,
+			AcceptanceCost:            shipment.AcceptanceCost,
+			AcceptanceDate:            shipment.AcceptanceDate,
+			PositionsQty:              shipment.PositionsQty,
+			SentQty:                   shipment.SentQty,
+			AcceptedQty:               shipment.AcceptedQty,
+			LogisticsAllocationMethod: shipment.LogisticsAllocationMethod,
+			ResolutionDate:            shipment.ResolutionDate,
+			CreatedBy:                 createdByStr,
+			CreatedAt:                 shipment.CreatedAt,
+			UpdatedBy:                 updatedByStr,
+			UpdatedAt:                 shipment.UpdatedAt,
 		})
 	}
 
@@ -151,7 +219,7 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 		}
 		storeID = &id
 
-		_, err = s.storeRepo.GetByID(ctx, id)
+		store, err := s.storeRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrStoreNotFound {
 				log.Warn().Str("storeId", *req.StoreID).Msg("Store not found")
@@ -160,6 +228,10 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 			log.Error().Err(err).Str("storeId", *req.StoreID).Msg("Failed to validate store")
 			return nil, err
 		}
+		if store.IsArchived {
+			log.Warn().Str("storeId", *req.StoreID).Msg("Store is archived")
+			return nil, repository.ErrStoreArchived
+		}
 	}
 
 	var warehouseID *uuid.UUID
@@ -171,7 +243,7 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 		}
 		warehouseID = &id
 
-		_, err = s.warehouseRepo.GetByID(ctx, id)
+		warehouse, err := s.warehouseRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrWarehouseNotFound {
 				log.Warn().Str("warehouseId", *req.WarehouseID).Msg("Warehouse not found")
@@ -180,9 +252,14 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 			log.Error().Err(err).Str("warehouseId", *req.WarehouseID).Msg("Failed to validate warehouse")
 			return nil, err
 		}
+		if warehouse.IsArchived {
+			log.Warn().Str("warehouseId", *req.WarehouseID).Msg("Warehouse is archived")
+			return nil, repository.ErrWarehouseArchived
+		}
 	}
 
 	var statusID *uuid.UUID
+	var status *repository.ShipmentStatus
 	if req.StatusID != nil && *req.StatusID != "" {
 		id, err := uuid.Parse(*req.StatusID)
 		if err != nil {
@@ -191,7 +268,7 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 		}
 		statusID = &id
 
-		_, err = s.shipmentStatusRepo.GetByID(ctx, id)
+		status, err = s.shipmentStatusRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrShipmentStatusNotFound {
 				log.Warn().Str("statusId", *req.StatusID).Msg("Shipment status not found")
@@ -200,11 +277,22 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 			log.Error().Err(err).Str("statusId", *req.StatusID).Msg("Failed to validate shipment status")
 			return nil, err
 		}
+		if status.IsArchived {
+			log.Warn().Str("statusId", *req.StatusID).Msg("Shipment status is archived")
+			return nil, repository.ErrShipmentStatusArchived
+		}
+		if err := s.transitions.Check(ctx, repository.StatusEntityMpShipment, nil, status.Name); err != nil {
+			return nil, err
+		}
 	}
 
-	if req.AcceptedQty > req.SentQty {
-		log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Accepted quantity cannot exceed sent quantity")
-		return nil, repository.ErrInvalidQuantity
+	method := req.LogisticsAllocationMethod
+	if method == "" {
+		method = repository.AllocationByQuantity
+	}
+	if !repository.IsValidShipmentAllocationMethod(method) {
+		log.Warn().Str("method", method).Msg("Invalid shipment logistics allocation method")
+		return nil, repository.ErrInvalidAllocationMethod
 	}
 
 	shipment, err := s.repo.Create(ctx,
@@ -214,12 +302,9 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 		warehouseID,
 		statusID,
 		req.LogisticsCost,
-		req.UnitLogistics,
 		req.AcceptanceCost,
 		req.AcceptanceDate,
-		req.PositionsQty,
-		req.SentQty,
-		req.AcceptedQty,
+		method,
 		&userID,
 	)
 	if err != nil {
@@ -227,6 +312,10 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 		return nil, err
 	}
 
+	if status != nil {
+		s.transitions.Record(ctx, repository.StatusEntityMpShipment, shipment.ShipmentID, nil, status.Name, nil, userID)
+	}
+
 	var storeIDStr *string
 	if shipment.StoreID != nil {
 		str := shipment.StoreID.String()
@@ -254,28 +343,37 @@ func (s *MpShipmentService) Create(ctx context.Context, userID uuid.UUID, req dt
 	}
 
 	log.Info().Str("shipmentId", shipment.ShipmentID.String()).Str("shipmentNumber", shipment.ShipmentNumber).Str("userId", userID.String()).Msg("Mp shipment created successfully")
-	return &dto.MpShipmentResponse{
-		ShipmentID:     shipment.ShipmentID.String(),
-		ShipmentDate:   shipment.ShipmentDate,
-		ShipmentNumber: shipment.ShipmentNumber,
-		StoreID:        storeIDStr,
-		WarehouseID:    warehouseIDStr,
-		StatusID:       statusIDStr,
-		LogisticsCost:  shipment.LogisticsCost,
-		UnitLogistics:  shipment.UnitLogistics,
-		AcceptanceCost: shipment.AcceptanceCost,
-		AcceptanceDate: shipment.AcceptanceDate,
-		PositionsQty:   shipment.PositionsQty,
-		SentQty:        shipment.SentQty,
-		AcceptedQty:    shipment.AcceptedQty,
-		CreatedBy:      createdByStr,
-		CreatedAt:      shipment.CreatedAt,
-		UpdatedBy:      updatedByStr,
-		UpdatedAt:      shipment.UpdatedAt,
-	}, nil
+	result := &dto.MpShipmentResponse{
+		ShipmentID:                shipment.ShipmentID.String(),
+		ShipmentDate:              shipment.ShipmentDate,
+		ShipmentNumber:            shipment.ShipmentNumber,
+		StoreID:                   storeIDStr,
+		WarehouseID:               warehouseIDStr,
+		StatusID:                  statusIDStr,
+		LogisticsCost:             shipment.LogisticsCost,
+		UnitLogistics:             shipment.UnitLogistics,
+		AcceptanceCost:            shipment.AcceptanceCost,
+		AcceptanceDate:            shipment.AcceptanceDate,
+		PositionsQty:              shipment.PositionsQty,
+		SentQty:                   shipment.SentQty,
+		AcceptedQty:               shipment.AcceptedQty,
+		LogisticsAllocationMethod: shipment.LogisticsAllocationMethod,
+		ResolutionDate:            shipment.ResolutionDate,
+		CreatedBy:                 createdByStr,
+		CreatedAt:                 shipment.CreatedAt,
+		UpdatedBy:                 updatedByStr,
+		UpdatedAt:                 shipment.UpdatedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpShipment, shipment.ShipmentID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.UUID, req dto.MpShipmentUpdateRequest) (*dto.MpShipmentResponse, error) {
+	before, err := s.GetByID(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+
 	var storeID *uuid.UUID
 	if req.StoreID != nil && *req.StoreID != "" {
 		id, err := uuid.Parse(*req.StoreID)
@@ -285,7 +383,7 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 		}
 		storeID = &id
 
-		_, err = s.storeRepo.GetByID(ctx, id)
+		store, err := s.storeRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrStoreNotFound {
 				log.Warn().Str("storeId", *req.StoreID).Msg("Store not found")
@@ -294,6 +392,10 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 			log.Error().Err(err).Str("storeId", *req.StoreID).Msg("Failed to validate store")
 			return nil, err
 		}
+		if store.IsArchived && (before.StoreID == nil || *before.StoreID != id.String()) {
+			log.Warn().Str("storeId", *req.StoreID).Msg("Store is archived")
+			return nil, repository.ErrStoreArchived
+		}
 	}
 
 	var warehouseID *uuid.UUID
@@ -305,7 +407,7 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 		}
 		warehouseID = &id
 
-		_, err = s.warehouseRepo.GetByID(ctx, id)
+		warehouse, err := s.warehouseRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrWarehouseNotFound {
 				log.Warn().Str("warehouseId", *req.WarehouseID).Msg("Warehouse not found")
@@ -314,18 +416,53 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 			log.Error().Err(err).Str("warehouseId", *req.WarehouseID).Msg("Failed to validate warehouse")
 			return nil, err
 		}
+		if warehouse.IsArchived && (before.WarehouseID == nil || *before.WarehouseID != id.String()) {
+			log.Warn().Str("warehouseId", *req.WarehouseID).Msg("Warehouse is archived")
+			return nil, repository.ErrWarehouseArchived
+		}
 	}
 
-	var statusID *uuid.UUID
+	existing, err := s.repo.GetByID(ctx, shipmentID)
+	if err != nil {
+		if err != repository.ErrMpShipmentNotFound {
+			log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to load mp shipment before update")
+		}
+		return nil, err
+	}
+
+	fromStatus, err := s.statusName(ctx, existing.StatusID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Без shipmentDate дата сохраняется. У отправленной отгрузки дата и склад задают списание в журнале
+	// движений, поэтому менять их нельзя; без warehouseId склад такой отгрузки сохраняется
+	if req.ShipmentDate == nil {
+		req.ShipmentDate = existing.ShipmentDate
+	}
+	if fromStatus != nil && repository.IsShipmentDispatched(*fromStatus) {
+		if warehouseID == nil {
+			warehouseID = existing.WarehouseID
+		}
+		warehouseChanged := (warehouseID == nil) != (existing.WarehouseID == nil) ||
+			(warehouseID != nil && *warehouseID != *existing.WarehouseID)
+		if !sameDate(req.ShipmentDate, existing.ShipmentDate) || warehouseChanged {
+			log.Warn().Str("shipmentId", shipmentID.String()).Str("status", *fromStatus).Msg("Date and warehouse of a dispatched mp shipment cannot be changed")
+			return nil, repository.ErrShipmentDispatched
+		}
+	}
+
+	// Без statusId статус сохраняется; смена статуса проверяется по разрешенным переходам
+	statusID := existing.StatusID
+	var newStatus *repository.ShipmentStatus
 	if req.StatusID != nil && *req.StatusID != "" {
 		id, err := uuid.Parse(*req.StatusID)
 		if err != nil {
 			log.Warn().Str("statusId", *req.StatusID).Msg("Invalid status ID format")
 			return nil, repository.ErrShipmentStatusNotFound
 		}
-		statusID = &id
 
-		_, err = s.shipmentStatusRepo.GetByID(ctx, id)
+		status, err := s.shipmentStatusRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrShipmentStatusNotFound {
 				log.Warn().Str("statusId", *req.StatusID).Msg("Shipment status not found")
@@ -334,11 +471,37 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 			log.Error().Err(err).Str("statusId", *req.StatusID).Msg("Failed to validate shipment status")
 			return nil, err
 		}
+
+		if existing.StatusID == nil || *existing.StatusID != id {
+			if status.IsArchived {
+				log.Warn().Str("statusId", *req.StatusID).Msg("Shipment status is archived")
+				return nil, repository.ErrShipmentStatusArchived
+			}
+			if err := s.transitions.Check(ctx, repository.StatusEntityMpShipment, fromStatus, status.Name); err != nil {
+				return nil, err
+			}
+			newStatus = status
+		}
+		statusID = &id
 	}
 
-	if req.AcceptedQty > req.SentQty {
-		log.Warn().Int("sentQty", req.SentQty).Int("acceptedQty", req.AcceptedQty).Msg("Accepted quantity cannot exceed sent quantity")
-		return nil, repository.ErrInvalidQuantity
+	method := req.LogisticsAllocationMethod
+	if method == "" {
+		method = existing.LogisticsAllocationMethod
+	}
+	if !repository.IsValidShipmentAllocationMethod(method) {
+		log.Warn().Str("method", method).Msg("Invalid shipment logistics allocation method")
+		return nil, repository.ErrInvalidAllocationMethod
+	}
+
+	if newStatus != nil && newStatus.Name == repository.ShipmentStatusSent {
+		if err := s.checkDispatch(ctx, shipmentID); err != nil {
+			return nil, err
+		}
+		if req.ShipmentDate == nil {
+			date := today()
+			req.ShipmentDate = &date
+		}
 	}
 
 	shipment, err := s.repo.Update(ctx, shipmentID,
@@ -348,12 +511,9 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 		warehouseID,
 		statusID,
 		req.LogisticsCost,
-		req.UnitLogistics,
 		req.AcceptanceCost,
 		req.AcceptanceDate,
-		req.PositionsQty,
-		req.SentQty,
-		req.AcceptedQty,
+		method,
 		&userID,
 	)
 	if err != nil {
@@ -361,6 +521,13 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 		return nil, err
 	}
 
+	// Стоимость логистики или способ ее распределения могли измениться - перераспределяем по позициям
+	if err := s.logistics.Recalculate(ctx, shipmentID, userID); err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to recalc shipment aggregates after update")
+	} else if reloaded, err := s.repo.GetByID(ctx, shipmentID); err == nil {
+		shipment = reloaded
+	}
+
 	var storeIDStr *string
 	if shipment.StoreID != nil {
 		str := shipment.StoreID.String()
@@ -387,35 +554,246 @@ func (s *MpShipmentService) Update(ctx context.Context, shipmentID, userID uuid.
 		updatedByStr = &str
 	}
 
+	if newStatus != nil {
+		s.transitions.Record(ctx, repository.StatusEntityMpShipment, shipmentID, fromStatus, newStatus.Name, nil, userID)
+		runStatusHooks(ctx, s.hooks, repository.StatusEntityMpShipment, newStatus.Name, shipmentID, userID)
+	}
+
 	log.Info().Str("shipmentId", shipmentID.String()).Str("userId", userID.String()).Msg("Mp shipment updated successfully")
-	return &dto.MpShipmentResponse{
-		ShipmentID:     shipment.ShipmentID.String(),
-		ShipmentDate:   shipment.ShipmentDate,
-		ShipmentNumber: shipment.ShipmentNumber,
-		StoreID:        storeIDStr,
-		WarehouseID:    warehouseIDStr,
-		StatusID:       statusIDStr,
-		LogisticsCost:  shipment.LogisticsCost,
-		UnitLogistics:  shipment.UnitLogistics,
-		AcceptanceCost: shipment.AcceptanceCost,
-		AcceptanceDate: shipment.AcceptanceDate,
-		PositionsQty:   shipment.PositionsQty,
-		SentQty:        shipment.SentQty,
-		AcceptedQty:    shipment.AcceptedQty,
-		CreatedBy:      createdByStr,
-		CreatedAt:      shipment.CreatedAt,
-		UpdatedBy:      updatedByStr,
-		UpdatedAt:      shipment.UpdatedAt,
-	}, nil
+	result := &dto.MpShipmentResponse{
+		ShipmentID:                shipment.ShipmentID.String(),
+		ShipmentDate:              shipment.ShipmentDate,
+		ShipmentNumber:            shipment.ShipmentNumber,
+		StoreID:                   storeIDStr,
+		WarehouseID:               warehouseIDStr,
+		StatusID:                  statusIDStr,
+		LogisticsCost:             shipment.LogisticsCost,
+		UnitLogistics:             shipment.UnitLogistics,
+		AcceptanceCost:            shipment.AcceptanceCost,
+		AcceptanceDate:            shipment.AcceptanceDate,
+		PositionsQty:              shipment.PositionsQty,
+		SentQty:                   shipment.SentQty,
+		AcceptedQty:               shipment.AcceptedQty,
+		LogisticsAllocationMethod: shipment.LogisticsAllocationMethod,
+		ResolutionDate:            shipment.ResolutionDate,
+		CreatedBy:                 createdByStr,
+		CreatedAt:                 shipment.CreatedAt,
+		UpdatedBy:                 updatedByStr,
+		UpdatedAt:                 shipment.UpdatedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpShipment, shipmentID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+// Transition moves the shipment to another status if the move is allowed by status_transitions and runs
+// the hooks registered for the new status.
+func (s *MpShipmentService) Transition(ctx context.Context, shipmentID, userID uuid.UUID, req dto.StatusTransitionRequest) (*dto.MpShipmentResponse, error) {
+	toStatusID, err := uuid.Parse(req.StatusID)
+	if err != nil {
+		log.Warn().Str("statusId", req.StatusID).Msg("Invalid status ID format")
+		return nil, repository.ErrShipmentStatusNotFound
+	}
+	toStatus, err := s.shipmentStatusRepo.GetByID(ctx, toStatusID)
+	if err != nil {
+		if err != repository.ErrShipmentStatusNotFound {
+			log.Error().Err(err).Str("statusId", req.StatusID).Msg("Failed to validate shipment status")
+		}
+		return nil, err
+	}
+
+	shipment, err := s.repo.GetByID(ctx, shipmentID)
+	if err != nil {
+		if err != repository.ErrMpShipmentNotFound {
+			log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get mp shipment for status transition")
+		}
+		return nil, err
+	}
+	if shipment.StatusID != nil && *shipment.StatusID == toStatusID {
+		return s.GetByID(ctx, shipmentID)
+	}
+	if toStatus.IsArchived {
+		log.Warn().Str("statusId", req.StatusID).Msg("Shipment status is archived")
+		return nil, repository.ErrShipmentStatusArchived
+	}
+
+	fromStatus, err := s.statusName(ctx, shipment.StatusID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityMpShipment, fromStatus, toStatus.Name); err != nil {
+		return nil, err
+	}
+
+	before, err := s.GetByID(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if toStatus.Name == repository.ShipmentStatusSent {
+		if err := s.checkDispatch(ctx, shipmentID); err != nil {
+			return nil, err
+		}
+		// Остаток списывается на дату отгрузки - без нее отправленная отгрузка не попала бы в движения
+		if shipment.ShipmentDate == nil {
+			if err := s.repo.SetShipmentDate(ctx, shipmentID, today(), userID); err != nil {
+				log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to set mp shipment date")
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.repo.UpdateStatus(ctx, shipmentID, shipment.StatusID, toStatusID, userID); err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Str("status", toStatus.Name).Msg("Failed to change mp shipment status")
+		return nil, err
+	}
+
+	s.transitions.Record(ctx, repository.StatusEntityMpShipment, shipmentID, fromStatus, toStatus.Name, req.Comment, userID)
+	runStatusHooks(ctx, s.hooks, repository.StatusEntityMpShipment, toStatus.Name, shipmentID, userID)
+
+	log.Info().Str("shipmentId", shipmentID.String()).Str("status", toStatus.Name).Str("userId", userID.String()).Msg("Mp shipment status changed")
+	result, err := s.GetByID(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpShipment, shipmentID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+// ResolveDiscrepancy splits the difference between sent and accepted quantity of every item of an accepted or
+// rejected shipment into goods returned to the item's warehouse on the resolution date and goods lost in
+// transit. A repeated resolution replaces the previous one.
+func (s *MpShipmentService) ResolveDiscrepancy(ctx context.Context, shipmentID, userID uuid.UUID, req dto.MpShipmentResolutionRequest) (*dto.MpShipmentResponse, error) {
+	shipment, err := s.repo.GetByID(ctx, shipmentID)
+	if err != nil {
+		if err != repository.ErrMpShipmentNotFound {
+			log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get mp shipment for discrepancy resolution")
+		}
+		return nil, err
+	}
+
+	status, err := s.statusName(ctx, shipment.StatusID)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil || (*status != repository.ShipmentStatusAccepted && *status != repository.ShipmentStatusRejected) {
+		log.Warn().Str("shipmentId", shipmentID.String()).Msg("Mp shipment is neither accepted nor rejected")
+		return nil, repository.ErrShipmentNotClosed
+	}
+
+	resolutionDate := today()
+	if req.ResolutionDate != nil {
+		resolutionDate = *req.ResolutionDate
+	}
+	if shipment.ShipmentDate != nil && resolutionDate.Before(*shipment.ShipmentDate) {
+		log.Warn().Str("shipmentId", shipmentID.String()).Msg("Resolution date is before shipment date")
+		return nil, repository.ErrInvalidDateRange
+	}
+
+	items, err := s.itemRepo.GetByShipmentID(ctx, shipmentID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get mp shipment items")
+		return nil, err
+	}
+	requested := make(map[uuid.UUID]repository.ShipmentItemResolution, len(req.Items))
+	for _, item := range req.Items {
+		itemID, err := uuid.Parse(item.ShipmentItemID)
+		if err != nil {
+			log.Warn().Str("shipmentItemId", item.ShipmentItemID).Msg("Invalid shipment item ID format")
+			return nil, repository.ErrMpShipmentItemNotFound
+		}
+		if _, ok := requested[itemID]; ok || item.ReturnedQty < 0 || item.LostQty < 0 {
+			log.Warn().Str("shipmentItemId", item.ShipmentItemID).Msg("Invalid shipment item resolution")
+			return nil, repository.ErrInvalidResolution
+		}
+		requested[itemID] = repository.ShipmentItemResolution{ShipmentItemID: itemID, ReturnedQty: item.ReturnedQty, LostQty: item.LostQty}
+	}
+
+	// Каждое расхождение должно быть разобрано полностью; возвраты маркетплейса по отгрузке уменьшают то,
+	// что еще может вернуться на склад
+	returnedByDocuments, err := s.repo.GetReturnedByDocuments(ctx, shipmentID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get returned quantities of mp shipment")
+		return nil, err
+	}
+	resolutions := make([]repository.ShipmentItemResolution, 0, len(requested))
+	sentByProduct := make(map[uuid.UUID]int)
+	resolvedByProduct := make(map[uuid.UUID]int)
+	var changes []repository.StockChange
+	for _, item := range items {
+		resolution, ok := requested[item.ShipmentItemID]
+		delete(requested, item.ShipmentItemID)
+		if resolution.ReturnedQty+resolution.LostQty != item.SentQty-item.AcceptedQty {
+			log.Warn().Str("shipmentItemId", item.ShipmentItemID.String()).Int("sentQty", item.SentQty).Int("acceptedQty", item.AcceptedQty).
+				Int("returnedQty", resolution.ReturnedQty).Int("lostQty", resolution.LostQty).Msg("Resolution does not cover shipment item discrepancy")
+			return nil, repository.ErrInvalidResolution
+		}
+		if ok {
+			resolutions = append(resolutions, resolution)
+		}
+		sentByProduct[item.ProductID] += item.SentQty
+		resolvedByProduct[item.ProductID] += resolution.ReturnedQty + resolution.LostQty
+		if delta := resolution.ReturnedQty - item.ReturnedQty; delta < 0 {
+			changes = append(changes, repository.StockChange{ProductID: item.ProductID, WarehouseID: item.WarehouseID, Quantity: delta})
+		}
+	}
+	if len(requested) > 0 {
+		log.Warn().Str("shipmentId", shipmentID.String()).Msg("Resolution refers to items of another shipment")
+		return nil, repository.ErrMpShipmentItemNotFound
+	}
+	for productID, resolved := range resolvedByProduct {
+		if resolved+returnedByDocuments[productID] > sentByProduct[productID] {
+			log.Warn().Str("shipmentId", shipmentID.String()).Str("productId", productID.String()).Msg("Resolution together with marketplace returns exceeds sent quantity")
+			return nil, repository.ErrReturnExceedsShipped
+		}
+	}
+	if err := s.negativeStock.Check(ctx, repository.StatusEntityMpShipment, shipmentID, stockChanges(changes)); err != nil {
+		return nil, err
+	}
+
+	before, err := s.GetByID(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ResolveDiscrepancy(ctx, shipmentID, resolutionDate, resolutions, userID); err != nil {
+		if err != repository.ErrInvalidResolution && err != repository.ErrMpShipmentItemNotFound {
+			log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to resolve mp shipment discrepancy")
+		}
+		return nil, err
+	}
+
+	log.Info().Str("shipmentId", shipmentID.String()).Str("userId", userID.String()).Msg("Mp shipment discrepancy resolved")
+	result, err := s.GetByID(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityMpShipment, shipmentID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+func (s *MpShipmentService) History(ctx context.Context, shipmentID uuid.UUID) ([]dto.StatusHistoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, shipmentID); err != nil {
+		if err != repository.ErrMpShipmentNotFound {
+			log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get mp shipment for status history")
+		}
+		return nil, err
+	}
+	return s.transitions.History(ctx, repository.StatusEntityMpShipment, shipmentID)
 }
 
 func (s *MpShipmentService) Delete(ctx context.Context, shipmentID uuid.UUID) error {
-	err := s.repo.Delete(ctx, shipmentID)
+	before, err := s.GetByID(ctx, shipmentID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, shipmentID)
 	if err != nil {
 		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to delete mp shipment")
 		return err
 	}
 
 	log.Info().Str("shipmentId", shipmentID.String()).Msg("Mp shipment deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityMpShipment, shipmentID, repository.AuditActionDelete, before, nil)
 	return nil
 }
@@ -11,11 +11,12 @@ import (
 )
 
 type OrderStatusService struct {
-	repo *repository.OrderStatusRepository
+	repo  *repository.OrderStatusRepository
+	audit *AuditService
 }
 
-func NewOrderStatusService(repo *repository.OrderStatusRepository) *OrderStatusService {
-	return &OrderStatusService{repo: repo}
+func NewOrderStatusService(repo *repository.OrderStatusRepository, audit *AuditService) *OrderStatusService {
+	return &OrderStatusService{repo: repo, audit: audit}
 }
 
 func (s *OrderStatusService) GetByID(ctx context.Context, statusID uuid.UUID) (*dto.OrderStatusResponse, error) {
@@ -28,11 +29,13 @@ func (s *OrderStatusService) GetByID(ctx context.Context, statusID uuid.UUID) (*
 	return &dto.OrderStatusResponse{
 		OrderStatusID: status.OrderStatusID.String(),
 		Name:          status.Name,
+		IsArchived:    status.IsArchived,
+		ArchivedAt:    status.ArchivedAt,
 	}, nil
 }
 
-func (s *OrderStatusService) List(ctx context.Context, limit, offset int) ([]dto.OrderStatusResponse, error) {
-	statuses, err := s.repo.List(ctx, limit, offset)
+func (s *OrderStatusService) List(ctx context.Context, limit, offset int, includeArchived bool) ([]dto.OrderStatusResponse, error) {
+	statuses, err := s.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to list order statuses")
 		return nil, err
@@ -43,6 +46,8 @@ func (s *OrderStatusService) List(ctx context.Context, limit, offset int) ([]dto
 		result = append(result, dto.OrderStatusResponse{
 			OrderStatusID: status.OrderStatusID.String(),
 			Name:          status.Name,
+			IsArchived:    status.IsArchived,
+			ArchivedAt:    status.ArchivedAt,
 		})
 	}
 
@@ -57,13 +62,22 @@ func (s *OrderStatusService) Create(ctx context.Context, req dto.OrderStatusCrea
 	}
 
 	log.Info().Str("statusId", status.OrderStatusID.String()).Str("name", status.Name).Msg("Order status created successfully")
-	return &dto.OrderStatusResponse{
+	result := &dto.OrderStatusResponse{
 		OrderStatusID: status.OrderStatusID.String(),
 		Name:          status.Name,
-	}, nil
+		IsArchived:    status.IsArchived,
+		ArchivedAt:    status.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityOrderStatus, status.OrderStatusID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *OrderStatusService) Update(ctx context.Context, statusID uuid.UUID, req dto.OrderStatusUpdateRequest) (*dto.OrderStatusResponse, error) {
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+
 	status, err := s.repo.Update(ctx, statusID, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to update order status")
@@ -71,19 +85,53 @@ func (s *OrderStatusService) Update(ctx context.Context, statusID uuid.UUID, req
 	}
 
 	log.Info().Str("statusId", statusID.String()).Msg("Order status updated successfully")
-	return &dto.OrderStatusResponse{
+	result := &dto.OrderStatusResponse{
 		OrderStatusID: status.OrderStatusID.String(),
 		Name:          status.Name,
-	}, nil
+		IsArchived:    status.IsArchived,
+		ArchivedAt:    status.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityOrderStatus, statusID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
+// Delete archives the order status: it disappears from lists and cannot be used in new documents, but
+// documents and stock history that reference it stay intact. Use Restore to bring it back.
 func (s *OrderStatusService) Delete(ctx context.Context, statusID uuid.UUID) error {
-	err := s.repo.Delete(ctx, statusID)
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Archive(ctx, statusID)
 	if err != nil {
-		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to delete order status")
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to archive order status")
 		return err
 	}
 
-	log.Info().Str("statusId", statusID.String()).Msg("Order status deleted successfully")
+	log.Info().Str("statusId", statusID.String()).Msg("Order status archived successfully")
+	if after, err := s.GetByID(ctx, statusID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityOrderStatus, statusID, repository.AuditActionDelete, before, after)
+	}
 	return nil
 }
+
+func (s *OrderStatusService) Restore(ctx context.Context, statusID uuid.UUID) (*dto.OrderStatusResponse, error) {
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, statusID); err != nil {
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to restore order status")
+		return nil, err
+	}
+
+	log.Info().Str("statusId", statusID.String()).Msg("Order status restored successfully")
+	result, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityOrderStatus, statusID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+type PermissionService struct {
+	repo *repository.PermissionRepository
+}
+
+func NewPermissionService(repo *repository.PermissionRepository) *PermissionService {
+	return &PermissionService{repo: repo}
+}
+
+func (s *PermissionService) List(ctx context.Context) ([]dto.PermissionResponse, error) {
+	permissions, err := s.repo.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list permissions")
+		return nil, err
+	}
+
+	result := make([]dto.PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		result = append(result, dto.PermissionResponse{
+			PermissionID: permission.PermissionID.String(),
+			Resource:     permission.Resource,
+			Action:       permission.Action,
+			Code:         auth.PermissionCode(permission.Resource, permission.Action),
+		})
+	}
+
+	return result, nil
+}
+
+// HasPermission implements middleware.PermissionChecker.
+func (s *PermissionService) HasPermission(ctx context.Context, roleID uuid.UUID, resource, action string) (bool, error) {
+	allowed, err := s.repo.HasPermission(ctx, roleID, resource, action)
+	if err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Str("resource", resource).Str("action", action).Msg("Failed to check permission")
+		return false, err
+	}
+	return allowed, nil
+}
+
+func permissionCodes(permissions []repository.Permission) []string {
+	codes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		codes = append(codes, auth.PermissionCode(permission.Resource, permission.Action))
+	}
+	return codes
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"warehouse-backend/internal/dto"
@@ -13,12 +14,14 @@ import (
 type ProductCostService struct {
 	repo        *repository.ProductCostRepository
 	productRepo *repository.ProductRepository
+	audit       *AuditService
 }
 
-func NewProductCostService(repo *repository.ProductCostRepository, productRepo *repository.ProductRepository) *ProductCostService {
+func NewProductCostService(repo *repository.ProductCostRepository, productRepo *repository.ProductRepository, audit *AuditService) *ProductCostService {
 	return &ProductCostService{
 		repo:        repo,
 		productRepo: productRepo,
+		audit:       audit,
 	}
 }
 
@@ -29,6 +32,11 @@ func (s *ProductCostService) GetByID(ctx context.Context, costID uuid.UUID) (*dt
 		return nil, err
 	}
 
+	var sourceOrderIDStr *string
+	if cost.SourceOrderID != nil {
+		str := cost.SourceOrderID.String()
+		sourceOrderIDStr = &str
+	}
 	var createdByStr *string
 	if cost.CreatedBy != nil {
 		str := cost.CreatedBy.String()
@@ -47,6 +55,7 @@ func (s *ProductCostService) GetByID(ctx context.Context, costID uuid.UUID) (*dt
 		PeriodEnd:           cost.PeriodEnd,
 		UnitCostToWarehouse: cost.UnitCostToWarehouse,
 		Notes:               cost.Notes,
+		SourceOrderID:       sourceOrderIDStr,
 		CreatedBy:           createdByStr,
 		CreatedAt:           cost.CreatedAt,
 		UpdatedBy:           updatedByStr,
@@ -64,6 +73,11 @@ func (s *ProductCostService) List(ctx context.Context, limit, offset int, produc
 
 	result := make([]dto.ProductCostResponse, 0, len(costs))
 	for _, cost := range costs {
+		var sourceOrderIDStr *string
+		if cost.SourceOrderID != nil {
+			str := cost.SourceOrderID.String()
+			sourceOrderIDStr = &str
+		}
 		var createdByStr *string
 		if cost.CreatedBy != nil {
 			str := cost.CreatedBy.String()
@@ -82,6 +96,7 @@ func (s *ProductCostService) List(ctx context.Context, limit, offset int, produc
 			PeriodEnd:           cost.PeriodEnd,
 			UnitCostToWarehouse: cost.UnitCostToWarehouse,
 			Notes:               cost.Notes,
+			SourceOrderID:       sourceOrderIDStr,
 			CreatedBy:           createdByStr,
 			CreatedAt:           cost.CreatedAt,
 			UpdatedBy:           updatedByStr,
@@ -118,12 +133,23 @@ func (s *ProductCostService) Create(ctx context.Context, userID uuid.UUID, req d
 		return nil, repository.ErrInvalidQuantity
 	}
 
-	cost, err := s.repo.Create(ctx, productID, req.PeriodStart, req.PeriodEnd, req.UnitCostToWarehouse, req.Notes, &userID)
+	trimmed, err := s.resolveOverlaps(ctx, productID, req.PeriodStart, req.PeriodEnd, nil, req.AutoTrim)
+	if err != nil {
+		return nil, err
+	}
+
+	cost, err := s.repo.Create(ctx, productID, req.PeriodStart, req.PeriodEnd, req.UnitCostToWarehouse, req.Notes, &userID, costIDs(trimmed))
 	if err != nil {
 		log.Error().Err(err).Str("productId", req.ProductID).Str("userId", userID.String()).Msg("Failed to create product cost")
 		return nil, err
 	}
+	s.recordTrimmed(ctx, trimmed)
 
+	var sourceOrderIDStr *string
+	if cost.SourceOrderID != nil {
+		str := cost.SourceOrderID.String()
+		sourceOrderIDStr = &str
+	}
 	var createdByStr *string
 	if cost.CreatedBy != nil {
 		str := cost.CreatedBy.String()
@@ -136,21 +162,29 @@ func (s *ProductCostService) Create(ctx context.Context, userID uuid.UUID, req d
 	}
 
 	log.Info().Str("costId", cost.CostID.String()).Str("productId", req.ProductID).Str("userId", userID.String()).Msg("Product cost created successfully")
-	return &dto.ProductCostResponse{
+	result := &dto.ProductCostResponse{
 		CostID:              cost.CostID.String(),
 		ProductID:           cost.ProductID.String(),
 		PeriodStart:         cost.PeriodStart,
 		PeriodEnd:           cost.PeriodEnd,
 		UnitCostToWarehouse: cost.UnitCostToWarehouse,
 		Notes:               cost.Notes,
+		SourceOrderID:       sourceOrderIDStr,
 		CreatedBy:           createdByStr,
 		CreatedAt:           cost.CreatedAt,
 		UpdatedBy:           updatedByStr,
 		UpdatedAt:           cost.UpdatedAt,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityProductCost, cost.CostID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *ProductCostService) Update(ctx context.Context, costID, userID uuid.UUID, req dto.ProductCostUpdateRequest) (*dto.ProductCostResponse, error) {
+	before, err := s.GetByID(ctx, costID)
+	if err != nil {
+		return nil, err
+	}
+
 	productID, err := uuid.Parse(req.ProductID)
 	if err != nil {
 		log.Warn().Str("productId", req.ProductID).Msg("Invalid product ID format")
@@ -176,12 +210,23 @@ func (s *ProductCostService) Update(ctx context.Context, costID, userID uuid.UUI
 		return nil, repository.ErrInvalidQuantity
 	}
 
-	cost, err := s.repo.Update(ctx, costID, productID, req.PeriodStart, req.PeriodEnd, req.UnitCostToWarehouse, req.Notes, &userID)
+	trimmed, err := s.resolveOverlaps(ctx, productID, req.PeriodStart, req.PeriodEnd, &costID, req.AutoTrim)
+	if err != nil {
+		return nil, err
+	}
+
+	cost, err := s.repo.Update(ctx, costID, productID, req.PeriodStart, req.PeriodEnd, req.UnitCostToWarehouse, req.Notes, &userID, costIDs(trimmed))
 	if err != nil {
 		log.Error().Err(err).Str("costId", costID.String()).Str("userId", userID.String()).Msg("Failed to update product cost")
 		return nil, err
 	}
+	s.recordTrimmed(ctx, trimmed)
 
+	var sourceOrderIDStr *string
+	if cost.SourceOrderID != nil {
+		str := cost.SourceOrderID.String()
+		sourceOrderIDStr = &str
+	}
 	var createdByStr *string
 	if cost.CreatedBy != nil {
 		str := cost.CreatedBy.String()
@@ -194,27 +239,171 @@ func (s *ProductCostService) Update(ctx context.Context, costID, userID uuid.UUI
 	}
 
 	log.Info().Str("costId", costID.String()).Str("userId", userID.String()).Msg("Product cost updated successfully")
-	return &dto.ProductCostResponse{
+	result := &dto.ProductCostResponse{
 		CostID:              cost.CostID.String(),
 		ProductID:           cost.ProductID.String(),
 		PeriodStart:         cost.PeriodStart,
 		PeriodEnd:           cost.PeriodEnd,
 		UnitCostToWarehouse: cost.UnitCostToWarehouse,
 		Notes:               cost.Notes,
+		SourceOrderID:       sourceOrderIDStr,
 		CreatedBy:           createdByStr,
 		CreatedAt:           cost.CreatedAt,
 		UpdatedBy:           updatedByStr,
 		UpdatedAt:           cost.UpdatedAt,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityProductCost, costID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
 func (s *ProductCostService) Delete(ctx context.Context, costID uuid.UUID) error {
-	err := s.repo.Delete(ctx, costID)
+	before, err := s.GetByID(ctx, costID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, costID)
 	if err != nil {
 		log.Error().Err(err).Str("costId", costID.String()).Msg("Failed to delete product cost")
 		return err
 	}
 
 	log.Info().Str("costId", costID.String()).Msg("Product cost deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityProductCost, costID, repository.AuditActionDelete, before, nil)
 	return nil
 }
+
+// resolveOverlaps rejects a period intersecting other periods of the product. With autoTrim, earlier
+// periods that overlap only the beginning of the new one are returned so that the repository shortens them
+// to end the day before periodStart together with the write; any other overlap is still rejected.
+func (s *ProductCostService) resolveOverlaps(ctx context.Context, productID uuid.UUID, periodStart, periodEnd time.Time, excludeID *uuid.UUID, autoTrim bool) ([]*dto.ProductCostResponse, error) {
+	overlapping, err := s.repo.FindOverlapping(ctx, productID, periodStart, periodEnd, excludeID)
+	if err != nil {
+		log.Error().Err(err).Str("productId", productID.String()).Msg("Failed to check product cost overlaps")
+		return nil, err
+	}
+	if len(overlapping) == 0 {
+		return nil, nil
+	}
+
+	if autoTrim {
+		trimmed := make([]*dto.ProductCostResponse, 0, len(overlapping))
+		for _, cost := range overlapping {
+			if !cost.PeriodStart.Before(periodStart) || cost.PeriodEnd.After(periodEnd) {
+				log.Warn().Str("productId", productID.String()).Str("costId", cost.CostID.String()).Msg("Product cost period cannot be trimmed")
+				return nil, repository.ErrProductCostOverlap
+			}
+			before, err := s.GetByID(ctx, cost.CostID)
+			if err != nil {
+				return nil, err
+			}
+			trimmed = append(trimmed, before)
+		}
+		return trimmed, nil
+	}
+
+	log.Warn().
+		Str("productId", productID.String()).
+		Str("conflictingCostId", overlapping[0].CostID.String()).
+		Time("periodStart", periodStart).
+		Time("periodEnd", periodEnd).
+		Msg("Product cost period overlaps existing period")
+	return nil, repository.ErrProductCostOverlap
+}
+
+// recordTrimmed logs and audits periods shortened by a successful create or update.
+func (s *ProductCostService) recordTrimmed(ctx context.Context, trimmed []*dto.ProductCostResponse) {
+	for _, before := range trimmed {
+		costID := uuid.MustParse(before.CostID)
+		after, err := s.GetByID(ctx, costID)
+		if err != nil {
+			continue
+		}
+		log.Info().Str("costId", before.CostID).Time("periodEnd", after.PeriodEnd).Msg("Product cost period trimmed")
+		s.audit.Record(ctx, repository.AuditEntityProductCost, costID, repository.AuditActionUpdate, before, after)
+	}
+}
+
+func costIDs(costs []*dto.ProductCostResponse) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(costs))
+	for _, cost := range costs {
+		ids = append(ids, uuid.MustParse(cost.CostID))
+	}
+	return ids
+}
+
+// GetCoverage reports products that lack a cost for some days of [dateFrom, dateTo] or have overlapping periods.
+func (s *ProductCostService) GetCoverage(ctx context.Context, dateFrom, dateTo time.Time, productID *uuid.UUID) ([]dto.ProductCostCoverageResponse, error) {
+	periods, err := s.repo.GetCoveragePeriods(ctx, dateFrom, dateTo, productID)
+	if err != nil {
+		log.Error().Err(err).Time("dateFrom", dateFrom).Time("dateTo", dateTo).Msg("Failed to get product cost coverage")
+		return nil, err
+	}
+
+	result := make([]dto.ProductCostCoverageResponse, 0)
+	for i := 0; i < len(periods); {
+		j := i
+		for j < len(periods) && periods[j].ProductID == periods[i].ProductID {
+			j++
+		}
+
+		coverage := productCostCoverage(periods[i:j], dateFrom, dateTo)
+		if coverage.NoCost || len(coverage.Gaps) > 0 || len(coverage.Overlaps) > 0 {
+			result = append(result, coverage)
+		}
+		i = j
+	}
+
+	return result, nil
+}
+
+// productCostCoverage walks periods of one product sorted by start and collects uncovered and doubly covered days.
+func productCostCoverage(periods []repository.ProductCostPeriod, dateFrom, dateTo time.Time) dto.ProductCostCoverageResponse {
+	coverage := dto.ProductCostCoverageResponse{
+		ProductID: periods[0].ProductID.String(),
+		Article:   periods[0].Article,
+		Gaps:      make([]dto.DateRangeResponse, 0),
+	}
+
+	addGap := func(from, to time.Time) {
+		coverage.Gaps = append(coverage.Gaps, dto.DateRangeResponse{From: from, To: to})
+		coverage.UncoveredDays += int(to.Sub(from).Hours()/24) + 1
+	}
+
+	next := dateFrom // первый день, еще не покрытый ни одним периодом
+	covered := false
+	for _, period := range periods {
+		if period.PeriodStart == nil || period.PeriodEnd == nil {
+			continue
+		}
+		start, end := *period.PeriodStart, *period.PeriodEnd
+		if start.Before(dateFrom) {
+			start = dateFrom
+		}
+		if end.After(dateTo) {
+			end = dateTo
+		}
+		covered = true
+
+		if start.After(next) {
+			addGap(next, start.AddDate(0, 0, -1))
+		} else if start.Before(next) {
+			overlapEnd := next.AddDate(0, 0, -1)
+			if end.Before(overlapEnd) {
+				overlapEnd = end
+			}
+			coverage.Overlaps = append(coverage.Overlaps, dto.DateRangeResponse{From: start, To: overlapEnd})
+		}
+
+		if !end.Before(next) {
+			next = end.AddDate(0, 0, 1)
+		}
+	}
+
+	if !next.After(dateTo) {
+		addGap(next, dateTo)
+	}
+	coverage.NoCost = !covered
+
+	return coverage
+}
@@ -16,13 +16,15 @@ type ProductService struct {
 	repo      *repository.ProductRepository
 	imageRepo *repository.ProductImageRepository
 	baseURL   string // Base URL for serving images (e.g., "http://localhost:8080")
+	audit     *AuditService
 }
 
-func NewProductService(repo *repository.ProductRepository, imageRepo *repository.ProductImageRepository, baseURL string) *ProductService {
+func NewProductService(repo *repository.ProductRepository, imageRepo *repository.ProductImageRepository, baseURL string, audit *AuditService) *ProductService {
 	return &ProductService{
 		repo:      repo,
 		imageRepo: imageRepo,
 		baseURL:   baseURL,
+		audit:     audit,
 	}
 }
 
@@ -51,6 +53,8 @@ func (s *ProductService) GetByID(ctx context.Context, productID uuid.UUID) (*dto
 		PurchasePrice:   product.PurchasePrice,
 		ProcessingPrice: product.ProcessingPrice,
 		Images:          imageResponses,
+		IsArchived:      product.IsArchived,
+		ArchivedAt:      product.ArchivedAt,
 	}, nil
 }
 
@@ -78,6 +82,8 @@ func (s *ProductService) GetByArticle(ctx context.Context, article string) (*dto
 		PurchasePrice:   product.PurchasePrice,
 		ProcessingPrice: product.ProcessingPrice,
 		Images:          imageResponses,
+		IsArchived:      product.IsArchived,
+		ArchivedAt:      product.ArchivedAt,
 	}, nil
 }
 
@@ -105,11 +111,13 @@ func (s *ProductService) GetByBarcode(ctx context.Context, barcode string) (*dto
 		PurchasePrice:   product.PurchasePrice,
 		ProcessingPrice: product.ProcessingPrice,
 		Images:          imageResponses,
+		IsArchived:      product.IsArchived,
+		ArchivedAt:      product.ArchivedAt,
 	}, nil
 }
 
-func (s *ProductService) List(ctx context.Context, limit, offset int) ([]dto.ProductResponse, error) {
-	products, err := s.repo.List(ctx, limit, offset)
+func (s *ProductService) List(ctx context.Context, limit, offset int, includeArchived bool) ([]dto.ProductResponse, error) {
+	products, err := s.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to list products")
 		return nil, err
@@ -128,6 +136,8 @@ func (s *ProductService) List(ctx context.Context, limit, offset int) ([]dto.Pro
 			PurchasePrice:   product.PurchasePrice,
 			ProcessingPrice: product.ProcessingPrice,
 			Images:          s.mapImagesToDTO(images),
+			IsArchived:      product.IsArchived,
+			ArchivedAt:      product.ArchivedAt,
 		}
 
 		result = append(result, productResponse)
@@ -160,7 +170,7 @@ func (s *ProductService) Create(ctx context.Context, req dto.ProductCreateReques
 	images, _ := s.imageRepo.GetByProductID(ctx, product.ProductID)
 	imageResponses := s.mapImagesToDTO(images)
 
-	return &dto.ProductResponse{
+	result := &dto.ProductResponse{
 		ProductID:       product.ProductID.String(),
 		Article:         product.Article,
 		Barcode:         product.Barcode,
@@ -169,10 +179,19 @@ func (s *ProductService) Create(ctx context.Context, req dto.ProductCreateReques
 		PurchasePrice:   product.PurchasePrice,
 		ProcessingPrice: product.ProcessingPrice,
 		Images:          imageResponses,
-	}, nil
+		IsArchived:      product.IsArchived,
+		ArchivedAt:      product.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityProduct, product.ProductID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *ProductService) Update(ctx context.Context, productID uuid.UUID, req dto.ProductUpdateRequest) (*dto.ProductResponse, error) {
+	before, err := s.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
 	product, err := s.repo.Update(ctx, productID, req.Article, req.Barcode, req.UnitWeight, req.UnitCost, req.PurchasePrice, req.ProcessingPrice)
 	if err != nil {
 		log.Error().Err(err).Str("productId", productID.String()).Msg("Failed to update product")
@@ -194,7 +213,7 @@ func (s *ProductService) Update(ctx context.Context, productID uuid.UUID, req dt
 	images, _ := s.imageRepo.GetByProductID(ctx, productID)
 	imageResponses := s.mapImagesToDTO(images)
 
-	return &dto.ProductResponse{
+	result := &dto.ProductResponse{
 		ProductID:       product.ProductID.String(),
 		Article:         product.Article,
 		Barcode:         product.Barcode,
@@ -203,20 +222,54 @@ func (s *ProductService) Update(ctx context.Context, productID uuid.UUID, req dt
 		PurchasePrice:   product.PurchasePrice,
 		ProcessingPrice: product.ProcessingPrice,
 		Images:          imageResponses,
-	}, nil
+		IsArchived:      product.IsArchived,
+		ArchivedAt:      product.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityProduct, productID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
+// Delete archives the product: it disappears from lists and cannot be used in new documents, but
+// documents and stock history that reference it stay intact. Use Restore to bring it back.
 func (s *ProductService) Delete(ctx context.Context, productID uuid.UUID) error {
-	// Images will be deleted automatically due to CASCADE constraint
-	err := s.repo.Delete(ctx, productID)
+	before, err := s.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Archive(ctx, productID)
 	if err != nil {
-		log.Error().Err(err).Str("productId", productID.String()).Msg("Failed to delete product")
+		log.Error().Err(err).Str("productId", productID.String()).Msg("Failed to archive product")
 		return err
 	}
-	log.Info().Str("productId", productID.String()).Msg("Product deleted successfully")
+
+	log.Info().Str("productId", productID.String()).Msg("Product archived successfully")
+	if after, err := s.GetByID(ctx, productID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityProduct, productID, repository.AuditActionDelete, before, after)
+	}
 	return nil
 }
 
+func (s *ProductService) Restore(ctx context.Context, productID uuid.UUID) (*dto.ProductResponse, error) {
+	before, err := s.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, productID); err != nil {
+		log.Error().Err(err).Str("productId", productID.String()).Msg("Failed to restore product")
+		return nil, err
+	}
+
+	log.Info().Str("productId", productID.String()).Msg("Product restored successfully")
+	result, err := s.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityProduct, productID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
 func (s *ProductService) syncProductImages(ctx context.Context, productID uuid.UUID, imagePaths []string) {
 	existingImages, _ := s.imageRepo.GetByProductID(ctx, productID)
 	existingPaths := make(map[string]*repository.ProductImage)
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"warehouse-backend/internal/auth"
 	"warehouse-backend/internal/dto"
 	"warehouse-backend/internal/repository"
 
@@ -11,11 +12,17 @@ import (
 )
 
 type RoleService struct {
-	repo *repository.RoleRepository
+	repo           *repository.RoleRepository
+	permissionRepo *repository.PermissionRepository
+	audit          *AuditService
 }
 
-func NewRoleService(repo *repository.RoleRepository) *RoleService {
-	return &RoleService{repo: repo}
+func NewRoleService(repo *repository.RoleRepository, permissionRepo *repository.PermissionRepository, audit *AuditService) *RoleService {
+	return &RoleService{
+		repo:           repo,
+		permissionRepo: permissionRepo,
+		audit:          audit,
+	}
 }
 
 func (s *RoleService) GetByID(ctx context.Context, roleID uuid.UUID) (*dto.RoleResponse, error) {
@@ -25,9 +32,16 @@ func (s *RoleService) GetByID(ctx context.Context, roleID uuid.UUID) (*dto.RoleR
 		return nil, err
 	}
 
+	permissions, err := s.permissionRepo.GetByRoleID(ctx, roleID)
+	if err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to get role permissions")
+		return nil, err
+	}
+
 	return &dto.RoleResponse{
-		RoleID: role.RoleID.String(),
-		Name:   role.Name,
+		RoleID:      role.RoleID.String(),
+		Name:        role.Name,
+		Permissions: permissionCodes(permissions),
 	}, nil
 }
 
@@ -57,13 +71,20 @@ func (s *RoleService) Create(ctx context.Context, req dto.RoleCreateRequest) (*d
 	}
 
 	log.Info().Str("roleId", role.RoleID.String()).Str("name", req.Name).Msg("Role created successfully")
-	return &dto.RoleResponse{
+	result := &dto.RoleResponse{
 		RoleID: role.RoleID.String(),
 		Name:   role.Name,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityRole, role.RoleID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *RoleService) Update(ctx context.Context, roleID uuid.UUID, req dto.RoleUpdateRequest) (*dto.RoleResponse, error) {
+	before, err := s.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
 	role, err := s.repo.Update(ctx, roleID, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to update role")
@@ -71,19 +92,88 @@ func (s *RoleService) Update(ctx context.Context, roleID uuid.UUID, req dto.Role
 	}
 
 	log.Info().Str("roleId", roleID.String()).Msg("Role updated successfully")
-	return &dto.RoleResponse{
+	result := &dto.RoleResponse{
 		RoleID: role.RoleID.String(),
 		Name:   role.Name,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityRole, roleID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
 func (s *RoleService) Delete(ctx context.Context, roleID uuid.UUID) error {
-	err := s.repo.Delete(ctx, roleID)
+	before, err := s.GetByID(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, roleID)
 	if err != nil {
 		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to delete role")
 		return err
 	}
 
 	log.Info().Str("roleId", roleID.String()).Msg("Role deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityRole, roleID, repository.AuditActionDelete, before, nil)
 	return nil
 }
+
+func (s *RoleService) GetPermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	if _, err := s.repo.GetByID(ctx, roleID); err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to get role for permissions")
+		return nil, err
+	}
+
+	permissions, err := s.permissionRepo.GetByRoleID(ctx, roleID)
+	if err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to get role permissions")
+		return nil, err
+	}
+
+	return permissionCodes(permissions), nil
+}
+
+// UpdatePermissions replaces the role's permissions with the given "resource:action" codes.
+func (s *RoleService) UpdatePermissions(ctx context.Context, roleID uuid.UUID, req dto.RolePermissionsUpdateRequest) ([]string, error) {
+	before, err := s.GetPermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := s.permissionRepo.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load permission catalog")
+		return nil, err
+	}
+
+	byCode := make(map[string]uuid.UUID, len(catalog))
+	for _, permission := range catalog {
+		byCode[auth.PermissionCode(permission.Resource, permission.Action)] = permission.PermissionID
+	}
+
+	permissionIDs := make([]uuid.UUID, 0, len(req.Permissions))
+	for _, code := range req.Permissions {
+		id, ok := byCode[code]
+		if !ok {
+			log.Warn().Str("roleId", roleID.String()).Str("permission", code).Msg("Unknown permission code")
+			return nil, repository.ErrPermissionNotFound
+		}
+		permissionIDs = append(permissionIDs, id)
+	}
+
+	if err := s.permissionRepo.ReplaceRolePermissions(ctx, roleID, permissionIDs); err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to update role permissions")
+		return nil, err
+	}
+
+	permissions, err := s.permissionRepo.GetByRoleID(ctx, roleID)
+	if err != nil {
+		log.Error().Err(err).Str("roleId", roleID.String()).Msg("Failed to reload role permissions")
+		return nil, err
+	}
+
+	log.Info().Str("roleId", roleID.String()).Int("permissions", len(permissionIDs)).Msg("Role permissions updated successfully")
+	result := permissionCodes(permissions)
+	s.audit.Record(ctx, repository.AuditEntityRole, roleID, repository.AuditActionUpdate,
+		dto.RolePermissionsUpdateRequest{Permissions: before}, dto.RolePermissionsUpdateRequest{Permissions: result})
+	return result, nil
+}
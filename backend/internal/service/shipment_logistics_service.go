@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ShipmentLogisticsService пересчитывает агрегаты отгрузки на маркетплейс и распределяет ее логистику по позициям.
+type ShipmentLogisticsService struct {
+	shipmentRepo *repository.MpShipmentRepository
+	itemRepo     *repository.MpShipmentItemRepository
+	productRepo  *repository.ProductRepository
+}
+
+func NewShipmentLogisticsService(shipmentRepo *repository.MpShipmentRepository, itemRepo *repository.MpShipmentItemRepository, productRepo *repository.ProductRepository) *ShipmentLogisticsService {
+	return &ShipmentLogisticsService{
+		shipmentRepo: shipmentRepo,
+		itemRepo:     itemRepo,
+		productRepo:  productRepo,
+	}
+}
+
+// Recalculate allocates the shipment's logistics and acceptance cost to its items by sent quantity or by
+// sent weight (sent_qty * unit_weight), depending on the shipment's allocation method, and refreshes
+// positions_qty, sent_qty, accepted_qty and unit_logistics of the shipment. When the items have no weight,
+// the cost is allocated by quantity.
+func (s *ShipmentLogisticsService) Recalculate(ctx context.Context, shipmentID, userID uuid.UUID) error {
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to load mp shipment for logistics allocation")
+		return err
+	}
+
+	items, err := s.itemRepo.GetByShipmentID(ctx, shipmentID)
+	if err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to load mp shipment items for logistics allocation")
+		return err
+	}
+
+	var cost float64
+	if shipment.LogisticsCost != nil {
+		cost += *shipment.LogisticsCost
+	}
+	if shipment.AcceptanceCost != nil {
+		cost += *shipment.AcceptanceCost
+	}
+
+	quantities := make([]float64, len(items))
+	for i, item := range items {
+		quantities[i] = float64(item.SentQty)
+	}
+
+	basis := quantities
+	if shipment.LogisticsAllocationMethod == repository.AllocationByWeight {
+		weights := make(map[uuid.UUID]int)
+		basis = make([]float64, len(items))
+		for i, item := range items {
+			weight, ok := weights[item.ProductID]
+			if !ok {
+				product, err := s.productRepo.GetByID(ctx, item.ProductID)
+				if err != nil {
+					log.Error().Err(err).Str("productId", item.ProductID.String()).Msg("Failed to load product weight for logistics allocation")
+					return err
+				}
+				weight = product.UnitWeight
+				weights[item.ProductID] = weight
+			}
+			basis[i] = float64(item.SentQty * weight)
+		}
+	}
+
+	shares := allocateByBasis(cost, basis)
+	if shares == nil {
+		shares = allocateByBasis(cost, quantities)
+	}
+	if shares == nil {
+		shares = make([]float64, len(items))
+	}
+
+	allocations := make([]repository.ShipmentItemLogistics, 0, len(items))
+	for i, item := range items {
+		allocations = append(allocations, repository.ShipmentItemLogistics{
+			ShipmentItemID:   item.ShipmentItemID,
+			LogisticsForItem: shares[i],
+		})
+	}
+
+	if err := s.shipmentRepo.ApplyItemLogistics(ctx, shipmentID, allocations, userID); err != nil {
+		log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to save mp shipment aggregates")
+		return err
+	}
+
+	return nil
+}
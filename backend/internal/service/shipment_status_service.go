@@ -11,11 +11,12 @@ import (
 )
 
 type ShipmentStatusService struct {
-	repo *repository.ShipmentStatusRepository
+	repo  *repository.ShipmentStatusRepository
+	audit *AuditService
 }
 
-func NewShipmentStatusService(repo *repository.ShipmentStatusRepository) *ShipmentStatusService {
-	return &ShipmentStatusService{repo: repo}
+func NewShipmentStatusService(repo *repository.ShipmentStatusRepository, audit *AuditService) *ShipmentStatusService {
+	return &ShipmentStatusService{repo: repo, audit: audit}
 }
 
 func (s *ShipmentStatusService) GetByID(ctx context.Context, statusID uuid.UUID) (*dto.ShipmentStatusResponse, error) {
@@ -28,11 +29,13 @@ func (s *ShipmentStatusService) GetByID(ctx context.Context, statusID uuid.UUID)
 	return &dto.ShipmentStatusResponse{
 		ShipmentStatusID: status.ShipmentStatusID.String(),
 		Name:             status.Name,
+		IsArchived:       status.IsArchived,
+		ArchivedAt:       status.ArchivedAt,
 	}, nil
 }
 
-func (s *ShipmentStatusService) List(ctx context.Context, limit, offset int) ([]dto.ShipmentStatusResponse, error) {
-	statuses, err := s.repo.List(ctx, limit, offset)
+func (s *ShipmentStatusService) List(ctx context.Context, limit, offset int, includeArchived bool) ([]dto.ShipmentStatusResponse, error) {
+	statuses, err := s.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to list shipment statuses")
 		return nil, err
@@ -43,6 +46,8 @@ func (s *ShipmentStatusService) List(ctx context.Context, limit, offset int) ([]
 		result = append(result, dto.ShipmentStatusResponse{
 			ShipmentStatusID: status.ShipmentStatusID.String(),
 			Name:             status.Name,
+			IsArchived:       status.IsArchived,
+			ArchivedAt:       status.ArchivedAt,
 		})
 	}
 
@@ -57,13 +62,22 @@ func (s *ShipmentStatusService) Create(ctx context.Context, req dto.ShipmentStat
 	}
 
 	log.Info().Str("statusId", status.ShipmentStatusID.String()).Str("name", status.Name).Msg("Shipment status created successfully")
-	return &dto.ShipmentStatusResponse{
+	result := &dto.ShipmentStatusResponse{
 		ShipmentStatusID: status.ShipmentStatusID.String(),
 		Name:             status.Name,
-	}, nil
+		IsArchived:       status.IsArchived,
+		ArchivedAt:       status.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityShipmentStatus, status.ShipmentStatusID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *ShipmentStatusService) Update(ctx context.Context, statusID uuid.UUID, req dto.ShipmentStatusUpdateRequest) (*dto.ShipmentStatusResponse, error) {
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+
 	status, err := s.repo.Update(ctx, statusID, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to update shipment status")
@@ -71,19 +85,53 @@ func (s *ShipmentStatusService) Update(ctx context.Context, statusID uuid.UUID,
 	}
 
 	log.Info().Str("statusId", statusID.String()).Msg("Shipment status updated successfully")
-	return &dto.ShipmentStatusResponse{
+	result := &dto.ShipmentStatusResponse{
 		ShipmentStatusID: status.ShipmentStatusID.String(),
 		Name:             status.Name,
-	}, nil
+		IsArchived:       status.IsArchived,
+		ArchivedAt:       status.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityShipmentStatus, statusID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
+// Delete archives the shipment status: it disappears from lists and cannot be used in new documents, but
+// documents and stock history that reference it stay intact. Use Restore to bring it back.
 func (s *ShipmentStatusService) Delete(ctx context.Context, statusID uuid.UUID) error {
-	err := s.repo.Delete(ctx, statusID)
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Archive(ctx, statusID)
 	if err != nil {
-		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to delete shipment status")
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to archive shipment status")
 		return err
 	}
 
-	log.Info().Str("statusId", statusID.String()).Msg("Shipment status deleted successfully")
+	log.Info().Str("statusId", statusID.String()).Msg("Shipment status archived successfully")
+	if after, err := s.GetByID(ctx, statusID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityShipmentStatus, statusID, repository.AuditActionDelete, before, after)
+	}
 	return nil
 }
+
+func (s *ShipmentStatusService) Restore(ctx context.Context, statusID uuid.UUID) (*dto.ShipmentStatusResponse, error) {
+	before, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, statusID); err != nil {
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to restore shipment status")
+		return nil, err
+	}
+
+	log.Info().Str("statusId", statusID.String()).Msg("Shipment status restored successfully")
+	result, err := s.GetByID(ctx, statusID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityShipmentStatus, statusID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
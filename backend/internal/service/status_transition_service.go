@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// StatusHook выполняет побочные эффекты перехода документа в новый статус.
+type StatusHook func(ctx context.Context, entityID, userID uuid.UUID) error
+
+// StatusTransitionService проверяет смену статусов документов по таблице status_transitions.
+type StatusTransitionService struct {
+	repo *repository.StatusTransitionRepository
+}
+
+func NewStatusTransitionService(repo *repository.StatusTransitionRepository) *StatusTransitionService {
+	return &StatusTransitionService{repo: repo}
+}
+
+// Check returns ErrInvalidStatusTransition unless a document of entityType may move from fromStatus to
+// toStatus. Keeping the current one is always allowed. The first status (fromStatus is nil) must be an
+// initial one, i.e. no transition leads to it: otherwise a document could skip the checks and side effects
+// of reaching that status (dispatch, receipt, completion).
+func (s *StatusTransitionService) Check(ctx context.Context, entityType string, fromStatus *string, toStatus string) error {
+	if fromStatus == nil {
+		target, err := s.repo.IsTarget(ctx, entityType, toStatus)
+		if err != nil {
+			log.Error().Err(err).Str("entityType", entityType).Str("to", toStatus).Msg("Failed to check initial status")
+			return err
+		}
+		if target {
+			log.Warn().Str("entityType", entityType).Str("status", toStatus).Msg("Status cannot be assigned as the first one")
+			return repository.ErrInvalidStatusTransition
+		}
+		return nil
+	}
+	if *fromStatus == toStatus {
+		return nil
+	}
+
+	allowed, err := s.repo.IsAllowed(ctx, entityType, *fromStatus, toStatus)
+	if err != nil {
+		log.Error().Err(err).Str("entityType", entityType).Str("from", *fromStatus).Str("to", toStatus).Msg("Failed to check status transition")
+		return err
+	}
+	if !allowed {
+		targets, err := s.repo.ListTargets(ctx, entityType, *fromStatus)
+		if err != nil {
+			log.Error().Err(err).Str("entityType", entityType).Str("from", *fromStatus).Msg("Failed to list allowed status transitions")
+		}
+		log.Warn().
+			Str("entityType", entityType).
+			Str("from", *fromStatus).
+			Str("to", toStatus).
+			Strs("allowed", targets).
+			Msg("Status transition is not allowed")
+		return repository.ErrInvalidStatusTransition
+	}
+
+	return nil
+}
+
+// Record adds a status change of the document to its history. The change itself is already saved, so a
+// failure is logged and not returned.
+func (s *StatusTransitionService) Record(ctx context.Context, entityType string, entityID uuid.UUID, fromStatus *string, toStatus string, comment *string, userID uuid.UUID) {
+	if err := s.repo.RecordHistory(ctx, entityType, entityID, fromStatus, toStatus, comment, &userID); err != nil {
+		log.Error().Err(err).
+			Str("entityType", entityType).
+			Str("entityId", entityID.String()).
+			Str("status", toStatus).
+			Msg("Failed to record status history")
+	}
+}
+
+func (s *StatusTransitionService) History(ctx context.Context, entityType string, entityID uuid.UUID) ([]dto.StatusHistoryResponse, error) {
+	entries, err := s.repo.GetHistory(ctx, entityType, entityID)
+	if err != nil {
+		log.Error().Err(err).Str("entityType", entityType).Str("entityId", entityID.String()).Msg("Failed to get status history")
+		return nil, err
+	}
+
+	result := make([]dto.StatusHistoryResponse, 0, len(entries))
+	for _, entry := range entries {
+		var changedByStr *string
+		if entry.ChangedBy != nil {
+			str := entry.ChangedBy.String()
+			changedByStr = &str
+		}
+
+		result = append(result, dto.StatusHistoryResponse{
+			HistoryID:      entry.HistoryID.String(),
+			FromStatus:     entry.FromStatus,
+			ToStatus:       entry.ToStatus,
+			Comment:        entry.Comment,
+			ChangedBy:      changedByStr,
+			ChangedByEmail: entry.ChangedByEmail,
+			ChangedAt:      entry.ChangedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// runStatusHooks runs the hooks registered for the target status. The status is already saved at this
+// point, so a failing hook is logged and does not fail the request.
+func runStatusHooks(ctx context.Context, hooks map[string][]StatusHook, entityType, toStatus string, entityID, userID uuid.UUID) {
+	for _, hook := range hooks[toStatus] {
+		if err := hook(ctx, entityID, userID); err != nil {
+			log.Error().Err(err).
+				Str("entityType", entityType).
+				Str("entityId", entityID.String()).
+				Str("status", toStatus).
+				Msg("Status transition hook failed")
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+type StockLevelService struct {
+	repo          *repository.StockLevelRepository
+	productRepo   *repository.ProductRepository
+	warehouseRepo *repository.WarehouseRepository
+	audit         *AuditService
+}
+
+func NewStockLevelService(repo *repository.StockLevelRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, audit *AuditService) *StockLevelService {
+	return &StockLevelService{
+		repo:          repo,
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		audit:         audit,
+	}
+}
+
+func toStockLevelResponse(level *repository.StockLevel) dto.StockLevelResponse {
+	var createdByStr *string
+	if level.CreatedBy != nil {
+		str := level.CreatedBy.String()
+		createdByStr = &str
+	}
+	var updatedByStr *string
+	if level.UpdatedBy != nil {
+		str := level.UpdatedBy.String()
+		updatedByStr = &str
+	}
+
+	return dto.StockLevelResponse{
+		LevelID:     level.LevelID.String(),
+		ProductID:   level.ProductID.String(),
+		WarehouseID: level.WarehouseID.String(),
+		MinQty:      level.MinQty,
+		MaxQty:      level.MaxQty,
+		CreatedBy:   createdByStr,
+		CreatedAt:   level.CreatedAt,
+		UpdatedBy:   updatedByStr,
+		UpdatedAt:   level.UpdatedAt,
+	}
+}
+
+func (s *StockLevelService) GetByID(ctx context.Context, levelID uuid.UUID) (*dto.StockLevelResponse, error) {
+	level, err := s.repo.GetByID(ctx, levelID)
+	if err != nil {
+		log.Error().Err(err).Str("levelId", levelID.String()).Msg("Failed to get stock level by ID")
+		return nil, err
+	}
+
+	result := toStockLevelResponse(level)
+	return &result, nil
+}
+
+func (s *StockLevelService) List(ctx context.Context, limit, offset int, productID, warehouseID *uuid.UUID) ([]dto.StockLevelResponse, error) {
+	levels, err := s.repo.List(ctx, limit, offset, productID, warehouseID)
+	if err != nil {
+		log.Error().Err(err).Int("limit", limit).Int("offset", offset).
+			Interface("productId", productID).Interface("warehouseId", warehouseID).Msg("Failed to list stock levels")
+		return nil, err
+	}
+
+	result := make([]dto.StockLevelResponse, 0, len(levels))
+	for i := range levels {
+		result = append(result, toStockLevelResponse(&levels[i]))
+	}
+
+	return result, nil
+}
+
+// validate checks the product and warehouse of a level and its quantities. Archived product or warehouse
+// is rejected unless the level already points to it.
+func (s *StockLevelService) validate(ctx context.Context, productIDStr, warehouseIDStr string, minQty, maxQty int, existing *repository.StockLevel) (uuid.UUID, uuid.UUID, error) {
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		log.Warn().Str("productId", productIDStr).Msg("Invalid product ID format")
+		return uuid.Nil, uuid.Nil, repository.ErrProductNotFound
+	}
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		if err == repository.ErrProductNotFound {
+			log.Warn().Str("productId", productIDStr).Msg("Product not found")
+			return uuid.Nil, uuid.Nil, repository.ErrProductNotFound
+		}
+		log.Error().Err(err).Str("productId", productIDStr).Msg("Failed to validate product")
+		return uuid.Nil, uuid.Nil, err
+	}
+	if product.IsArchived && (existing == nil || existing.ProductID != productID) {
+		log.Warn().Str("productId", productIDStr).Msg("Product is archived")
+		return uuid.Nil, uuid.Nil, repository.ErrProductArchived
+	}
+
+	warehouseID, err := uuid.Parse(warehouseIDStr)
+	if err != nil {
+		log.Warn().Str("warehouseId", warehouseIDStr).Msg("Invalid warehouse ID format")
+		return uuid.Nil, uuid.Nil, repository.ErrWarehouseNotFound
+	}
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
+	if err != nil {
+		if err == repository.ErrWarehouseNotFound {
+			log.Warn().Str("warehouseId", warehouseIDStr).Msg("Warehouse not found")
+			return uuid.Nil, uuid.Nil, repository.ErrWarehouseNotFound
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseIDStr).Msg("Failed to validate warehouse")
+		return uuid.Nil, uuid.Nil, err
+	}
+	if warehouse.IsArchived && (existing == nil || existing.WarehouseID != warehouseID) {
+		log.Warn().Str("warehouseId", warehouseIDStr).Msg("Warehouse is archived")
+		return uuid.Nil, uuid.Nil, repository.ErrWarehouseArchived
+	}
+
+	if minQty < 0 || maxQty < minQty {
+		log.Warn().Int("minQty", minQty).Int("maxQty", maxQty).Msg("Stock level requires 0 <= minQty <= maxQty")
+		return uuid.Nil, uuid.Nil, repository.ErrInvalidStockLevel
+	}
+
+	return productID, warehouseID, nil
+}
+
+func (s *StockLevelService) Create(ctx context.Context, userID uuid.UUID, req dto.StockLevelCreateRequest) (*dto.StockLevelResponse, error) {
+	productID, warehouseID, err := s.validate(ctx, req.ProductID, req.WarehouseID, req.MinQty, req.MaxQty, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := s.repo.Create(ctx, productID, warehouseID, req.MinQty, req.MaxQty, &userID)
+	if err != nil {
+		log.Error().Err(err).Str("productId", req.ProductID).Str("warehouseId", req.WarehouseID).Str("userId", userID.String()).Msg("Failed to create stock level")
+		return nil, err
+	}
+
+	log.Info().Str("levelId", level.LevelID.String()).Str("productId", req.ProductID).Str("warehouseId", req.WarehouseID).Str("userId", userID.String()).Msg("Stock level created successfully")
+	result := toStockLevelResponse(level)
+	s.audit.Record(ctx, repository.AuditEntityStockLevel, level.LevelID, repository.AuditActionCreate, nil, result)
+	return &result, nil
+}
+
+func (s *StockLevelService) Update(ctx context.Context, levelID, userID uuid.UUID, req dto.StockLevelUpdateRequest) (*dto.StockLevelResponse, error) {
+	existing, err := s.repo.GetByID(ctx, levelID)
+	if err != nil {
+		if err != repository.ErrStockLevelNotFound {
+			log.Error().Err(err).Str("levelId", levelID.String()).Msg("Failed to get stock level")
+		}
+		return nil, err
+	}
+	before := toStockLevelResponse(existing)
+
+	productID, warehouseID, err := s.validate(ctx, req.ProductID, req.WarehouseID, req.MinQty, req.MaxQty, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := s.repo.Update(ctx, levelID, productID, warehouseID, req.MinQty, req.MaxQty, &userID)
+	if err != nil {
+		log.Error().Err(err).Str("levelId", levelID.String()).Str("userId", userID.String()).Msg("Failed to update stock level")
+		return nil, err
+	}
+
+	log.Info().Str("levelId", levelID.String()).Str("userId", userID.String()).Msg("Stock level updated successfully")
+	result := toStockLevelResponse(level)
+	s.audit.Record(ctx, repository.AuditEntityStockLevel, levelID, repository.AuditActionUpdate, before, result)
+	return &result, nil
+}
+
+func (s *StockLevelService) Delete(ctx context.Context, levelID uuid.UUID) error {
+	before, err := s.GetByID(ctx, levelID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, levelID)
+	if err != nil {
+		log.Error().Err(err).Str("levelId", levelID.String()).Msg("Failed to delete stock level")
+		return err
+	}
+
+	log.Info().Str("levelId", levelID.String()).Msg("Stock level deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityStockLevel, levelID, repository.AuditActionDelete, before, nil)
+	return nil
+}
+
+func (s *StockLevelService) GetReorderSuggestions(ctx context.Context, warehouseID, productID *uuid.UUID) ([]dto.ReorderSuggestionResponse, error) {
+	suggestions, err := s.repo.GetReorderSuggestions(ctx, warehouseID, productID)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Interface("productId", productID).Msg("Failed to get reorder suggestions")
+		return nil, err
+	}
+
+	result := make([]dto.ReorderSuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		result = append(result, dto.ReorderSuggestionResponse{
+			LevelID:       suggestion.LevelID.String(),
+			ProductID:     suggestion.ProductID.String(),
+			Article:       suggestion.Article,
+			WarehouseID:   suggestion.WarehouseID.String(),
+			MinQty:        suggestion.MinQty,
+			MaxQty:        suggestion.MaxQty,
+			CurrentQty:    suggestion.CurrentQty,
+			IncomingQty:   suggestion.IncomingQty,
+			ProjectedQty:  suggestion.ProjectedQty,
+			SuggestedQty:  suggestion.SuggestedQty,
+			PurchasePrice: suggestion.PurchasePrice,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// StockLotService ведет партии по FIFO: отгрузки на маркетплейсы, списания инвентаризации и перемещения
+// забирают товар из самых ранних партий склада, возвраты непринятого товара и приемка перемещения
+// возвращают на склад те партии, которые документ забрал. Остаток самого раннего снапшота склада сверх партий -
+// начальная партия без себестоимости, она расходуется первой.
+type StockLotService struct {
+	repo         *repository.StockLotRepository
+	shipmentRepo *repository.MpShipmentRepository
+	statusRepo   *repository.ShipmentStatusRepository
+}
+
+func NewStockLotService(repo *repository.StockLotRepository, shipmentRepo *repository.MpShipmentRepository, statusRepo *repository.ShipmentStatusRepository) *StockLotService {
+	return &StockLotService{
+		repo:         repo,
+		shipmentRepo: shipmentRepo,
+		statusRepo:   statusRepo,
+	}
+}
+
+// lotPortion - количество одной партии (индекс в lotLedger.lots)
+type lotPortion struct {
+	lot int
+	qty int
+}
+
+type lotStockKey struct {
+	productID   uuid.UUID
+	warehouseID uuid.UUID
+}
+
+// lotLedger is the result of replaying lot movements: what is left of every lot on every warehouse and which
+// lots every document item took or brought back.
+type lotLedger struct {
+	lots  []repository.StockLot
+	stock map[lotStockKey][]lotPortion
+	// taken - партии, забранные позицией документа; uncovered - сколько позиция забрала сверх партий
+	taken     map[uuid.UUID][]lotPortion
+	uncovered map[uuid.UUID]int
+	returned  map[uuid.UUID][]lotPortion
+}
+
+// replayLots applies the movements to the lots in date order. Lots received on a date are available to the
+// movements of the same date. The opening lot of a pair is what its earliest snapshot has beyond the lots left
+// on the warehouse at the end of the snapshot date; it has no cost and, as the oldest stock, is consumed first.
+// Stock without a lot (inventory surplus, marketplace returns) is not tracked, so a consumption exceeding the
+// lots of the warehouse is counted as uncovered.
+func replayLots(openings, lots []repository.StockLot, movements []repository.LotMovement) *lotLedger {
+	ledger := &lotLedger{
+		// Начальные партии идут первыми: порядок партий в ledger.lots - порядок FIFO
+		lots:      append(append(make([]repository.StockLot, 0, len(openings)+len(lots)), openings...), lots...),
+		stock:     make(map[lotStockKey][]lotPortion),
+		taken:     make(map[uuid.UUID][]lotPortion),
+		uncovered: make(map[uuid.UUID]int),
+		returned:  make(map[uuid.UUID][]lotPortion),
+	}
+
+	nextOpening, nextLot := 0, len(openings)
+	// advance receives the lots received on or before date and opens the snapshots taken before date; a snapshot
+	// already includes the lots received and the movements made on its date
+	advance := func(date time.Time) {
+		for {
+			lotDue := nextLot < len(ledger.lots) && !ledger.lots[nextLot].ReceiptDate.After(date)
+			openingDue := nextOpening < len(openings) && ledger.lots[nextOpening].ReceiptDate.Before(date)
+			switch {
+			case lotDue && (!openingDue || !ledger.lots[nextLot].ReceiptDate.After(ledger.lots[nextOpening].ReceiptDate)):
+				lot := ledger.lots[nextLot]
+				ledger.add(lotStockKey{productID: lot.ProductID, warehouseID: lot.WarehouseID}, lotPortion{lot: nextLot, qty: lot.Quantity})
+				nextLot++
+			case openingDue:
+				opening := &ledger.lots[nextOpening]
+				key := lotStockKey{productID: opening.ProductID, warehouseID: opening.WarehouseID}
+				opening.Quantity = max(0, opening.Quantity-quantityOf(ledger.stock[key]))
+				if opening.Quantity > 0 {
+					ledger.add(key, lotPortion{lot: nextOpening, qty: opening.Quantity})
+				}
+				nextOpening++
+			default:
+				return
+			}
+		}
+	}
+
+	for _, movement := range movements {
+		advance(movement.MovementDate)
+		key := lotStockKey{productID: movement.ProductID, warehouseID: movement.WarehouseID}
+
+		switch movement.MovementType {
+		case repository.LotMovementShipment, repository.LotMovementWriteOff, repository.LotMovementTransferOut:
+			taken, uncovered := ledger.take(key, movement.Quantity)
+			ledger.taken[movement.ItemID] = append(ledger.taken[movement.ItemID], taken...)
+			ledger.uncovered[movement.ItemID] += uncovered
+		case repository.LotMovementShipmentReturn, repository.LotMovementTransferIn:
+			// Возвращаются партии в том порядке, в котором позиция их забрала; непокрытое партиями не возвращается
+			back := firstPortions(ledger.taken[movement.ItemID], movement.Quantity)
+			for _, portion := range back {
+				ledger.add(key, portion)
+			}
+			if movement.MovementType == repository.LotMovementShipmentReturn {
+				ledger.returned[movement.ItemID] = back
+			}
+		}
+	}
+	advance(time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	return ledger
+}
+
+// add puts the portion on the warehouse keeping the portions in FIFO (lot) order.
+func (l *lotLedger) add(key lotStockKey, portion lotPortion) {
+	portions := l.stock[key]
+	i := 0
+	for i < len(portions) && portions[i].lot < portion.lot {
+		i++
+	}
+	if i < len(portions) && portions[i].lot == portion.lot {
+		portions[i].qty += portion.qty
+		return
+	}
+	portions = append(portions, lotPortion{})
+	copy(portions[i+1:], portions[i:])
+	portions[i] = portion
+	l.stock[key] = portions
+}
+
+// take removes qty from the earliest lots of the warehouse and returns the portions taken and the quantity
+// no lot covered.
+func (l *lotLedger) take(key lotStockKey, qty int) ([]lotPortion, int) {
+	portions := l.stock[key]
+	var taken []lotPortion
+	for qty > 0 && len(portions) > 0 {
+		n := min(qty, portions[0].qty)
+		taken = append(taken, lotPortion{lot: portions[0].lot, qty: n})
+		portions[0].qty -= n
+		qty -= n
+		if portions[0].qty == 0 {
+			portions = portions[1:]
+		}
+	}
+	l.stock[key] = portions
+	return taken, qty
+}
+
+// firstPortions returns the first qty units of the portions.
+func firstPortions(portions []lotPortion, qty int) []lotPortion {
+	var result []lotPortion
+	for _, portion := range portions {
+		if qty <= 0 {
+			break
+		}
+		n := min(qty, portion.qty)
+		result = append(result, lotPortion{lot: portion.lot, qty: n})
+		qty -= n
+	}
+	return result
+}
+
+// cost returns the cost of the portions and the quantity of lots without unit cost.
+func (l *lotLedger) cost(portions []lotPortion) (float64, int) {
+	var cost float64
+	var uncosted int
+	for _, portion := range portions {
+		unitCost := l.lots[portion.lot].UnitCost
+		if unitCost == nil {
+			uncosted += portion.qty
+			continue
+		}
+		cost += float64(portion.qty) * *unitCost
+	}
+	return roundMoney(cost), uncosted
+}
+
+func quantityOf(portions []lotPortion) int {
+	var qty int
+	for _, portion := range portions {
+		qty += portion.qty
+	}
+	return qty
+}
+
+func (s *StockLotService) replay(ctx context.Context, productID *uuid.UUID) (*lotLedger, []repository.LotMovement, error) {
+	openings, err := s.repo.GetOpeningLots(ctx, productID)
+	if err != nil {
+		log.Error().Err(err).Interface("productId", productID).Msg("Failed to get opening stock lots")
+		return nil, nil, err
+	}
+	lots, err := s.repo.GetLots(ctx, productID)
+	if err != nil {
+		log.Error().Err(err).Interface("productId", productID).Msg("Failed to get stock lots")
+		return nil, nil, err
+	}
+	movements, err := s.repo.GetLotMovements(ctx, productID)
+	if err != nil {
+		log.Error().Err(err).Interface("productId", productID).Msg("Failed to get stock lot movements")
+		return nil, nil, err
+	}
+	return replayLots(openings, lots, movements), movements, nil
+}
+
+// GetLots reports what is left of every lot on every warehouse. Fully consumed lots are included only when
+// includeEmpty is set (on the warehouse they were received to).
+func (s *StockLotService) GetLots(ctx context.Context, productID, warehouseID *uuid.UUID, includeEmpty bool) (*dto.StockLotReportResponse, error) {
+	ledger, _, err := s.replay(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := make(map[int]map[uuid.UUID]int)
+	for key, portions := range ledger.stock {
+		for _, portion := range portions {
+			if remaining[portion.lot] == nil {
+				remaining[portion.lot] = make(map[uuid.UUID]int)
+			}
+			remaining[portion.lot][key.warehouseID] += portion.qty
+		}
+	}
+
+	result := &dto.StockLotReportResponse{Items: make([]dto.StockLotResponse, 0)}
+	for i, lot := range ledger.lots {
+		byWarehouse := remaining[i]
+		// Начальная партия, которую снапшот не добавил сверх остатка партий, не показывается
+		if len(byWarehouse) == 0 && includeEmpty && (!lot.Opening || lot.Quantity > 0) {
+			byWarehouse = map[uuid.UUID]int{lot.WarehouseID: 0}
+		}
+		// Сначала склад приемки, затем склады, куда партию переместили
+		warehouses := make([]uuid.UUID, 0, len(byWarehouse))
+		if _, ok := byWarehouse[lot.WarehouseID]; ok {
+			warehouses = append(warehouses, lot.WarehouseID)
+		}
+		moved := make([]uuid.UUID, 0, len(byWarehouse))
+		for id := range byWarehouse {
+			if id != lot.WarehouseID {
+				moved = append(moved, id)
+			}
+		}
+		sort.Slice(moved, func(a, b int) bool { return moved[a].String() < moved[b].String() })
+		warehouses = append(warehouses, moved...)
+
+		for _, id := range warehouses {
+			if warehouseID != nil && id != *warehouseID {
+				continue
+			}
+			qty := byWarehouse[id]
+			item := dto.StockLotResponse{
+				LotID:        lot.LotID.String(),
+				Opening:      lot.Opening,
+				OrderNumber:  lot.OrderNumber,
+				ProductID:    lot.ProductID.String(),
+				WarehouseID:  id.String(),
+				ReceiptDate:  lot.ReceiptDate,
+				UnitCost:     lot.UnitCost,
+				ReceivedQty:  lot.Quantity,
+				RemainingQty: qty,
+			}
+			if !lot.Opening {
+				orderID := lot.OrderID.String()
+				item.OrderID = &orderID
+			}
+			result.TotalQty += qty
+			if lot.UnitCost != nil {
+				value := roundMoney(float64(qty) * *lot.UnitCost)
+				item.RemainingValue = &value
+				result.TotalValue += value
+			} else {
+				result.UncostedQty += qty
+			}
+			result.Items = append(result.Items, item)
+		}
+	}
+	result.TotalValue = roundMoney(result.TotalValue)
+
+	return result, nil
+}
+
+// shipmentItemCost builds the cost of a shipment item from the lots it took and got back.
+func (l *lotLedger) shipmentItemCost(movement repository.LotMovement) dto.MpShipmentItemCostResponse {
+	taken := l.taken[movement.ItemID]
+	returned := l.returned[movement.ItemID]
+	cost, uncosted := l.cost(taken)
+	returnedCost, _ := l.cost(returned)
+
+	item := dto.MpShipmentItemCostResponse{
+		ShipmentItemID: movement.ItemID.String(),
+		ProductID:      movement.ProductID.String(),
+		WarehouseID:    movement.WarehouseID.String(),
+		SentQty:        movement.Quantity,
+		ReturnedQty:    quantityOf(returned),
+		UncostedQty:    uncosted + l.uncovered[movement.ItemID],
+		Cost:           cost,
+		ReturnedCost:   returnedCost,
+		NetCost:        roundMoney(cost - returnedCost),
+		Lots:           make([]dto.MpShipmentLotCostResponse, 0, len(taken)),
+	}
+	for _, portion := range taken {
+		lot := l.lots[portion.lot]
+		item.Lots = append(item.Lots, dto.MpShipmentLotCostResponse{
+			LotID:       lot.LotID.String(),
+			Opening:     lot.Opening,
+			OrderNumber: lot.OrderNumber,
+			ReceiptDate: lot.ReceiptDate,
+			Quantity:    portion.qty,
+			UnitCost:    lot.UnitCost,
+		})
+	}
+	return item
+}
+
+func addShipmentItemCost(shipment *dto.MpShipmentCostResponse, item dto.MpShipmentItemCostResponse) {
+	shipment.SentQty += item.SentQty
+	shipment.ReturnedQty += item.ReturnedQty
+	shipment.UncostedQty += item.UncostedQty
+	shipment.Cost = roundMoney(shipment.Cost + item.Cost)
+	shipment.ReturnedCost = roundMoney(shipment.ReturnedCost + item.ReturnedCost)
+	shipment.NetCost = roundMoney(shipment.Cost - shipment.ReturnedCost)
+}
+
+func toMpShipmentCostResponse(shipmentID uuid.UUID, shipmentNumber string, shipmentDate time.Time, storeID, warehouseID *uuid.UUID) dto.MpShipmentCostResponse {
+	result := dto.MpShipmentCostResponse{
+		ShipmentID:     shipmentID.String(),
+		ShipmentNumber: shipmentNumber,
+		ShipmentDate:   shipmentDate,
+	}
+	if storeID != nil {
+		str := storeID.String()
+		result.StoreID = &str
+	}
+	if warehouseID != nil {
+		str := warehouseID.String()
+		result.WarehouseID = &str
+	}
+	return result
+}
+
+// GetShipmentCosts reports the cost of goods shipped by every sent shipment with shipment date in the range.
+func (s *StockLotService) GetShipmentCosts(ctx context.Context, dateFrom, dateTo *time.Time, storeID *uuid.UUID) ([]dto.MpShipmentCostResponse, error) {
+	shipments, err := s.repo.GetShipments(ctx, dateFrom, dateTo, storeID)
+	if err != nil {
+		log.Error().Err(err).Interface("storeId", storeID).Msg("Failed to get shipments for cost report")
+		return nil, err
+	}
+
+	ledger, movements, err := s.replay(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.MpShipmentCostResponse, 0, len(shipments))
+	index := make(map[uuid.UUID]int, len(shipments))
+	for _, shipment := range shipments {
+		index[shipment.ShipmentID] = len(result)
+		result = append(result, toMpShipmentCostResponse(shipment.ShipmentID, shipment.ShipmentNumber, shipment.ShipmentDate, shipment.StoreID, shipment.WarehouseID))
+	}
+	for _, movement := range movements {
+		if movement.MovementType != repository.LotMovementShipment {
+			continue
+		}
+		if i, ok := index[movement.DocumentID]; ok {
+			addShipmentItemCost(&result[i], ledger.shipmentItemCost(movement))
+		}
+	}
+
+	return result, nil
+}
+
+// GetShipmentCost reports the cost of goods shipped by the shipment with the lots taken by every item.
+func (s *StockLotService) GetShipmentCost(ctx context.Context, shipmentID uuid.UUID) (*dto.MpShipmentCostResponse, error) {
+	shipment, err := s.shipmentRepo.GetByID(ctx, shipmentID)
+	if err != nil {
+		if err != repository.ErrMpShipmentNotFound {
+			log.Error().Err(err).Str("shipmentId", shipmentID.String()).Msg("Failed to get mp shipment for cost report")
+		}
+		return nil, err
+	}
+
+	dispatched := false
+	if shipment.StatusID != nil && shipment.ShipmentDate != nil {
+		status, err := s.statusRepo.GetByID(ctx, *shipment.StatusID)
+		if err != nil {
+			log.Error().Err(err).Str("statusId", shipment.StatusID.String()).Msg("Failed to get shipment status")
+			return nil, err
+		}
+		dispatched = repository.IsShipmentDispatched(status.Name)
+	}
+	if !dispatched {
+		log.Warn().Str("shipmentId", shipmentID.String()).Msg("Mp shipment is not sent yet")
+		return nil, repository.ErrShipmentNotSent
+	}
+
+	ledger, movements, err := s.replay(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toMpShipmentCostResponse(shipment.ShipmentID, shipment.ShipmentNumber, *shipment.ShipmentDate, shipment.StoreID, shipment.WarehouseID)
+	result.Items = make([]dto.MpShipmentItemCostResponse, 0)
+	for _, movement := range movements {
+		if movement.MovementType != repository.LotMovementShipment || movement.DocumentID != shipmentID {
+			continue
+		}
+		item := ledger.shipmentItemCost(movement)
+		addShipmentItemCost(&result, item)
+		result.Items = append(result.Items, item)
+	}
+
+	return &result, nil
+}
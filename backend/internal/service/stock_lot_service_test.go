@@ -0,0 +1,248 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/repository"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// withoutEmpty drops entries without portions: take leaves an empty slice for a drained warehouse
+func withoutEmpty[K comparable](m map[K][]lotPortion) map[K][]lotPortion {
+	result := make(map[K][]lotPortion)
+	for key, portions := range m {
+		if len(portions) > 0 {
+			result[key] = portions
+		}
+	}
+	return result
+}
+
+func withoutZero(m map[uuid.UUID]int) map[uuid.UUID]int {
+	result := make(map[uuid.UUID]int)
+	for key, qty := range m {
+		if qty != 0 {
+			result[key] = qty
+		}
+	}
+	return result
+}
+
+func TestReplayLots(t *testing.T) {
+	product := uuid.New()
+	warehouse := uuid.New()
+	other := uuid.New()
+	key := lotStockKey{productID: product, warehouseID: warehouse}
+	otherKey := lotStockKey{productID: product, warehouseID: other}
+	shipmentItem := uuid.New()
+	transferItem := uuid.New()
+
+	lot := func(receiptDate string, qty int) repository.StockLot {
+		return repository.StockLot{ProductID: product, WarehouseID: warehouse, ReceiptDate: date(receiptDate), Quantity: qty}
+	}
+	opening := func(snapshotDate string, qty int) repository.StockLot {
+		return repository.StockLot{ProductID: product, WarehouseID: warehouse, ReceiptDate: date(snapshotDate), Quantity: qty, Opening: true}
+	}
+	movement := func(movementType string, warehouseID uuid.UUID, movementDate string, qty int, itemID uuid.UUID) repository.LotMovement {
+		return repository.LotMovement{MovementType: movementType, ProductID: product, WarehouseID: warehouseID, MovementDate: date(movementDate), Quantity: qty, ItemID: itemID}
+	}
+
+	tests := []struct {
+		name      string
+		openings  []repository.StockLot
+		lots      []repository.StockLot
+		movements []repository.LotMovement
+		// openingQty - количество начальных партий после вычета партий, лежавших на складе на дату снапшота
+		openingQty []int
+		stock      map[lotStockKey][]lotPortion
+		taken      map[uuid.UUID][]lotPortion
+		uncovered  map[uuid.UUID]int
+		returned   map[uuid.UUID][]lotPortion
+	}{
+		{
+			name:     "opening lot holds only the snapshot beyond lots and is consumed first",
+			openings: []repository.StockLot{opening("2025-01-31", 10)},
+			lots:     []repository.StockLot{lot("2025-01-20", 4)},
+			movements: []repository.LotMovement{
+				movement(repository.LotMovementShipment, warehouse, "2025-02-05", 8, shipmentItem),
+			},
+			openingQty: []int{6},
+			stock:      map[lotStockKey][]lotPortion{key: {{lot: 1, qty: 2}}},
+			taken:      map[uuid.UUID][]lotPortion{shipmentItem: {{lot: 0, qty: 6}, {lot: 1, qty: 2}}},
+		},
+		{
+			name:       "opening lot without stock beyond lots is dropped",
+			openings:   []repository.StockLot{opening("2025-01-31", 3)},
+			lots:       []repository.StockLot{lot("2025-01-20", 5)},
+			openingQty: []int{0},
+			stock:      map[lotStockKey][]lotPortion{key: {{lot: 1, qty: 5}}},
+		},
+		{
+			name: "lot received on the movement date is available to it",
+			lots: []repository.StockLot{lot("2025-02-10", 5)},
+			movements: []repository.LotMovement{
+				movement(repository.LotMovementShipment, warehouse, "2025-02-10", 3, shipmentItem),
+			},
+			stock: map[lotStockKey][]lotPortion{key: {{lot: 0, qty: 2}}},
+			taken: map[uuid.UUID][]lotPortion{shipmentItem: {{lot: 0, qty: 3}}},
+		},
+		{
+			name:     "snapshot opens after the movements of its own date",
+			openings: []repository.StockLot{opening("2025-02-10", 5)},
+			movements: []repository.LotMovement{
+				movement(repository.LotMovementShipment, warehouse, "2025-02-10", 2, shipmentItem),
+			},
+			openingQty: []int{5},
+			stock:      map[lotStockKey][]lotPortion{key: {{lot: 0, qty: 5}}},
+			uncovered:  map[uuid.UUID]int{shipmentItem: 2},
+		},
+		{
+			name: "return brings back the lots the item took in FIFO order",
+			lots: []repository.StockLot{lot("2025-01-01", 5), lot("2025-01-02", 5)},
+			movements: []repository.LotMovement{
+				movement(repository.LotMovementShipment, warehouse, "2025-01-10", 7, shipmentItem),
+				movement(repository.LotMovementShipmentReturn, warehouse, "2025-01-15", 6, shipmentItem),
+			},
+			stock:    map[lotStockKey][]lotPortion{key: {{lot: 0, qty: 5}, {lot: 1, qty: 4}}},
+			taken:    map[uuid.UUID][]lotPortion{shipmentItem: {{lot: 0, qty: 5}, {lot: 1, qty: 2}}},
+			returned: map[uuid.UUID][]lotPortion{shipmentItem: {{lot: 0, qty: 5}, {lot: 1, qty: 1}}},
+		},
+		{
+			name: "transfer moves the taken lots to the receiving warehouse",
+			lots: []repository.StockLot{lot("2025-01-01", 4), lot("2025-01-05", 6)},
+			movements: []repository.LotMovement{
+				movement(repository.LotMovementTransferOut, warehouse, "2025-01-10", 5, transferItem),
+				movement(repository.LotMovementTransferIn, other, "2025-01-12", 5, transferItem),
+			},
+			stock: map[lotStockKey][]lotPortion{
+				key:      {{lot: 1, qty: 5}},
+				otherKey: {{lot: 0, qty: 4}, {lot: 1, qty: 1}},
+			},
+			taken: map[uuid.UUID][]lotPortion{transferItem: {{lot: 0, qty: 4}, {lot: 1, qty: 1}}},
+		},
+		{
+			name: "consumption beyond lots is uncovered and not returned",
+			lots: []repository.StockLot{lot("2025-01-01", 2)},
+			movements: []repository.LotMovement{
+				movement(repository.LotMovementShipment, warehouse, "2025-01-10", 5, shipmentItem),
+				movement(repository.LotMovementShipmentReturn, warehouse, "2025-01-15", 5, shipmentItem),
+			},
+			stock:     map[lotStockKey][]lotPortion{key: {{lot: 0, qty: 2}}},
+			taken:     map[uuid.UUID][]lotPortion{shipmentItem: {{lot: 0, qty: 2}}},
+			uncovered: map[uuid.UUID]int{shipmentItem: 3},
+			returned:  map[uuid.UUID][]lotPortion{shipmentItem: {{lot: 0, qty: 2}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := replayLots(tt.openings, tt.lots, tt.movements)
+
+			for i, want := range tt.openingQty {
+				if got := ledger.lots[i].Quantity; got != want {
+					t.Errorf("opening lot %d quantity = %d, want %d", i, got, want)
+				}
+			}
+			if got, want := withoutEmpty(ledger.stock), withoutEmpty(tt.stock); !reflect.DeepEqual(got, want) {
+				t.Errorf("stock = %+v, want %+v", got, want)
+			}
+			if got, want := withoutEmpty(ledger.taken), withoutEmpty(tt.taken); !reflect.DeepEqual(got, want) {
+				t.Errorf("taken = %+v, want %+v", got, want)
+			}
+			if got, want := withoutZero(ledger.uncovered), withoutZero(tt.uncovered); !reflect.DeepEqual(got, want) {
+				t.Errorf("uncovered = %v, want %v", got, want)
+			}
+			if got, want := withoutEmpty(ledger.returned), withoutEmpty(tt.returned); !reflect.DeepEqual(got, want) {
+				t.Errorf("returned = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLotLedgerTake(t *testing.T) {
+	key := lotStockKey{productID: uuid.New(), warehouseID: uuid.New()}
+
+	tests := []struct {
+		name      string
+		stock     []lotPortion
+		qty       int
+		taken     []lotPortion
+		uncovered int
+		left      []lotPortion
+	}{
+		{
+			name:  "takes from the earliest lot",
+			stock: []lotPortion{{lot: 0, qty: 5}, {lot: 1, qty: 5}},
+			qty:   3,
+			taken: []lotPortion{{lot: 0, qty: 3}},
+			left:  []lotPortion{{lot: 0, qty: 2}, {lot: 1, qty: 5}},
+		},
+		{
+			name:  "spans several lots",
+			stock: []lotPortion{{lot: 0, qty: 2}, {lot: 1, qty: 5}},
+			qty:   4,
+			taken: []lotPortion{{lot: 0, qty: 2}, {lot: 1, qty: 2}},
+			left:  []lotPortion{{lot: 1, qty: 3}},
+		},
+		{
+			name:      "reports the quantity beyond the lots",
+			stock:     []lotPortion{{lot: 0, qty: 2}},
+			qty:       5,
+			taken:     []lotPortion{{lot: 0, qty: 2}},
+			uncovered: 3,
+		},
+		{
+			name:      "empty warehouse",
+			qty:       4,
+			uncovered: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := &lotLedger{stock: map[lotStockKey][]lotPortion{key: tt.stock}}
+
+			taken, uncovered := ledger.take(key, tt.qty)
+			if !reflect.DeepEqual(taken, tt.taken) || uncovered != tt.uncovered {
+				t.Errorf("take() = %+v, %d, want %+v, %d", taken, uncovered, tt.taken, tt.uncovered)
+			}
+			if left := ledger.stock[key]; len(left) != len(tt.left) || (len(left) > 0 && !reflect.DeepEqual(left, tt.left)) {
+				t.Errorf("stock left = %+v, want %+v", left, tt.left)
+			}
+		})
+	}
+}
+
+func TestFirstPortions(t *testing.T) {
+	portions := []lotPortion{{lot: 0, qty: 3}, {lot: 2, qty: 4}}
+
+	tests := []struct {
+		name string
+		qty  int
+		want []lotPortion
+	}{
+		{name: "part of the first portion", qty: 2, want: []lotPortion{{lot: 0, qty: 2}}},
+		{name: "whole first portion", qty: 3, want: []lotPortion{{lot: 0, qty: 3}}},
+		{name: "spans portions", qty: 5, want: []lotPortion{{lot: 0, qty: 3}, {lot: 2, qty: 2}}},
+		{name: "more than all portions", qty: 10, want: []lotPortion{{lot: 0, qty: 3}, {lot: 2, qty: 4}}},
+		{name: "zero", qty: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstPortions(portions, tt.qty); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("firstPortions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
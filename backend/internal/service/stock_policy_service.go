@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Политика отрицательных остатков: что делать, если приемка отгрузки или списание инвентаризацией
+// уводит остаток ниже нуля
+const (
+	NegativeStockPolicyBlock = "block" // операция отклоняется
+	NegativeStockPolicyWarn  = "warn"  // операция выполняется, в лог пишется предупреждение
+	NegativeStockPolicyAllow = "allow" // проверка не выполняется
+)
+
+func IsValidNegativeStockPolicy(policy string) bool {
+	switch policy {
+	case NegativeStockPolicyBlock, NegativeStockPolicyWarn, NegativeStockPolicyAllow:
+		return true
+	}
+	return false
+}
+
+// StockPolicyService применяет политику отрицательных остатков к изменениям остатков документами
+// и не дает документам забирать товар, размещенный в ячейках.
+type StockPolicyService struct {
+	stockRepo    *repository.StockRepository
+	locationRepo *repository.StorageLocationRepository
+	policy       string
+}
+
+// NewStockPolicyService falls back to the warn policy when the configured one is unknown.
+func NewStockPolicyService(stockRepo *repository.StockRepository, locationRepo *repository.StorageLocationRepository, policy string) *StockPolicyService {
+	if !IsValidNegativeStockPolicy(policy) {
+		log.Warn().Str("policy", policy).Msg("Unknown negative stock policy, using warn")
+		policy = NegativeStockPolicyWarn
+	}
+	return &StockPolicyService{
+		stockRepo:    stockRepo,
+		locationRepo: locationRepo,
+		policy:       policy,
+	}
+}
+
+func (s *StockPolicyService) Policy() string {
+	return s.policy
+}
+
+// Check applies the policy to the stock changes of a document. Under the block policy it returns
+// ErrNegativeStock if any change would take current stock below zero. Regardless of the policy it returns
+// ErrStockNotPicked if a document takes stock that is still placed in bins (see checkPicked).
+func (s *StockPolicyService) Check(ctx context.Context, documentType string, documentID uuid.UUID, changes []repository.StockChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := s.checkPicked(ctx, documentType, documentID, changes); err != nil {
+		return err
+	}
+	if s.policy == NegativeStockPolicyAllow {
+		return nil
+	}
+
+	shortages, err := s.stockRepo.FindShortages(ctx, changes)
+	if err != nil {
+		log.Error().Err(err).Str("documentType", documentType).Str("documentId", documentID.String()).Msg("Failed to check negative stock")
+		return err
+	}
+
+	for _, shortage := range shortages {
+		log.Warn().
+			Str("policy", s.policy).
+			Str("documentType", documentType).
+			Str("documentId", documentID.String()).
+			Str("productId", shortage.ProductID.String()).
+			Str("warehouseId", shortage.WarehouseID.String()).
+			Int("onHand", shortage.OnHand).
+			Int("change", shortage.Change).
+			Int("projected", shortage.Projected).
+			Msg("Operation makes stock negative")
+	}
+
+	if len(shortages) > 0 && s.policy == NegativeStockPolicyBlock {
+		return repository.ErrNegativeStock
+	}
+	return nil
+}
+
+// checkPicked makes sure that the outgoing changes of a document are covered by the unplaced stock of the
+// warehouse. Bin stock changes only through location moves, so goods leaving the warehouse have to be picked
+// from their bins (a move without destination) first; otherwise the bins would keep showing them. Outgoing
+// stock beyond the unplaced one is allowed only when the bins hold none of the product.
+func (s *StockPolicyService) checkPicked(ctx context.Context, documentType string, documentID uuid.UUID, changes []repository.StockChange) error {
+	outgoing := make(map[uuid.UUID]map[uuid.UUID]int)
+	for _, change := range stockChanges(changes) {
+		if change.Quantity >= 0 {
+			continue
+		}
+		if outgoing[change.WarehouseID] == nil {
+			outgoing[change.WarehouseID] = make(map[uuid.UUID]int)
+		}
+		outgoing[change.WarehouseID][change.ProductID] = -change.Quantity
+	}
+
+	for warehouseID, products := range outgoing {
+		stock, err := s.locationRepo.GetStock(ctx, warehouseID)
+		if err != nil {
+			log.Error().Err(err).Str("documentType", documentType).Str("documentId", documentID.String()).
+				Str("warehouseId", warehouseID.String()).Msg("Failed to get location stock")
+			return err
+		}
+		placed := make(map[uuid.UUID]int)
+		unplaced := make(map[uuid.UUID]int)
+		for _, item := range stock {
+			if item.LocationID != nil {
+				placed[item.ProductID] += item.Quantity
+			} else {
+				unplaced[item.ProductID] += item.Quantity
+			}
+		}
+
+		for productID, qty := range products {
+			if placed[productID] > 0 && unplaced[productID] < qty {
+				log.Warn().
+					Str("documentType", documentType).
+					Str("documentId", documentID.String()).
+					Str("productId", productID.String()).
+					Str("warehouseId", warehouseID.String()).
+					Int("unplaced", unplaced[productID]).
+					Int("placed", placed[productID]).
+					Int("outgoing", qty).
+					Msg("Outgoing stock is placed in bins and has to be picked first")
+				return repository.ErrStockNotPicked
+			}
+		}
+	}
+
+	return nil
+}
+
+// stockChanges sums quantities per product and warehouse, dropping pairs that net to zero.
+func stockChanges(changes []repository.StockChange) []repository.StockChange {
+	index := make(map[[2]uuid.UUID]int)
+	var result []repository.StockChange
+	for _, change := range changes {
+		key := [2]uuid.UUID{change.ProductID, change.WarehouseID}
+		if i, ok := index[key]; ok {
+			result[i].Quantity += change.Quantity
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, change)
+	}
+
+	n := 0
+	for _, change := range result {
+		if change.Quantity != 0 {
+			result[n] = change
+			n++
+		}
+	}
+	return result[:n]
+}
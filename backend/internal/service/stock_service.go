@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"warehouse-backend/internal/dto"
@@ -10,12 +14,22 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
 type StockService struct {
-	repo *repository.StockRepository
+	repo          *repository.StockRepository
+	reservations  *repository.StockReservationRepository
+	negativeStock *StockPolicyService
 }
 
-func NewStockService(repo *repository.StockRepository) *StockService {
-	return &StockService{repo: repo}
+func NewStockService(repo *repository.StockRepository, reservations *repository.StockReservationRepository, negativeStock *StockPolicyService) *StockService {
+	return &StockService{
+		repo:          repo,
+		reservations:  reservations,
+		negativeStock: negativeStock,
+	}
 }
 
 func (s *StockService) GetCurrentStock(
@@ -46,3 +60,182 @@ func (s *StockService) GetCurrentStock(
 
 	return result, nil
 }
+
+func (s *StockService) GetStockAsOf(
+	ctx context.Context,
+	asOfDate time.Time,
+	warehouseID *uuid.UUID,
+	productID *uuid.UUID,
+	limit int,
+	offset int,
+) ([]dto.StockAsOfItemResponse, error) {
+
+	items, err := s.repo.GetStockAsOf(ctx, asOfDate, warehouseID, productID, limit, offset)
+	if err != nil {
+		log.Error().Err(err).
+			Time("asOfDate", asOfDate).
+			Interface("warehouseId", warehouseID).
+			Interface("productId", productID).
+			Int("limit", limit).
+			Int("offset", offset).
+			Msg("Failed to get stock as of date")
+		return nil, err
+	}
+
+	result := make([]dto.StockAsOfItemResponse, 0, len(items))
+	for _, item := range items {
+		result = append(result, dto.StockAsOfItemResponse{
+			ProductID:         item.ProductID.String(),
+			WarehouseID:       item.WarehouseID.String(),
+			AsOfDate:          asOfDate,
+			SnapshotDate:      item.SnapshotDate,
+			SnapshotQuantity:  item.SnapshotQuantity,
+			MovementsQuantity: item.MovementsQuantity,
+			Quantity:          item.Quantity,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *StockService) GetMovements(
+	ctx context.Context,
+	filter repository.StockMovementFilter,
+	cursor string,
+	limit int,
+) ([]dto.StockMovementResponse, *string, error) {
+
+	if filter.MovementType != nil && !repository.IsValidMovementType(*filter.MovementType) {
+		log.Warn().Str("movementType", *filter.MovementType).Msg("Invalid movement type")
+		return nil, nil, repository.ErrInvalidMovementType
+	}
+
+	if cursor != "" {
+		afterDate, afterID, err := decodeMovementCursor(cursor)
+		if err != nil {
+			log.Warn().Str("cursor", cursor).Msg("Invalid stock movements cursor")
+			return nil, nil, ErrInvalidCursor
+		}
+		filter.AfterDate = &afterDate
+		filter.AfterID = &afterID
+	}
+
+	movements, err := s.repo.GetMovements(ctx, filter, limit)
+	if err != nil {
+		log.Error().Err(err).Interface("filter", filter).Int("limit", limit).Msg("Failed to get stock movements")
+		return nil, nil, err
+	}
+
+	result := make([]dto.StockMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		result = append(result, stockMovementResponse(movement))
+	}
+
+	var nextCursor *string
+	if len(movements) == limit {
+		last := movements[len(movements)-1]
+		c := encodeMovementCursor(last.MovementDate, last.MovementID)
+		nextCursor = &c
+	}
+
+	return result, nextCursor, nil
+}
+
+func stockMovementResponse(movement repository.StockMovement) dto.StockMovementResponse {
+	var productIDStr *string
+	if movement.ProductID != nil {
+		str := movement.ProductID.String()
+		productIDStr = &str
+	}
+
+	return dto.StockMovementResponse{
+		MovementID:     movement.MovementID.String(),
+		ProductID:      productIDStr,
+		WarehouseID:    movement.WarehouseID.String(),
+		MovementDate:   movement.MovementDate,
+		Quantity:       movement.Quantity,
+		MovementType:   movement.MovementType,
+		DocumentID:     movement.DocumentID.String(),
+		RunningBalance: movement.RunningBalance,
+	}
+}
+
+// GetNegativeStock reports the pairs with negative current stock and the outgoing documents that took
+// stock below zero (at most documentsLimit, newest first).
+func (s *StockService) GetNegativeStock(ctx context.Context, warehouseID, productID *uuid.UUID, documentsLimit int) (*dto.NegativeStockReportResponse, error) {
+	items, err := s.repo.GetNegativeStock(ctx, warehouseID, productID)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Interface("productId", productID).Msg("Failed to get negative stock")
+		return nil, err
+	}
+
+	movements, err := s.repo.GetNegativeStockMovements(ctx, warehouseID, productID, documentsLimit)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Interface("productId", productID).Msg("Failed to get documents that made stock negative")
+		return nil, err
+	}
+
+	result := &dto.NegativeStockReportResponse{
+		Policy:    s.negativeStock.Policy(),
+		Items:     make([]dto.StockItemResponse, 0, len(items)),
+		Documents: make([]dto.StockMovementResponse, 0, len(movements)),
+	}
+	for _, item := range items {
+		result.Items = append(result.Items, dto.StockItemResponse{
+			ProductID:       item.ProductID.String(),
+			WarehouseID:     item.WarehouseID.String(),
+			CurrentQuantity: item.CurrentQuantity,
+		})
+	}
+	for _, movement := range movements {
+		result.Documents = append(result.Documents, stockMovementResponse(movement))
+	}
+
+	return result, nil
+}
+
+// Курсор журнала движений — base64 от "дата|movement_id" последней отданной строки
+func encodeMovementCursor(movementDate time.Time, movementID uuid.UUID) string {
+	raw := movementDate.Format("2006-01-02") + "|" + movementID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeMovementCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+
+	movementDate, err := time.Parse("2006-01-02", parts[0])
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+
+	movementID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+
+	return movementDate, movementID, nil
+}
+
+func (s *StockService) GetAvailability(ctx context.Context, productID, warehouseID uuid.UUID) (*dto.StockAvailabilityResponse, error) {
+	availability, err := s.reservations.GetAvailability(ctx, productID, warehouseID, nil)
+	if err != nil {
+		log.Error().Err(err).Str("productId", productID.String()).Str("warehouseId", warehouseID.String()).Msg("Failed to get stock availability")
+		return nil, err
+	}
+
+	return &dto.StockAvailabilityResponse{
+		ProductID:   availability.ProductID.String(),
+		WarehouseID: availability.WarehouseID.String(),
+		OnHand:      availability.OnHand,
+		Reserved:    availability.Reserved,
+		Available:   availability.Available,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// StockSnapshotJobService формирует снапшоты остатков на конец месяца и ведет журнал запусков.
+type StockSnapshotJobService struct {
+	repo    *repository.StockSnapshotRepository
+	runRepo *repository.StockSnapshotRunRepository
+}
+
+func NewStockSnapshotJobService(repo *repository.StockSnapshotRepository, runRepo *repository.StockSnapshotRunRepository) *StockSnapshotJobService {
+	return &StockSnapshotJobService{
+		repo:    repo,
+		runRepo: runRepo,
+	}
+}
+
+// lastMonthEnd returns the last day of the month preceding now.
+func lastMonthEnd(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+}
+
+// Generate creates the snapshots for the end of the previous month and records the run. A run that finds
+// generation already in progress is recorded as skipped and returns ErrSnapshotGenerationLocked along with it;
+// a failed run is recorded with its error.
+func (s *StockSnapshotJobService) Generate(ctx context.Context, trigger string, userID *uuid.UUID) (*dto.StockSnapshotRunResponse, error) {
+	snapshotDate := lastMonthEnd(time.Now())
+
+	run, err := s.runRepo.Create(ctx, trigger, snapshotDate, userID)
+	if err != nil {
+		log.Error().Err(err).Str("trigger", trigger).Msg("Failed to record stock snapshot run")
+		return nil, err
+	}
+
+	created, genErr := s.repo.Generate(ctx, snapshotDate, userID)
+
+	status := repository.SnapshotRunStatusSucceeded
+	var errMsg *string
+	switch {
+	case genErr == repository.ErrSnapshotGenerationLocked:
+		status = repository.SnapshotRunStatusSkipped
+	case genErr != nil:
+		status = repository.SnapshotRunStatusFailed
+		msg := genErr.Error()
+		errMsg = &msg
+	}
+
+	// журнал пишется и при отмене ctx запроса, иначе запуск остался бы в статусе running
+	runID := run.RunID
+	run, err = s.runRepo.Finish(context.WithoutCancel(ctx), runID, status, created, errMsg)
+	if err != nil {
+		log.Error().Err(err).Str("runId", runID.String()).Msg("Failed to record stock snapshot run result")
+		return nil, err
+	}
+
+	logEvent := log.Info()
+	switch status {
+	case repository.SnapshotRunStatusFailed:
+		logEvent = log.Error().Err(genErr)
+	case repository.SnapshotRunStatusSkipped:
+		logEvent = log.Warn()
+	}
+	logEvent.Str("runId", run.RunID.String()).Str("trigger", trigger).Time("snapshotDate", snapshotDate).
+		Str("status", status).Int64("created", created).Msg("Stock snapshot generation finished")
+
+	result := stockSnapshotRunResponse(run)
+	return &result, genErr
+}
+
+func (s *StockSnapshotJobService) ListRuns(ctx context.Context, limit, offset int) ([]dto.StockSnapshotRunResponse, error) {
+	runs, err := s.runRepo.List(ctx, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list stock snapshot runs")
+		return nil, err
+	}
+
+	result := make([]dto.StockSnapshotRunResponse, 0, len(runs))
+	for i := range runs {
+		result = append(result, stockSnapshotRunResponse(&runs[i]))
+	}
+	return result, nil
+}
+
+func stockSnapshotRunResponse(run *repository.StockSnapshotRun) dto.StockSnapshotRunResponse {
+	var startedByStr *string
+	if run.StartedBy != nil {
+		str := run.StartedBy.String()
+		startedByStr = &str
+	}
+
+	return dto.StockSnapshotRunResponse{
+		RunID:            run.RunID.String(),
+		Trigger:          run.Trigger,
+		SnapshotDate:     run.SnapshotDate,
+		Status:           run.Status,
+		SnapshotsCreated: run.SnapshotsCreated,
+		Error:            run.Error,
+		StartedBy:        startedByStr,
+		StartedAt:        run.StartedAt,
+		FinishedAt:       run.FinishedAt,
+	}
+}
@@ -14,13 +14,15 @@ type StockSnapshotService struct {
 	repo          *repository.StockSnapshotRepository
 	warehouseRepo *repository.WarehouseRepository
 	productRepo   *repository.ProductRepository
+	audit         *AuditService
 }
 
-func NewStockSnapshotService(repo *repository.StockSnapshotRepository, warehouseRepo *repository.WarehouseRepository, productRepo *repository.ProductRepository) *StockSnapshotService {
+func NewStockSnapshotService(repo *repository.StockSnapshotRepository, warehouseRepo *repository.WarehouseRepository, productRepo *repository.ProductRepository, audit *AuditService) *StockSnapshotService {
 	return &StockSnapshotService{
 		repo:          repo,
 		warehouseRepo: warehouseRepo,
 		productRepo:   productRepo,
+		audit:         audit,
 	}
 }
 
@@ -128,7 +130,7 @@ func (s *StockSnapshotService) Create(ctx context.Context, userID uuid.UUID, req
 	}
 
 	log.Info().Str("snapshotId", snapshot.SnapshotID.String()).Str("warehouseId", req.WarehouseID).Str("productId", req.ProductID).Str("userId", userID.String()).Msg("Stock snapshot created successfully")
-	return &dto.StockSnapshotResponse{
+	result := &dto.StockSnapshotResponse{
 		SnapshotID:   snapshot.SnapshotID.String(),
 		ProductID:    snapshot.ProductID.String(),
 		WarehouseID:  snapshot.WarehouseID.String(),
@@ -136,10 +138,17 @@ func (s *StockSnapshotService) Create(ctx context.Context, userID uuid.UUID, req
 		Quantity:     snapshot.Quantity,
 		CreatedBy:    createdByStr,
 		CreatedAt:    snapshot.CreatedAt,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityStockSnapshot, snapshot.SnapshotID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *StockSnapshotService) Update(ctx context.Context, snapshotID uuid.UUID, req dto.StockSnapshotUpdateRequest) (*dto.StockSnapshotResponse, error) {
+	before, err := s.GetByID(ctx, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
 	warehouseID, err := uuid.Parse(req.WarehouseID)
 	if err != nil {
 		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
@@ -188,7 +197,7 @@ func (s *StockSnapshotService) Update(ctx context.Context, snapshotID uuid.UUID,
 	}
 
 	log.Info().Str("snapshotId", snapshotID.String()).Msg("Stock snapshot updated successfully")
-	return &dto.StockSnapshotResponse{
+	result := &dto.StockSnapshotResponse{
 		SnapshotID:   snapshot.SnapshotID.String(),
 		ProductID:    snapshot.ProductID.String(),
 		WarehouseID:  snapshot.WarehouseID.String(),
@@ -196,16 +205,64 @@ func (s *StockSnapshotService) Update(ctx context.Context, snapshotID uuid.UUID,
 		Quantity:     snapshot.Quantity,
 		CreatedBy:    createdByStr,
 		CreatedAt:    snapshot.CreatedAt,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityStockSnapshot, snapshotID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
 func (s *StockSnapshotService) Delete(ctx context.Context, snapshotID uuid.UUID) error {
-	err := s.repo.Delete(ctx, snapshotID)
+	before, err := s.GetByID(ctx, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, snapshotID)
 	if err != nil {
 		log.Error().Err(err).Str("snapshotId", snapshotID.String()).Msg("Failed to delete stock snapshot")
 		return err
 	}
 
 	log.Info().Str("snapshotId", snapshotID.String()).Msg("Stock snapshot deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityStockSnapshot, snapshotID, repository.AuditActionDelete, before, nil)
 	return nil
 }
+
+// Verify checks that every snapshot equals the previous snapshot of its product and warehouse plus the movements
+// between them and returns the mismatches. With repair the mismatched snapshots are corrected; each correction
+// is recorded in the audit log.
+func (s *StockSnapshotService) Verify(ctx context.Context, warehouseID, productID *uuid.UUID, repair bool) (*dto.StockSnapshotVerifyResponse, error) {
+	mismatches, err := s.repo.Verify(ctx, warehouseID, productID, repair)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Interface("productId", productID).Bool("repair", repair).Msg("Failed to verify stock snapshots")
+		return nil, err
+	}
+
+	result := &dto.StockSnapshotVerifyResponse{
+		Mismatches: make([]dto.StockSnapshotMismatchResponse, 0, len(mismatches)),
+	}
+	for _, m := range mismatches {
+		result.Mismatches = append(result.Mismatches, dto.StockSnapshotMismatchResponse{
+			SnapshotID:        m.SnapshotID.String(),
+			ProductID:         m.ProductID.String(),
+			WarehouseID:       m.WarehouseID.String(),
+			SnapshotDate:      m.SnapshotDate,
+			PreviousDate:      m.PreviousDate,
+			PreviousQuantity:  m.PreviousQuantity,
+			MovementsQuantity: m.MovementsQuantity,
+			Quantity:          m.Quantity,
+			ExpectedQuantity:  m.ExpectedQuantity,
+			Difference:        m.Quantity - m.ExpectedQuantity,
+			Repaired:          m.Repaired,
+		})
+		if m.Repaired {
+			result.Repaired++
+			s.audit.Record(ctx, repository.AuditEntityStockSnapshot, m.SnapshotID, repository.AuditActionUpdate,
+				map[string]int{"quantity": m.Quantity}, map[string]int{"quantity": m.ExpectedQuantity})
+		}
+	}
+
+	if len(mismatches) > 0 {
+		log.Warn().Int("mismatches", len(mismatches)).Int("repaired", result.Repaired).Msg("Stock snapshots do not match movements")
+	}
+	return result, nil
+}
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+type StorageLocationService struct {
+	repo              *repository.StorageLocationRepository
+	warehouseRepo     *repository.WarehouseRepository
+	supplierOrderRepo *repository.SupplierOrderRepository
+	audit             *AuditService
+}
+
+func NewStorageLocationService(repo *repository.StorageLocationRepository, warehouseRepo *repository.WarehouseRepository, supplierOrderRepo *repository.SupplierOrderRepository, audit *AuditService) *StorageLocationService {
+	return &StorageLocationService{
+		repo:              repo,
+		warehouseRepo:     warehouseRepo,
+		supplierOrderRepo: supplierOrderRepo,
+		audit:             audit,
+	}
+}
+
+// locationAddresses joins the codes of each location and its ancestors from the zone down, e.g. "A-01-3-2".
+func locationAddresses(locations []repository.StorageLocation) map[uuid.UUID]string {
+	byID := make(map[uuid.UUID]*repository.StorageLocation, len(locations))
+	for i := range locations {
+		byID[locations[i].LocationID] = &locations[i]
+	}
+
+	addresses := make(map[uuid.UUID]string, len(locations))
+	for _, location := range locations {
+		var codes []string
+		for current := byID[location.LocationID]; current != nil; {
+			codes = append([]string{current.Code}, codes...)
+			if current.ParentID == nil {
+				break
+			}
+			current = byID[*current.ParentID]
+		}
+		addresses[location.LocationID] = strings.Join(codes, "-")
+	}
+	return addresses
+}
+
+func toStorageLocationResponse(location *repository.StorageLocation, address string) dto.StorageLocationResponse {
+	var parentIDStr *string
+	if location.ParentID != nil {
+		str := location.ParentID.String()
+		parentIDStr = &str
+	}
+	var createdByStr *string
+	if location.CreatedBy != nil {
+		str := location.CreatedBy.String()
+		createdByStr = &str
+	}
+	var updatedByStr *string
+	if location.UpdatedBy != nil {
+		str := location.UpdatedBy.String()
+		updatedByStr = &str
+	}
+
+	return dto.StorageLocationResponse{
+		LocationID:   location.LocationID.String(),
+		WarehouseID:  location.WarehouseID.String(),
+		ParentID:     parentIDStr,
+		LocationType: location.LocationType,
+		Code:         location.Code,
+		Address:      address,
+		Name:         location.Name,
+		IsArchived:   location.IsArchived,
+		ArchivedAt:   location.ArchivedAt,
+		CreatedBy:    createdByStr,
+		CreatedAt:    location.CreatedAt,
+		UpdatedBy:    updatedByStr,
+		UpdatedAt:    location.UpdatedAt,
+	}
+}
+
+// checkWarehouse returns ErrWarehouseNotFound for an unknown warehouse and, if active is set,
+// ErrWarehouseArchived for an archived one.
+func (s *StorageLocationService) checkWarehouse(ctx context.Context, warehouseID uuid.UUID, active bool) error {
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
+	if err != nil {
+		if err != repository.ErrWarehouseNotFound {
+			log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to get warehouse")
+		}
+		return err
+	}
+	if active && warehouse.IsArchived {
+		log.Warn().Str("warehouseId", warehouseID.String()).Msg("Warehouse is archived")
+		return repository.ErrWarehouseArchived
+	}
+	return nil
+}
+
+// addresses returns the addresses of all locations of the warehouse, archived ones included.
+func (s *StorageLocationService) addresses(ctx context.Context, warehouseID uuid.UUID) ([]repository.StorageLocation, map[uuid.UUID]string, error) {
+	locations, err := s.repo.ListByWarehouse(ctx, warehouseID, true)
+	if err != nil {
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to list storage locations")
+		return nil, nil, err
+	}
+	return locations, locationAddresses(locations), nil
+}
+
+// getLocation loads a location and reports locations of other warehouses as not found.
+func (s *StorageLocationService) getLocation(ctx context.Context, warehouseID, locationID uuid.UUID) (*repository.StorageLocation, error) {
+	location, err := s.repo.GetByID(ctx, locationID)
+	if err != nil {
+		if err != repository.ErrStorageLocationNotFound {
+			log.Error().Err(err).Str("locationId", locationID.String()).Msg("Failed to get storage location")
+		}
+		return nil, err
+	}
+	if location.WarehouseID != warehouseID {
+		log.Warn().Str("locationId", locationID.String()).Str("warehouseId", warehouseID.String()).Msg("Storage location belongs to another warehouse")
+		return nil, repository.ErrStorageLocationNotFound
+	}
+	return location, nil
+}
+
+// List returns the locations of the warehouse ordered by address, so that children follow their parent.
+func (s *StorageLocationService) List(ctx context.Context, warehouseID uuid.UUID, includeArchived bool) ([]dto.StorageLocationResponse, error) {
+	if err := s.checkWarehouse(ctx, warehouseID, false); err != nil {
+		return nil, err
+	}
+
+	locations, addresses, err := s.addresses(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.StorageLocationResponse, 0, len(locations))
+	for i := range locations {
+		if locations[i].IsArchived && !includeArchived {
+			continue
+		}
+		result = append(result, toStorageLocationResponse(&locations[i], addresses[locations[i].LocationID]))
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Address < result[b].Address })
+
+	return result, nil
+}
+
+func (s *StorageLocationService) GetByID(ctx context.Context, warehouseID, locationID uuid.UUID) (*dto.StorageLocationResponse, error) {
+	location, err := s.getLocation(ctx, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, addresses, err := s.addresses(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toStorageLocationResponse(location, addresses[locationID])
+	return &result, nil
+}
+
+func (s *StorageLocationService) Create(ctx context.Context, warehouseID, userID uuid.UUID, req dto.StorageLocationCreateRequest) (*dto.StorageLocationResponse, error) {
+	if err := s.checkWarehouse(ctx, warehouseID, true); err != nil {
+		return nil, err
+	}
+
+	if !repository.IsValidLocationType(req.LocationType) {
+		log.Warn().Str("locationType", req.LocationType).Msg("Invalid storage location type")
+		return nil, repository.ErrInvalidLocationType
+	}
+
+	var parentID *uuid.UUID
+	if req.ParentID != nil && *req.ParentID != "" {
+		id, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			log.Warn().Str("parentId", *req.ParentID).Msg("Invalid parent location ID format")
+			return nil, repository.ErrInvalidLocationParent
+		}
+		parentID = &id
+	}
+
+	if parentID == nil {
+		if req.LocationType != repository.LocationTypeZone {
+			log.Warn().Str("locationType", req.LocationType).Msg("Only zones can be created without a parent location")
+			return nil, repository.ErrInvalidLocationParent
+		}
+	} else {
+		parent, err := s.getLocation(ctx, warehouseID, *parentID)
+		if err != nil {
+			if err == repository.ErrStorageLocationNotFound {
+				return nil, repository.ErrInvalidLocationParent
+			}
+			return nil, err
+		}
+		if parent.IsArchived {
+			log.Warn().Str("parentId", parentID.String()).Msg("Parent location is archived")
+			return nil, repository.ErrStorageLocationArchived
+		}
+		if repository.ChildLocationType(parent.LocationType) != req.LocationType {
+			log.Warn().Str("parentType", parent.LocationType).Str("locationType", req.LocationType).Msg("Location type does not fit the parent location")
+			return nil, repository.ErrInvalidLocationParent
+		}
+	}
+
+	location, err := s.repo.Create(ctx, warehouseID, parentID, req.LocationType, req.Code, req.Name, userID)
+	if err != nil {
+		if err != repository.ErrStorageLocationExists {
+			log.Error().Err(err).Str("warehouseId", warehouseID.String()).Str("code", req.Code).Str("userId", userID.String()).Msg("Failed to create storage location")
+		}
+		return nil, err
+	}
+
+	log.Info().Str("locationId", location.LocationID.String()).Str("warehouseId", warehouseID.String()).Str("userId", userID.String()).Msg("Storage location created successfully")
+	result, err := s.GetByID(ctx, warehouseID, location.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityStorageLocation, location.LocationID, repository.AuditActionCreate, nil, result)
+	return result, nil
+}
+
+func (s *StorageLocationService) Update(ctx context.Context, warehouseID, locationID, userID uuid.UUID, req dto.StorageLocationUpdateRequest) (*dto.StorageLocationResponse, error) {
+	before, err := s.GetByID(ctx, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Update(ctx, locationID, req.Code, req.Name, userID); err != nil {
+		if err != repository.ErrStorageLocationExists {
+			log.Error().Err(err).Str("locationId", locationID.String()).Str("userId", userID.String()).Msg("Failed to update storage location")
+		}
+		return nil, err
+	}
+
+	log.Info().Str("locationId", locationID.String()).Str("userId", userID.String()).Msg("Storage location updated successfully")
+	result, err := s.GetByID(ctx, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityStorageLocation, locationID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+// Delete archives the location. Only an empty location without active children can be archived; moves
+// that reference it keep their history. Use Restore to bring it back.
+func (s *StorageLocationService) Delete(ctx context.Context, warehouseID, locationID uuid.UUID) error {
+	before, err := s.GetByID(ctx, warehouseID, locationID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Archive(ctx, locationID); err != nil {
+		if err != repository.ErrLocationHasChildren && err != repository.ErrLocationNotEmpty {
+			log.Error().Err(err).Str("locationId", locationID.String()).Msg("Failed to archive storage location")
+		}
+		return err
+	}
+
+	log.Info().Str("locationId", locationID.String()).Msg("Storage location archived successfully")
+	if after, err := s.GetByID(ctx, warehouseID, locationID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityStorageLocation, locationID, repository.AuditActionDelete, before, after)
+	}
+	return nil
+}
+
+// Restore brings back an archived location; its parent has to be restored first.
+func (s *StorageLocationService) Restore(ctx context.Context, warehouseID, locationID uuid.UUID) (*dto.StorageLocationResponse, error) {
+	location, err := s.getLocation(ctx, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if location.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *location.ParentID)
+		if err != nil {
+			log.Error().Err(err).Str("parentId", location.ParentID.String()).Msg("Failed to get parent location")
+			return nil, err
+		}
+		if parent.IsArchived {
+			log.Warn().Str("locationId", locationID.String()).Msg("Parent location is archived")
+			return nil, repository.ErrStorageLocationArchived
+		}
+	}
+
+	before, err := s.GetByID(ctx, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, locationID); err != nil {
+		log.Error().Err(err).Str("locationId", locationID.String()).Msg("Failed to restore storage location")
+		return nil, err
+	}
+
+	log.Info().Str("locationId", locationID.String()).Msg("Storage location restored successfully")
+	result, err := s.GetByID(ctx, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityStorageLocation, locationID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+// GetStock returns the stock of the warehouse by location; rows without location are the unplaced stock.
+func (s *StorageLocationService) GetStock(ctx context.Context, warehouseID uuid.UUID) ([]dto.LocationStockResponse, error) {
+	if err := s.checkWarehouse(ctx, warehouseID, false); err != nil {
+		return nil, err
+	}
+
+	_, addresses, err := s.addresses(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	stock, err := s.repo.GetStock(ctx, warehouseID)
+	if err != nil {
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to get location stock")
+		return nil, err
+	}
+
+	result := make([]dto.LocationStockResponse, 0, len(stock))
+	for _, item := range stock {
+		row := dto.LocationStockResponse{
+			ProductID: item.ProductID.String(),
+			Article:   item.Article,
+			Quantity:  item.Quantity,
+		}
+		if item.LocationID != nil {
+			locationIDStr := item.LocationID.String()
+			address := addresses[*item.LocationID]
+			row.LocationID = &locationIDStr
+			row.Address = &address
+		}
+		result = append(result, row)
+	}
+	sort.SliceStable(result, func(a, b int) bool {
+		if (result[a].Address == nil) != (result[b].Address == nil) {
+			return result[a].Address == nil
+		}
+		return result[a].Address != nil && *result[a].Address < *result[b].Address
+	})
+
+	return result, nil
+}
+
+// putAwaySuggestion - размещение неразмещенного товара; LocationID пуст, если свободных ячеек не осталось
+type putAwaySuggestion struct {
+	ProductID   uuid.UUID
+	Article     string
+	Quantity    int
+	LocationID  *uuid.UUID
+	LocationQty int
+}
+
+// putAway suggests a bin for the unplaced stock of every product of the warehouse (with orderID only for
+// products received into the warehouse by that supplier order, at most the received quantity). A product
+// goes to the bin already holding most of it; otherwise to the first empty bin by address, one product
+// per empty bin.
+func (s *StorageLocationService) putAway(ctx context.Context, warehouseID uuid.UUID, orderID *uuid.UUID) ([]putAwaySuggestion, map[uuid.UUID]string, error) {
+	if err := s.checkWarehouse(ctx, warehouseID, false); err != nil {
+		return nil, nil, err
+	}
+
+	var received map[uuid.UUID]int
+	if orderID != nil {
+		if _, err := s.supplierOrderRepo.GetByID(ctx, *orderID); err != nil {
+			if err != repository.ErrSupplierOrderNotFound {
+				log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order")
+			}
+			return nil, nil, err
+		}
+		var err error
+		received, err = s.repo.GetReceivedQuantities(ctx, *orderID, warehouseID)
+		if err != nil {
+			log.Error().Err(err).Str("orderId", orderID.String()).Str("warehouseId", warehouseID.String()).Msg("Failed to get received quantities")
+			return nil, nil, err
+		}
+	}
+
+	locations, addresses, err := s.addresses(ctx, warehouseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stock, err := s.repo.GetStock(ctx, warehouseID)
+	if err != nil {
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to get location stock")
+		return nil, nil, err
+	}
+
+	var bins []uuid.UUID
+	isBin := make(map[uuid.UUID]bool)
+	for _, location := range locations {
+		if location.LocationType == repository.LocationTypeBin && !location.IsArchived {
+			bins = append(bins, location.LocationID)
+			isBin[location.LocationID] = true
+		}
+	}
+	sort.Slice(bins, func(a, b int) bool { return addresses[bins[a]] < addresses[bins[b]] })
+
+	occupied := make(map[uuid.UUID]bool)
+	held := make(map[uuid.UUID]repository.LocationStock)
+	for _, item := range stock {
+		if item.LocationID == nil {
+			continue
+		}
+		occupied[*item.LocationID] = true
+		if item.Quantity <= 0 || !isBin[*item.LocationID] {
+			continue
+		}
+		if best, ok := held[item.ProductID]; !ok || item.Quantity > best.Quantity {
+			held[item.ProductID] = item
+		}
+	}
+
+	var suggestions []putAwaySuggestion
+	nextBin := 0
+	for _, item := range stock {
+		if item.LocationID != nil {
+			continue
+		}
+		quantity := item.Quantity
+		if received != nil {
+			quantity = min(quantity, received[item.ProductID])
+		}
+		if quantity <= 0 {
+			continue
+		}
+
+		suggestion := putAwaySuggestion{ProductID: item.ProductID, Article: item.Article, Quantity: quantity}
+		if best, ok := held[item.ProductID]; ok {
+			suggestion.LocationID = best.LocationID
+			suggestion.LocationQty = best.Quantity
+		} else {
+			for nextBin < len(bins) && occupied[bins[nextBin]] {
+				nextBin++
+			}
+			if nextBin < len(bins) {
+				binID := bins[nextBin]
+				suggestion.LocationID = &binID
+				occupied[binID] = true
+			}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, addresses, nil
+}
+
+func (s *StorageLocationService) GetPutAwaySuggestions(ctx context.Context, warehouseID uuid.UUID, orderID *uuid.UUID) ([]dto.PutAwaySuggestionResponse, error) {
+	suggestions, addresses, err := s.putAway(ctx, warehouseID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.PutAwaySuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		row := dto.PutAwaySuggestionResponse{
+			ProductID:   suggestion.ProductID.String(),
+			Article:     suggestion.Article,
+			Quantity:    suggestion.Quantity,
+			LocationQty: suggestion.LocationQty,
+		}
+		if suggestion.LocationID != nil {
+			locationIDStr := suggestion.LocationID.String()
+			address := addresses[*suggestion.LocationID]
+			row.LocationID = &locationIDStr
+			row.Address = &address
+		}
+		result = append(result, row)
+	}
+
+	return result, nil
+}
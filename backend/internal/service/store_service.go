@@ -11,11 +11,12 @@ import (
 )
 
 type StoreService struct {
-	repo *repository.StoreRepository
+	repo  *repository.StoreRepository
+	audit *AuditService
 }
 
-func NewStoreService(repo *repository.StoreRepository) *StoreService {
-	return &StoreService{repo: repo}
+func NewStoreService(repo *repository.StoreRepository, audit *AuditService) *StoreService {
+	return &StoreService{repo: repo, audit: audit}
 }
 
 func (s *StoreService) GetByID(ctx context.Context, storeID uuid.UUID) (*dto.StoreResponse, error) {
@@ -26,13 +27,15 @@ func (s *StoreService) GetByID(ctx context.Context, storeID uuid.UUID) (*dto.Sto
 	}
 
 	return &dto.StoreResponse{
-		StoreID: store.StoreID.String(),
-		Name:    store.Name,
+		StoreID:    store.StoreID.String(),
+		Name:       store.Name,
+		IsArchived: store.IsArchived,
+		ArchivedAt: store.ArchivedAt,
 	}, nil
 }
 
-func (s *StoreService) List(ctx context.Context, limit, offset int) ([]dto.StoreResponse, error) {
-	stores, err := s.repo.List(ctx, limit, offset)
+func (s *StoreService) List(ctx context.Context, limit, offset int, includeArchived bool) ([]dto.StoreResponse, error) {
+	stores, err := s.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to list stores")
 		return nil, err
@@ -41,8 +44,10 @@ func (s *StoreService) List(ctx context.Context, limit, offset int) ([]dto.Store
 	result := make([]dto.StoreResponse, 0, len(stores))
 	for _, store := range stores {
 		result = append(result, dto.StoreResponse{
-			StoreID: store.StoreID.String(),
-			Name:    store.Name,
+			StoreID:    store.StoreID.String(),
+			Name:       store.Name,
+			IsArchived: store.IsArchived,
+			ArchivedAt: store.ArchivedAt,
 		})
 	}
 
@@ -57,13 +62,22 @@ func (s *StoreService) Create(ctx context.Context, req dto.StoreCreateRequest) (
 	}
 
 	log.Info().Str("storeId", store.StoreID.String()).Str("name", store.Name).Msg("Store created successfully")
-	return &dto.StoreResponse{
-		StoreID: store.StoreID.String(),
-		Name:    store.Name,
-	}, nil
+	result := &dto.StoreResponse{
+		StoreID:    store.StoreID.String(),
+		Name:       store.Name,
+		IsArchived: store.IsArchived,
+		ArchivedAt: store.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityStore, store.StoreID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *StoreService) Update(ctx context.Context, storeID uuid.UUID, req dto.StoreUpdateRequest) (*dto.StoreResponse, error) {
+	before, err := s.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
 	store, err := s.repo.Update(ctx, storeID, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("storeId", storeID.String()).Msg("Failed to update store")
@@ -71,19 +85,53 @@ func (s *StoreService) Update(ctx context.Context, storeID uuid.UUID, req dto.St
 	}
 
 	log.Info().Str("storeId", storeID.String()).Msg("Store updated successfully")
-	return &dto.StoreResponse{
-		StoreID: store.StoreID.String(),
-		Name:    store.Name,
-	}, nil
+	result := &dto.StoreResponse{
+		StoreID:    store.StoreID.String(),
+		Name:       store.Name,
+		IsArchived: store.IsArchived,
+		ArchivedAt: store.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityStore, storeID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
+// Delete archives the store: it disappears from lists and cannot be used in new documents, but
+// documents and stock history that reference it stay intact. Use Restore to bring it back.
 func (s *StoreService) Delete(ctx context.Context, storeID uuid.UUID) error {
-	err := s.repo.Delete(ctx, storeID)
+	before, err := s.GetByID(ctx, storeID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Archive(ctx, storeID)
 	if err != nil {
-		log.Error().Err(err).Str("storeId", storeID.String()).Msg("Failed to delete store")
+		log.Error().Err(err).Str("storeId", storeID.String()).Msg("Failed to archive store")
 		return err
 	}
 
-	log.Info().Str("storeId", storeID.String()).Msg("Store deleted successfully")
+	log.Info().Str("storeId", storeID.String()).Msg("Store archived successfully")
+	if after, err := s.GetByID(ctx, storeID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityStore, storeID, repository.AuditActionDelete, before, after)
+	}
 	return nil
 }
+
+func (s *StoreService) Restore(ctx context.Context, storeID uuid.UUID) (*dto.StoreResponse, error) {
+	before, err := s.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, storeID); err != nil {
+		log.Error().Err(err).Str("storeId", storeID.String()).Msg("Failed to restore store")
+		return nil, err
+	}
+
+	log.Info().Str("storeId", storeID.String()).Msg("Store restored successfully")
+	result, err := s.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityStore, storeID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
@@ -13,12 +13,14 @@ import (
 type SupplierOrderDocumentService struct {
 	repo      *repository.SupplierOrderDocumentRepository
 	orderRepo *repository.SupplierOrderRepository
+	audit     *AuditService
 }
 
-func NewSupplierOrderDocumentService(repo *repository.SupplierOrderDocumentRepository, orderRepo *repository.SupplierOrderRepository) *SupplierOrderDocumentService {
+func NewSupplierOrderDocumentService(repo *repository.SupplierOrderDocumentRepository, orderRepo *repository.SupplierOrderRepository, audit *AuditService) *SupplierOrderDocumentService {
 	return &SupplierOrderDocumentService{
 		repo:      repo,
 		orderRepo: orderRepo,
+		audit:     audit,
 	}
 }
 
@@ -82,16 +84,23 @@ func (s *SupplierOrderDocumentService) Create(ctx context.Context, req dto.Suppl
 	}
 
 	log.Info().Str("documentId", doc.DocumentID.String()).Str("orderId", req.OrderID).Str("name", doc.Name).Msg("Supplier order document created successfully")
-	return &dto.SupplierOrderDocumentResponse{
+	result := &dto.SupplierOrderDocumentResponse{
 		DocumentID:  doc.DocumentID.String(),
 		OrderID:     doc.OrderID.String(),
 		Name:        doc.Name,
 		Description: doc.Description,
 		FilePath:    doc.FilePath,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrderDocument, doc.DocumentID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *SupplierOrderDocumentService) Update(ctx context.Context, documentID uuid.UUID, req dto.SupplierOrderDocumentUpdateRequest) (*dto.SupplierOrderDocumentResponse, error) {
+	before, err := s.GetByID(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
 	orderID, err := uuid.Parse(req.OrderID)
 	if err != nil {
 		log.Warn().Str("orderId", req.OrderID).Msg("Invalid order ID format")
@@ -114,22 +123,30 @@ func (s *SupplierOrderDocumentService) Update(ctx context.Context, documentID uu
 	}
 
 	log.Info().Str("documentId", documentID.String()).Msg("Supplier order document updated successfully")
-	return &dto.SupplierOrderDocumentResponse{
+	result := &dto.SupplierOrderDocumentResponse{
 		DocumentID:  doc.DocumentID.String(),
 		OrderID:     doc.OrderID.String(),
 		Name:        doc.Name,
 		Description: doc.Description,
 		FilePath:    doc.FilePath,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrderDocument, documentID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
 func (s *SupplierOrderDocumentService) Delete(ctx context.Context, documentID uuid.UUID) error {
-	err := s.repo.Delete(ctx, documentID)
+	before, err := s.GetByID(ctx, documentID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, documentID)
 	if err != nil {
 		log.Error().Err(err).Str("documentId", documentID.String()).Msg("Failed to delete supplier order document")
 		return err
 	}
 
 	log.Info().Str("documentId", documentID.String()).Msg("Supplier order document deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrderDocument, documentID, repository.AuditActionDelete, before, nil)
 	return nil
 }
@@ -11,69 +11,51 @@ import (
 )
 
 type SupplierOrderItemService struct {
-	repo          *repository.SupplierOrderItemRepository
-	orderRepo     *repository.SupplierOrderRepository
-	productRepo   *repository.ProductRepository
-	warehouseRepo *repository.WarehouseRepository
+	repo            *repository.SupplierOrderItemRepository
+	orderRepo       *repository.SupplierOrderRepository
+	orderStatusRepo *repository.OrderStatusRepository
+	productRepo     *repository.ProductRepository
+	warehouseRepo   *repository.WarehouseRepository
+	landedCost      *LandedCostService
+	audit           *AuditService
 }
 
-func NewSupplierOrderItemService(repo *repository.SupplierOrderItemRepository, orderRepo *repository.SupplierOrderRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository) *SupplierOrderItemService {
+func NewSupplierOrderItemService(repo *repository.SupplierOrderItemRepository, orderRepo *repository.SupplierOrderRepository, orderStatusRepo *repository.OrderStatusRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, landedCost *LandedCostService, audit *AuditService) *SupplierOrderItemService {
 	return &SupplierOrderItemService{
-		repo:          repo,
-		orderRepo:     orderRepo,
-		productRepo:   productRepo,
-		warehouseRepo: warehouseRepo,
+		repo:            repo,
+		orderRepo:       orderRepo,
+		orderStatusRepo: orderStatusRepo,
+		productRepo:     productRepo,
+		warehouseRepo:   warehouseRepo,
+		landedCost:      landedCost,
+		audit:           audit,
 	}
 }
 
-// recalcAndUpdateOrderAggregates aggregates items of an order and persists totals into supplier_orders.
-func (s *SupplierOrderItemService) recalcAndUpdateOrderAggregates(ctx context.Context, orderID, userID uuid.UUID) error {
-	items, err := s.repo.GetByOrderID(ctx, orderID)
+// checkOrderOpen rejects item changes of a received or cancelled order: received quantities, receipt movements,
+// lots and cost periods of such an order are set by Receive and must not change behind it.
+func (s *SupplierOrderItemService) checkOrderOpen(ctx context.Context, order *repository.SupplierOrder) error {
+	if order.StatusID == nil {
+		return nil
+	}
+	status, err := s.orderStatusRepo.GetByID(ctx, *order.StatusID)
 	if err != nil {
-		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to load order items for aggregation")
+		log.Error().Err(err).Str("statusId", order.StatusID.String()).Msg("Failed to get order status")
 		return err
 	}
-
-	positionsQty := len(items)
-	totalQty := 0
-	var totalWeight float64
-	var totalCost float64
-	var totalLogistics float64
-
-	for _, item := range items {
-		totalQty += item.OrderedQty
-		totalWeight += float64(item.TotalWeight)
-		if item.TotalPrice != nil {
-			totalCost += *item.TotalPrice
-		}
-		if item.TotalLogistics != nil {
-			totalLogistics += *item.TotalLogistics
-		}
-	}
-
-	var weightPtr *float64
-	if positionsQty > 0 {
-		weightPtr = &totalWeight
-	}
-
-	var costPtr *float64
-	if positionsQty > 0 {
-		costPtr = &totalCost
-	}
-
-	var logisticsPtr *float64
-	if positionsQty > 0 {
-		logisticsPtr = &totalLogistics
+	if status.Name == repository.OrderStatusReceived || status.Name == repository.OrderStatusCancelled {
+		log.Warn().Str("orderId", order.OrderID.String()).Str("status", status.Name).Msg("Supplier order is closed for item changes")
+		return repository.ErrSupplierOrderClosed
 	}
-
-	if err := s.orderRepo.UpdateAggregates(ctx, orderID, positionsQty, totalQty, weightPtr, costPtr, logisticsPtr, &userID); err != nil {
-		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to update supplier order aggregates")
-		return err
-	}
-
 	return nil
 }
 
+// recalcAndUpdateOrderAggregates reallocates order logistics to items, recomputes their self cost
+// and persists totals into supplier_orders.
+func (s *SupplierOrderItemService) recalcAndUpdateOrderAggregates(ctx context.Context, orderID, userID uuid.UUID) error {
+	return s.landedCost.Recalculate(ctx, orderID, userID)
+}
+
 func (s *SupplierOrderItemService) GetByID(ctx context.Context, itemID uuid.UUID) (*dto.SupplierOrderItemResponse, error) {
 	item, err := s.repo.GetByID(ctx, itemID)
 	if err != nil {
@@ -135,7 +117,7 @@ func (s *SupplierOrderItemService) Create(ctx context.Context, userID uuid.UUID,
 		log.Warn().Str("orderId", req.OrderID).Msg("Invalid order ID format")
 		return nil, repository.ErrSupplierOrderNotFound
 	}
-	_, err = s.orderRepo.GetByID(ctx, orderID)
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		if err == repository.ErrSupplierOrderNotFound {
 			log.Warn().Str("orderId", req.OrderID).Msg("Supplier order not found")
@@ -144,13 +126,16 @@ func (s *SupplierOrderItemService) Create(ctx context.Context, userID uuid.UUID,
 		log.Error().Err(err).Str("orderId", req.OrderID).Msg("Failed to validate supplier order")
 		return nil, err
 	}
+	if err := s.checkOrderOpen(ctx, order); err != nil {
+		return nil, err
+	}
 
 	productID, err := uuid.Parse(req.ProductID)
 	if err != nil {
 		log.Warn().Str("productId", req.ProductID).Msg("Invalid product ID format")
 		return nil, repository.ErrProductNotFound
 	}
-	_, err = s.productRepo.GetByID(ctx, productID)
+	product, err := s.productRepo.GetByID(ctx, productID)
 	if err != nil {
 		if err == repository.ErrProductNotFound {
 			log.Warn().Str("productId", req.ProductID).Msg("Product not found")
@@ -159,13 +144,17 @@ func (s *SupplierOrderItemService) Create(ctx context.Context, userID uuid.UUID,
 		log.Error().Err(err).Str("productId", req.ProductID).Msg("Failed to validate product")
 		return nil, err
 	}
+	if product.IsArchived {
+		log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+		return nil, repository.ErrProductArchived
+	}
 
 	warehouseID, err := uuid.Parse(req.WarehouseID)
 	if err != nil {
 		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
 		return nil, repository.ErrWarehouseNotFound
 	}
-	_, err = s.warehouseRepo.GetByID(ctx, warehouseID)
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
 	if err != nil {
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
@@ -174,10 +163,9 @@ func (s *SupplierOrderItemService) Create(ctx context.Context, userID uuid.UUID,
 		log.Error().Err(err).Str("warehouseId", req.WarehouseID).Msg("Failed to validate warehouse")
 		return nil, err
 	}
-
-	if req.ReceivedQty > req.OrderedQty {
-		log.Warn().Int("orderedQty", req.OrderedQty).Int("receivedQty", req.ReceivedQty).Msg("Received quantity cannot exceed ordered quantity")
-		return nil, repository.ErrInvalidQuantity
+	if warehouse.IsArchived {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
 	}
 
 	item, err := s.repo.Create(ctx,
@@ -185,14 +173,14 @@ func (s *SupplierOrderItemService) Create(ctx context.Context, userID uuid.UUID,
 		productID,
 		warehouseID,
 		req.OrderedQty,
-		req.ReceivedQty,
+		0,
 		req.TotalWeight,
 		req.PurchasePrice,
 		req.TotalPrice,
-		req.TotalLogistics,
-		req.UnitLogistics,
-		req.UnitSelfCost,
-		req.TotalSelfCost,
+		nil,
+		nil,
+		nil,
+		nil,
 		req.FulfillmentCost,
 	)
 	if err != nil {
@@ -203,9 +191,12 @@ func (s *SupplierOrderItemService) Create(ctx context.Context, userID uuid.UUID,
 	if aggErr := s.recalcAndUpdateOrderAggregates(ctx, orderID, userID); aggErr != nil {
 		log.Error().Err(aggErr).Str("orderId", req.OrderID).Msg("Failed to recalc aggregates after item create")
 	}
+	if reloaded, err := s.repo.GetByID(ctx, item.OrderItemID); err == nil {
+		item = reloaded
+	}
 
 	log.Info().Str("orderItemId", item.OrderItemID.String()).Str("orderId", req.OrderID).Str("productId", req.ProductID).Str("userId", userID.String()).Msg("Supplier order item created successfully")
-	return &dto.SupplierOrderItemResponse{
+	result := &dto.SupplierOrderItemResponse{
 		OrderItemID:     item.OrderItemID.String(),
 		OrderID:         item.OrderID.String(),
 		ProductID:       item.ProductID.String(),
@@ -220,16 +211,23 @@ func (s *SupplierOrderItemService) Create(ctx context.Context, userID uuid.UUID,
 		UnitSelfCost:    item.UnitSelfCost,
 		TotalSelfCost:   item.TotalSelfCost,
 		FulfillmentCost: item.FulfillmentCost,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrderItem, item.OrderItemID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *SupplierOrderItemService) Update(ctx context.Context, itemID, userID uuid.UUID, req dto.SupplierOrderItemUpdateRequest) (*dto.SupplierOrderItemResponse, error) {
+	before, err := s.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
 	orderID, err := uuid.Parse(req.OrderID)
 	if err != nil {
 		log.Warn().Str("orderId", req.OrderID).Msg("Invalid order ID format")
 		return nil, repository.ErrSupplierOrderNotFound
 	}
-	_, err = s.orderRepo.GetByID(ctx, orderID)
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		if err == repository.ErrSupplierOrderNotFound {
 			log.Warn().Str("orderId", req.OrderID).Msg("Supplier order not found")
@@ -238,13 +236,26 @@ func (s *SupplierOrderItemService) Update(ctx context.Context, itemID, userID uu
 		log.Error().Err(err).Str("orderId", req.OrderID).Msg("Failed to validate supplier order")
 		return nil, err
 	}
+	if err := s.checkOrderOpen(ctx, order); err != nil {
+		return nil, err
+	}
+	if before.OrderID != orderID.String() {
+		current, err := s.orderRepo.GetByID(ctx, uuid.MustParse(before.OrderID))
+		if err != nil {
+			log.Error().Err(err).Str("orderId", before.OrderID).Msg("Failed to get supplier order of the item")
+			return nil, err
+		}
+		if err := s.checkOrderOpen(ctx, current); err != nil {
+			return nil, err
+		}
+	}
 
 	productID, err := uuid.Parse(req.ProductID)
 	if err != nil {
 		log.Warn().Str("productId", req.ProductID).Msg("Invalid product ID format")
 		return nil, repository.ErrProductNotFound
 	}
-	_, err = s.productRepo.GetByID(ctx, productID)
+	product, err := s.productRepo.GetByID(ctx, productID)
 	if err != nil {
 		if err == repository.ErrProductNotFound {
 			log.Warn().Str("productId", req.ProductID).Msg("Product not found")
@@ -253,13 +264,17 @@ func (s *SupplierOrderItemService) Update(ctx context.Context, itemID, userID uu
 		log.Error().Err(err).Str("productId", req.ProductID).Msg("Failed to validate product")
 		return nil, err
 	}
+	if product.IsArchived && before.ProductID != productID.String() {
+		log.Warn().Str("productId", req.ProductID).Msg("Product is archived")
+		return nil, repository.ErrProductArchived
+	}
 
 	warehouseID, err := uuid.Parse(req.WarehouseID)
 	if err != nil {
 		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Invalid warehouse ID format")
 		return nil, repository.ErrWarehouseNotFound
 	}
-	_, err = s.warehouseRepo.GetByID(ctx, warehouseID)
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
 	if err != nil {
 		if err == repository.ErrWarehouseNotFound {
 			log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse not found")
@@ -268,10 +283,9 @@ func (s *SupplierOrderItemService) Update(ctx context.Context, itemID, userID uu
 		log.Error().Err(err).Str("warehouseId", req.WarehouseID).Msg("Failed to validate warehouse")
 		return nil, err
 	}
-
-	if req.ReceivedQty > req.OrderedQty {
-		log.Warn().Int("orderedQty", req.OrderedQty).Int("receivedQty", req.ReceivedQty).Msg("Received quantity cannot exceed ordered quantity")
-		return nil, repository.ErrInvalidQuantity
+	if warehouse.IsArchived && before.WarehouseID != warehouseID.String() {
+		log.Warn().Str("warehouseId", req.WarehouseID).Msg("Warehouse is archived")
+		return nil, repository.ErrWarehouseArchived
 	}
 
 	item, err := s.repo.Update(ctx, itemID,
@@ -279,14 +293,14 @@ func (s *SupplierOrderItemService) Update(ctx context.Context, itemID, userID uu
 		productID,
 		warehouseID,
 		req.OrderedQty,
-		req.ReceivedQty,
+		before.ReceivedQty,
 		req.TotalWeight,
 		req.PurchasePrice,
 		req.TotalPrice,
-		req.TotalLogistics,
-		req.UnitLogistics,
-		req.UnitSelfCost,
-		req.TotalSelfCost,
+		nil,
+		nil,
+		nil,
+		nil,
 		req.FulfillmentCost,
 	)
 	if err != nil {
@@ -297,9 +311,12 @@ func (s *SupplierOrderItemService) Update(ctx context.Context, itemID, userID uu
 	if aggErr := s.recalcAndUpdateOrderAggregates(ctx, orderID, userID); aggErr != nil {
 		log.Error().Err(aggErr).Str("orderId", req.OrderID).Msg("Failed to recalc aggregates after item update")
 	}
+	if reloaded, err := s.repo.GetByID(ctx, item.OrderItemID); err == nil {
+		item = reloaded
+	}
 
 	log.Info().Str("itemId", itemID.String()).Str("userId", userID.String()).Msg("Supplier order item updated successfully")
-	return &dto.SupplierOrderItemResponse{
+	result := &dto.SupplierOrderItemResponse{
 		OrderItemID:     item.OrderItemID.String(),
 		OrderID:         item.OrderID.String(),
 		ProductID:       item.ProductID.String(),
@@ -314,7 +331,9 @@ func (s *SupplierOrderItemService) Update(ctx context.Context, itemID, userID uu
 		UnitSelfCost:    item.UnitSelfCost,
 		TotalSelfCost:   item.TotalSelfCost,
 		FulfillmentCost: item.FulfillmentCost,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrderItem, itemID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
 func (s *SupplierOrderItemService) Delete(ctx context.Context, itemID, userID uuid.UUID) error {
@@ -323,6 +342,18 @@ func (s *SupplierOrderItemService) Delete(ctx context.Context, itemID, userID uu
 		log.Error().Err(err).Str("itemId", itemID.String()).Msg("Failed to load supplier order item before deletion")
 		return err
 	}
+	order, err := s.orderRepo.GetByID(ctx, item.OrderID)
+	if err != nil {
+		log.Error().Err(err).Str("orderId", item.OrderID.String()).Msg("Failed to get supplier order of the item")
+		return err
+	}
+	if err := s.checkOrderOpen(ctx, order); err != nil {
+		return err
+	}
+	before, err := s.GetByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
 
 	err = s.repo.Delete(ctx, itemID)
 	if err != nil {
@@ -335,5 +366,6 @@ func (s *SupplierOrderItemService) Delete(ctx context.Context, itemID, userID uu
 	}
 
 	log.Info().Str("itemId", itemID.String()).Msg("Supplier order item deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrderItem, itemID, repository.AuditActionDelete, before, nil)
 	return nil
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"warehouse-backend/internal/dto"
@@ -13,15 +15,44 @@ import (
 type SupplierOrderService struct {
 	repo            *repository.SupplierOrderRepository
 	orderStatusRepo *repository.OrderStatusRepository
+	receiptRepo     *repository.SupplierOrderReceiptRepository
+	stockLevelRepo  *repository.StockLevelRepository
+	landedCost      *LandedCostService
+	transitions     *StatusTransitionService
+	hooks           map[string][]StatusHook
+	audit           *AuditService
 }
 
-func NewSupplierOrderService(repo *repository.SupplierOrderRepository, orderStatusRepo *repository.OrderStatusRepository) *SupplierOrderService {
+func NewSupplierOrderService(repo *repository.SupplierOrderRepository, orderStatusRepo *repository.OrderStatusRepository, receiptRepo *repository.SupplierOrderReceiptRepository, stockLevelRepo *repository.StockLevelRepository, landedCost *LandedCostService, transitions *StatusTransitionService, audit *AuditService) *SupplierOrderService {
 	return &SupplierOrderService{
 		repo:            repo,
 		orderStatusRepo: orderStatusRepo,
+		receiptRepo:     receiptRepo,
+		stockLevelRepo:  stockLevelRepo,
+		landedCost:      landedCost,
+		transitions:     transitions,
+		hooks:           make(map[string][]StatusHook),
+		audit:           audit,
 	}
 }
 
+// OnStatus registers a hook that runs after an order is moved to the given status.
+func (s *SupplierOrderService) OnStatus(status string, hook StatusHook) {
+	s.hooks[status] = append(s.hooks[status], hook)
+}
+
+func (s *SupplierOrderService) statusName(ctx context.Context, statusID *uuid.UUID) (*string, error) {
+	if statusID == nil {
+		return nil, nil
+	}
+	status, err := s.orderStatusRepo.GetByID(ctx, *statusID)
+	if err != nil {
+		log.Error().Err(err).Str("statusId", statusID.String()).Msg("Failed to get order status")
+		return nil, err
+	}
+	return &status.Name, nil
+}
+
 func (s *SupplierOrderService) GetByID(ctx context.Context, orderID uuid.UUID) (*dto.SupplierOrderResponse, error) {
 	order, err := s.repo.GetByID(ctx, orderID)
 	if err != nil {
@@ -51,26 +82,27 @@ func (s *SupplierOrderService) GetByID(ctx context.Context, orderID uuid.UUID) (
 	}
 
 	return &dto.SupplierOrderResponse{
-		OrderID:             order.OrderID.String(),
-		OrderNumber:         order.OrderNumber,
-		Buyer:               order.Buyer,
-		StatusID:            statusIDStr,
-		PurchaseDate:        order.PurchaseDate,
-		PlannedReceiptDate:  order.PlannedReceiptDate,
-		ActualReceiptDate:   order.ActualReceiptDate,
-		LogisticsChinaMsk:   order.LogisticsChinaMsk,
-		LogisticsMskKzn:     order.LogisticsMskKzn,
-		LogisticsAdditional: order.LogisticsAdditional,
-		LogisticsTotal:      order.LogisticsTotal,
-		OrderItemCost:       order.OrderItemCost,
-		PositionsQty:        order.PositionsQty,
-		TotalQty:            order.TotalQty,
-		OrderItemWeight:     order.OrderItemWeight,
-		ParentOrderID:       parentOrderIDStr,
-		CreatedBy:           createdByStr,
-		CreatedAt:           order.CreatedAt,
-		UpdatedBy:           updatedByStr,
-		UpdatedAt:           order.UpdatedAt,
+		OrderID:                   order.OrderID.String(),
+		OrderNumber:               order.OrderNumber,
+		Buyer:                     order.Buyer,
+		StatusID:                  statusIDStr,
+		PurchaseDate:              order.PurchaseDate,
+		PlannedReceiptDate:        order.PlannedReceiptDate,
+		ActualReceiptDate:         order.ActualReceiptDate,
+		LogisticsChinaMsk:         order.LogisticsChinaMsk,
+		LogisticsMskKzn:           order.LogisticsMskKzn,
+		LogisticsAdditional:       order.LogisticsAdditional,
+		LogisticsTotal:            order.LogisticsTotal,
+		OrderItemCost:             order.OrderItemCost,
+		PositionsQty:              order.PositionsQty,
+		TotalQty:                  order.TotalQty,
+		OrderItemWeight:           order.OrderItemWeight,
+		LogisticsAllocationMethod: order.LogisticsAllocationMethod,
+		ParentOrderID:             parentOrderIDStr,
+		CreatedBy:                 createdByStr,
+		CreatedAt:                 order.CreatedAt,
+		UpdatedBy:                 updatedByStr,
+		UpdatedAt:                 order.UpdatedAt,
 	}, nil
 }
 
@@ -105,26 +137,27 @@ func (s *SupplierOrderService) List(ctx context.Context, limit, offset int, stat
 		}
 
 		result = append(result, dto.SupplierOrderResponse{
-			OrderID:             order.OrderID.String(),
-			OrderNumber:         order.OrderNumber,
-			Buyer:               order.Buyer,
-			StatusID:            statusIDStr,
-			PurchaseDate:        order.PurchaseDate,
-			PlannedReceiptDate:  order.PlannedReceiptDate,
-			ActualReceiptDate:   order.ActualReceiptDate,
-			LogisticsChinaMsk:   order.LogisticsChinaMsk,
-			LogisticsMskKzn:     order.LogisticsMskKzn,
-			LogisticsAdditional: order.LogisticsAdditional,
-			LogisticsTotal:      order.LogisticsTotal,
-			OrderItemCost:       order.OrderItemCost,
-			PositionsQty:        order.PositionsQty,
-			TotalQty:            order.TotalQty,
-			OrderItemWeight:     order.OrderItemWeight,
-			ParentOrderID:       parentOrderIDStr,
-			CreatedBy:           createdByStr,
-			CreatedAt:           order.CreatedAt,
-			UpdatedBy:           updatedByStr,
-			UpdatedAt:           order.UpdatedAt,
+			OrderID:                   order.OrderID.String(),
+			OrderNumber:               order.OrderNumber,
+			Buyer:                     order.Buyer,
+			StatusID:                  statusIDStr,
+			PurchaseDate:              order.PurchaseDate,
+			PlannedReceiptDate:        order.PlannedReceiptDate,
+			ActualReceiptDate:         order.ActualReceiptDate,
+			LogisticsChinaMsk:         order.LogisticsChinaMsk,
+			LogisticsMskKzn:           order.LogisticsMskKzn,
+			LogisticsAdditional:       order.LogisticsAdditional,
+			LogisticsTotal:            order.LogisticsTotal,
+			OrderItemCost:             order.OrderItemCost,
+			PositionsQty:              order.PositionsQty,
+			TotalQty:                  order.TotalQty,
+			OrderItemWeight:           order.OrderItemWeight,
+			LogisticsAllocationMethod: order.LogisticsAllocationMethod,
+			ParentOrderID:             parentOrderIDStr,
+			CreatedBy:                 createdByStr,
+			CreatedAt:                 order.CreatedAt,
+			UpdatedBy:                 updatedByStr,
+			UpdatedAt:                 order.UpdatedAt,
 		})
 	}
 
@@ -133,6 +166,7 @@ func (s *SupplierOrderService) List(ctx context.Context, limit, offset int, stat
 
 func (s *SupplierOrderService) Create(ctx context.Context, userID uuid.UUID, req dto.SupplierOrderCreateRequest) (*dto.SupplierOrderResponse, error) {
 	var statusID *uuid.UUID
+	var status *repository.OrderStatus
 	if req.StatusID != nil && *req.StatusID != "" {
 		id, err := uuid.Parse(*req.StatusID)
 		if err != nil {
@@ -141,7 +175,7 @@ func (s *SupplierOrderService) Create(ctx context.Context, userID uuid.UUID, req
 		}
 		statusID = &id
 
-		_, err = s.orderStatusRepo.GetByID(ctx, id)
+		status, err = s.orderStatusRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrOrderStatusNotFound {
 				log.Warn().Str("statusId", *req.StatusID).Msg("Order status not found")
@@ -150,6 +184,13 @@ func (s *SupplierOrderService) Create(ctx context.Context, userID uuid.UUID, req
 			log.Error().Err(err).Str("statusId", *req.StatusID).Msg("Failed to validate order status")
 			return nil, err
 		}
+		if status.IsArchived {
+			log.Warn().Str("statusId", *req.StatusID).Msg("Order status is archived")
+			return nil, repository.ErrOrderStatusArchived
+		}
+		if err := s.transitions.Check(ctx, repository.StatusEntitySupplierOrder, nil, status.Name); err != nil {
+			return nil, err
+		}
 	}
 
 	var parentOrderID *uuid.UUID
@@ -179,20 +220,23 @@ func (s *SupplierOrderService) Create(ctx context.Context, userID uuid.UUID, req
 		}
 	}
 
-	if req.ActualReceiptDate != nil && req.PlannedReceiptDate != nil {
-		if req.ActualReceiptDate.Before(*req.PlannedReceiptDate) {
-			log.Warn().Time("plannedReceiptDate", *req.PlannedReceiptDate).Time("actualReceiptDate", *req.ActualReceiptDate).Msg("Actual receipt date must be after planned receipt date")
-			return nil, repository.ErrInvalidDateRange
-		}
+	allocationMethod := repository.AllocationByWeight
+	if req.LogisticsAllocationMethod != nil && *req.LogisticsAllocationMethod != "" {
+		allocationMethod = *req.LogisticsAllocationMethod
+	}
+	if !repository.IsValidAllocationMethod(allocationMethod) {
+		log.Warn().Str("logisticsAllocationMethod", allocationMethod).Msg("Invalid logistics allocation method")
+		return nil, repository.ErrInvalidAllocationMethod
 	}
 
+	// Фактическая дата поступления ставится только приёмкой (Receive)
 	order, err := s.repo.Create(ctx,
 		req.OrderNumber,
 		req.Buyer,
 		statusID,
 		req.PurchaseDate,
 		req.PlannedReceiptDate,
-		req.ActualReceiptDate,
+		nil,
 		req.LogisticsChinaMsk,
 		req.LogisticsMskKzn,
 		req.LogisticsAdditional,
@@ -201,6 +245,7 @@ func (s *SupplierOrderService) Create(ctx context.Context, userID uuid.UUID, req
 		req.OrderItemWeight,
 		req.PositionsQty,
 		req.TotalQty,
+		allocationMethod,
 		parentOrderID,
 		&userID,
 	)
@@ -209,6 +254,10 @@ func (s *SupplierOrderService) Create(ctx context.Context, userID uuid.UUID, req
 		return nil, err
 	}
 
+	if status != nil {
+		s.transitions.Record(ctx, repository.StatusEntitySupplierOrder, order.OrderID, nil, status.Name, nil, userID)
+	}
+
 	var statusIDStr *string
 	if order.StatusID != nil {
 		str := order.StatusID.String()
@@ -231,41 +280,59 @@ func (s *SupplierOrderService) Create(ctx context.Context, userID uuid.UUID, req
 	}
 
 	log.Info().Str("orderId", order.OrderID.String()).Str("orderNumber", order.OrderNumber).Str("userId", userID.String()).Msg("Supplier order created successfully")
-	return &dto.SupplierOrderResponse{
-		OrderID:             order.OrderID.String(),
-		OrderNumber:         order.OrderNumber,
-		Buyer:               order.Buyer,
-		StatusID:            statusIDStr,
-		PurchaseDate:        order.PurchaseDate,
-		PlannedReceiptDate:  order.PlannedReceiptDate,
-		ActualReceiptDate:   order.ActualReceiptDate,
-		LogisticsChinaMsk:   order.LogisticsChinaMsk,
-		LogisticsMskKzn:     order.LogisticsMskKzn,
-		LogisticsAdditional: order.LogisticsAdditional,
-		LogisticsTotal:      order.LogisticsTotal,
-		OrderItemCost:       order.OrderItemCost,
-		PositionsQty:        order.PositionsQty,
-		TotalQty:            order.TotalQty,
-		OrderItemWeight:     order.OrderItemWeight,
-		ParentOrderID:       parentOrderIDStr,
-		CreatedBy:           createdByStr,
-		CreatedAt:           order.CreatedAt,
-		UpdatedBy:           updatedByStr,
-		UpdatedAt:           order.UpdatedAt,
-	}, nil
+	result := &dto.SupplierOrderResponse{
+		OrderID:                   order.OrderID.String(),
+		OrderNumber:               order.OrderNumber,
+		Buyer:                     order.Buyer,
+		StatusID:                  statusIDStr,
+		PurchaseDate:              order.PurchaseDate,
+		PlannedReceiptDate:        order.PlannedReceiptDate,
+		ActualReceiptDate:         order.ActualReceiptDate,
+		LogisticsChinaMsk:         order.LogisticsChinaMsk,
+		LogisticsMskKzn:           order.LogisticsMskKzn,
+		LogisticsAdditional:       order.LogisticsAdditional,
+		LogisticsTotal:            order.LogisticsTotal,
+		OrderItemCost:             order.OrderItemCost,
+		PositionsQty:              order.PositionsQty,
+		TotalQty:                  order.TotalQty,
+		OrderItemWeight:           order.OrderItemWeight,
+		LogisticsAllocationMethod: order.LogisticsAllocationMethod,
+		ParentOrderID:             parentOrderIDStr,
+		CreatedBy:                 createdByStr,
+		CreatedAt:                 order.CreatedAt,
+		UpdatedBy:                 updatedByStr,
+		UpdatedAt:                 order.UpdatedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrder, order.OrderID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *SupplierOrderService) Update(ctx context.Context, orderID, userID uuid.UUID, req dto.SupplierOrderUpdateRequest) (*dto.SupplierOrderResponse, error) {
-	var statusID *uuid.UUID
+	before, err := s.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		if err != repository.ErrSupplierOrderNotFound {
+			log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order")
+		}
+		return nil, err
+	}
+
+	// Без statusId статус сохраняется; смена статуса проверяется по разрешенным переходам
+	statusID := current.StatusID
+	var newStatus *repository.OrderStatus
+	var fromStatus *string
 	if req.StatusID != nil && *req.StatusID != "" {
 		id, err := uuid.Parse(*req.StatusID)
 		if err != nil {
 			log.Warn().Str("statusId", *req.StatusID).Msg("Invalid status ID format")
 			return nil, repository.ErrOrderStatusNotFound
 		}
-		statusID = &id
 
-		_, err = s.orderStatusRepo.GetByID(ctx, id)
+		status, err := s.orderStatusRepo.GetByID(ctx, id)
 		if err != nil {
 			if err == repository.ErrOrderStatusNotFound {
 				log.Warn().Str("statusId", *req.StatusID).Msg("Order status not found")
@@ -274,6 +341,26 @@ func (s *SupplierOrderService) Update(ctx context.Context, orderID, userID uuid.
 			log.Error().Err(err).Str("statusId", *req.StatusID).Msg("Failed to validate order status")
 			return nil, err
 		}
+
+		if current.StatusID == nil || *current.StatusID != id {
+			if status.IsArchived {
+				log.Warn().Str("statusId", *req.StatusID).Msg("Order status is archived")
+				return nil, repository.ErrOrderStatusArchived
+			}
+			if status.Name == repository.OrderStatusReceived {
+				log.Warn().Str("orderId", orderID.String()).Msg("Order status can be set to received only by receiving the order")
+				return nil, repository.ErrReceiptRequired
+			}
+			fromStatus, err = s.statusName(ctx, current.StatusID)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.transitions.Check(ctx, repository.StatusEntitySupplierOrder, fromStatus, status.Name); err != nil {
+				return nil, err
+			}
+			newStatus = status
+		}
+		statusID = &id
 	}
 
 	var parentOrderID *uuid.UUID
@@ -299,6 +386,16 @@ func (s *SupplierOrderService) Update(ctx context.Context, orderID, userID uuid.
 			log.Error().Err(err).Str("parentOrderId", *req.ParentOrderID).Msg("Failed to validate parent order")
 			return nil, err
 		}
+
+		isDescendant, err := s.repo.IsDescendant(ctx, orderID, id)
+		if err != nil {
+			log.Error().Err(err).Str("orderId", orderID.String()).Str("parentOrderId", *req.ParentOrderID).Msg("Failed to check order tree")
+			return nil, err
+		}
+		if isDescendant {
+			log.Warn().Str("orderId", orderID.String()).Str("parentOrderId", *req.ParentOrderID).Msg("Parent order is a descendant of the order")
+			return nil, repository.ErrInvalidParentOrder
+		}
 	}
 
 	if req.PlannedReceiptDate != nil && req.PurchaseDate != nil {
@@ -308,11 +405,13 @@ func (s *SupplierOrderService) Update(ctx context.Context, orderID, userID uuid.
 		}
 	}
 
-	if req.ActualReceiptDate != nil && req.PlannedReceiptDate != nil {
-		if req.ActualReceiptDate.Before(*req.PlannedReceiptDate) {
-			log.Warn().Time("plannedReceiptDate", *req.PlannedReceiptDate).Time("actualReceiptDate", *req.ActualReceiptDate).Msg("Actual receipt date must be after planned receipt date")
-			return nil, repository.ErrInvalidDateRange
-		}
+	allocationMethod := current.LogisticsAllocationMethod
+	if req.LogisticsAllocationMethod != nil && *req.LogisticsAllocationMethod != "" {
+		allocationMethod = *req.LogisticsAllocationMethod
+	}
+	if !repository.IsValidAllocationMethod(allocationMethod) {
+		log.Warn().Str("logisticsAllocationMethod", allocationMethod).Msg("Invalid logistics allocation method")
+		return nil, repository.ErrInvalidAllocationMethod
 	}
 
 	order, err := s.repo.Update(ctx, orderID,
@@ -321,7 +420,7 @@ func (s *SupplierOrderService) Update(ctx context.Context, orderID, userID uuid.
 		statusID,
 		req.PurchaseDate,
 		req.PlannedReceiptDate,
-		req.ActualReceiptDate,
+		current.ActualReceiptDate,
 		req.LogisticsChinaMsk,
 		req.LogisticsMskKzn,
 		req.LogisticsAdditional,
@@ -330,6 +429,7 @@ func (s *SupplierOrderService) Update(ctx context.Context, orderID, userID uuid.
 		req.OrderItemWeight,
 		req.PositionsQty,
 		req.TotalQty,
+		allocationMethod,
 		parentOrderID,
 		&userID,
 	)
@@ -359,38 +459,530 @@ func (s *SupplierOrderService) Update(ctx context.Context, orderID, userID uuid.
 		updatedByStr = &str
 	}
 
+	if newStatus != nil {
+		s.transitions.Record(ctx, repository.StatusEntitySupplierOrder, orderID, fromStatus, newStatus.Name, nil, userID)
+		runStatusHooks(ctx, s.hooks, repository.StatusEntitySupplierOrder, newStatus.Name, orderID, userID)
+	}
+
+	if err := s.landedCost.Recalculate(ctx, orderID, userID); err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to reallocate costs after order update")
+	} else if reloaded, err := s.repo.GetByID(ctx, orderID); err == nil {
+		order = reloaded
+	}
+
 	log.Info().Str("orderId", orderID.String()).Str("userId", userID.String()).Msg("Supplier order updated successfully")
-	return &dto.SupplierOrderResponse{
-		OrderID:             order.OrderID.String(),
-		OrderNumber:         order.OrderNumber,
-		Buyer:               order.Buyer,
-		StatusID:            statusIDStr,
-		PurchaseDate:        order.PurchaseDate,
-		PlannedReceiptDate:  order.PlannedReceiptDate,
-		ActualReceiptDate:   order.ActualReceiptDate,
-		LogisticsChinaMsk:   order.LogisticsChinaMsk,
-		LogisticsMskKzn:     order.LogisticsMskKzn,
-		LogisticsAdditional: order.LogisticsAdditional,
-		LogisticsTotal:      order.LogisticsTotal,
-		OrderItemCost:       order.OrderItemCost,
-		PositionsQty:        order.PositionsQty,
-		TotalQty:            order.TotalQty,
-		OrderItemWeight:     order.OrderItemWeight,
-		ParentOrderID:       parentOrderIDStr,
-		CreatedBy:           createdByStr,
-		CreatedAt:           order.CreatedAt,
-		UpdatedBy:           updatedByStr,
-		UpdatedAt:           order.UpdatedAt,
-	}, nil
+	result := &dto.SupplierOrderResponse{
+		OrderID:                   order.OrderID.String(),
+		OrderNumber:               order.OrderNumber,
+		Buyer:                     order.Buyer,
+		StatusID:                  statusIDStr,
+		PurchaseDate:              order.PurchaseDate,
+		PlannedReceiptDate:        order.PlannedReceiptDate,
+		ActualReceiptDate:         order.ActualReceiptDate,
+		LogisticsChinaMsk:         order.LogisticsChinaMsk,
+		LogisticsMskKzn:           order.LogisticsMskKzn,
+		LogisticsAdditional:       order.LogisticsAdditional,
+		LogisticsTotal:            order.LogisticsTotal,
+		OrderItemCost:             order.OrderItemCost,
+		PositionsQty:              order.PositionsQty,
+		TotalQty:                  order.TotalQty,
+		OrderItemWeight:           order.OrderItemWeight,
+		LogisticsAllocationMethod: order.LogisticsAllocationMethod,
+		ParentOrderID:             parentOrderIDStr,
+		CreatedBy:                 createdByStr,
+		CreatedAt:                 order.CreatedAt,
+		UpdatedBy:                 updatedByStr,
+		UpdatedAt:                 order.UpdatedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrder, orderID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+// Transition moves the order to another status if the move is allowed by status_transitions and runs
+// the hooks registered for the new status. "Получен" is reached only through Receive.
+func (s *SupplierOrderService) Transition(ctx context.Context, orderID, userID uuid.UUID, req dto.StatusTransitionRequest) (*dto.SupplierOrderResponse, error) {
+	toStatusID, err := uuid.Parse(req.StatusID)
+	if err != nil {
+		log.Warn().Str("statusId", req.StatusID).Msg("Invalid status ID format")
+		return nil, repository.ErrOrderStatusNotFound
+	}
+	toStatus, err := s.orderStatusRepo.GetByID(ctx, toStatusID)
+	if err != nil {
+		if err != repository.ErrOrderStatusNotFound {
+			log.Error().Err(err).Str("statusId", req.StatusID).Msg("Failed to validate order status")
+		}
+		return nil, err
+	}
+	if toStatus.Name == repository.OrderStatusReceived {
+		log.Warn().Str("orderId", orderID.String()).Msg("Order status can be set to received only by receiving the order")
+		return nil, repository.ErrReceiptRequired
+	}
+
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		if err != repository.ErrSupplierOrderNotFound {
+			log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order for status transition")
+		}
+		return nil, err
+	}
+	if order.StatusID != nil && *order.StatusID == toStatusID {
+		return s.GetByID(ctx, orderID)
+	}
+	if toStatus.IsArchived {
+		log.Warn().Str("statusId", req.StatusID).Msg("Order status is archived")
+		return nil, repository.ErrOrderStatusArchived
+	}
+
+	fromStatus, err := s.statusName(ctx, order.StatusID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntitySupplierOrder, fromStatus, toStatus.Name); err != nil {
+		return nil, err
+	}
+
+	before, err := s.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, orderID, order.StatusID, toStatusID, userID); err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Str("status", toStatus.Name).Msg("Failed to change supplier order status")
+		return nil, err
+	}
+
+	s.transitions.Record(ctx, repository.StatusEntitySupplierOrder, orderID, fromStatus, toStatus.Name, req.Comment, userID)
+	runStatusHooks(ctx, s.hooks, repository.StatusEntitySupplierOrder, toStatus.Name, orderID, userID)
+
+	log.Info().Str("orderId", orderID.String()).Str("status", toStatus.Name).Str("userId", userID.String()).Msg("Supplier order status changed")
+	result, err := s.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrder, orderID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+func (s *SupplierOrderService) History(ctx context.Context, orderID uuid.UUID) ([]dto.StatusHistoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, orderID); err != nil {
+		if err != repository.ErrSupplierOrderNotFound {
+			log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order for status history")
+		}
+		return nil, err
+	}
+	return s.transitions.History(ctx, repository.StatusEntitySupplierOrder, orderID)
 }
 
 func (s *SupplierOrderService) Delete(ctx context.Context, orderID uuid.UUID) error {
-	err := s.repo.Delete(ctx, orderID)
+	before, err := s.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, orderID)
 	if err != nil {
 		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to delete supplier order")
 		return err
 	}
 
 	log.Info().Str("orderId", orderID.String()).Msg("Supplier order deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrder, orderID, repository.AuditActionDelete, before, nil)
 	return nil
 }
+
+// CreateFromReorderSuggestions creates a draft order with one item per reorder suggestion (optionally only
+// for the given warehouse and products): suggested quantity at the product's purchase price.
+func (s *SupplierOrderService) CreateFromReorderSuggestions(ctx context.Context, userID uuid.UUID, req dto.ReorderOrderCreateRequest) (*dto.SupplierOrderResponse, error) {
+	var warehouseID *uuid.UUID
+	if req.WarehouseID != nil && *req.WarehouseID != "" {
+		id, err := uuid.Parse(*req.WarehouseID)
+		if err != nil {
+			log.Warn().Str("warehouseId", *req.WarehouseID).Msg("Invalid warehouse ID format")
+			return nil, repository.ErrWarehouseNotFound
+		}
+		warehouseID = &id
+	}
+
+	productIDs := make(map[uuid.UUID]bool, len(req.ProductIDs))
+	for _, v := range req.ProductIDs {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			log.Warn().Str("productId", v).Msg("Invalid product ID format")
+			return nil, repository.ErrProductNotFound
+		}
+		productIDs[id] = true
+	}
+
+	suggestions, err := s.stockLevelRepo.GetReorderSuggestions(ctx, warehouseID, nil)
+	if err != nil {
+		log.Error().Err(err).Interface("warehouseId", warehouseID).Msg("Failed to get reorder suggestions")
+		return nil, err
+	}
+
+	items := make([]repository.SupplierOrderDraftItem, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if len(productIDs) > 0 && !productIDs[suggestion.ProductID] {
+			continue
+		}
+		items = append(items, repository.SupplierOrderDraftItem{
+			ProductID:     suggestion.ProductID,
+			WarehouseID:   suggestion.WarehouseID,
+			OrderedQty:    suggestion.SuggestedQty,
+			PurchasePrice: suggestion.PurchasePrice,
+			TotalWeight:   suggestion.UnitWeight * suggestion.SuggestedQty,
+		})
+	}
+	if len(items) == 0 {
+		log.Warn().Interface("warehouseId", warehouseID).Strs("productIds", req.ProductIDs).Msg("Nothing to reorder")
+		return nil, repository.ErrNoReorderSuggestions
+	}
+
+	now := time.Now()
+	orderNumber := fmt.Sprintf("REORDER-%s", now.Format("20060102-150405"))
+	if req.OrderNumber != nil && *req.OrderNumber != "" {
+		orderNumber = *req.OrderNumber
+	}
+	purchaseDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var statusID *uuid.UUID
+	draft, err := s.orderStatusRepo.GetByName(ctx, repository.OrderStatusDraft)
+	if err != nil && err != repository.ErrOrderStatusNotFound {
+		log.Error().Err(err).Str("status", repository.OrderStatusDraft).Msg("Failed to resolve order status")
+		return nil, err
+	}
+	if draft != nil {
+		statusID = &draft.OrderStatusID
+	}
+
+	order, err := s.repo.CreateWithItems(ctx, orderNumber, req.Buyer, statusID, &purchaseDate, items, userID)
+	if err != nil {
+		log.Error().Err(err).Str("orderNumber", orderNumber).Str("userId", userID.String()).Msg("Failed to create supplier order from reorder suggestions")
+		return nil, err
+	}
+
+	if draft != nil {
+		comment := "Создан из предложений дозаказа"
+		s.transitions.Record(ctx, repository.StatusEntitySupplierOrder, order.OrderID, nil, draft.Name, &comment, userID)
+	}
+
+	if err := s.landedCost.Recalculate(ctx, order.OrderID, userID); err != nil {
+		log.Error().Err(err).Str("orderId", order.OrderID.String()).Msg("Failed to allocate costs of reorder order")
+	}
+
+	log.Info().Str("orderId", order.OrderID.String()).Str("orderNumber", orderNumber).Int("items", len(items)).Str("userId", userID.String()).Msg("Supplier order created from reorder suggestions")
+	result, err := s.GetByID(ctx, order.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrder, order.OrderID, repository.AuditActionCreate, nil, result)
+	return result, nil
+}
+
+// Receive records the actual received quantities for the order, moves it to the "Получен" status
+// and returns the receipt with per-item discrepancies against ordered quantities.
+func (s *SupplierOrderService) Receive(ctx context.Context, orderID, userID uuid.UUID, req dto.SupplierOrderReceiveRequest) (*dto.SupplierOrderReceiptResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, repository.ErrEmptyReceipt
+	}
+
+	lines := make([]repository.ReceiptLine, 0, len(req.Items))
+	seen := make(map[uuid.UUID]struct{}, len(req.Items))
+	for _, item := range req.Items {
+		itemID, err := uuid.Parse(item.OrderItemID)
+		if err != nil {
+			log.Warn().Str("orderItemId", item.OrderItemID).Msg("Invalid order item ID format")
+			return nil, repository.ErrSupplierOrderItemNotFound
+		}
+		if item.ReceivedQty < 0 {
+			log.Warn().Str("orderItemId", item.OrderItemID).Int("receivedQty", item.ReceivedQty).Msg("Negative received quantity")
+			return nil, repository.ErrInvalidReceivedQty
+		}
+		if _, ok := seen[itemID]; ok {
+			log.Warn().Str("orderItemId", item.OrderItemID).Msg("Duplicate order item in receipt")
+			return nil, repository.ErrDuplicateReceiptLine
+		}
+		seen[itemID] = struct{}{}
+		lines = append(lines, repository.ReceiptLine{OrderItemID: itemID, ReceivedQty: item.ReceivedQty})
+	}
+
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		if err == repository.ErrSupplierOrderNotFound {
+			return nil, err
+		}
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order for receiving")
+		return nil, err
+	}
+
+	receiptDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.ReceiptDate != nil {
+		receiptDate = *req.ReceiptDate
+	}
+	if order.PurchaseDate != nil && receiptDate.Before(*order.PurchaseDate) {
+		log.Warn().Time("purchaseDate", *order.PurchaseDate).Time("receiptDate", receiptDate).Msg("Receipt date must be after purchase date")
+		return nil, repository.ErrInvalidDateRange
+	}
+
+	receivedStatus, err := s.orderStatusRepo.GetByName(ctx, repository.OrderStatusReceived)
+	if err != nil {
+		log.Error().Err(err).Str("status", repository.OrderStatusReceived).Msg("Failed to resolve received order status")
+		return nil, err
+	}
+
+	// Уже полученный или отмененный заказ отклоняется при приемке с отдельными ошибками
+	fromStatus, err := s.statusName(ctx, order.StatusID)
+	if err != nil {
+		return nil, err
+	}
+	if fromStatus != nil && *fromStatus != repository.OrderStatusReceived && *fromStatus != repository.OrderStatusCancelled {
+		if err := s.transitions.Check(ctx, repository.StatusEntitySupplierOrder, fromStatus, repository.OrderStatusReceived); err != nil {
+			return nil, err
+		}
+	}
+
+	before, err := s.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.receiptRepo.Receive(ctx, orderID, receivedStatus.OrderStatusID, receiptDate, lines, req.Notes, userID)
+	if err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Str("userId", userID.String()).Msg("Failed to receive supplier order")
+		return nil, err
+	}
+
+	s.transitions.Record(ctx, repository.StatusEntitySupplierOrder, orderID, fromStatus, repository.OrderStatusReceived, req.Notes, userID)
+	runStatusHooks(ctx, s.hooks, repository.StatusEntitySupplierOrder, repository.OrderStatusReceived, orderID, userID)
+
+	response := toSupplierOrderReceiptResponse(*receipt)
+	log.Info().
+		Str("orderId", orderID.String()).
+		Str("receiptId", response.ReceiptID).
+		Bool("hasDiscrepancies", response.HasDiscrepancies).
+		Int("costPeriods", len(response.Costs)).
+		Str("userId", userID.String()).
+		Msg("Supplier order received successfully")
+	if after, err := s.GetByID(ctx, orderID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntitySupplierOrder, orderID, repository.AuditActionUpdate, before, after)
+	}
+	return &response, nil
+}
+
+func (s *SupplierOrderService) GetReceipts(ctx context.Context, orderID uuid.UUID) ([]dto.SupplierOrderReceiptResponse, error) {
+	if _, err := s.repo.GetByID(ctx, orderID); err != nil {
+		if err != repository.ErrSupplierOrderNotFound {
+			log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order")
+		}
+		return nil, err
+	}
+
+	receipts, err := s.receiptRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order receipts")
+		return nil, err
+	}
+
+	result := make([]dto.SupplierOrderReceiptResponse, 0, len(receipts))
+	for _, receipt := range receipts {
+		result = append(result, toSupplierOrderReceiptResponse(receipt))
+	}
+
+	return result, nil
+}
+
+func toSupplierOrderReceiptResponse(receipt repository.SupplierOrderReceipt) dto.SupplierOrderReceiptResponse {
+	var receivedByStr *string
+	if receipt.ReceivedBy != nil {
+		str := receipt.ReceivedBy.String()
+		receivedByStr = &str
+	}
+
+	response := dto.SupplierOrderReceiptResponse{
+		ReceiptID:   receipt.ReceiptID.String(),
+		OrderID:     receipt.OrderID.String(),
+		ReceiptDate: receipt.ReceiptDate,
+		Notes:       receipt.Notes,
+		ReceivedBy:  receivedByStr,
+		CreatedAt:   receipt.CreatedAt,
+		Items:       make([]dto.SupplierOrderReceiptItemResponse, 0, len(receipt.Items)),
+	}
+
+	for _, item := range receipt.Items {
+		discrepancy := "MATCH"
+		if item.DiscrepancyQty < 0 {
+			discrepancy = "SHORTAGE"
+		} else if item.DiscrepancyQty > 0 {
+			discrepancy = "OVERAGE"
+		}
+		if item.DiscrepancyQty != 0 {
+			response.HasDiscrepancies = true
+		}
+		response.TotalOrderedQty += item.OrderedQty
+		response.TotalReceivedQty += item.ReceivedQty
+
+		response.Items = append(response.Items, dto.SupplierOrderReceiptItemResponse{
+			ReceiptItemID:  item.ReceiptItemID.String(),
+			OrderItemID:    item.OrderItemID.String(),
+			ProductID:      item.ProductID.String(),
+			WarehouseID:    item.WarehouseID.String(),
+			OrderedQty:     item.OrderedQty,
+			ReceivedQty:    item.ReceivedQty,
+			DiscrepancyQty: item.DiscrepancyQty,
+			Discrepancy:    discrepancy,
+		})
+	}
+
+	for _, cost := range receipt.Costs {
+		response.Costs = append(response.Costs, dto.SupplierOrderReceiptCostResponse{
+			CostID:           cost.CostID.String(),
+			ProductID:        cost.ProductID.String(),
+			PreviousUnitCost: cost.PreviousUnitCost,
+			ExistingQty:      cost.ExistingQty,
+			ReceivedQty:      cost.ReceivedQty,
+			ReceivedUnitCost: cost.ReceivedUnitCost,
+			UnitCost:         cost.UnitCost,
+			PeriodStart:      cost.PeriodStart,
+			PeriodEnd:        cost.PeriodEnd,
+		})
+	}
+
+	return response
+}
+
+// SplitRemainder moves the undelivered part of the order into a new child order. When no order number
+// is given, the child is numbered "<parent number>-<n>".
+func (s *SupplierOrderService) SplitRemainder(ctx context.Context, orderID, userID uuid.UUID, req dto.SupplierOrderSplitRequest) (*dto.SupplierOrderResponse, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		if err != repository.ErrSupplierOrderNotFound {
+			log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order for split")
+		}
+		return nil, err
+	}
+
+	if order.StatusID != nil {
+		status, err := s.orderStatusRepo.GetByID(ctx, *order.StatusID)
+		if err != nil && err != repository.ErrOrderStatusNotFound {
+			log.Error().Err(err).Str("statusId", order.StatusID.String()).Msg("Failed to get order status")
+			return nil, err
+		}
+		if status != nil && status.Name == repository.OrderStatusCancelled {
+			log.Warn().Str("orderId", orderID.String()).Msg("Cannot split cancelled supplier order")
+			return nil, repository.ErrOrderCancelled
+		}
+	}
+
+	var childOrderNumber string
+	if req.OrderNumber != nil && *req.OrderNumber != "" {
+		childOrderNumber = *req.OrderNumber
+	} else {
+		children, err := s.repo.CountChildren(ctx, orderID)
+		if err != nil {
+			log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to count child orders")
+			return nil, err
+		}
+		childOrderNumber = fmt.Sprintf("%s-%d", order.OrderNumber, children+1)
+	}
+
+	var childStatusID *uuid.UUID
+	awaiting, err := s.orderStatusRepo.GetByName(ctx, repository.OrderStatusAwaitingSupply)
+	if err != nil && err != repository.ErrOrderStatusNotFound {
+		log.Error().Err(err).Str("status", repository.OrderStatusAwaitingSupply).Msg("Failed to resolve order status")
+		return nil, err
+	}
+	if awaiting != nil {
+		childStatusID = &awaiting.OrderStatusID
+	}
+
+	before, err := s.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := s.repo.SplitRemainder(ctx, orderID, childOrderNumber, childStatusID, userID)
+	if err != nil {
+		log.Error().Err(err).Str("orderId", orderID.String()).Str("childOrderNumber", childOrderNumber).Msg("Failed to split supplier order remainder")
+		return nil, err
+	}
+
+	if awaiting != nil {
+		comment := fmt.Sprintf("Остаток заказа %s", order.OrderNumber)
+		s.transitions.Record(ctx, repository.StatusEntitySupplierOrder, child.OrderID, nil, awaiting.Name, &comment, userID)
+	}
+
+	for _, id := range []uuid.UUID{orderID, child.OrderID} {
+		if err := s.landedCost.Recalculate(ctx, id, userID); err != nil {
+			log.Error().Err(err).Str("orderId", id.String()).Msg("Failed to reallocate costs after split")
+		}
+	}
+
+	log.Info().Str("orderId", orderID.String()).Str("childOrderId", child.OrderID.String()).Str("userId", userID.String()).Msg("Supplier order remainder split into child order")
+	if after, err := s.GetByID(ctx, orderID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntitySupplierOrder, orderID, repository.AuditActionUpdate, before, after)
+	}
+
+	result, err := s.GetByID(ctx, child.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntitySupplierOrder, child.OrderID, repository.AuditActionCreate, nil, result)
+	return result, nil
+}
+
+// GetTree returns the order tree (from the root ancestor) with quantities aggregated over descendants.
+func (s *SupplierOrderService) GetTree(ctx context.Context, orderID uuid.UUID) (*dto.SupplierOrderTreeNodeResponse, error) {
+	nodes, err := s.repo.GetTree(ctx, orderID)
+	if err != nil {
+		if err != repository.ErrSupplierOrderNotFound {
+			log.Error().Err(err).Str("orderId", orderID.String()).Msg("Failed to get supplier order tree")
+		}
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]repository.SupplierOrderTreeNode, len(nodes))
+	for _, node := range nodes[1:] {
+		if node.ParentOrderID != nil {
+			children[*node.ParentOrderID] = append(children[*node.ParentOrderID], node)
+		}
+	}
+
+	root := buildSupplierOrderTree(nodes[0], children)
+	return &root, nil
+}
+
+func buildSupplierOrderTree(node repository.SupplierOrderTreeNode, children map[uuid.UUID][]repository.SupplierOrderTreeNode) dto.SupplierOrderTreeNodeResponse {
+	var statusIDStr *string
+	if node.StatusID != nil {
+		str := node.StatusID.String()
+		statusIDStr = &str
+	}
+	var parentOrderIDStr *string
+	if node.ParentOrderID != nil && node.Depth > 0 {
+		str := node.ParentOrderID.String()
+		parentOrderIDStr = &str
+	}
+
+	response := dto.SupplierOrderTreeNodeResponse{
+		OrderID:          node.OrderID.String(),
+		OrderNumber:      node.OrderNumber,
+		StatusID:         statusIDStr,
+		ParentOrderID:    parentOrderIDStr,
+		OrderedQty:       node.OrderedQty,
+		ReceivedQty:      node.ReceivedQty,
+		TotalOrderedQty:  node.OrderedQty,
+		TotalReceivedQty: node.ReceivedQty,
+		Children:         make([]dto.SupplierOrderTreeNodeResponse, 0, len(children[node.OrderID])),
+	}
+
+	for _, child := range children[node.OrderID] {
+		if child.Depth != node.Depth+1 {
+			continue
+		}
+		childResponse := buildSupplierOrderTree(child, children)
+		response.TotalOrderedQty += childResponse.TotalOrderedQty
+		response.TotalReceivedQty += childResponse.TotalReceivedQty
+		response.Children = append(response.Children, childResponse)
+	}
+
+	return response
+}
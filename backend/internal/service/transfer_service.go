@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"warehouse-backend/internal/dto"
+	"warehouse-backend/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+type TransferService struct {
+	repo          *repository.TransferRepository
+	productRepo   *repository.ProductRepository
+	warehouseRepo *repository.WarehouseRepository
+	negativeStock *StockPolicyService
+	transitions   *StatusTransitionService
+	audit         *AuditService
+}
+
+func NewTransferService(repo *repository.TransferRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository, negativeStock *StockPolicyService, transitions *StatusTransitionService, audit *AuditService) *TransferService {
+	return &TransferService{
+		repo:          repo,
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		negativeStock: negativeStock,
+		transitions:   transitions,
+		audit:         audit,
+	}
+}
+
+func toTransferResponse(transfer *repository.Transfer, items []repository.TransferItem) *dto.TransferResponse {
+	var createdByStr *string
+	if transfer.CreatedBy != nil {
+		str := transfer.CreatedBy.String()
+		createdByStr = &str
+	}
+	var updatedByStr *string
+	if transfer.UpdatedBy != nil {
+		str := transfer.UpdatedBy.String()
+		updatedByStr = &str
+	}
+
+	result := &dto.TransferResponse{
+		TransferID:             transfer.TransferID.String(),
+		TransferNumber:         transfer.TransferNumber,
+		SourceWarehouseID:      transfer.SourceWarehouseID.String(),
+		DestinationWarehouseID: transfer.DestinationWarehouseID.String(),
+		Status:                 transfer.Status,
+		ShipmentDate:           transfer.ShipmentDate,
+		ReceiptDate:            transfer.ReceiptDate,
+		Notes:                  transfer.Notes,
+		CreatedBy:              createdByStr,
+		CreatedAt:              transfer.CreatedAt,
+		UpdatedBy:              updatedByStr,
+		UpdatedAt:              transfer.UpdatedAt,
+	}
+
+	for _, item := range items {
+		var discrepancy *int
+		if transfer.Status == repository.TransferStatusReceived {
+			d := item.ReceivedQty - item.ShippedQty
+			discrepancy = &d
+		}
+		result.Items = append(result.Items, dto.TransferItemResponse{
+			TransferItemID: item.TransferItemID.String(),
+			ProductID:      item.ProductID.String(),
+			Article:        item.Article,
+			ShippedQty:     item.ShippedQty,
+			ReceivedQty:    item.ReceivedQty,
+			DiscrepancyQty: discrepancy,
+		})
+	}
+
+	return result
+}
+
+func (s *TransferService) GetByID(ctx context.Context, transferID uuid.UUID) (*dto.TransferResponse, error) {
+	transfer, err := s.repo.GetByID(ctx, transferID)
+	if err != nil {
+		if err != repository.ErrTransferNotFound {
+			log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to get transfer by ID")
+		}
+		return nil, err
+	}
+
+	items, err := s.repo.GetItems(ctx, transferID)
+	if err != nil {
+		log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to get transfer items")
+		return nil, err
+	}
+
+	return toTransferResponse(transfer, items), nil
+}
+
+// List returns transfers without items.
+func (s *TransferService) List(ctx context.Context, limit, offset int, status *string, warehouseID *uuid.UUID) ([]dto.TransferResponse, error) {
+	transfers, err := s.repo.List(ctx, limit, offset, status, warehouseID)
+	if err != nil {
+		log.Error().Err(err).Int("limit", limit).Int("offset", offset).
+			Interface("status", status).Interface("warehouseId", warehouseID).Msg("Failed to list transfers")
+		return nil, err
+	}
+
+	result := make([]dto.TransferResponse, 0, len(transfers))
+	for i := range transfers {
+		result = append(result, *toTransferResponse(&transfers[i], nil))
+	}
+
+	return result, nil
+}
+
+func (s *TransferService) validateWarehouse(ctx context.Context, warehouseIDStr string) (uuid.UUID, error) {
+	warehouseID, err := uuid.Parse(warehouseIDStr)
+	if err != nil {
+		log.Warn().Str("warehouseId", warehouseIDStr).Msg("Invalid warehouse ID format")
+		return uuid.Nil, repository.ErrWarehouseNotFound
+	}
+	warehouse, err := s.warehouseRepo.GetByID(ctx, warehouseID)
+	if err != nil {
+		if err == repository.ErrWarehouseNotFound {
+			log.Warn().Str("warehouseId", warehouseIDStr).Msg("Warehouse not found")
+			return uuid.Nil, repository.ErrWarehouseNotFound
+		}
+		log.Error().Err(err).Str("warehouseId", warehouseIDStr).Msg("Failed to validate warehouse")
+		return uuid.Nil, err
+	}
+	if warehouse.IsArchived {
+		log.Warn().Str("warehouseId", warehouseIDStr).Msg("Warehouse is archived")
+		return uuid.Nil, repository.ErrWarehouseArchived
+	}
+	return warehouseID, nil
+}
+
+// validate checks the warehouses and items of a draft transfer and converts them for the repository.
+func (s *TransferService) validate(ctx context.Context, sourceIDStr, destinationIDStr string, reqItems []dto.TransferItemRequest) (uuid.UUID, uuid.UUID, []repository.TransferItemInput, error) {
+	sourceID, err := s.validateWarehouse(ctx, sourceIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, nil, err
+	}
+	destinationID, err := s.validateWarehouse(ctx, destinationIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, nil, err
+	}
+	if sourceID == destinationID {
+		log.Warn().Str("warehouseId", sourceIDStr).Msg("Transfer source and destination are the same warehouse")
+		return uuid.Nil, uuid.Nil, nil, repository.ErrSameWarehouse
+	}
+
+	if len(reqItems) == 0 {
+		return uuid.Nil, uuid.Nil, nil, repository.ErrEmptyTransfer
+	}
+
+	items := make([]repository.TransferItemInput, 0, len(reqItems))
+	seen := make(map[uuid.UUID]bool, len(reqItems))
+	for _, reqItem := range reqItems {
+		productID, err := uuid.Parse(reqItem.ProductID)
+		if err != nil {
+			log.Warn().Str("productId", reqItem.ProductID).Msg("Invalid product ID format")
+			return uuid.Nil, uuid.Nil, nil, repository.ErrProductNotFound
+		}
+		if seen[productID] {
+			log.Warn().Str("productId", reqItem.ProductID).Msg("Product is listed twice in transfer")
+			return uuid.Nil, uuid.Nil, nil, repository.ErrDuplicateItem
+		}
+		seen[productID] = true
+
+		product, err := s.productRepo.GetByID(ctx, productID)
+		if err != nil {
+			if err == repository.ErrProductNotFound {
+				log.Warn().Str("productId", reqItem.ProductID).Msg("Product not found")
+				return uuid.Nil, uuid.Nil, nil, repository.ErrProductNotFound
+			}
+			log.Error().Err(err).Str("productId", reqItem.ProductID).Msg("Failed to validate product")
+			return uuid.Nil, uuid.Nil, nil, err
+		}
+		if product.IsArchived {
+			log.Warn().Str("productId", reqItem.ProductID).Msg("Product is archived")
+			return uuid.Nil, uuid.Nil, nil, repository.ErrProductArchived
+		}
+
+		if reqItem.ShippedQty <= 0 {
+			log.Warn().Int("shippedQty", reqItem.ShippedQty).Msg("Shipped quantity must be positive")
+			return uuid.Nil, uuid.Nil, nil, repository.ErrInvalidQuantity
+		}
+
+		items = append(items, repository.TransferItemInput{ProductID: productID, Quantity: reqItem.ShippedQty})
+	}
+
+	return sourceID, destinationID, items, nil
+}
+
+func (s *TransferService) Create(ctx context.Context, userID uuid.UUID, req dto.TransferCreateRequest) (*dto.TransferResponse, error) {
+	sourceID, destinationID, items, err := s.validate(ctx, req.SourceWarehouseID, req.DestinationWarehouseID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.repo.Create(ctx, req.TransferNumber, sourceID, destinationID, req.Notes, items, userID)
+	if err != nil {
+		log.Error().Err(err).Str("transferNumber", req.TransferNumber).Str("userId", userID.String()).Msg("Failed to create transfer")
+		return nil, err
+	}
+
+	s.transitions.Record(ctx, repository.StatusEntityTransfer, transfer.TransferID, nil, transfer.Status, nil, userID)
+
+	log.Info().Str("transferId", transfer.TransferID.String()).Str("transferNumber", transfer.TransferNumber).Str("userId", userID.String()).Msg("Transfer created successfully")
+	result, err := s.GetByID(ctx, transfer.TransferID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityTransfer, transfer.TransferID, repository.AuditActionCreate, nil, result)
+	return result, nil
+}
+
+func (s *TransferService) Update(ctx context.Context, transferID, userID uuid.UUID, req dto.TransferUpdateRequest) (*dto.TransferResponse, error) {
+	before, err := s.GetByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != repository.TransferStatusDraft {
+		log.Warn().Str("transferId", transferID.String()).Str("status", before.Status).Msg("Only draft transfers can be edited")
+		return nil, repository.ErrTransferNotEditable
+	}
+
+	sourceID, destinationID, items, err := s.validate(ctx, req.SourceWarehouseID, req.DestinationWarehouseID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Update(ctx, transferID, req.TransferNumber, sourceID, destinationID, req.Notes, items, userID); err != nil {
+		log.Error().Err(err).Str("transferId", transferID.String()).Str("userId", userID.String()).Msg("Failed to update transfer")
+		return nil, err
+	}
+
+	log.Info().Str("transferId", transferID.String()).Str("userId", userID.String()).Msg("Transfer updated successfully")
+	result, err := s.GetByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityTransfer, transferID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
+
+func (s *TransferService) Delete(ctx context.Context, transferID uuid.UUID) error {
+	before, err := s.GetByID(ctx, transferID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, transferID); err != nil {
+		if err != repository.ErrTransferNotEditable {
+			log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to delete transfer")
+		}
+		return err
+	}
+
+	log.Info().Str("transferId", transferID.String()).Msg("Transfer deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityTransfer, transferID, repository.AuditActionDelete, before, nil)
+	return nil
+}
+
+func (s *TransferService) History(ctx context.Context, transferID uuid.UUID) ([]dto.StatusHistoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, transferID); err != nil {
+		if err != repository.ErrTransferNotFound {
+			log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to get transfer for status history")
+		}
+		return nil, err
+	}
+	return s.transitions.History(ctx, repository.StatusEntityTransfer, transferID)
+}
+
+// today returns the current date at midnight UTC, the way DATE columns are scanned.
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Ship sends a draft transfer: its quantities leave the source warehouse on the shipment date (today by
+// default). The negative stock policy is applied to the source warehouse.
+func (s *TransferService) Ship(ctx context.Context, transferID, userID uuid.UUID, req dto.TransferShipRequest) (*dto.TransferResponse, error) {
+	before, err := s.GetByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityTransfer, &before.Status, repository.TransferStatusInTransit); err != nil {
+		return nil, err
+	}
+	if len(before.Items) == 0 {
+		return nil, repository.ErrEmptyTransfer
+	}
+
+	sourceID, err := uuid.Parse(before.SourceWarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]repository.StockChange, 0, len(before.Items))
+	for _, item := range before.Items {
+		productID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, repository.StockChange{ProductID: productID, WarehouseID: sourceID, Quantity: -item.ShippedQty})
+	}
+	if err := s.negativeStock.Check(ctx, repository.StatusEntityTransfer, transferID, stockChanges(changes)); err != nil {
+		return nil, err
+	}
+
+	shipmentDate := today()
+	if req.ShipmentDate != nil {
+		shipmentDate = *req.ShipmentDate
+	}
+
+	if err := s.repo.Ship(ctx, transferID, shipmentDate, userID); err != nil {
+		log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to ship transfer")
+		return nil, err
+	}
+
+	return s.afterTransition(ctx, transferID, userID, before, repository.TransferStatusInTransit, req.Comment)
+}
+
+// Receive accepts an in-transit transfer on the destination warehouse on the receipt date (today by default).
+// Items not listed in the request are received in full; a shortage against the shipped quantity stays as
+// a discrepancy of the item. More than shipped cannot be received: the excess never left the source warehouse.
+func (s *TransferService) Receive(ctx context.Context, transferID, userID uuid.UUID, req dto.TransferReceiveRequest) (*dto.TransferResponse, error) {
+	before, err := s.GetByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityTransfer, &before.Status, repository.TransferStatusReceived); err != nil {
+		return nil, err
+	}
+
+	shipped := make(map[uuid.UUID]int, len(before.Items))
+	for _, item := range before.Items {
+		shipped[uuid.MustParse(item.ProductID)] = item.ShippedQty
+	}
+
+	received := make(map[uuid.UUID]int, len(req.Items))
+	for _, item := range req.Items {
+		productID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			log.Warn().Str("productId", item.ProductID).Msg("Invalid product ID format")
+			return nil, repository.ErrProductNotFound
+		}
+		if _, ok := received[productID]; ok {
+			return nil, repository.ErrDuplicateItem
+		}
+		if item.ReceivedQty < 0 {
+			log.Warn().Int("receivedQty", item.ReceivedQty).Msg("Received quantity must be non-negative")
+			return nil, repository.ErrInvalidQuantity
+		}
+		if shippedQty, ok := shipped[productID]; ok && item.ReceivedQty > shippedQty {
+			log.Warn().Str("productId", item.ProductID).Int("shippedQty", shippedQty).Int("receivedQty", item.ReceivedQty).
+				Msg("Received quantity exceeds shipped quantity")
+			return nil, repository.ErrReceivedExceedsShipped
+		}
+		received[productID] = item.ReceivedQty
+	}
+
+	receiptDate := today()
+	if req.ReceiptDate != nil {
+		receiptDate = *req.ReceiptDate
+	}
+	if before.ShipmentDate != nil && receiptDate.Before(*before.ShipmentDate) {
+		log.Warn().Time("shipmentDate", *before.ShipmentDate).Time("receiptDate", receiptDate).Msg("Receipt date must not be before shipment date")
+		return nil, repository.ErrInvalidDateRange
+	}
+
+	if err := s.repo.Receive(ctx, transferID, receiptDate, received, userID); err != nil {
+		if err != repository.ErrProductNotFound && err != repository.ErrReceivedExceedsShipped {
+			log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to receive transfer")
+		}
+		return nil, err
+	}
+
+	return s.afterTransition(ctx, transferID, userID, before, repository.TransferStatusReceived, req.Comment)
+}
+
+// Cancel cancels a draft or in-transit transfer; goods of an in-transit transfer return to the source warehouse.
+func (s *TransferService) Cancel(ctx context.Context, transferID, userID uuid.UUID, req dto.TransferCancelRequest) (*dto.TransferResponse, error) {
+	before, err := s.GetByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitions.Check(ctx, repository.StatusEntityTransfer, &before.Status, repository.TransferStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Cancel(ctx, transferID, before.Status, userID); err != nil {
+		log.Error().Err(err).Str("transferId", transferID.String()).Msg("Failed to cancel transfer")
+		return nil, err
+	}
+
+	return s.afterTransition(ctx, transferID, userID, before, repository.TransferStatusCancelled, req.Comment)
+}
+
+func (s *TransferService) afterTransition(ctx context.Context, transferID, userID uuid.UUID, before *dto.TransferResponse, toStatus string, comment *string) (*dto.TransferResponse, error) {
+	s.transitions.Record(ctx, repository.StatusEntityTransfer, transferID, &before.Status, toStatus, comment, userID)
+
+	log.Info().Str("transferId", transferID.String()).Str("status", toStatus).Str("userId", userID.String()).Msg("Transfer status changed")
+	result, err := s.GetByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityTransfer, transferID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
@@ -14,12 +14,14 @@ import (
 type UserService struct {
 	repo     *repository.UserRepository
 	roleRepo *repository.RoleRepository
+	audit    *AuditService
 }
 
-func NewUserService(repo *repository.UserRepository, roleRepo *repository.RoleRepository) *UserService {
+func NewUserService(repo *repository.UserRepository, roleRepo *repository.RoleRepository, audit *AuditService) *UserService {
 	return &UserService{
 		repo:     repo,
 		roleRepo: roleRepo,
+		audit:    audit,
 	}
 }
 
@@ -37,11 +39,13 @@ func (s *UserService) GetByID(ctx context.Context, userID uuid.UUID) (*dto.UserR
 		Surname:    user.Surname,
 		Patronymic: user.Patronymic,
 		RoleID:     user.RoleID.String(),
+		IsArchived: user.IsArchived,
+		ArchivedAt: user.ArchivedAt,
 	}, nil
 }
 
-func (s *UserService) List(ctx context.Context, limit, offset int) ([]dto.UserResponse, error) {
-	users, err := s.repo.List(ctx, limit, offset)
+func (s *UserService) List(ctx context.Context, limit, offset int, includeArchived bool) ([]dto.UserResponse, error) {
+	users, err := s.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to list users")
 		return nil, err
@@ -56,6 +60,8 @@ func (s *UserService) List(ctx context.Context, limit, offset int) ([]dto.UserRe
 			Surname:    user.Surname,
 			Patronymic: user.Patronymic,
 			RoleID:     user.RoleID.String(),
+			IsArchived: user.IsArchived,
+			ArchivedAt: user.ArchivedAt,
 		})
 	}
 
@@ -92,17 +98,26 @@ func (s *UserService) Create(ctx context.Context, req dto.UserCreateRequest) (*d
 	}
 
 	log.Info().Str("userId", user.UserID.String()).Str("email", req.Email).Str("roleId", req.RoleID).Msg("User created successfully")
-	return &dto.UserResponse{
+	result := &dto.UserResponse{
 		UserID:     user.UserID.String(),
 		Email:      user.Email,
 		Name:       user.Name,
 		Surname:    user.Surname,
 		Patronymic: user.Patronymic,
 		RoleID:     user.RoleID.String(),
-	}, nil
+		IsArchived: user.IsArchived,
+		ArchivedAt: user.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityUser, user.UserID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *UserService) Update(ctx context.Context, userID uuid.UUID, req dto.UserUpdateRequest) (*dto.UserResponse, error) {
+	before, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	roleID, err := uuid.Parse(req.RoleID)
 	if err != nil {
 		log.Warn().Str("roleId", req.RoleID).Msg("Invalid role ID format")
@@ -136,23 +151,57 @@ func (s *UserService) Update(ctx context.Context, userID uuid.UUID, req dto.User
 	}
 
 	log.Info().Str("userId", userID.String()).Msg("User updated successfully")
-	return &dto.UserResponse{
+	result := &dto.UserResponse{
 		UserID:     user.UserID.String(),
 		Email:      user.Email,
 		Name:       user.Name,
 		Surname:    user.Surname,
 		Patronymic: user.Patronymic,
 		RoleID:     user.RoleID.String(),
-	}, nil
+		IsArchived: user.IsArchived,
+		ArchivedAt: user.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityUser, userID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
+// Delete archives the user: it disappears from lists and cannot be used in new documents, but
+// documents and stock history that reference it stay intact. Use Restore to bring it back.
 func (s *UserService) Delete(ctx context.Context, userID uuid.UUID) error {
-	err := s.repo.Delete(ctx, userID)
+	before, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Archive(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to delete user")
+		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to archive user")
 		return err
 	}
 
-	log.Info().Str("userId", userID.String()).Msg("User deleted successfully")
+	log.Info().Str("userId", userID.String()).Msg("User archived successfully")
+	if after, err := s.GetByID(ctx, userID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityUser, userID, repository.AuditActionDelete, before, after)
+	}
 	return nil
 }
+
+func (s *UserService) Restore(ctx context.Context, userID uuid.UUID) (*dto.UserResponse, error) {
+	before, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, userID); err != nil {
+		log.Error().Err(err).Str("userId", userID.String()).Msg("Failed to restore user")
+		return nil, err
+	}
+
+	log.Info().Str("userId", userID.String()).Msg("User restored successfully")
+	result, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityUser, userID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
@@ -13,12 +13,14 @@ import (
 type WarehouseService struct {
 	repo              *repository.WarehouseRepository
 	warehouseTypeRepo *repository.WarehouseTypeRepository
+	audit             *AuditService
 }
 
-func NewWarehouseService(repo *repository.WarehouseRepository, warehouseTypeRepo *repository.WarehouseTypeRepository) *WarehouseService {
+func NewWarehouseService(repo *repository.WarehouseRepository, warehouseTypeRepo *repository.WarehouseTypeRepository, audit *AuditService) *WarehouseService {
 	return &WarehouseService{
 		repo:              repo,
 		warehouseTypeRepo: warehouseTypeRepo,
+		audit:             audit,
 	}
 }
 
@@ -40,11 +42,13 @@ func (s *WarehouseService) GetByID(ctx context.Context, warehouseID uuid.UUID) (
 		Name:            warehouse.Name,
 		WarehouseTypeID: warehouseTypeIDStr,
 		Location:        warehouse.Location,
+		IsArchived:      warehouse.IsArchived,
+		ArchivedAt:      warehouse.ArchivedAt,
 	}, nil
 }
 
-func (s *WarehouseService) List(ctx context.Context, limit, offset int) ([]dto.WarehouseResponse, error) {
-	warehouses, err := s.repo.List(ctx, limit, offset)
+func (s *WarehouseService) List(ctx context.Context, limit, offset int, includeArchived bool) ([]dto.WarehouseResponse, error) {
+	warehouses, err := s.repo.List(ctx, limit, offset, includeArchived)
 	if err != nil {
 		log.Error().Err(err).Int("limit", limit).Int("offset", offset).Msg("Failed to list warehouses")
 		return nil, err
@@ -62,6 +66,8 @@ func (s *WarehouseService) List(ctx context.Context, limit, offset int) ([]dto.W
 			Name:            warehouse.Name,
 			WarehouseTypeID: warehouseTypeIDStr,
 			Location:        warehouse.Location,
+			IsArchived:      warehouse.IsArchived,
+			ArchivedAt:      warehouse.ArchivedAt,
 		})
 	}
 
@@ -102,15 +108,24 @@ func (s *WarehouseService) Create(ctx context.Context, req dto.WarehouseCreateRe
 	}
 
 	log.Info().Str("warehouseId", warehouse.WarehouseID.String()).Str("name", warehouse.Name).Msg("Warehouse created successfully")
-	return &dto.WarehouseResponse{
+	result := &dto.WarehouseResponse{
 		WarehouseID:     warehouse.WarehouseID.String(),
 		Name:            warehouse.Name,
 		WarehouseTypeID: warehouseTypeIDStr,
 		Location:        warehouse.Location,
-	}, nil
+		IsArchived:      warehouse.IsArchived,
+		ArchivedAt:      warehouse.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityWarehouse, warehouse.WarehouseID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *WarehouseService) Update(ctx context.Context, warehouseID uuid.UUID, req dto.WarehouseUpdateRequest) (*dto.WarehouseResponse, error) {
+	before, err := s.GetByID(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
 	var warehouseTypeID *uuid.UUID
 	if req.WarehouseTypeID != nil && *req.WarehouseTypeID != "" {
 		id, err := uuid.Parse(*req.WarehouseTypeID)
@@ -144,21 +159,55 @@ func (s *WarehouseService) Update(ctx context.Context, warehouseID uuid.UUID, re
 	}
 
 	log.Info().Str("warehouseId", warehouseID.String()).Msg("Warehouse updated successfully")
-	return &dto.WarehouseResponse{
+	result := &dto.WarehouseResponse{
 		WarehouseID:     warehouse.WarehouseID.String(),
 		Name:            warehouse.Name,
 		WarehouseTypeID: warehouseTypeIDStr,
 		Location:        warehouse.Location,
-	}, nil
+		IsArchived:      warehouse.IsArchived,
+		ArchivedAt:      warehouse.ArchivedAt,
+	}
+	s.audit.Record(ctx, repository.AuditEntityWarehouse, warehouseID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
+// Delete archives the warehouse: it disappears from lists and cannot be used in new documents, but
+// documents and stock history that reference it stay intact. Use Restore to bring it back.
 func (s *WarehouseService) Delete(ctx context.Context, warehouseID uuid.UUID) error {
-	err := s.repo.Delete(ctx, warehouseID)
+	before, err := s.GetByID(ctx, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Archive(ctx, warehouseID)
 	if err != nil {
-		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to delete warehouse")
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to archive warehouse")
 		return err
 	}
 
-	log.Info().Str("warehouseId", warehouseID.String()).Msg("Warehouse deleted successfully")
+	log.Info().Str("warehouseId", warehouseID.String()).Msg("Warehouse archived successfully")
+	if after, err := s.GetByID(ctx, warehouseID); err == nil {
+		s.audit.Record(ctx, repository.AuditEntityWarehouse, warehouseID, repository.AuditActionDelete, before, after)
+	}
 	return nil
 }
+
+func (s *WarehouseService) Restore(ctx context.Context, warehouseID uuid.UUID) (*dto.WarehouseResponse, error) {
+	before, err := s.GetByID(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(ctx, warehouseID); err != nil {
+		log.Error().Err(err).Str("warehouseId", warehouseID.String()).Msg("Failed to restore warehouse")
+		return nil, err
+	}
+
+	log.Info().Str("warehouseId", warehouseID.String()).Msg("Warehouse restored successfully")
+	result, err := s.GetByID(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, repository.AuditEntityWarehouse, warehouseID, repository.AuditActionUpdate, before, result)
+	return result, nil
+}
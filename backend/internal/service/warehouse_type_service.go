@@ -11,11 +11,12 @@ import (
 )
 
 type WarehouseTypeService struct {
-	repo *repository.WarehouseTypeRepository
+	repo  *repository.WarehouseTypeRepository
+	audit *AuditService
 }
 
-func NewWarehouseTypeService(repo *repository.WarehouseTypeRepository) *WarehouseTypeService {
-	return &WarehouseTypeService{repo: repo}
+func NewWarehouseTypeService(repo *repository.WarehouseTypeRepository, audit *AuditService) *WarehouseTypeService {
+	return &WarehouseTypeService{repo: repo, audit: audit}
 }
 
 func (s *WarehouseTypeService) GetByID(ctx context.Context, warehouseTypeID uuid.UUID) (*dto.WarehouseTypeResponse, error) {
@@ -57,13 +58,20 @@ func (s *WarehouseTypeService) Create(ctx context.Context, req dto.WarehouseType
 	}
 
 	log.Info().Str("warehouseTypeId", warehouseType.WarehouseTypeID.String()).Str("name", warehouseType.Name).Msg("Warehouse type created successfully")
-	return &dto.WarehouseTypeResponse{
+	result := &dto.WarehouseTypeResponse{
 		WarehouseTypeID: warehouseType.WarehouseTypeID.String(),
 		Name:            warehouseType.Name,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityWarehouseType, warehouseType.WarehouseTypeID, repository.AuditActionCreate, nil, result)
+	return result, nil
 }
 
 func (s *WarehouseTypeService) Update(ctx context.Context, warehouseTypeID uuid.UUID, req dto.WarehouseTypeUpdateRequest) (*dto.WarehouseTypeResponse, error) {
+	before, err := s.GetByID(ctx, warehouseTypeID)
+	if err != nil {
+		return nil, err
+	}
+
 	warehouseType, err := s.repo.Update(ctx, warehouseTypeID, req.Name)
 	if err != nil {
 		log.Error().Err(err).Str("warehouseTypeId", warehouseTypeID.String()).Msg("Failed to update warehouse type")
@@ -71,19 +79,27 @@ func (s *WarehouseTypeService) Update(ctx context.Context, warehouseTypeID uuid.
 	}
 
 	log.Info().Str("warehouseTypeId", warehouseTypeID.String()).Msg("Warehouse type updated successfully")
-	return &dto.WarehouseTypeResponse{
+	result := &dto.WarehouseTypeResponse{
 		WarehouseTypeID: warehouseType.WarehouseTypeID.String(),
 		Name:            warehouseType.Name,
-	}, nil
+	}
+	s.audit.Record(ctx, repository.AuditEntityWarehouseType, warehouseTypeID, repository.AuditActionUpdate, before, result)
+	return result, nil
 }
 
 func (s *WarehouseTypeService) Delete(ctx context.Context, warehouseTypeID uuid.UUID) error {
-	err := s.repo.Delete(ctx, warehouseTypeID)
+	before, err := s.GetByID(ctx, warehouseTypeID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, warehouseTypeID)
 	if err != nil {
 		log.Error().Err(err).Str("warehouseTypeId", warehouseTypeID.String()).Msg("Failed to delete warehouse type")
 		return err
 	}
 
 	log.Info().Str("warehouseTypeId", warehouseTypeID.String()).Msg("Warehouse type deleted successfully")
+	s.audit.Record(ctx, repository.AuditEntityWarehouseType, warehouseTypeID, repository.AuditActionDelete, before, nil)
 	return nil
 }
@@ -6,24 +6,75 @@ SET session_replication_role = 'replica';
 
 -- ===== Удаление данных из таблиц с зависимостями (дочерние таблицы) =====
 
+-- Журнал аудита
+DELETE FROM audit_log;
+
+-- История статусов документов (зависит от users)
+DELETE FROM status_history;
+
+-- Запуски формирования снапшотов (зависит от users)
+DELETE FROM stock_snapshot_runs;
+
+-- Изменения снапшотов инвентаризациями (зависит от inventories, products, warehouses)
+DELETE FROM inventory_snapshot_adjustments;
+
 -- Снапшоты остатков (зависит от products, warehouses, users)
 DELETE FROM stock_snapshots;
 
+-- Уровни запасов (зависит от products, warehouses, users)
+DELETE FROM stock_levels;
+
 -- Себестоимость продуктов (зависит от products, users)
 DELETE FROM product_costs;
 
+-- Строки пересчета (зависит от inventory_counts, products, users)
+DELETE FROM inventory_count_lines;
+
+-- Пересчеты инвентаризаций (зависит от inventories, warehouses, users)
+DELETE FROM inventory_counts;
+
 -- Элементы инвентаризации (зависит от inventories, products, warehouses)
 DELETE FROM inventory_items;
 
 -- Инвентаризации (зависит от inventory_statuses, users)
 DELETE FROM inventories;
 
+-- Позиции перемещений по ячейкам (зависит от location_moves, products, storage_locations)
+DELETE FROM location_move_items;
+
+-- Перемещения по ячейкам (зависит от warehouses, users)
+DELETE FROM location_moves;
+
+-- Места хранения (зависит от warehouses, users)
+DELETE FROM storage_locations;
+
+-- Позиции перемещений (зависит от transfers, products)
+DELETE FROM transfer_items;
+
+-- Перемещения между складами (зависит от warehouses, users)
+DELETE FROM transfers;
+
+-- Позиции возвратов с маркетплейсов (зависит от mp_returns, products)
+DELETE FROM mp_return_items;
+
+-- Возвраты с маркетплейсов (зависит от stores, mp_shipments, warehouses, users)
+DELETE FROM mp_returns;
+
+-- Резервы остатков (зависит от mp_shipment_items, products, warehouses)
+DELETE FROM stock_reservations;
+
 -- Элементы отгрузок на маркетплейсы (зависит от mp_shipments, products, warehouses)
 DELETE FROM mp_shipment_items;
 
 -- Отгрузки на маркетплейсы (зависит от stores, warehouses, shipment_statuses, users)
 DELETE FROM mp_shipments;
 
+-- Позиции актов приёмки (зависит от supplier_order_receipts, supplier_order_items)
+DELETE FROM supplier_order_receipt_items;
+
+-- Акты приёмки заказов (зависит от supplier_orders, users)
+DELETE FROM supplier_order_receipts;
+
 -- Документы заказов поставщиков (зависит от supplier_orders)
 DELETE FROM supplier_order_documents;
 
@@ -61,6 +112,11 @@ DELETE FROM stores;
 -- Типы складов
 DELETE FROM warehouse_types;
 
+-- Переходы статусов не очищаются: они заполняются schema.sql
+
+-- Привязка разрешений к ролям (каталог permissions не очищается: он заполняется schema.sql)
+DELETE FROM role_permissions;
+
 -- Роли пользователей (должна быть последней, так как на неё ссылаются users)
 DELETE FROM user_roles;
 
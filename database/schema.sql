@@ -2,6 +2,7 @@
 -- Включение UUID
 -- =====================================================
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS btree_gist; -- EXCLUDE-ограничение на периоды себестоимости
 
 -- =====================================================
 -- Роли и пользователи
@@ -19,9 +20,66 @@ CREATE TABLE IF NOT EXISTS users (
     surname VARCHAR(100),
     patronymic VARCHAR(100),
     password_hash VARCHAR(255) NOT NULL,
-    role_id UUID NOT NULL REFERENCES user_roles(role_id)
+    role_id UUID NOT NULL REFERENCES user_roles(role_id),
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
 );
 
+-- =====================================================
+-- Разрешения (ресурс + действие) и их привязка к ролям
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS permissions (
+    permission_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    resource VARCHAR(50) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    UNIQUE (resource, action)
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id UUID NOT NULL REFERENCES user_roles(role_id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions(permission_id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- Каталог разрешений: каждый ресурс API × действия read/create/update/delete
+INSERT INTO permissions (resource, action)
+SELECT r.resource, a.action
+FROM (VALUES
+    ('stock'),
+    ('products'),
+    ('warehouses'),
+    ('warehouse_types'),
+    ('stores'),
+    ('supplier_orders'),
+    ('mp_shipments'),
+    ('order_statuses'),
+    ('shipment_statuses'),
+    ('inventory_statuses'),
+    ('inventories'),
+    ('transfers'),
+    ('mp_returns'),
+    ('storage_locations'),
+    ('product_costs'),
+    ('stock_snapshots'),
+    ('users'),
+    ('roles'),
+    ('files'),
+    ('audit')
+) AS r(resource)
+CROSS JOIN (VALUES ('read'), ('create'), ('update'), ('delete')) AS a(action)
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Роль администратора с полным доступом: без неё после развёртывания некому настроить роли
+INSERT INTO user_roles (role_id, name) VALUES
+('11111111-1111-1111-1111-111111111111', 'Администратор')
+ON CONFLICT (role_id) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '11111111-1111-1111-1111-111111111111', p.permission_id
+FROM permissions p
+ON CONFLICT DO NOTHING;
+
 -- =====================================================
 -- Справочники
 -- =====================================================
@@ -31,16 +89,25 @@ CREATE TABLE IF NOT EXISTS warehouse_types (
     name VARCHAR(100) UNIQUE NOT NULL
 );
 
+-- Брак из возвратов маркетплейсов принимается только на склад этого типа
+INSERT INTO warehouse_types (warehouse_type_id, name) VALUES
+('10000000-0000-0000-0000-000000000002', 'Склад возвратов')
+ON CONFLICT DO NOTHING;
+
 CREATE TABLE IF NOT EXISTS stores (
     store_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name VARCHAR(100) UNIQUE NOT NULL
+    name VARCHAR(100) UNIQUE NOT NULL,
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS warehouses (
     warehouse_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(100) UNIQUE NOT NULL,
     warehouse_type_id UUID REFERENCES warehouse_types(warehouse_type_id),
-    location VARCHAR(100)
+    location VARCHAR(100),
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
 );
 
 -- =====================================================
@@ -54,7 +121,9 @@ CREATE TABLE IF NOT EXISTS products (
     unit_weight INTEGER NOT NULL DEFAULT 0,
     unit_cost DECIMAL(10,2),
     purchase_price DECIMAL(10,2),
-    processing_price DECIMAL(10,2)
+    processing_price DECIMAL(10,2),
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
 );
 
 -- =====================================================
@@ -82,7 +151,9 @@ CREATE INDEX IF NOT EXISTS idx_product_images_order
 
 CREATE TABLE IF NOT EXISTS order_statuses (
     order_status_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name VARCHAR(50) UNIQUE NOT NULL
+    name VARCHAR(50) UNIQUE NOT NULL,
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS supplier_orders (
@@ -101,6 +172,9 @@ CREATE TABLE IF NOT EXISTS supplier_orders (
     positions_qty INTEGER NOT NULL DEFAULT 0,
     total_qty INTEGER NOT NULL DEFAULT 0,
     order_item_weight DECIMAL(10,2),
+    -- база распределения логистики по позициям: weight (вес), value (стоимость), quantity (количество)
+    logistics_allocation_method VARCHAR(20) NOT NULL DEFAULT 'weight'
+        CHECK (logistics_allocation_method IN ('weight', 'value', 'quantity')),
     parent_order_id UUID REFERENCES supplier_orders(order_id),
     created_by UUID REFERENCES users(user_id),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -133,13 +207,34 @@ CREATE TABLE IF NOT EXISTS supplier_order_documents (
     file_path TEXT NOT NULL
 );
 
+-- Акты приёмки заказов: фактически полученное количество и расхождения с заказанным
+CREATE TABLE IF NOT EXISTS supplier_order_receipts (
+    receipt_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES supplier_orders(order_id) ON DELETE CASCADE,
+    receipt_date DATE NOT NULL,
+    notes VARCHAR(255),
+    received_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS supplier_order_receipt_items (
+    receipt_item_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    receipt_id UUID NOT NULL REFERENCES supplier_order_receipts(receipt_id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES supplier_order_items(order_item_id) ON DELETE CASCADE,
+    ordered_qty INTEGER NOT NULL,
+    received_qty INTEGER NOT NULL,
+    discrepancy_qty INTEGER NOT NULL -- received_qty - ordered_qty: < 0 недостача, > 0 излишек
+);
+
 -- =====================================================
 -- Отгрузки
 -- =====================================================
 
 CREATE TABLE IF NOT EXISTS shipment_statuses (
     shipment_status_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name VARCHAR(50) UNIQUE NOT NULL
+    name VARCHAR(50) UNIQUE NOT NULL,
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS mp_shipments (
@@ -156,6 +251,11 @@ CREATE TABLE IF NOT EXISTS mp_shipments (
     positions_qty INTEGER NOT NULL DEFAULT 0,
     sent_qty INTEGER NOT NULL DEFAULT 0,
     accepted_qty INTEGER NOT NULL DEFAULT 0,
+    -- база распределения логистики и приемки по позициям: weight (вес) или quantity (количество)
+    logistics_allocation_method VARCHAR(20) NOT NULL DEFAULT 'quantity'
+        CHECK (logistics_allocation_method IN ('weight', 'quantity')),
+    -- дата разбора расхождения sent_qty - accepted_qty: вернувшийся товар поступает на склад этой датой
+    resolution_date DATE,
     created_by UUID REFERENCES users(user_id),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_by UUID REFERENCES users(user_id),
@@ -169,16 +269,100 @@ CREATE TABLE IF NOT EXISTS mp_shipment_items (
     warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
     sent_qty INTEGER NOT NULL DEFAULT 0,
     accepted_qty INTEGER NOT NULL DEFAULT 0,
-    logistics_for_item DECIMAL(10,2)
+    logistics_for_item DECIMAL(10,2),
+    -- расхождение sent_qty - accepted_qty: вернулось на склад / утеряно в пути
+    returned_qty INTEGER NOT NULL DEFAULT 0,
+    lost_qty INTEGER NOT NULL DEFAULT 0,
+    -- movement_id возврата на склад в vw_stock_movements (списание использует shipment_item_id)
+    return_movement_id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    CONSTRAINT mp_shipment_items_resolution
+        CHECK (returned_qty >= 0 AND lost_qty >= 0 AND returned_qty + lost_qty <= sent_qty - accepted_qty)
+);
+
+-- Резервы остатков под позиции отгрузок в статусе "Создан".
+-- Резерв активен, пока released_at IS NULL; снимается при отправке отгрузки, когда товар списывается с остатка
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    reservation_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shipment_item_id UUID NOT NULL UNIQUE REFERENCES mp_shipment_items(shipment_item_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(product_id),
+    warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    released_at TIMESTAMP
+);
+
+-- =====================================================
+-- Переходы статусов
+-- =====================================================
+
+-- Разрешенные переходы статусов документов. Статусы задаются названиями, т.к. у каждого
+-- типа документа свой справочник статусов. Смена статуса, которой нет в таблице, запрещена
+CREATE TABLE IF NOT EXISTS status_transitions (
+    transition_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_type VARCHAR(30) NOT NULL
+        CHECK (entity_type IN ('supplier_order', 'mp_shipment', 'inventory', 'transfer', 'mp_return', 'location_move')),
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    UNIQUE (entity_type, from_status, to_status)
+);
+
+INSERT INTO status_transitions (entity_type, from_status, to_status) VALUES
+    ('supplier_order', 'Черновик', 'Ожидает поставки'),
+    ('supplier_order', 'Ожидает поставки', 'В пути'),
+    ('supplier_order', 'Ожидает поставки', 'Получен'),
+    ('supplier_order', 'В пути', 'Получен'),
+    ('supplier_order', 'Черновик', 'Отменен'),
+    ('supplier_order', 'Ожидает поставки', 'Отменен'),
+    ('supplier_order', 'В пути', 'Отменен'),
+    ('mp_shipment', 'Создан', 'Отправлен'),
+    ('mp_shipment', 'Отправлен', 'В пути'),
+    ('mp_shipment', 'Отправлен', 'Принят'),
+    ('mp_shipment', 'Отправлен', 'Отклонен'),
+    ('mp_shipment', 'В пути', 'Принят'),
+    ('mp_shipment', 'В пути', 'Отклонен'),
+    ('inventory', 'Черновик', 'В процессе'),
+    ('inventory', 'В процессе', 'Завершена'),
+    ('inventory', 'Черновик', 'Отменена'),
+    ('inventory', 'В процессе', 'Отменена'),
+    ('inventory', 'Завершена', 'Отменена'),
+    ('transfer', 'Черновик', 'В пути'),
+    ('transfer', 'В пути', 'Получено'),
+    ('transfer', 'Черновик', 'Отменено'),
+    ('transfer', 'В пути', 'Отменено'),
+    ('mp_return', 'Черновик', 'Проведен'),
+    ('mp_return', 'Черновик', 'Отменен'),
+    ('mp_return', 'Проведен', 'Отменен'),
+    ('location_move', 'Черновик', 'Проведен'),
+    ('location_move', 'Черновик', 'Отменен'),
+    ('location_move', 'Проведен', 'Отменен')
+ON CONFLICT (entity_type, from_status, to_status) DO NOTHING;
+
+-- История смены статусов документов. Запись не удаляется вместе с документом,
+-- поэтому entity_id не ссылается на таблицы документов
+CREATE TABLE IF NOT EXISTS status_history (
+    history_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_type VARCHAR(30) NOT NULL
+        CHECK (entity_type IN ('supplier_order', 'mp_shipment', 'inventory', 'transfer', 'mp_return', 'location_move')),
+    entity_id UUID NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    comment VARCHAR(255),
+    changed_by UUID REFERENCES users(user_id),
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX IF NOT EXISTS idx_status_history_entity
+    ON status_history(entity_type, entity_id, changed_at);
+
 -- =====================================================
 -- Инвентаризация
 -- =====================================================
 
 CREATE TABLE IF NOT EXISTS inventory_statuses (
     inventory_status_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name VARCHAR(50) UNIQUE NOT NULL
+    name VARCHAR(50) UNIQUE NOT NULL,
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS inventories (
@@ -202,6 +386,113 @@ CREATE TABLE IF NOT EXISTS inventory_items (
     reason VARCHAR(255)
 );
 
+-- Пересчет склада в рамках инвентаризации. При начале пересчета ожидаемые остатки склада
+-- фиксируются из vw_current_stock, при завершении инвентаризации по расхождениям создаются inventory_items
+CREATE TABLE IF NOT EXISTS inventory_counts (
+    inventory_id UUID PRIMARY KEY REFERENCES inventories(inventory_id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    started_by UUID REFERENCES users(user_id),
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    applied_at TIMESTAMP -- когда по пересчету созданы корректировки
+);
+
+CREATE TABLE IF NOT EXISTS inventory_count_lines (
+    count_line_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    inventory_id UUID NOT NULL REFERENCES inventory_counts(inventory_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(product_id),
+    expected_qty INTEGER NOT NULL DEFAULT 0,
+    counted_qty INTEGER CHECK (counted_qty >= 0), -- NULL, пока товар не пересчитан
+    counted_by UUID REFERENCES users(user_id),
+    counted_at TIMESTAMP,
+    UNIQUE (inventory_id, product_id)
+);
+
+-- =====================================================
+-- Перемещения между складами
+-- =====================================================
+
+-- Статус перемещения: Черновик -> В пути (списано со склада-отправителя на shipment_date) ->
+-- Получено (оприходовано на складе-получателе на receipt_date); Отменено не влияет на остатки
+CREATE TABLE IF NOT EXISTS transfers (
+    transfer_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transfer_number VARCHAR(50) UNIQUE NOT NULL,
+    source_warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    destination_warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    status VARCHAR(50) NOT NULL DEFAULT 'Черновик'
+        CHECK (status IN ('Черновик', 'В пути', 'Получено', 'Отменено')),
+    shipment_date DATE,
+    receipt_date DATE,
+    notes VARCHAR(255),
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID REFERENCES users(user_id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT transfers_distinct_warehouses CHECK (source_warehouse_id <> destination_warehouse_id),
+    CONSTRAINT transfers_dates CHECK (receipt_date IS NULL OR shipment_date IS NULL OR receipt_date >= shipment_date)
+);
+
+CREATE TABLE IF NOT EXISTS transfer_items (
+    transfer_item_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transfer_id UUID NOT NULL REFERENCES transfers(transfer_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(product_id),
+    shipped_qty INTEGER NOT NULL CHECK (shipped_qty > 0),
+    received_qty INTEGER NOT NULL DEFAULT 0 CHECK (received_qty >= 0),
+    -- movement_id поступления в vw_stock_movements (списание использует transfer_item_id),
+    -- чтобы ключ (movement_date, movement_id) журнала движений оставался уникальным
+    receipt_movement_id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    UNIQUE (transfer_id, product_id),
+    -- принять больше отправленного нельзя: излишек не списан со склада-отправителя
+    CONSTRAINT transfer_items_received_qty CHECK (received_qty <= shipped_qty)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfers_source ON transfers(source_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_destination ON transfers(destination_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_status ON transfers(status);
+CREATE INDEX IF NOT EXISTS idx_transfer_items_transfer ON transfer_items(transfer_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_items_product ON transfer_items(product_id);
+
+-- =====================================================
+-- Возвраты с маркетплейсов
+-- =====================================================
+
+-- Отказы маркетплейса по отгрузке (return_type = 'rejection') и возвраты покупателей ('customer').
+-- Проведенный возврат оприходует товар на склад возвратов warehouse_id на return_date
+CREATE TABLE IF NOT EXISTS mp_returns (
+    return_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    return_number VARCHAR(50) UNIQUE NOT NULL,
+    store_id UUID NOT NULL REFERENCES stores(store_id),
+    shipment_id UUID REFERENCES mp_shipments(shipment_id) ON DELETE SET NULL,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    return_type VARCHAR(20) NOT NULL DEFAULT 'customer'
+        CHECK (return_type IN ('rejection', 'customer')),
+    status VARCHAR(50) NOT NULL DEFAULT 'Черновик'
+        CHECK (status IN ('Черновик', 'Проведен', 'Отменен')),
+    return_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    notes VARCHAR(255),
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID REFERENCES users(user_id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Состояние возвращенного товара: resellable (годен к продаже), defect (брак) - остаются на складе
+-- возвратов; write_off (списание) на остатки не поступает
+CREATE TABLE IF NOT EXISTS mp_return_items (
+    return_item_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    return_id UUID NOT NULL REFERENCES mp_returns(return_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(product_id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    condition VARCHAR(20) NOT NULL
+        CHECK (condition IN ('resellable', 'defect', 'write_off')),
+    UNIQUE (return_id, product_id, condition)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mp_returns_store ON mp_returns(store_id);
+CREATE INDEX IF NOT EXISTS idx_mp_returns_shipment ON mp_returns(shipment_id);
+CREATE INDEX IF NOT EXISTS idx_mp_returns_status ON mp_returns(status);
+CREATE INDEX IF NOT EXISTS idx_mp_return_items_return ON mp_return_items(return_id);
+CREATE INDEX IF NOT EXISTS idx_mp_return_items_product ON mp_return_items(product_id);
+
 -- =====================================================
 -- Себестоимость и снапшоты
 -- =====================================================
@@ -213,10 +504,16 @@ CREATE TABLE IF NOT EXISTS product_costs (
     period_end DATE NOT NULL,
     unit_cost_to_warehouse DECIMAL(10,2) NOT NULL,
     notes VARCHAR(255),
+    source_order_id UUID REFERENCES supplier_orders(order_id) ON DELETE SET NULL, -- заказ, приемка которого открыла период
     created_by UUID REFERENCES users(user_id),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_by UUID REFERENCES users(user_id),
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- периоды одного товара не должны пересекаться, иначе vw_stock_with_cost дублирует строки
+    CONSTRAINT product_costs_no_overlap EXCLUDE USING gist (
+        product_id WITH =,
+        daterange(period_start, period_end, '[]') WITH &&
+    )
 );
 
 CREATE TABLE IF NOT EXISTS stock_snapshots (
@@ -230,11 +527,149 @@ CREATE TABLE IF NOT EXISTS stock_snapshots (
     UNIQUE (product_id, warehouse_id, snapshot_date)
 );
 
+-- Запуски формирования снапшотов на конец месяца (по расписанию и вручную)
+CREATE TABLE IF NOT EXISTS stock_snapshot_runs (
+    run_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    snapshot_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'succeeded', 'failed', 'skipped')),
+    snapshots_created INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_by UUID REFERENCES users(user_id),
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_snapshot_runs_started
+    ON stock_snapshot_runs(started_at DESC);
+
+-- Изменения снапшотов, внесенные завершенной инвентаризацией. По ним изменения откатываются
+-- при отмене или удалении инвентаризации
+CREATE TABLE IF NOT EXISTS inventory_snapshot_adjustments (
+    inventory_id UUID NOT NULL REFERENCES inventories(inventory_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(product_id),
+    warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    snapshot_date DATE NOT NULL,
+    delta INTEGER NOT NULL,
+    PRIMARY KEY (inventory_id, product_id, warehouse_id, snapshot_date)
+);
+
+-- =====================================================
+-- Уровни запасов
+-- =====================================================
+
+-- Минимальный и максимальный остаток товара на складе: когда остаток вместе с ожидаемыми поставками
+-- опускается ниже min_qty, товар предлагается к дозаказу до max_qty
+CREATE TABLE IF NOT EXISTS stock_levels (
+    level_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id) ON DELETE CASCADE,
+    min_qty INTEGER NOT NULL CHECK (min_qty >= 0),
+    max_qty INTEGER NOT NULL,
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID REFERENCES users(user_id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT stock_levels_min_max CHECK (max_qty >= min_qty),
+    UNIQUE (product_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_levels_warehouse ON stock_levels(warehouse_id);
+
+-- =====================================================
+-- Адресное хранение
+-- =====================================================
+
+-- Места хранения внутри склада: зона -> стеллаж -> полка -> ячейка. Код уникален среди мест
+-- с общим родителем, адрес места складывается из кодов по цепочке родителей (например, A-01-3-2)
+CREATE TABLE IF NOT EXISTS storage_locations (
+    location_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    parent_id UUID REFERENCES storage_locations(location_id),
+    location_type VARCHAR(10) NOT NULL
+        CHECK (location_type IN ('zone', 'rack', 'shelf', 'bin')),
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100),
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at TIMESTAMP,
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID REFERENCES users(user_id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT storage_locations_zone_root CHECK ((location_type = 'zone') = (parent_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_storage_locations_root_code
+    ON storage_locations(warehouse_id, code) WHERE parent_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_storage_locations_child_code
+    ON storage_locations(parent_id, code) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_storage_locations_warehouse ON storage_locations(warehouse_id);
+
+-- Перемещение товара между ячейками одного склада. Проведенное перемещение снимает товар с
+-- from_location_id и кладет в to_location_id. Пустой from_location_id - размещение неразмещенного
+-- товара (например, после приемки), пустой to_location_id - снятие товара из ячейки (отбор).
+-- Остаток склада в vw_current_stock перемещения не меняют
+CREATE TABLE IF NOT EXISTS location_moves (
+    move_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    move_number VARCHAR(50) UNIQUE NOT NULL,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(warehouse_id),
+    status VARCHAR(50) NOT NULL DEFAULT 'Черновик'
+        CHECK (status IN ('Черновик', 'Проведен', 'Отменен')),
+    notes VARCHAR(255),
+    created_by UUID REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID REFERENCES users(user_id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS location_move_items (
+    move_item_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    move_id UUID NOT NULL REFERENCES location_moves(move_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(product_id),
+    from_location_id UUID REFERENCES storage_locations(location_id),
+    to_location_id UUID REFERENCES storage_locations(location_id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    CONSTRAINT location_move_items_locations CHECK (
+        COALESCE(from_location_id, to_location_id) IS NOT NULL
+        AND from_location_id IS DISTINCT FROM to_location_id
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_moves_warehouse ON location_moves(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_location_moves_status ON location_moves(status);
+CREATE INDEX IF NOT EXISTS idx_location_move_items_move ON location_move_items(move_id);
+CREATE INDEX IF NOT EXISTS idx_location_move_items_from ON location_move_items(from_location_id);
+CREATE INDEX IF NOT EXISTS idx_location_move_items_to ON location_move_items(to_location_id);
+
+-- =====================================================
+-- Журнал аудита
+-- =====================================================
+
+-- Изменения сущностей, выполненные через API: состояние до и после и список изменившихся полей.
+-- Записи переживают удаление сущности и пользователя, поэтому entity_id и user_id без внешних ключей
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before_data JSONB,
+    after_data JSONB,
+    changes JSONB,
+    user_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user ON audit_log(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+
 -- =====================================================
 -- Индексы
 -- =====================================================
 
 CREATE INDEX IF NOT EXISTS idx_users_role ON users(role_id);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission ON role_permissions(permission_id);
 
 CREATE INDEX IF NOT EXISTS idx_supplier_orders_status ON supplier_orders(status_id);
 CREATE INDEX IF NOT EXISTS idx_supplier_orders_parent ON supplier_orders(parent_order_id);
@@ -246,6 +681,9 @@ CREATE INDEX IF NOT EXISTS idx_supplier_order_items_stock ON supplier_order_item
 
 CREATE INDEX IF NOT EXISTS idx_supplier_order_docs_order ON supplier_order_documents(order_id);
 
+CREATE INDEX IF NOT EXISTS idx_supplier_order_receipts_order ON supplier_order_receipts(order_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_order_receipt_items_receipt ON supplier_order_receipt_items(receipt_id);
+
 CREATE INDEX IF NOT EXISTS idx_mp_shipments_store ON mp_shipments(store_id);
 CREATE INDEX IF NOT EXISTS idx_mp_shipments_warehouse ON mp_shipments(warehouse_id);
 CREATE INDEX IF NOT EXISTS idx_mp_shipments_status ON mp_shipments(status_id);
@@ -253,6 +691,7 @@ CREATE INDEX IF NOT EXISTS idx_mp_shipments_status ON mp_shipments(status_id);
 CREATE INDEX IF NOT EXISTS idx_mp_shipment_items_shipment ON mp_shipment_items(shipment_id);
 CREATE INDEX IF NOT EXISTS idx_mp_shipment_items_product ON mp_shipment_items(product_id);
 CREATE INDEX IF NOT EXISTS idx_mp_shipment_items_stock ON mp_shipment_items(product_id, warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active ON stock_reservations(product_id, warehouse_id) WHERE released_at IS NULL;
 
 CREATE INDEX IF NOT EXISTS idx_inventories_status ON inventories(status_id);
 
@@ -10,6 +10,34 @@ INSERT INTO user_roles (role_id, name) VALUES
 ('33333333-3333-3333-3333-333333333333', 'Кладовщик')
 ON CONFLICT (role_id) DO NOTHING;
 
+-- Администратор: полный доступ (повторяет выдачу из schema.sql, так как clear_all_data.sql очищает role_permissions)
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '11111111-1111-1111-1111-111111111111', p.permission_id
+FROM permissions p
+ON CONFLICT DO NOTHING;
+
+-- Менеджер: чтение всего, управление документами и основными справочниками,
+-- без управления пользователями, ролями и статусами
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '22222222-2222-2222-2222-222222222222', p.permission_id
+FROM permissions p
+WHERE p.action = 'read'
+   OR p.resource IN (
+        'products', 'warehouses', 'stores', 'supplier_orders', 'mp_shipments',
+        'inventories', 'transfers', 'mp_returns', 'storage_locations', 'product_costs', 'stock_snapshots', 'files'
+   )
+ON CONFLICT DO NOTHING;
+
+-- Кладовщик: чтение складских данных, работа с отгрузками и инвентаризациями,
+-- без удаления и без доступа к себестоимости, пользователям, ролям и журналу аудита
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '33333333-3333-3333-3333-333333333333', p.permission_id
+FROM permissions p
+WHERE (p.action = 'read' AND p.resource NOT IN ('product_costs', 'users', 'roles', 'audit'))
+   OR (p.action IN ('create', 'update') AND p.resource IN ('mp_shipments', 'inventories', 'transfers', 'mp_returns', 'storage_locations', 'files'))
+   OR (p.action = 'update' AND p.resource = 'supplier_orders')
+ON CONFLICT DO NOTHING;
+
 -- Пароль для всех тестовых пользователей: "password123" (bcrypt hash)
 -- Хеш сгенерирован с помощью: go run cmd/hash_password/main.go password123
 INSERT INTO users (user_id, email, name, surname, patronymic, password_hash, role_id) VALUES
@@ -0,0 +1,40 @@
+CREATE OR REPLACE VIEW vw_location_stock AS
+
+-- Остаток товара в месте хранения по проведенным перемещениям по ячейкам: приход в to_location_id,
+-- расход из from_location_id. Неразмещенный остаток склада - разница vw_current_stock (включая пары
+-- без снапшота) и суммы по местам.
+-- Расходные документы остаток ячеек не меняют: товар для них снимается с ячеек перемещением (отбор)
+WITH location_moves_flat AS (
+    SELECT
+        mi.product_id,
+        mi.to_location_id AS location_id,
+        mi.quantity
+    FROM location_move_items mi
+    JOIN location_moves m
+        ON m.move_id = mi.move_id
+    WHERE m.status = 'Проведен'
+      AND mi.to_location_id IS NOT NULL
+
+    UNION ALL
+
+    SELECT
+        mi.product_id,
+        mi.from_location_id,
+        -mi.quantity
+    FROM location_move_items mi
+    JOIN location_moves m
+        ON m.move_id = mi.move_id
+    WHERE m.status = 'Проведен'
+      AND mi.from_location_id IS NOT NULL
+)
+
+SELECT
+    sl.warehouse_id,
+    lm.location_id,
+    lm.product_id,
+    SUM(lm.quantity) AS quantity
+FROM location_moves_flat lm
+JOIN storage_locations sl
+    ON sl.location_id = lm.location_id
+GROUP BY sl.warehouse_id, lm.location_id, lm.product_id
+HAVING SUM(lm.quantity) <> 0;
\ No newline at end of file
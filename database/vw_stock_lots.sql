@@ -0,0 +1,19 @@
+CREATE OR REPLACE VIEW vw_stock_lots AS
+
+-- Партия - позиция полученного заказа поставщику: товар, склад приемки, дата поступления
+-- и себестоимость единицы. Идентификатор партии совпадает с movement_id прихода в vw_stock_movements
+SELECT
+    soi.order_item_id AS lot_id,
+    so.order_id,
+    so.order_number,
+    soi.product_id,
+    soi.warehouse_id,
+    so.actual_receipt_date AS receipt_date,
+    soi.received_qty AS quantity,
+    soi.unit_self_cost AS unit_cost
+FROM supplier_order_items soi
+JOIN supplier_orders so
+    ON so.order_id = soi.order_id
+WHERE so.actual_receipt_date IS NOT NULL
+  AND soi.product_id IS NOT NULL
+  AND soi.received_qty > 0;
\ No newline at end of file
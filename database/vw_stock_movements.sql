@@ -7,7 +7,8 @@ SELECT
     so.actual_receipt_date AS movement_date,
     soi.received_qty AS quantity,
     'SUPPLIER_RECEIPT' AS movement_type,
-    so.order_id AS document_id
+    so.order_id AS document_id,
+    soi.order_item_id AS movement_id
 FROM supplier_order_items soi
 JOIN supplier_orders so
     ON so.order_id = soi.order_id
@@ -15,30 +16,106 @@ WHERE so.actual_receipt_date IS NOT NULL
 
 UNION ALL
 
--- 2. Отгрузка на маркетплейсы
+-- 2. Отгрузка на маркетплейсы: отправленное количество списывается на дату отгрузки
 SELECT
     msi.product_id,
     msi.warehouse_id,
-    ms.acceptance_date AS movement_date,
-    -msi.accepted_qty AS quantity,
+    ms.shipment_date AS movement_date,
+    -msi.sent_qty AS quantity,
     'MP_SHIPMENT' AS movement_type,
-    ms.shipment_id AS document_id
+    ms.shipment_id AS document_id,
+    msi.shipment_item_id AS movement_id
 FROM mp_shipment_items msi
 JOIN mp_shipments ms
     ON ms.shipment_id = msi.shipment_id
-WHERE ms.acceptance_date IS NOT NULL
+JOIN shipment_statuses sst
+    ON sst.shipment_status_id = ms.status_id
+   AND sst.name IN ('Отправлен', 'В пути', 'Принят', 'Отклонен')
+WHERE ms.shipment_date IS NOT NULL
 
 UNION ALL
 
--- 3. Инвентаризация
+-- 3. Непринятый маркетплейсом товар, вернувшийся на склад после разбора расхождения
+SELECT
+    msi.product_id,
+    msi.warehouse_id,
+    ms.resolution_date AS movement_date,
+    msi.returned_qty AS quantity,
+    'MP_SHIPMENT_RETURN' AS movement_type,
+    ms.shipment_id AS document_id,
+    msi.return_movement_id AS movement_id
+FROM mp_shipment_items msi
+JOIN mp_shipments ms
+    ON ms.shipment_id = msi.shipment_id
+WHERE ms.resolution_date IS NOT NULL
+  AND msi.returned_qty > 0
+
+UNION ALL
+
+-- 4. Инвентаризация (только завершенные)
 SELECT
     ii.product_id,
     ii.warehouse_id,
     i.adjustment_date AS movement_date,
     (ii.receipt_qty - ii.write_off_qty) AS quantity,
     'INVENTORY_ADJUSTMENT' AS movement_type,
-    i.inventory_id AS document_id
+    i.inventory_id AS document_id,
+    ii.inventory_item_id AS movement_id
 FROM inventory_items ii
 JOIN inventories i
     ON i.inventory_id = ii.inventory_id
-WHERE i.adjustment_date IS NOT NULL;
\ No newline at end of file
+JOIN inventory_statuses ist
+    ON ist.inventory_status_id = i.status_id
+   AND ist.name = 'Завершена'
+WHERE i.adjustment_date IS NOT NULL
+
+UNION ALL
+
+-- 5. Перемещение: списание со склада-отправителя (в пути или получено)
+SELECT
+    ti.product_id,
+    t.source_warehouse_id AS warehouse_id,
+    t.shipment_date AS movement_date,
+    -ti.shipped_qty AS quantity,
+    'TRANSFER_OUT' AS movement_type,
+    t.transfer_id AS document_id,
+    ti.transfer_item_id AS movement_id
+FROM transfer_items ti
+JOIN transfers t
+    ON t.transfer_id = ti.transfer_id
+WHERE t.status IN ('В пути', 'Получено')
+  AND t.shipment_date IS NOT NULL
+
+UNION ALL
+
+-- 6. Перемещение: поступление на склад-получатель (только полученные)
+SELECT
+    ti.product_id,
+    t.destination_warehouse_id AS warehouse_id,
+    t.receipt_date AS movement_date,
+    ti.received_qty AS quantity,
+    'TRANSFER_IN' AS movement_type,
+    t.transfer_id AS document_id,
+    ti.receipt_movement_id AS movement_id
+FROM transfer_items ti
+JOIN transfers t
+    ON t.transfer_id = ti.transfer_id
+WHERE t.status = 'Получено'
+  AND t.receipt_date IS NOT NULL
+
+UNION ALL
+
+-- 7. Возвраты с маркетплейсов (только проведенные, без списанного товара)
+SELECT
+    ri.product_id,
+    r.warehouse_id,
+    r.return_date AS movement_date,
+    ri.quantity,
+    'MP_RETURN' AS movement_type,
+    r.return_id AS document_id,
+    ri.return_item_id AS movement_id
+FROM mp_return_items ri
+JOIN mp_returns r
+    ON r.return_id = ri.return_id
+WHERE r.status = 'Проведен'
+  AND ri.condition <> 'write_off';
\ No newline at end of file
@@ -17,7 +17,8 @@ SELECT
     m.movement_date,
     m.quantity,
     m.movement_type,
-    m.document_id
+    m.document_id,
+    m.movement_id
 FROM vw_stock_movements m
 JOIN last_snapshot ls
     ON ls.product_id = m.product_id
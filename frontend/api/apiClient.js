@@ -97,6 +97,7 @@ const api = {
       const queryParams = new URLSearchParams();
       if (params.limit) queryParams.append('limit', params.limit);
       if (params.offset) queryParams.append('offset', params.offset);
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
       const query = queryParams.toString();
       return await request(`/products${query ? `?${query}` : ''}`);
     },
@@ -126,6 +127,12 @@ const api = {
       return { success: true };
     },
 
+    restore: async (id) => {
+      return await request(`/products/${id}/restore`, {
+        method: 'POST',
+      });
+    },
+
     // Product images
     getImages: async (productId) => {
       return await request(`/products/${productId}/images`);
@@ -192,6 +199,7 @@ const api = {
       const queryParams = new URLSearchParams();
       if (params.limit) queryParams.append('limit', params.limit);
       if (params.offset) queryParams.append('offset', params.offset);
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
       const query = queryParams.toString();
       return await request(`/warehouses${query ? `?${query}` : ''}`);
     },
@@ -220,6 +228,12 @@ const api = {
       });
       return { success: true };
     },
+
+    restore: async (id) => {
+      return await request(`/warehouses/${id}/restore`, {
+        method: 'POST',
+      });
+    },
   },
 
   warehouseTypes: {
@@ -262,6 +276,7 @@ const api = {
       const queryParams = new URLSearchParams();
       if (params.limit) queryParams.append('limit', params.limit);
       if (params.offset) queryParams.append('offset', params.offset);
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
       const query = queryParams.toString();
       return await request(`/stores${query ? `?${query}` : ''}`);
     },
@@ -290,6 +305,12 @@ const api = {
       });
       return { success: true };
     },
+
+    restore: async (id) => {
+      return await request(`/stores/${id}/restore`, {
+        method: 'POST',
+      });
+    },
   },
 
   supplierOrders: {
@@ -331,6 +352,46 @@ const api = {
       return await request(`/supplier-orders/${orderId}/items`);
     },
 
+    receive: async (orderId, data) => {
+      return await request(`/supplier-orders/${orderId}/receive`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    getReceipts: async (orderId) => {
+      return await request(`/supplier-orders/${orderId}/receipts`);
+    },
+
+    splitRemainder: async (orderId, data = {}) => {
+      return await request(`/supplier-orders/${orderId}/split`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    createFromReorder: async (data = {}) => {
+      return await request('/supplier-orders/reorder', {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    transition: async (orderId, statusId, comment) => {
+      return await request(`/supplier-orders/${orderId}/transition`, {
+        method: 'POST',
+        body: { statusId, comment },
+      });
+    },
+
+    getHistory: async (orderId) => {
+      return await request(`/supplier-orders/${orderId}/history`);
+    },
+
+    getTree: async (orderId) => {
+      return await request(`/supplier-orders/${orderId}/tree`);
+    },
+
     getDocuments: async (orderId) => {
       return await request(`/supplier-orders/${orderId}/documents`);
     },
@@ -430,6 +491,37 @@ const api = {
     getItems: async (shipmentId) => {
       return await request(`/mp-shipments/${shipmentId}/items`);
     },
+
+    transition: async (shipmentId, statusId, comment) => {
+      return await request(`/mp-shipments/${shipmentId}/transition`, {
+        method: 'POST',
+        body: { statusId, comment },
+      });
+    },
+
+    resolveDiscrepancy: async (shipmentId, data) => {
+      return await request(`/mp-shipments/${shipmentId}/resolve-discrepancy`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    getHistory: async (shipmentId) => {
+      return await request(`/mp-shipments/${shipmentId}/history`);
+    },
+
+    getCost: async (shipmentId) => {
+      return await request(`/mp-shipments/${shipmentId}/cost`);
+    },
+
+    getCosts: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.dateFrom) queryParams.append('dateFrom', params.dateFrom);
+      if (params.dateTo) queryParams.append('dateTo', params.dateTo);
+      if (params.storeId) queryParams.append('storeId', params.storeId);
+      const query = queryParams.toString();
+      return await request(`/mp-shipments/costs${query ? `?${query}` : ''}`);
+    },
   },
 
   mpShipmentItems: {
@@ -459,11 +551,70 @@ const api = {
     },
   },
 
+  mpReturns: {
+    list: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.limit) queryParams.append('limit', params.limit);
+      if (params.offset) queryParams.append('offset', params.offset);
+      if (params.storeId) queryParams.append('storeId', params.storeId);
+      if (params.shipmentId) queryParams.append('shipmentId', params.shipmentId);
+      if (params.status) queryParams.append('status', params.status);
+      const query = queryParams.toString();
+      return await request(`/mp-returns${query ? `?${query}` : ''}`);
+    },
+
+    get: async (id) => {
+      return await request(`/mp-returns/${id}`);
+    },
+
+    // data: { returnNumber, storeId, shipmentId, warehouseId, returnType, returnDate, notes,
+    //         items: [{ productId, quantity, condition }] }
+    create: async (data) => {
+      return await request('/mp-returns', {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    update: async (id, data) => {
+      return await request(`/mp-returns/${id}`, {
+        method: 'PUT',
+        body: data,
+      });
+    },
+
+    delete: async (id) => {
+      await request(`/mp-returns/${id}`, {
+        method: 'DELETE',
+      });
+      return { success: true };
+    },
+
+    getHistory: async (returnId) => {
+      return await request(`/mp-returns/${returnId}/history`);
+    },
+
+    post: async (returnId, comment) => {
+      return await request(`/mp-returns/${returnId}/post`, {
+        method: 'POST',
+        body: { comment },
+      });
+    },
+
+    cancel: async (returnId, comment) => {
+      return await request(`/mp-returns/${returnId}/cancel`, {
+        method: 'POST',
+        body: { comment },
+      });
+    },
+  },
+
   orderStatuses: {
     list: async (params = {}) => {
       const queryParams = new URLSearchParams();
       if (params.limit) queryParams.append('limit', params.limit);
       if (params.offset) queryParams.append('offset', params.offset);
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
       const query = queryParams.toString();
       return await request(`/order-statuses${query ? `?${query}` : ''}`);
     },
@@ -492,6 +643,12 @@ const api = {
       });
       return { success: true };
     },
+
+    restore: async (id) => {
+      return await request(`/order-statuses/${id}/restore`, {
+        method: 'POST',
+      });
+    },
   },
 
   shipmentStatuses: {
@@ -499,6 +656,7 @@ const api = {
       const queryParams = new URLSearchParams();
       if (params.limit) queryParams.append('limit', params.limit);
       if (params.offset) queryParams.append('offset', params.offset);
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
       const query = queryParams.toString();
       return await request(`/shipment-statuses${query ? `?${query}` : ''}`);
     },
@@ -527,6 +685,12 @@ const api = {
       });
       return { success: true };
     },
+
+    restore: async (id) => {
+      return await request(`/shipment-statuses/${id}/restore`, {
+        method: 'POST',
+      });
+    },
   },
 
   inventoryStatuses: {
@@ -534,6 +698,7 @@ const api = {
       const queryParams = new URLSearchParams();
       if (params.limit) queryParams.append('limit', params.limit);
       if (params.offset) queryParams.append('offset', params.offset);
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
       const query = queryParams.toString();
       return await request(`/inventory-statuses${query ? `?${query}` : ''}`);
     },
@@ -562,6 +727,12 @@ const api = {
       });
       return { success: true };
     },
+
+    restore: async (id) => {
+      return await request(`/inventory-statuses/${id}/restore`, {
+        method: 'POST',
+      });
+    },
   },
 
   inventories: {
@@ -602,6 +773,213 @@ const api = {
     getItems: async (inventoryId) => {
       return await request(`/inventories/${inventoryId}/items`);
     },
+
+    transition: async (inventoryId, statusId, comment) => {
+      return await request(`/inventories/${inventoryId}/transition`, {
+        method: 'POST',
+        body: { statusId, comment },
+      });
+    },
+
+    getHistory: async (inventoryId) => {
+      return await request(`/inventories/${inventoryId}/history`);
+    },
+
+    getCount: async (inventoryId) => {
+      return await request(`/inventories/${inventoryId}/count`);
+    },
+
+    startCount: async (inventoryId, warehouseId) => {
+      return await request(`/inventories/${inventoryId}/count`, {
+        method: 'POST',
+        body: { warehouseId },
+      });
+    },
+
+    // data: { productId } или { barcode }, quantity, replace
+    recordCount: async (inventoryId, data) => {
+      return await request(`/inventories/${inventoryId}/count/lines`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+  },
+
+  transfers: {
+    list: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.limit) queryParams.append('limit', params.limit);
+      if (params.offset) queryParams.append('offset', params.offset);
+      if (params.status) queryParams.append('status', params.status);
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      const query = queryParams.toString();
+      return await request(`/transfers${query ? `?${query}` : ''}`);
+    },
+
+    get: async (id) => {
+      return await request(`/transfers/${id}`);
+    },
+
+    create: async (data) => {
+      return await request('/transfers', {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    update: async (id, data) => {
+      return await request(`/transfers/${id}`, {
+        method: 'PUT',
+        body: data,
+      });
+    },
+
+    delete: async (id) => {
+      await request(`/transfers/${id}`, {
+        method: 'DELETE',
+      });
+      return { success: true };
+    },
+
+    getHistory: async (transferId) => {
+      return await request(`/transfers/${transferId}/history`);
+    },
+
+    // data: { shipmentDate, comment }
+    ship: async (transferId, data = {}) => {
+      return await request(`/transfers/${transferId}/ship`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    // data: { receiptDate, items: [{ productId, receivedQty }], comment }
+    receive: async (transferId, data = {}) => {
+      return await request(`/transfers/${transferId}/receive`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    cancel: async (transferId, comment) => {
+      return await request(`/transfers/${transferId}/cancel`, {
+        method: 'POST',
+        body: { comment },
+      });
+    },
+  },
+
+  storageLocations: {
+    list: async (warehouseId, params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
+      const query = queryParams.toString();
+      return await request(`/warehouses/${warehouseId}/locations${query ? `?${query}` : ''}`);
+    },
+
+    get: async (warehouseId, locationId) => {
+      return await request(`/warehouses/${warehouseId}/locations/${locationId}`);
+    },
+
+    // data: { parentId, locationType, code, name }
+    create: async (warehouseId, data) => {
+      return await request(`/warehouses/${warehouseId}/locations`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    update: async (warehouseId, locationId, data) => {
+      return await request(`/warehouses/${warehouseId}/locations/${locationId}`, {
+        method: 'PUT',
+        body: data,
+      });
+    },
+
+    delete: async (warehouseId, locationId) => {
+      await request(`/warehouses/${warehouseId}/locations/${locationId}`, {
+        method: 'DELETE',
+      });
+      return { success: true };
+    },
+
+    restore: async (warehouseId, locationId) => {
+      return await request(`/warehouses/${warehouseId}/locations/${locationId}/restore`, {
+        method: 'POST',
+      });
+    },
+
+    getStock: async (warehouseId) => {
+      return await request(`/warehouses/${warehouseId}/locations/stock`);
+    },
+
+    getPutAway: async (warehouseId, params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.orderId) queryParams.append('orderId', params.orderId);
+      const query = queryParams.toString();
+      return await request(`/warehouses/${warehouseId}/locations/put-away${query ? `?${query}` : ''}`);
+    },
+
+    // data: { moveNumber, orderId, notes }
+    createPutAway: async (warehouseId, data = {}) => {
+      return await request(`/warehouses/${warehouseId}/locations/put-away`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    listMoves: async (warehouseId, params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.limit) queryParams.append('limit', params.limit);
+      if (params.offset) queryParams.append('offset', params.offset);
+      if (params.status) queryParams.append('status', params.status);
+      const query = queryParams.toString();
+      return await request(`/warehouses/${warehouseId}/locations/moves${query ? `?${query}` : ''}`);
+    },
+
+    getMove: async (warehouseId, moveId) => {
+      return await request(`/warehouses/${warehouseId}/locations/moves/${moveId}`);
+    },
+
+    // data: { moveNumber, notes, items: [{ productId, fromLocationId, toLocationId, quantity }] }
+    createMove: async (warehouseId, data) => {
+      return await request(`/warehouses/${warehouseId}/locations/moves`, {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    updateMove: async (warehouseId, moveId, data) => {
+      return await request(`/warehouses/${warehouseId}/locations/moves/${moveId}`, {
+        method: 'PUT',
+        body: data,
+      });
+    },
+
+    deleteMove: async (warehouseId, moveId) => {
+      await request(`/warehouses/${warehouseId}/locations/moves/${moveId}`, {
+        method: 'DELETE',
+      });
+      return { success: true };
+    },
+
+    getMoveHistory: async (warehouseId, moveId) => {
+      return await request(`/warehouses/${warehouseId}/locations/moves/${moveId}/history`);
+    },
+
+    postMove: async (warehouseId, moveId, comment) => {
+      return await request(`/warehouses/${warehouseId}/locations/moves/${moveId}/post`, {
+        method: 'POST',
+        body: { comment },
+      });
+    },
+
+    cancelMove: async (warehouseId, moveId, comment) => {
+      return await request(`/warehouses/${warehouseId}/locations/moves/${moveId}/cancel`, {
+        method: 'POST',
+        body: { comment },
+      });
+    },
   },
 
   inventoryItems: {
@@ -641,6 +1019,14 @@ const api = {
       return await request(`/product-costs${query ? `?${query}` : ''}`);
     },
 
+    getCoverage: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.dateFrom) queryParams.append('dateFrom', params.dateFrom);
+      if (params.dateTo) queryParams.append('dateTo', params.dateTo);
+      if (params.productId) queryParams.append('productId', params.productId);
+      return await request(`/product-costs/coverage?${queryParams.toString()}`);
+    },
+
     get: async (id) => {
       return await request(`/product-costs/${id}`);
     },
@@ -702,6 +1088,38 @@ const api = {
       });
       return { success: true };
     },
+
+    generate: async () => {
+      return await request('/stock-snapshots/generate', {
+        method: 'POST',
+      });
+    },
+
+    listRuns: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.limit) queryParams.append('limit', params.limit);
+      if (params.offset) queryParams.append('offset', params.offset);
+      const query = queryParams.toString();
+      return await request(`/stock-snapshots/runs${query ? `?${query}` : ''}`);
+    },
+
+    verify: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      if (params.productId) queryParams.append('productId', params.productId);
+      const query = queryParams.toString();
+      return await request(`/stock-snapshots/verify${query ? `?${query}` : ''}`);
+    },
+
+    repair: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      if (params.productId) queryParams.append('productId', params.productId);
+      const query = queryParams.toString();
+      return await request(`/stock-snapshots/verify/repair${query ? `?${query}` : ''}`, {
+        method: 'POST',
+      });
+    },
   },
 
   stock: {
@@ -712,6 +1130,97 @@ const api = {
       const query = queryParams.toString();
       return await request(`/stock/current${query ? `?${query}` : ''}`);
     },
+
+    getAsOf: async (date, params = {}) => {
+      const queryParams = new URLSearchParams();
+      queryParams.append('date', date);
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      if (params.productId) queryParams.append('productId', params.productId);
+      if (params.limit) queryParams.append('limit', params.limit);
+      if (params.offset) queryParams.append('offset', params.offset);
+      return await request(`/stock/as-of?${queryParams.toString()}`);
+    },
+
+    getMovements: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.productId) queryParams.append('productId', params.productId);
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      if (params.movementType) queryParams.append('movementType', params.movementType);
+      if (params.documentId) queryParams.append('documentId', params.documentId);
+      if (params.dateFrom) queryParams.append('dateFrom', params.dateFrom);
+      if (params.dateTo) queryParams.append('dateTo', params.dateTo);
+      if (params.cursor) queryParams.append('cursor', params.cursor);
+      if (params.limit) queryParams.append('limit', params.limit);
+      const query = queryParams.toString();
+      return await request(`/stock/movements${query ? `?${query}` : ''}`);
+    },
+
+    getAvailability: async (productId, warehouseId) => {
+      const queryParams = new URLSearchParams();
+      queryParams.append('productId', productId);
+      queryParams.append('warehouseId', warehouseId);
+      return await request(`/stock/availability?${queryParams.toString()}`);
+    },
+
+    getNegative: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.productId) queryParams.append('productId', params.productId);
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      if (params.limit) queryParams.append('limit', params.limit);
+      const query = queryParams.toString();
+      return await request(`/stock/negative${query ? `?${query}` : ''}`);
+    },
+
+    getReorderSuggestions: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.productId) queryParams.append('productId', params.productId);
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      const query = queryParams.toString();
+      return await request(`/stock/reorder-suggestions${query ? `?${query}` : ''}`);
+    },
+
+    getLots: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.productId) queryParams.append('productId', params.productId);
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      if (params.includeEmpty) queryParams.append('includeEmpty', 'true');
+      const query = queryParams.toString();
+      return await request(`/stock/lots${query ? `?${query}` : ''}`);
+    },
+
+    listLevels: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.limit) queryParams.append('limit', params.limit);
+      if (params.offset) queryParams.append('offset', params.offset);
+      if (params.productId) queryParams.append('productId', params.productId);
+      if (params.warehouseId) queryParams.append('warehouseId', params.warehouseId);
+      const query = queryParams.toString();
+      return await request(`/stock/levels${query ? `?${query}` : ''}`);
+    },
+
+    getLevel: async (id) => {
+      return await request(`/stock/levels/${id}`);
+    },
+
+    createLevel: async (data) => {
+      return await request('/stock/levels', {
+        method: 'POST',
+        body: data,
+      });
+    },
+
+    updateLevel: async (id, data) => {
+      return await request(`/stock/levels/${id}`, {
+        method: 'PUT',
+        body: data,
+      });
+    },
+
+    deleteLevel: async (id) => {
+      return await request(`/stock/levels/${id}`, {
+        method: 'DELETE',
+      });
+    },
   },
 
   users: {
@@ -719,6 +1228,7 @@ const api = {
       const queryParams = new URLSearchParams();
       if (params.limit) queryParams.append('limit', params.limit);
       if (params.offset) queryParams.append('offset', params.offset);
+      if (params.includeArchived) queryParams.append('includeArchived', 'true');
       const query = queryParams.toString();
       return await request(`/users${query ? `?${query}` : ''}`);
     },
@@ -747,6 +1257,12 @@ const api = {
       });
       return { success: true };
     },
+
+    restore: async (id) => {
+      return await request(`/users/${id}/restore`, {
+        method: 'POST',
+      });
+    },
   },
 
   roles: {
@@ -782,6 +1298,39 @@ const api = {
       });
       return { success: true };
     },
+
+    getPermissions: async (id) => {
+      return await request(`/roles/${id}/permissions`);
+    },
+
+    updatePermissions: async (id, permissions) => {
+      return await request(`/roles/${id}/permissions`, {
+        method: 'PUT',
+        body: { permissions },
+      });
+    },
+  },
+
+  permissions: {
+    list: async () => {
+      return await request('/permissions');
+    },
+  },
+
+  audit: {
+    list: async (params = {}) => {
+      const queryParams = new URLSearchParams();
+      if (params.entityType) queryParams.append('entityType', params.entityType);
+      if (params.entityId) queryParams.append('entityId', params.entityId);
+      if (params.userId) queryParams.append('userId', params.userId);
+      if (params.action) queryParams.append('action', params.action);
+      if (params.dateFrom) queryParams.append('dateFrom', params.dateFrom);
+      if (params.dateTo) queryParams.append('dateTo', params.dateTo);
+      if (params.limit) queryParams.append('limit', params.limit);
+      if (params.offset) queryParams.append('offset', params.offset);
+      const query = queryParams.toString();
+      return await request(`/audit${query ? `?${query}` : ''}`);
+    },
   },
 
   upload: {